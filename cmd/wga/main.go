@@ -13,6 +13,9 @@ import (
 	"github.com/blackfyre/wga/internal/migrations"
 
 	"github.com/blackfyre/wga/internal/utils"
+	"github.com/blackfyre/wga/internal/utils/fulltext"
+	"github.com/blackfyre/wga/internal/utils/imagehash"
+	"github.com/blackfyre/wga/internal/utils/palette"
 	"github.com/blackfyre/wga/internal/utils/seed"
 	"github.com/blackfyre/wga/internal/utils/sitemap"
 
@@ -90,6 +93,22 @@ func main() {
 		},
 	})
 
+	app.RootCmd.AddCommand(&cobra.Command{
+		Use:   "rebuild-search-index",
+		Short: "Rebuild the full-text search index from scratch",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := fulltext.Rebuild(app); err != nil {
+				log.Fatal(err)
+			}
+
+			log.Println("Done rebuilding the search index")
+		},
+	})
+
+	app.RootCmd.AddCommand(findDuplicateImagesCommand(app))
+
+	app.RootCmd.AddCommand(extractPalettesCommand(app))
+
 	if runtimeConfig.Environment().IsDevelopment() {
 		app.RootCmd.AddCommand(&cobra.Command{
 			Use:   "seed:images",
@@ -133,7 +152,7 @@ func commandCapabilityFor(args []string) commandCapability {
 		switch arg {
 		case "generate-sitemap":
 			return commandNeedsSitemap
-		case "migrate", "generate-music-urls", "seed:images", "superuser":
+		case "migrate", "generate-music-urls", "seed:images", "superuser", "rebuild-search-index", "find-duplicate-images", "extract-palettes":
 			return commandNeedsNothing
 		case "serve":
 			return commandNeedsServer
@@ -144,3 +163,85 @@ func commandCapabilityFor(args []string) commandCapability {
 
 	return commandNeedsServer
 }
+
+func findDuplicateImagesCommand(app *pocketbase.PocketBase) *cobra.Command {
+	var distance int
+	var hash, format, output string
+	var rehash bool
+
+	cmd := &cobra.Command{
+		Use:   "find-duplicate-images",
+		Short: "Hash the artwork images and report clusters of near-duplicates",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kind, err := imagehash.ParseKind(hash)
+			if err != nil {
+				return err
+			}
+
+			if err := imagehash.ValidateFormat(format); err != nil {
+				return err
+			}
+
+			hashed, err := imagehash.HashMissing(app, rehash)
+			if err != nil {
+				return err
+			}
+			log.Printf("Hashed %d artwork images", hashed)
+
+			entries, err := imagehash.Entries(app, kind)
+			if err != nil {
+				return err
+			}
+
+			clusters := imagehash.Clusters(entries, distance)
+
+			w := os.Stdout
+			if output != "" {
+				w, err = os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer w.Close()
+			}
+
+			if err := imagehash.WriteReport(w, format, kind, distance, clusters); err != nil {
+				return err
+			}
+
+			log.Printf("Found %d clusters of near-duplicate images among %d artworks", len(clusters), len(entries))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&distance, "distance", imagehash.DefaultDistance, "maximum Hamming distance between near-duplicate images")
+	cmd.Flags().StringVar(&hash, "hash", string(imagehash.PHash), "hash to compare: ahash, dhash or phash")
+	cmd.Flags().StringVar(&format, "format", imagehash.FormatJSON, "report format: json or csv")
+	cmd.Flags().StringVar(&output, "output", "", "file to write the report to, instead of the standard output")
+	cmd.Flags().BoolVar(&rehash, "rehash", false, "hash every image again, not only those without up to date hashes")
+
+	return cmd
+}
+
+func extractPalettesCommand(app *pocketbase.PocketBase) *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "extract-palettes",
+		Short: "Extract the dominant colours of the artwork images without a palette",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			extracted, err := palette.ExtractMissing(app, all)
+			if err != nil {
+				return err
+			}
+
+			log.Printf("Extracted the palettes of %d artwork images", extracted)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "extract the palette of every image again, not only of those without an up to date palette")
+
+	return cmd
+}
@@ -19,6 +19,9 @@ func TestCommandCapabilityFor(t *testing.T) {
 		{name: "migration", args: []string{"migrate", "up"}, want: commandNeedsNothing},
 		{name: "migration collections", args: []string{"migrate", "collections"}, want: commandNeedsNothing},
 		{name: "music URLs", args: []string{"generate-music-urls"}, want: commandNeedsNothing},
+		{name: "search index rebuild", args: []string{"rebuild-search-index"}, want: commandNeedsNothing},
+		{name: "duplicate images", args: []string{"find-duplicate-images", "--format", "csv"}, want: commandNeedsNothing},
+		{name: "palettes", args: []string{"extract-palettes", "--all"}, want: commandNeedsNothing},
 		{name: "unknown command", args: []string{"not-a-command"}, want: commandNeedsNothing},
 		{name: "server data directory", args: []string{"--dir", "test_data"}, want: commandNeedsServer},
 		{name: "migration data directory", args: []string{"--dir", "test_data", "migrate", "up"}, want: commandNeedsNothing},
@@ -1,8 +1,10 @@
 package dto
 
 type Artist struct {
-	Id              string
-	Name            string
+	Id   string
+	Name string
+	// OtherNames holds the aliases, variants and sort keys of the name.
+	OtherNames      []ArtistName
 	BornDied        string
 	Schools         string
 	Profession      string
@@ -13,6 +15,40 @@ type Artist struct {
 	Works           ImageGrid
 	HxTarget        string
 	ShowBreadcrumbs bool
+	// Relations groups the related artists by how they are related.
+	Relations  []ArtistRelationGroup
+	NetworkUrl string
+}
+
+// ArtistRelationGroup lists the artists related to an artist in the same way, like "Pupil of".
+type ArtistRelationGroup struct {
+	Label   string
+	Artists []ArtistRelationLink
+}
+
+// ArtistRelationLink is a related artist, with the source of the relationship when there is one.
+type ArtistRelationLink struct {
+	Name      string
+	Url       string
+	Source    string
+	SourceUrl string
+}
+
+// ArtistNetworkView is the relationship network page of an artist.
+type ArtistNetworkView struct {
+	Artist Artist
+	// DataUrl is the url of the JSON graph the page draws.
+	DataUrl string
+	Depth   int
+}
+
+// ArtistName is a name an artist is known by besides their name.
+type ArtistName struct {
+	Name string
+	// Lang is the BCP 47 language tag of the name, empty when it does not matter.
+	Lang string
+	// SortKey is set for the name in index order, like "Gogh, Vincent van".
+	SortKey bool
 }
 
 type ArtistsView struct {
@@ -22,40 +58,226 @@ type ArtistsView struct {
 	Jsonld     string
 	QueryStr   string
 	HxTarget   string
+	Filters    ArtistFilterValues
+	// Letters is the A to Z index, with the number of artists matching the other filters.
+	Letters        []ArtistLetter
+	SchoolOptions  []SearchFacetOption
+	CenturyOptions []SearchFacetOption
+}
+
+// ArtistFilterValues are the filters of the artist list, as the query carries them.
+type ArtistFilterValues struct {
+	Letter     string
+	School     string
+	Profession string
+	Century    string
+	BornIn     string
+	DiedIn     string
+}
+
+// ArtistLetter is a letter of the artist index.
+type ArtistLetter struct {
+	Letter string
+	Count  int
+	Url    string
+	Active bool
 }
 
 type Artwork struct {
-	Id              string
-	Title           string
-	Comment         string
-	Technique       string
-	Jsonld          string
-	Url             string
+	Id        string
+	Title     string
+	Comment   string
+	Technique string
+	// Date is the display string of the dating of the artwork, empty when undated.
+	Date     string
+	Jsonld   string
+	Url      string
+	TilesUrl string
+	// RelatedUrl is the url of the "more like this" fragment, which is not loaded when empty.
+	RelatedUrl string
+	// Palette holds the dominant colours of the image, the most dominant first.
+	Palette []ColorSwatch
+	// AttributionHistory holds the reattributions of the artwork, the latest first.
+	AttributionHistory []AttributionChange
+	// Location is where the artwork is held, nil when it is not known.
+	Location *ArtworkLocation
+	// Series is the series the artwork is part of, nil when it is not part of one.
+	Series *ArtworkSeries
+	// Gallery holds the main image followed by the other images of the artwork, empty when the
+	// artwork has the main image only.
+	Gallery []ArtworkImage
+	// Comparison is the before and after restoration view, nil when there is nothing to compare.
+	Comparison      *ImageComparison
 	HxTarget        string
 	ShowBreadcrumbs bool
 	Image
 	Artist
 }
 
+// ArtworkImage is one of the images of an artwork in its gallery.
+type ArtworkImage struct {
+	// Kind is the label of the kind of the image, like "Verso".
+	Kind    string
+	Caption string
+	Image   string
+	Thumb   string
+}
+
+// Label returns the kind of the image with its caption, like "Detail: the donor".
+func (i ArtworkImage) Label() string {
+	if i.Caption == "" {
+		return i.Kind
+	}
+
+	return i.Kind + ": " + i.Caption
+}
+
+// ImageComparison is an image of an artwork before its restoration with one after it.
+type ImageComparison struct {
+	Before ArtworkImage
+	After  ArtworkImage
+}
+
+// ArtworkSeries is the series an artwork is part of as its page shows it.
+type ArtworkSeries struct {
+	Title string
+	Url   string
+	// Part is the position label of the artwork in the series, like "predella, left panel".
+	Part string
+	// Previous and Next are the neighbouring parts of the artwork, nil at the ends of the series.
+	Previous *SeriesPart
+	Next     *SeriesPart
+	Parts    []SeriesPart
+}
+
+// SeriesPart is a part of a series in the series navigation and overview.
+type SeriesPart struct {
+	Id    string
+	Title string
+	// Label is the position label of the part, empty when the series does not give one.
+	Label  string
+	Credit string
+	Url    string
+	Thumb  string
+	// Current is set on the part whose page shows the navigation.
+	Current bool
+}
+
+// Name returns the label of the part when it has one, its title otherwise.
+func (p SeriesPart) Name() string {
+	if p.Label != "" {
+		return p.Label
+	}
+
+	return p.Title
+}
+
+// ArtworkLocation is the location holding an artwork as its page shows it.
+type ArtworkLocation struct {
+	Name    string
+	Url     string
+	City    string
+	CityUrl string
+	// Room is the room or chapel the artwork is in, empty when not known.
+	Room            string
+	InventoryNumber string
+}
+
+// Details returns the room and the inventory number following the city, like
+// ", Room 12, inv. no. P000386", empty when neither is known.
+func (l ArtworkLocation) Details() string {
+	details := ""
+
+	if l.Room != "" {
+		details += ", " + l.Room
+	}
+
+	if l.InventoryNumber != "" {
+		details += ", inv. no. " + l.InventoryNumber
+	}
+
+	return details
+}
+
+// AttributionChange is a reattribution of an artwork as the timeline of its page shows it.
+type AttributionChange struct {
+	Date string
+	// Previous and Current are the credits before and after the change, empty for no author.
+	Previous string
+	Current  string
+	// Source is the citation of the new attribution, empty when none was given.
+	Source string
+}
+
+// CreditParts returns the credit of the artwork page, with the technique after the last author.
+func (a Artwork) CreditParts() []CreditPart {
+	parts := a.Image.CreditParts()
+
+	if a.Technique != "" {
+		parts[len(parts)-1].Suffix += ", " + a.Technique
+	}
+
+	return parts
+}
+
+// ColorSwatch is a dominant colour of an artwork image.
+type ColorSwatch struct {
+	Color string
+	// Percent is the share of the image the colour covers.
+	Percent   int
+	SearchUrl string
+}
+
 type ArtworkSearchDTO struct {
-	ArtFormOptions     map[string]string
-	ArtTypeOptions     map[string]string
-	ArtSchoolOptions   map[string]string
+	Facets             []SearchFacet
+	ArtPeriodOptions   map[string]string
 	ActiveFilterValues *ArtworkSearchFilterValues
 	ArtistNameList     map[string]string
-	NewFilterValues    string
-	ClearUrl           string
-	DualModeContext    *ArtworkSearchDualModeDto
-	Results            ArtworkSearchResultDTO
-	HxTarget           string
+	// ColorNames are the colour names the colour filter understands besides hex codes.
+	ColorNames []string
+	// LocationOptions are the locations holding artworks, CityNames the cities they are in.
+	LocationOptions []SearchFacetOption
+	CityNames       []string
+	NewFilterValues string
+	ClearUrl        string
+	DualModeContext *ArtworkSearchDualModeDto
+	Results         ArtworkSearchResultDTO
+	HxTarget        string
 }
 
 type ArtworkSearchFilterValues struct {
-	ArtFormString string
-	ArtTypeString string
-	SchoolString  string
-	Title         string
-	ArtistString  string
+	Title        string
+	ArtistString string
+	// ArtistExtra holds further typed-in artist names, which have no input of their own.
+	ArtistExtra  []string
+	PeriodString string
+	YearFrom     string
+	YearTo       string
+	Query        string
+	Color        string
+	Attribution  string
+	Location     string
+	City         string
+	// CollapseSeries shows every series once in the results.
+	CollapseSeries bool
+}
+
+// SearchFacet is a search filter whose options can be picked together, matching any of
+// them, or excluded. Picked options are sent as Name, excluded ones as Name + "_not".
+type SearchFacet struct {
+	Name    string
+	Label   string
+	Options []SearchFacetOption
+}
+
+// SearchFacetOption is an option of a search facet with the number of artworks it matches
+// together with the other active filters.
+type SearchFacetOption struct {
+	Value    string
+	Label    string
+	Count    int
+	Included bool
+	Excluded bool
 }
 
 type ArtworkSearchResultDTO struct {
@@ -63,10 +285,12 @@ type ArtworkSearchResultDTO struct {
 	Artworks        ImageGrid
 	ResultCount     int
 	ResultSummary   string
-	Pagination      string
-	HxTarget        string
-	DualModeUrls    map[string]string
-	DualModeTarget  string
+	// QueryError explains why the search query could not be parsed.
+	QueryError     string
+	Pagination     string
+	HxTarget       string
+	DualModeUrls   map[string]string
+	DualModeTarget string
 }
 
 type ArtworkSearchDualModeDto struct {
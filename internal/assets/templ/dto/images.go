@@ -1,5 +1,10 @@
 package dto
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Image struct {
 	Thumb     string
 	Image     string
@@ -10,7 +15,120 @@ type Image struct {
 	Id        string
 	Jsonld    interface{}
 	HxTarget  string
+	// Authors are the credited authors of the artwork, in order. Artist is the first of them.
+	Authors []ArtworkAuthor
+	// Series is the series the image stands for in collapsed search results, nil otherwise.
+	Series *ImageSeries
 	Artist
 }
 
+// ImageSeries is the series an image stands for, with the number of its parts.
+type ImageSeries struct {
+	Title string
+	Url   string
+	Parts int
+}
+
+// PartsNote returns the number of parts following the title of the series, like ", 7 parts",
+// empty for a series of a single part.
+func (s ImageSeries) PartsNote() string {
+	if s.Parts < 2 {
+		return ""
+	}
+
+	return fmt.Sprintf(", %d parts", s.Parts)
+}
+
+// ArtworkAuthor is an artist credited with an artwork.
+type ArtworkAuthor struct {
+	Name string
+	Url  string
+	// Qualifier qualifies the attribution, like "Workshop of", empty for the artist's own work.
+	Qualifier string
+}
+
+// Label returns the name of the author with the qualifier, like "Workshop of Rubens".
+func (a ArtworkAuthor) Label() string {
+	if a.Qualifier == "" {
+		return a.Name
+	}
+
+	return a.Qualifier + " " + a.Name
+}
+
+// Credit returns the authors of the image as a sentence, like "Rubens and workshop of Jan Brueghel".
+// Images without authors are credited to their artist.
+func (i Image) Credit() string {
+	if len(i.Authors) == 0 {
+		return i.Artist.Name
+	}
+
+	labels := make([]string, 0, len(i.Authors))
+	for index, a := range i.Authors {
+		if index > 0 && a.Qualifier != "" {
+			a.Qualifier = strings.ToLower(a.Qualifier[:1]) + a.Qualifier[1:]
+		}
+
+		labels = append(labels, a.Label())
+	}
+
+	if len(labels) == 1 {
+		return labels[0]
+	}
+
+	return strings.Join(labels[:len(labels)-1], ", ") + " and " + labels[len(labels)-1]
+}
+
+// CreditPart is an author as they read in the credit following the title of an image, with
+// the words before their name, like "by" or "and workshop of", and the punctuation after it.
+type CreditPart struct {
+	Prefix string
+	Suffix string
+	ArtworkAuthor
+}
+
+// CreditParts returns the authors of the image as they read in the credit following its title.
+// Images without authors are credited to their artist.
+func (i Image) CreditParts() []CreditPart {
+	authors := i.Authors
+	if len(authors) == 0 {
+		authors = []ArtworkAuthor{{Name: i.Artist.Name, Url: i.Artist.Url}}
+	}
+
+	parts := make([]CreditPart, 0, len(authors))
+	for index, a := range authors {
+		part := CreditPart{ArtworkAuthor: a}
+
+		if index > 0 && index == len(authors)-1 {
+			part.Prefix = "and "
+		}
+
+		if index < len(authors)-2 {
+			part.Suffix = ","
+		}
+
+		switch {
+		case a.Qualifier != "":
+			part.Prefix += strings.ToLower(a.Qualifier[:1]) + a.Qualifier[1:] + " "
+		case index == 0:
+			part.Prefix = "by "
+		}
+
+		parts = append(parts, part)
+	}
+
+	return parts
+}
+
+// ByLine returns the credit following the title of the image, like "by Rubens" or
+// "attributed to Giorgione".
+func (i Image) ByLine() string {
+	words := []string{}
+	for _, p := range i.CreditParts() {
+		words = append(words, p.Prefix+p.Name+p.Suffix)
+	}
+
+	return strings.Join(words, " ")
+}
+
 type ImageGrid []Image
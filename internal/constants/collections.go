@@ -1,16 +1,30 @@
 package constants
 
 const (
-	CollectionArtists     = "artists"
-	CollectionArtworks    = "artworks"
-	CollectionArtForms    = "art_forms"
-	CollectionArtTypes    = "art_types"
-	CollectionFeedbacks   = "feedbacks"
-	CollectionGuestbook   = "guestbook"
-	CollectionPostcards   = "postcards"
-	CollectionStaticPages = "static_pages"
-	CollectionStrings     = "strings"
-	CollectionSchools     = "schools"
-	CollectionGlossary    = "Glossary"
-	CacheGuestbookYears   = "guestbook:years"
+	CollectionArtists             = "artists"
+	CollectionArtworks            = "artworks"
+	CollectionArtForms            = "art_forms"
+	CollectionArtTypes            = "art_types"
+	CollectionFeedbacks           = "feedbacks"
+	CollectionGuestbook           = "guestbook"
+	CollectionPostcards           = "postcards"
+	CollectionPostcardDeliveries  = "postcard_deliveries"
+	CollectionStaticPages         = "static_pages"
+	CollectionStrings             = "strings"
+	CollectionSchools             = "schools"
+	CollectionGlossary            = "Glossary"
+	CollectionMusicComposers      = "music_composer"
+	CollectionMusicSongs          = "music_song"
+	CollectionUsers               = "users"
+	CollectionFavourites          = "favourites"
+	CollectionUserCollections     = "user_collections"
+	CollectionUserCollectionItems = "user_collection_items"
+	CollectionArtistNames         = "artist_names"
+	CollectionArtistRelationships = "artist_relationships"
+	CollectionArtworkAuthors      = "artwork_authors"
+	CollectionAttributionChanges  = "attribution_changes"
+	CollectionLocations           = "locations"
+	CollectionSeries              = "series"
+	CollectionArtworkImages       = "artwork_images"
+	CacheGuestbookYears           = "guestbook:years"
 )
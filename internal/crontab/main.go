@@ -9,5 +9,6 @@ func RegisterCronJobs(app *pocketbase.PocketBase, postcards config.Postcards, si
 	app.Logger().Debug("Registering cron jobs...")
 	sendPostcards(app, postcards)
 	generateSiteMap(app, sitemapConfig)
+	generateTiles(app)
 
 }
@@ -7,34 +7,192 @@ import (
 
 	"github.com/blackfyre/wga/internal/assets"
 	"github.com/blackfyre/wga/internal/config"
+	"github.com/blackfyre/wga/internal/constants"
 	"github.com/blackfyre/wga/internal/logging"
+	"github.com/blackfyre/wga/internal/utils/postcard"
+	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tools/mailer"
+	"github.com/pocketbase/pocketbase/tools/types"
 )
 
-// sendPostcard sends a postcard to every recipient and reports whether all deliveries succeeded.
-func sendPostcard(r *core.Record, app core.App, mailClient mailer.Mailer, postcards config.Postcards, runID string) bool {
-	recipients := convertCommaSeparatedEmailsToMailAddresses(r.GetString("recipients"))
-	allDelivered := true
+const (
+	// maxDeliveryAttempts is the number of times a recipient is tried before the delivery is given up.
+	maxDeliveryAttempts = 5
+	// deliveryBackoffBase is the wait after the first failed attempt. It doubles with every further failure.
+	deliveryBackoffBase = 5 * time.Minute
+	// receiptDelay is the wait between the last delivery and the sender receipt, so the
+	// receipt can tell which recipients picked the postcard up.
+	receiptDelay = 24 * time.Hour
+)
 
-	for index, rec := range recipients {
-		message, err := renderMessage(r, rec, postcards)
-		if err != nil {
-			logPostcardDelivery(app, runID, index+1, len(recipients), "render_failed", err)
-			allDelivered = false
+// Statuses of a postcard delivery row. Failed is terminal: the recipient is not tried again.
+const (
+	deliveryPending = "pending"
+	deliverySent    = "sent"
+	deliveryFailed  = "failed"
+)
+
+// recipientDelivery is the outcome of delivering a postcard to one recipient.
+type recipientDelivery struct {
+	Address   mail.Address
+	Delivered bool
+}
+
+// sendPostcard sends a postcard to every recipient whose delivery is due and
+// returns the delivery rows of the postcard with the outcome of this run applied.
+func sendPostcard(r *core.Record, app core.App, mailClient mailer.Mailer, postcards config.Postcards, runID string, now time.Time) ([]*core.Record, error) {
+	deliveries, err := loadDeliveries(r, app)
+	if err != nil {
+		return nil, err
+	}
+
+	for index, d := range deliveries {
+		if !deliveryDue(d, now) {
 			continue
 		}
 
-		if err := mailClient.Send(message); err != nil {
-			logPostcardDelivery(app, runID, index+1, len(recipients), "failed", err)
-			allDelivered = false
-			continue
+		attempt := d.GetInt("attempts") + 1
+		outcome := "sent"
+
+		message, err := renderMessage(r, mail.Address{Address: d.GetString("recipient")}, postcards)
+		if err != nil {
+			outcome = "render_failed"
+		} else if err = mailClient.Send(message); err != nil {
+			outcome = "failed"
+		}
+
+		logPostcardDelivery(app, runID, index+1, len(deliveries), attempt, outcome, err)
+		recordDeliveryAttempt(d, attempt, err, now)
+
+		if err := app.Save(d); err != nil {
+			logging.RunLogger(app, runID).Error("Postcard delivery state update failed",
+				"event", "postcard.delivery.state_update",
+				"recipient_index", index+1,
+				"outcome", "failed",
+				"error_type", logging.ErrorType(err),
+				"error", logging.Redact(err),
+			)
+		}
+	}
+
+	return deliveries, nil
+}
+
+// loadDeliveries returns the delivery rows of a postcard in recipient order,
+// creating a pending row for every recipient the first time the postcard is processed.
+func loadDeliveries(r *core.Record, app core.App) ([]*core.Record, error) {
+	deliveries, err := app.FindRecordsByFilter(
+		constants.CollectionPostcardDeliveries,
+		"postcard = {:postcard}",
+		"+position",
+		0,
+		0,
+		dbx.Params{"postcard": r.Id},
+	)
+	if err != nil || len(deliveries) > 0 {
+		return deliveries, err
+	}
+
+	collection, err := app.FindCollectionByNameOrId(constants.CollectionPostcardDeliveries)
+	if err != nil {
+		return nil, err
+	}
+
+	recipients := convertCommaSeparatedEmailsToMailAddresses(r.GetString("recipients"))
+
+	err = app.RunInTransaction(func(txApp core.App) error {
+		for index, rec := range recipients {
+			d := core.NewRecord(collection)
+			d.Set("postcard", r.Id)
+			d.Set("recipient", rec.Address)
+			d.Set("position", index)
+			d.Set("status", deliveryPending)
+
+			if err := txApp.Save(d); err != nil {
+				return err
+			}
+
+			deliveries = append(deliveries, d)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// deliveryDue reports whether a recipient should be tried in a run at the given time.
+func deliveryDue(d *core.Record, now time.Time) bool {
+	if d.GetString("status") != deliveryPending {
+		return false
+	}
+
+	next := d.GetDateTime("next_attempt_at")
+
+	return next.IsZero() || !next.Time().After(now)
+}
+
+// deliveryBackoff returns the wait before the next attempt after the given number of failed attempts.
+func deliveryBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	return deliveryBackoffBase << (attempts - 1)
+}
+
+// recordDeliveryAttempt applies the outcome of an attempt to a delivery row.
+// A failed recipient is scheduled for a retry until maxDeliveryAttempts is reached.
+func recordDeliveryAttempt(d *core.Record, attempt int, err error, now time.Time) {
+	d.Set("attempts", attempt)
+	d.Set("last_error_type", logging.ErrorType(err))
+	d.Set("next_attempt_at", "")
+
+	switch {
+	case err == nil:
+		d.Set("status", deliverySent)
+		d.Set("sent_at", now)
+	case attempt >= maxDeliveryAttempts:
+		d.Set("status", deliveryFailed)
+	default:
+		d.Set("next_attempt_at", now.Add(deliveryBackoff(attempt)))
+	}
+}
+
+// postcardStatus returns the status of a postcard given its delivery rows:
+// queued while any recipient is still pending, sent once every recipient got it
+// and failed when a recipient was given up on.
+func postcardStatus(deliveries []*core.Record) string {
+	status := "sent"
+
+	for _, d := range deliveries {
+		switch d.GetString("status") {
+		case deliveryPending:
+			return "queued"
+		case deliveryFailed:
+			status = "failed"
 		}
+	}
+
+	return status
+}
 
-		logPostcardDelivery(app, runID, index+1, len(recipients), "sent", nil)
+// deliveryOutcomes returns the per-recipient outcomes reported in the sender receipt.
+func deliveryOutcomes(deliveries []*core.Record) []recipientDelivery {
+	outcomes := make([]recipientDelivery, 0, len(deliveries))
+
+	for _, d := range deliveries {
+		outcomes = append(outcomes, recipientDelivery{
+			Address:   mail.Address{Address: d.GetString("recipient")},
+			Delivered: d.GetString("status") == deliverySent,
+		})
 	}
 
-	return allDelivered
+	return outcomes
 }
 
 // convertCommaSeparatedEmailsToMailAddresses converts a comma-separated string of email addresses
@@ -44,6 +202,11 @@ func convertCommaSeparatedEmailsToMailAddresses(emails string) []mail.Address {
 	var recipients []mail.Address
 
 	for _, recipient := range recipientsSlice {
+		recipient = strings.TrimSpace(recipient)
+		if recipient == "" {
+			continue
+		}
+
 		recipients = append(recipients, mail.Address{Address: recipient})
 	}
 
@@ -54,7 +217,7 @@ func convertCommaSeparatedEmailsToMailAddresses(emails string) []mail.Address {
 func renderMessage(r *core.Record, rec mail.Address, postcards config.Postcards) (*mailer.Message, error) {
 	html, err := assets.RenderEmail("postcard:notification", map[string]any{
 		"SenderName": r.GetString("sender_name"),
-		"PickUpUrl":  postcards.PublicURL.Resolve("/postcard?p=" + r.GetString("id")),
+		"PickUpUrl":  postcards.PublicURL.Resolve(postcard.PickUpPath(r.Id, rec.Address)),
 		"Title":      "",
 		"LogoUrl":    postcards.PublicURL.Resolve("/assets/images/logo.png"),
 	})
@@ -76,12 +239,18 @@ func renderMessage(r *core.Record, rec mail.Address, postcards config.Postcards)
 	return message, nil
 }
 
-// updatePostcardRecord marks a successfully delivered postcard as sent.
-func updatePostcardRecord(r *core.Record, app core.App, runID string) bool {
-	r.Set("status", "sent")
-	r.Set("sent_at", time.Now().Unix())
+// updatePostcardRecord moves a postcard whose deliveries are settled to its final status
+// and schedules the receipt when the sender asked for one.
+func updatePostcardRecord(r *core.Record, app core.App, runID string, status string, now time.Time) bool {
+	fields := map[string]any{"status": status}
+	if status == "sent" {
+		fields["sent_at"] = now
+	}
+	if r.GetBool("notify_sender") && r.GetString("sender_email") != "" {
+		fields["receipt_due_at"] = now.Add(receiptDelay)
+	}
 
-	if err := app.Save(r); err != nil {
+	if err := savePostcardFields(app, r, fields); err != nil {
 		logging.RunLogger(app, runID).Error("Postcard delivery record update failed",
 			"event", "postcard.delivery.record_update",
 			"outcome", "failed",
@@ -94,12 +263,28 @@ func updatePostcardRecord(r *core.Record, app core.App, runID string) bool {
 	return true
 }
 
-func logPostcardDelivery(app core.App, runID string, recipientIndex int, recipientCount int, outcome string, err error) {
+// savePostcardFields sets the fields on the stored postcard and saves it. The postcard is
+// read again first, so the pickups recorded since the run loaded it are kept.
+func savePostcardFields(app core.App, r *core.Record, fields map[string]any) error {
+	stored, err := app.FindRecordById(r.Collection().Id, r.Id)
+	if err != nil {
+		return err
+	}
+
+	for field, value := range fields {
+		stored.Set(field, value)
+	}
+
+	return app.Save(stored)
+}
+
+func logPostcardDelivery(app core.App, runID string, recipientIndex int, recipientCount int, attempt int, outcome string, err error) {
 	attributes := []any{
 		"event", "postcard.delivery.attempt",
 		"recipient_index", recipientIndex,
 		"recipient_count", recipientCount,
-		"attempt", 1,
+		"attempt", attempt,
+		"max_attempts", maxDeliveryAttempts,
 		"outcome", outcome,
 	}
 	logger := logging.RunLogger(app, runID)
@@ -116,18 +301,190 @@ func logPostcardDelivery(app core.App, runID string, recipientIndex int, recipie
 	logger.Error("Postcard delivery failed", attributes...)
 }
 
-func processPostcard(r *core.Record, app core.App, mailClient mailer.Mailer, postcards config.Postcards, runID string) bool {
-	allDelivered := sendPostcard(r, app, mailClient, postcards, runID)
-	if !updatePostcardRecord(r, app, runID) {
-		return false
+// receiptRecipient is a line of the sender receipt.
+type receiptRecipient struct {
+	Address string
+	Status  string
+}
+
+// receiptSubject returns the subject of the receipt, telling whether the postcard reached
+// every recipient, some of them or none.
+func receiptSubject(deliveries []recipientDelivery) string {
+	delivered := 0
+	for _, d := range deliveries {
+		if d.Delivered {
+			delivered++
+		}
 	}
 
-	return allDelivered
+	switch delivered {
+	case len(deliveries):
+		return "Your postcard has been sent"
+	case 0:
+		return "Your postcard could not be delivered"
+	default:
+		return "Your postcard could not be delivered to every recipient"
+	}
+}
+
+// renderReceipt renders the receipt sent to the sender once the postcard went out.
+// Recipients who already opened the pickup page are reported as picked up.
+func renderReceipt(r *core.Record, deliveries []recipientDelivery, postcards config.Postcards) (*mailer.Message, error) {
+	recipients := make([]receiptRecipient, 0, len(deliveries))
+	subject := receiptSubject(deliveries)
+
+	for _, d := range deliveries {
+		status := "Could not be delivered"
+		if openedAt, ok := postcard.OpenedAt(r, d.Address.Address); ok {
+			status = "Picked up on " + openedAt.UTC().Format("2 January 2006, 15:04 UTC")
+		} else if d.Delivered {
+			status = "Delivered, not picked up yet"
+		}
+
+		recipients = append(recipients, receiptRecipient{
+			Address: d.Address.Address,
+			Status:  status,
+		})
+	}
+
+	html, err := assets.RenderEmail("postcard:receipt", map[string]any{
+		"SenderName": r.GetString("sender_name"),
+		"ViewUrl":    postcards.PublicURL.Resolve(postcard.PickUpPath(r.Id, "")),
+		"Recipients": recipients,
+		"Title":      subject,
+		"LogoUrl":    postcards.PublicURL.Resolve("/assets/images/logo.png"),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	message := &mailer.Message{
+		From: mail.Address{
+			Name:    postcards.Sender.Name,
+			Address: postcards.Sender.Address.Address,
+		},
+		To: []mail.Address{{
+			Name:    r.GetString("sender_name"),
+			Address: r.GetString("sender_email"),
+		}},
+		Subject: subject,
+		HTML:    html,
+	}
+
+	return message, nil
+}
+
+// sendReceipt sends the delivery receipt to the sender when they asked for one
+// and records when it went out, so it is sent only once.
+func sendReceipt(r *core.Record, deliveries []recipientDelivery, app core.App, mailClient mailer.Mailer, postcards config.Postcards, runID string, now time.Time) {
+	if !r.GetBool("notify_sender") || r.GetString("sender_email") == "" || !r.GetDateTime("receipt_sent_at").IsZero() {
+		return
+	}
+
+	logger := logging.RunLogger(app, runID)
+
+	message, err := renderReceipt(r, deliveries, postcards)
+	if err == nil {
+		err = mailClient.Send(message)
+	}
+
+	if err != nil {
+		logger.Error("Postcard receipt failed",
+			"event", "postcard.receipt",
+			"outcome", "failed",
+			"error_type", logging.ErrorType(err),
+			"error", logging.Redact(err),
+		)
+		return
+	}
+
+	if err := savePostcardFields(app, r, map[string]any{"receipt_sent_at": now}); err != nil {
+		logger.Error("Postcard receipt record update failed",
+			"event", "postcard.receipt",
+			"outcome", "record_update_failed",
+			"error_type", logging.ErrorType(err),
+			"error", logging.Redact(err),
+		)
+		return
+	}
+
+	logger.Info("Postcard receipt sent",
+		"event", "postcard.receipt",
+		"outcome", "sent",
+	)
+}
+
+// processPostcard runs the due deliveries of a postcard and returns its status afterwards.
+// An empty status means the postcard could not be processed.
+func processPostcard(r *core.Record, app core.App, mailClient mailer.Mailer, postcards config.Postcards, runID string, now time.Time) string {
+	deliveries, err := sendPostcard(r, app, mailClient, postcards, runID, now)
+	if err != nil {
+		logging.RunLogger(app, runID).Error("Postcard delivery state lookup failed",
+			"event", "postcard.delivery.state_lookup",
+			"outcome", "failed",
+			"error_type", logging.ErrorType(err),
+			"error", logging.Redact(err),
+		)
+		return ""
+	}
+
+	status := postcardStatus(deliveries)
+	if status == "queued" {
+		return status
+	}
+
+	if !updatePostcardRecord(r, app, runID, status, now) {
+		return ""
+	}
+
+	return status
+}
+
+// sendDueReceipts sends the receipts scheduled before now, reporting the pickups recorded
+// until then. Failures are logged, and the receipt is tried again in the next run.
+func sendDueReceipts(app core.App, mailClient mailer.Mailer, postcards config.Postcards, runID string, now time.Time) {
+	logger := logging.RunLogger(app, runID)
+
+	records, err := app.FindRecordsByFilter(
+		constants.CollectionPostcards,
+		"receipt_due_at != '' && receipt_due_at <= {:now} && receipt_sent_at = ''",
+		"receipt_due_at",
+		0,
+		0,
+		dbx.Params{"now": now.UTC().Format(types.DefaultDateLayout)},
+	)
+	if err != nil {
+		logger.Error("Postcard receipt queue lookup failed",
+			"event", "postcard.receipt",
+			"outcome", "queue_lookup_failed",
+			"error_type", logging.ErrorType(err),
+			"error", logging.Redact(err),
+		)
+		return
+	}
+
+	for _, r := range records {
+		deliveries, err := loadDeliveries(r, app)
+		if err != nil {
+			logger.Error("Postcard delivery state lookup failed",
+				"event", "postcard.delivery.state_lookup",
+				"outcome", "failed",
+				"error_type", logging.ErrorType(err),
+				"error", logging.Redact(err),
+			)
+			continue
+		}
+
+		sendReceipt(r, deliveryOutcomes(deliveries), app, mailClient, postcards, runID, now)
+	}
 }
 
 // sendPostcards sends postcards based on a specified frequency.
 // It retrieves postcard records with a status of 'queued' from the database,
-// sends each postcard using the mail client, and updates the postcard record.
+// sends each postcard to the recipients whose delivery is due using the mail client,
+// and updates the postcard record once every delivery is settled.
+// It then sends the sender receipts that are due, see sendDueReceipts.
 // The frequency is provided by the application configuration.
 //
 // Parameters:
@@ -169,21 +526,27 @@ func sendPostcards(app core.App, postcards config.Postcards) {
 		}
 
 		mailClient := app.NewMailClient()
+		now := time.Now()
 		deliveredCount := 0
+		retryingCount := 0
 		failedCount := 0
 
 		for _, r := range records {
-			if !processPostcard(r, app, mailClient, postcards, runID) {
+			switch processPostcard(r, app, mailClient, postcards, runID, now) {
+			case "sent":
+				deliveredCount++
+			case "queued":
+				retryingCount++
+			default:
 				failedCount++
-				continue
 			}
-
-			deliveredCount++
 		}
 
+		sendDueReceipts(app, mailClient, postcards, runID, now)
+
 		if failedCount > 0 {
 			outcome := "partial_failure"
-			if deliveredCount == 0 {
+			if deliveredCount == 0 && retryingCount == 0 {
 				outcome = "failed"
 			}
 
@@ -191,6 +554,7 @@ func sendPostcards(app core.App, postcards config.Postcards) {
 				"event", "postcard.delivery.run",
 				"postcard_count", len(records),
 				"delivered_count", deliveredCount,
+				"retrying_count", retryingCount,
 				"failed_count", failedCount,
 				"outcome", outcome,
 			)
@@ -201,6 +565,7 @@ func sendPostcards(app core.App, postcards config.Postcards) {
 			"event", "postcard.delivery.run",
 			"postcard_count", len(records),
 			"delivered_count", deliveredCount,
+			"retrying_count", retryingCount,
 			"outcome", "completed",
 		)
 	})
@@ -5,11 +5,15 @@ import (
 	"fmt"
 	"net/mail"
 	"reflect"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/blackfyre/wga/internal/config"
 	"github.com/blackfyre/wga/internal/testutils"
+	"github.com/blackfyre/wga/internal/utils/postcard"
+	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tools/mailer"
@@ -58,6 +62,7 @@ func TestLogPostcardDeliveryUsesSafeRunFields(t *testing.T) {
 		"run-123",
 		1,
 		2,
+		1,
 		"sent",
 		nil,
 	)
@@ -66,6 +71,7 @@ func TestLogPostcardDeliveryUsesSafeRunFields(t *testing.T) {
 		"run-123",
 		2,
 		2,
+		3,
 		"failed",
 		errors.New("recipient@example.test token-value message-body-value"),
 	)
@@ -91,8 +97,8 @@ func TestLogPostcardDeliveryUsesSafeRunFields(t *testing.T) {
 	if _, ok := entry.Data["postcard_id"]; ok {
 		t.Fatal("delivery log must not contain the postcard pickup identifier")
 	}
-	if got := fmt.Sprint(entry.Data["attempt"]); got != "1" {
-		t.Fatalf("attempt = %s, want 1", got)
+	if got := fmt.Sprint(entry.Data["attempt"]); got != "3" {
+		t.Fatalf("attempt = %s, want 3", got)
 	}
 	if got := entry.Data["outcome"]; got != "failed" {
 		t.Fatalf("outcome = %v, want %q", got, "failed")
@@ -106,33 +112,47 @@ func TestLogPostcardDeliveryUsesSafeRunFields(t *testing.T) {
 	}
 }
 
-func TestProcessPostcardCompletesAfterPartialDeliveryFailure(t *testing.T) {
+func TestProcessPostcardRetriesOnlyFailedRecipients(t *testing.T) {
 	app := testutils.NewTestApp(t)
-	collection := core.NewBaseCollection("postcards")
-	collection.Fields.Add(
-		&core.TextField{Name: "status"},
-		&core.TextField{Name: "recipients"},
-		&core.TextField{Name: "sender_name"},
-	)
-	if err := app.Save(collection); err != nil {
-		t.Fatalf("create postcards collection: %v", err)
+	collection := newPostcardTestCollections(t, app)
+	record := savePostcardTestRecord(t, app, collection, "first@example.test,second@example.test,third@example.test", false)
+
+	mailClient := &scriptedMailer{
+		failing: map[string]int{"second@example.test": 1},
 	}
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
 
-	record := core.NewRecord(collection)
-	record.Set("status", "queued")
-	record.Set("recipients", "first@example.test,second@example.test,third@example.test")
-	record.Set("sender_name", "sender")
-	if err := app.Save(record); err != nil {
-		t.Fatalf("create postcard: %v", err)
+	if got := processPostcard(record, app, mailClient, postcardTestConfig(t), "run-123", now); got != "queued" {
+		t.Fatalf("status after first run = %q, want %q", got, "queued")
+	}
+	wantRecipients := []string{"first@example.test", "second@example.test", "third@example.test"}
+	if !reflect.DeepEqual(mailClient.recipients, wantRecipients) {
+		t.Fatalf("attempted recipients = %v, want %v", mailClient.recipients, wantRecipients)
 	}
 
-	mailClient := &scriptedMailer{
-		outcomes: []error{nil, errors.New("mail transport failed"), nil},
+	failed := findTestDelivery(t, app, record.Id, "second@example.test")
+	if got := failed.GetString("status"); got != deliveryPending {
+		t.Fatalf("failed delivery status = %q, want %q", got, deliveryPending)
 	}
-	if processPostcard(record, app, mailClient, postcardTestConfig(t), "run-123") {
-		t.Fatal("expected postcard delivery to fail")
+	if got := failed.GetInt("attempts"); got != 1 {
+		t.Fatalf("attempts = %d, want 1", got)
 	}
-	wantRecipients := []string{"first@example.test", "second@example.test", "third@example.test"}
+	if got := failed.GetString("last_error_type"); got != "*errors.errorString" {
+		t.Fatalf("last_error_type = %q", got)
+	}
+	if got, want := failed.GetDateTime("next_attempt_at").Time(), now.Add(deliveryBackoffBase); !got.Equal(want) {
+		t.Fatalf("next_attempt_at = %v, want %v", got, want)
+	}
+
+	processPostcard(record, app, mailClient, postcardTestConfig(t), "run-124", now.Add(time.Minute))
+	if len(mailClient.recipients) != 3 {
+		t.Fatalf("recipient retried before the backoff elapsed: %v", mailClient.recipients)
+	}
+
+	if got := processPostcard(record, app, mailClient, postcardTestConfig(t), "run-125", now.Add(deliveryBackoffBase)); got != "sent" {
+		t.Fatalf("status after retry = %q, want %q", got, "sent")
+	}
+	wantRecipients = append(wantRecipients, "second@example.test")
 	if !reflect.DeepEqual(mailClient.recipients, wantRecipients) {
 		t.Fatalf("attempted recipients = %v, want %v", mailClient.recipients, wantRecipients)
 	}
@@ -144,24 +164,256 @@ func TestProcessPostcardCompletesAfterPartialDeliveryFailure(t *testing.T) {
 	if got := stored.GetString("status"); got != "sent" {
 		t.Fatalf("status = %q, want %q", got, "sent")
 	}
-	queuedRecords, err := app.FindRecordsByFilter(collection.Id, "status = 'queued'", "", 0, 0)
+}
+
+func TestProcessPostcardFailsAfterMaxAttempts(t *testing.T) {
+	app := testutils.NewTestApp(t)
+	collection := newPostcardTestCollections(t, app)
+	record := savePostcardTestRecord(t, app, collection, "first@example.test,second@example.test", false)
+
+	mailClient := &scriptedMailer{
+		failing: map[string]int{"second@example.test": maxDeliveryAttempts},
+	}
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	status := ""
+	for attempt := 1; attempt <= maxDeliveryAttempts; attempt++ {
+		status = processPostcard(record, app, mailClient, postcardTestConfig(t), "run-123", now)
+		now = now.Add(deliveryBackoff(attempt))
+	}
+
+	if status != "failed" {
+		t.Fatalf("status = %q, want %q", status, "failed")
+	}
+	if got := len(mailClient.recipients); got != 1+maxDeliveryAttempts {
+		t.Fatalf("sent %d messages, want %d", got, 1+maxDeliveryAttempts)
+	}
+
+	failed := findTestDelivery(t, app, record.Id, "second@example.test")
+	if got := failed.GetString("status"); got != deliveryFailed {
+		t.Fatalf("delivery status = %q, want %q", got, deliveryFailed)
+	}
+	if got := failed.GetInt("attempts"); got != maxDeliveryAttempts {
+		t.Fatalf("attempts = %d, want %d", got, maxDeliveryAttempts)
+	}
+	if !failed.GetDateTime("next_attempt_at").IsZero() {
+		t.Fatal("expected no further attempt to be scheduled")
+	}
+
+	stored, err := app.FindRecordById(collection.Id, record.Id)
 	if err != nil {
-		t.Fatalf("load queued postcards: %v", err)
+		t.Fatalf("reload postcard: %v", err)
 	}
-	if len(queuedRecords) != 0 {
-		t.Fatalf("queued postcards = %d, want 0", len(queuedRecords))
+	if got := stored.GetString("status"); got != "failed" {
+		t.Fatalf("status = %q, want %q", got, "failed")
+	}
+}
+
+func TestDeliveryBackoffDoubles(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{
+		1: deliveryBackoffBase,
+		2: 2 * deliveryBackoffBase,
+		4: 8 * deliveryBackoffBase,
+	} {
+		if got := deliveryBackoff(attempts); got != want {
+			t.Fatalf("deliveryBackoff(%d) = %v, want %v", attempts, got, want)
+		}
 	}
 }
 
+func TestSendDueReceiptsReportsPickupsAfterTheDelay(t *testing.T) {
+	app := testutils.NewTestApp(t)
+	collection := newPostcardTestCollections(t, app)
+	record := savePostcardTestRecord(t, app, collection, "first@example.test,second@example.test,third@example.test", true)
+
+	mailClient := &scriptedMailer{
+		failing: map[string]int{"second@example.test": maxDeliveryAttempts},
+	}
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	settled := now
+
+	for attempt := 1; attempt <= maxDeliveryAttempts; attempt++ {
+		processPostcard(record, app, mailClient, postcardTestConfig(t), "run-123", now)
+		settled = now
+		now = now.Add(deliveryBackoff(attempt))
+	}
+
+	if slices.Contains(mailClient.recipients, "sender@example.test") {
+		t.Fatal("receipt sent with the deliveries")
+	}
+
+	if got := mailClient.messages[0].HTML; !strings.Contains(got, "r="+postcard.RecipientToken(record.Id, "first@example.test")) {
+		t.Fatalf("pickup link does not identify the recipient: %s", got)
+	}
+
+	// the first recipient picks the postcard up after the deliveries settled
+	stored, err := app.FindRecordById(collection.Id, record.Id)
+	if err != nil {
+		t.Fatalf("reload postcard: %v", err)
+	}
+	if _, err := postcard.MarkOpened(app, stored, postcard.RecipientToken(record.Id, "first@example.test")); err != nil {
+		t.Fatalf("record pickup: %v", err)
+	}
+
+	sent := len(mailClient.messages)
+	sendDueReceipts(app, mailClient, postcardTestConfig(t), "run-124", settled.Add(receiptDelay-time.Minute))
+	if len(mailClient.messages) != sent {
+		t.Fatal("receipt sent before it was due")
+	}
+
+	sendDueReceipts(app, mailClient, postcardTestConfig(t), "run-125", settled.Add(receiptDelay))
+
+	last := mailClient.messages[len(mailClient.messages)-1]
+	if got := last.To[0].Address; got != "sender@example.test" {
+		t.Fatalf("last message went to %q, want the sender", got)
+	}
+	if got, want := last.Subject, "Your postcard could not be delivered to every recipient"; got != want {
+		t.Fatalf("subject = %q, want %q", got, want)
+	}
+	for _, want := range []string{"Picked up on", "Could not be delivered", "Delivered, not picked up yet"} {
+		if !strings.Contains(last.HTML, want) {
+			t.Fatalf("receipt does not contain %q: %s", want, last.HTML)
+		}
+	}
+
+	stored, err = app.FindRecordById(collection.Id, record.Id)
+	if err != nil {
+		t.Fatalf("reload postcard: %v", err)
+	}
+	if stored.GetDateTime("receipt_sent_at").IsZero() {
+		t.Fatal("expected receipt_sent_at to be recorded")
+	}
+	if _, ok := postcard.OpenedAt(stored, "first@example.test"); !ok {
+		t.Fatal("expected the pickup to be kept when the receipt is recorded")
+	}
+
+	sent = len(mailClient.messages)
+	sendDueReceipts(app, mailClient, postcardTestConfig(t), "run-126", settled.Add(2*receiptDelay))
+	if len(mailClient.messages) != sent {
+		t.Fatal("receipt was sent again")
+	}
+}
+
+func TestReceiptSubjectFollowsTheDeliveries(t *testing.T) {
+	delivered := recipientDelivery{Address: mail.Address{Address: "first@example.test"}, Delivered: true}
+	failed := recipientDelivery{Address: mail.Address{Address: "second@example.test"}}
+
+	for _, c := range []struct {
+		deliveries []recipientDelivery
+		want       string
+	}{
+		{[]recipientDelivery{delivered}, "Your postcard has been sent"},
+		{[]recipientDelivery{delivered, failed}, "Your postcard could not be delivered to every recipient"},
+		{[]recipientDelivery{failed}, "Your postcard could not be delivered"},
+	} {
+		if got := receiptSubject(c.deliveries); got != c.want {
+			t.Errorf("receiptSubject(%v) = %q, want %q", c.deliveries, got, c.want)
+		}
+	}
+}
+
+func TestProcessPostcardSkipsReceiptWhenNotRequested(t *testing.T) {
+	app := testutils.NewTestApp(t)
+	collection := newPostcardTestCollections(t, app)
+	record := savePostcardTestRecord(t, app, collection, "first@example.test", false)
+
+	mailClient := &scriptedMailer{}
+	if got := processPostcard(record, app, mailClient, postcardTestConfig(t), "run-123", time.Now()); got != "sent" {
+		t.Fatalf("status = %q, want %q", got, "sent")
+	}
+	if !reflect.DeepEqual(mailClient.recipients, []string{"first@example.test"}) {
+		t.Fatalf("attempted recipients = %v, want only the recipient", mailClient.recipients)
+	}
+}
+
+// scriptedMailer fails the sends to an address as many times as given in failing
+// and succeeds otherwise.
 type scriptedMailer struct {
-	outcomes   []error
+	failing    map[string]int
 	recipients []string
+	messages   []*mailer.Message
 }
 
 func (m *scriptedMailer) Send(message *mailer.Message) error {
-	m.recipients = append(m.recipients, message.To[0].Address)
+	address := message.To[0].Address
+	m.recipients = append(m.recipients, address)
+	m.messages = append(m.messages, message)
+
+	if m.failing[address] > 0 {
+		m.failing[address]--
+		return errors.New("mail transport failed")
+	}
+
+	return nil
+}
+
+func newPostcardTestCollections(t *testing.T, app core.App) *core.Collection {
+	t.Helper()
+
+	postcards := core.NewBaseCollection("postcards")
+	postcards.Id = "postcards"
+	postcards.Fields.Add(
+		&core.TextField{Name: "status"},
+		&core.TextField{Name: "recipients"},
+		&core.TextField{Name: "sender_name"},
+		&core.TextField{Name: "sender_email"},
+		&core.BoolField{Name: "notify_sender"},
+		&core.DateField{Name: "sent_at"},
+		&core.JSONField{Name: "opened_at"},
+		&core.DateField{Name: "receipt_sent_at"},
+		&core.DateField{Name: "receipt_due_at"},
+	)
+	if err := app.Save(postcards); err != nil {
+		t.Fatalf("create postcards collection: %v", err)
+	}
+
+	deliveries := core.NewBaseCollection("postcard_deliveries")
+	deliveries.Fields.Add(
+		&core.RelationField{Name: "postcard", CollectionId: postcards.Id, MaxSelect: 1},
+		&core.TextField{Name: "recipient"},
+		&core.NumberField{Name: "position"},
+		&core.TextField{Name: "status"},
+		&core.NumberField{Name: "attempts"},
+		&core.TextField{Name: "last_error_type"},
+		&core.DateField{Name: "next_attempt_at"},
+		&core.DateField{Name: "sent_at"},
+	)
+	if err := app.Save(deliveries); err != nil {
+		t.Fatalf("create postcard deliveries collection: %v", err)
+	}
+
+	return postcards
+}
+
+func savePostcardTestRecord(t *testing.T, app core.App, collection *core.Collection, recipients string, notifySender bool) *core.Record {
+	t.Helper()
+
+	record := core.NewRecord(collection)
+	record.Set("status", "queued")
+	record.Set("recipients", recipients)
+	record.Set("sender_name", "sender")
+	record.Set("sender_email", "sender@example.test")
+	record.Set("notify_sender", notifySender)
+	if err := app.Save(record); err != nil {
+		t.Fatalf("create postcard: %v", err)
+	}
+
+	return record
+}
+
+func findTestDelivery(t *testing.T, app core.App, postcardId string, recipient string) *core.Record {
+	t.Helper()
+
+	d, err := app.FindFirstRecordByFilter(
+		"postcard_deliveries",
+		"postcard = {:postcard} && recipient = {:recipient}",
+		dbx.Params{"postcard": postcardId, "recipient": recipient},
+	)
+	if err != nil {
+		t.Fatalf("find delivery of %s: %v", recipient, err)
+	}
 
-	return m.outcomes[len(m.recipients)-1]
+	return d
 }
 
 func postcardTestConfig(t *testing.T) config.Postcards {
@@ -0,0 +1,59 @@
+package crontab
+
+import (
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/logging"
+	"github.com/blackfyre/wga/internal/utils/dzi"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// tilesBatchSize is how many missing pyramids one run of the job builds. The
+// batch is picked at random, so images that fail to build do not hold up the rest.
+const tilesBatchSize = 20
+
+// generateTiles builds the deep zoom pyramids the artwork hook did not, such as
+// those of artworks imported before pyramids existed or dropped from a full queue.
+func generateTiles(app *pocketbase.PocketBase) {
+	app.Logger().Debug("Registering cron job for tile pyramid generation...")
+	app.Cron().MustAdd("tiles", "*/30 * * * *", func() {
+		buildMissingTiles(app)
+	})
+}
+
+func buildMissingTiles(app core.App) {
+	records, err := app.FindRecordsByFilter(
+		constants.CollectionArtworks,
+		"image != '' && tiles_image != image",
+		"@random",
+		tilesBatchSize,
+		0,
+	)
+	if err != nil {
+		app.Logger().Error("Failed to find artworks without tile pyramids", "error", err.Error())
+		return
+	}
+
+	built := 0
+	for _, record := range records {
+		if err := dzi.Generate(app, record); err != nil {
+			app.Logger().Warn("Tile pyramid build failed",
+				"event", "artwork.tiles.build",
+				"record_id", record.Id,
+				"outcome", "failed",
+				"error_type", logging.ErrorType(err),
+				"error", logging.Redact(err),
+			)
+			continue
+		}
+		built++
+	}
+
+	if len(records) > 0 {
+		app.Logger().Info("Tile pyramids built",
+			"event", "artwork.tiles.run",
+			"built", built,
+			"pending", len(records)-built,
+		)
+	}
+}
@@ -0,0 +1,273 @@
+package accounts
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/blackfyre/wga/internal/assets/templ/components"
+	"github.com/blackfyre/wga/internal/assets/templ/pages"
+	tmplUtils "github.com/blackfyre/wga/internal/assets/templ/utils"
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/logging"
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/blackfyre/wga/internal/utils/session"
+	"github.com/blackfyre/wga/internal/validation"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// minPasswordLength matches the default of the PocketBase users collection.
+const minPasswordLength = 8
+
+func presentLogin(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	if session.User(c) != nil {
+		return c.Redirect(http.StatusSeeOther, "/account")
+	}
+
+	return renderPage(app, c, http.StatusOK, "Sign in", "/account/login", pages.LoginPage(pages.LoginPageDTO{
+		Next: c.Request.URL.Query().Get("next"),
+	}))
+}
+
+func processLogin(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	form := struct {
+		Email    string `form:"email"`
+		Password string `form:"password"`
+		Next     string `form:"next"`
+	}{}
+
+	if err := c.BindBody(&form); err != nil {
+		return utils.BadRequestError(c)
+	}
+
+	if !loginAttempts.allow(attemptKeys(c, form.Email)...) {
+		logging.RequestLogger(app, c).Warn("Account sign in rejected",
+			"event", "account.login.rejected",
+			"outcome", "too_many_attempts",
+		)
+
+		return renderPage(app, c, http.StatusTooManyRequests, "Sign in", "/account/login", pages.LoginPage(pages.LoginPageDTO{
+			Email: form.Email,
+			Next:  form.Next,
+			Error: "Too many sign in attempts. Try again in a few minutes.",
+		}))
+	}
+
+	user, err := app.FindAuthRecordByEmail(constants.CollectionUsers, strings.TrimSpace(form.Email))
+	if err != nil || !user.ValidatePassword(form.Password) {
+		logging.RequestLogger(app, c).Info("Account sign in rejected",
+			"event", "account.login.rejected",
+			"outcome", "invalid_credentials",
+		)
+
+		return renderPage(app, c, http.StatusUnauthorized, "Sign in", "/account/login", pages.LoginPage(pages.LoginPageDTO{
+			Email: form.Email,
+			Next:  form.Next,
+			Error: "Wrong email or password.",
+		}))
+	}
+
+	if err := session.SignIn(c, user); err != nil {
+		app.Logger().Error("Failed to start account session", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	return c.Redirect(http.StatusSeeOther, safeNext(form.Next))
+}
+
+func presentSignup(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	if session.User(c) != nil {
+		return c.Redirect(http.StatusSeeOther, "/account")
+	}
+
+	return renderPage(app, c, http.StatusOK, "Create an account", "/account/signup", pages.SignupPage(pages.SignupPageDTO{}))
+}
+
+func processSignup(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	form := struct {
+		Name            string `form:"name"`
+		Email           string `form:"email"`
+		Password        string `form:"password"`
+		PasswordConfirm string `form:"password_confirm"`
+		HoneyPotName    string `form:"hp_name"`
+		HoneyPotEmail   string `form:"hp_email"`
+	}{}
+
+	if err := c.BindBody(&form); err != nil {
+		return utils.BadRequestError(c)
+	}
+
+	if err := validation.ValidateHoneypot(form.HoneyPotName, form.HoneyPotEmail); err != nil {
+		logging.RequestLogger(app, c).Warn("Account sign up rejected",
+			"event", "account.signup.rejected",
+			"outcome", "honeypot",
+		)
+		return utils.BadRequestError(c)
+	}
+
+	content := pages.SignupPageDTO{
+		Name:  strings.TrimSpace(form.Name),
+		Email: strings.TrimSpace(form.Email),
+	}
+
+	if !signupAttempts.allow(attemptKeys(c, form.Email)...) {
+		logging.RequestLogger(app, c).Warn("Account sign up rejected",
+			"event", "account.signup.rejected",
+			"outcome", "too_many_attempts",
+		)
+
+		content.Error = "Too many sign up attempts. Try again later."
+		return renderPage(app, c, http.StatusTooManyRequests, "Create an account", "/account/signup", pages.SignupPage(content))
+	}
+
+	switch {
+	case len(form.Password) < minPasswordLength:
+		content.Error = "The password must be at least 8 characters long."
+	case form.Password != form.PasswordConfirm:
+		content.Error = "The passwords do not match."
+	}
+
+	if content.Error != "" {
+		return renderPage(app, c, http.StatusBadRequest, "Create an account", "/account/signup", pages.SignupPage(content))
+	}
+
+	users, err := app.FindCollectionByNameOrId(constants.CollectionUsers)
+	if err != nil {
+		app.Logger().Error("Users collection not found", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	user := core.NewRecord(users)
+	user.SetEmail(content.Email)
+	user.SetPassword(form.Password)
+	user.Set("name", content.Name)
+
+	if err := app.Save(user); err != nil {
+		logging.RequestLogger(app, c).Info("Account sign up rejected",
+			"event", "account.signup.rejected",
+			"outcome", "invalid_account",
+			"error_type", logging.ErrorType(err),
+		)
+
+		content.Error = "The account could not be created. Check the email address, or sign in if you already have an account."
+		return renderPage(app, c, http.StatusBadRequest, "Create an account", "/account/signup", pages.SignupPage(content))
+	}
+
+	if err := session.SignIn(c, user); err != nil {
+		app.Logger().Error("Failed to start account session", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	return c.Redirect(http.StatusSeeOther, "/account")
+}
+
+func processAccount(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	user := session.User(c)
+	if user == nil {
+		return signInRedirect(c, "/account")
+	}
+
+	favourites, err := app.FindRecordsByFilter(
+		constants.CollectionFavourites,
+		"user = {:user}",
+		"-created",
+		0,
+		0,
+		dbx.Params{"user": user.Id},
+	)
+	if err != nil {
+		app.Logger().Error("Error finding favourites", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	ids := make([]string, 0, len(favourites))
+	for _, f := range favourites {
+		ids = append(ids, f.GetString("artwork"))
+	}
+
+	images, err := artworkImages(app, ids)
+	if err != nil {
+		app.Logger().Error("Error finding favourite artworks", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	collections, err := findUserCollections(app, user)
+	if err != nil {
+		app.Logger().Error("Error finding collections", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	content := pages.AccountPageDTO{
+		Name:  displayName(user),
+		Email: user.Email(),
+	}
+
+	for _, id := range ids {
+		if img, ok := images[id]; ok {
+			content.Favourites = append(content.Favourites, img)
+		}
+	}
+
+	for _, col := range collections {
+		count, err := app.CountRecords(constants.CollectionUserCollectionItems, dbx.HashExp{"collection": col.Id})
+		if err != nil {
+			app.Logger().Error("Error counting collection items", "error", err.Error())
+			return utils.ServerFaultError(c)
+		}
+
+		content.Collections = append(content.Collections, pages.CollectionListItem{
+			Name:      col.GetString("name"),
+			Url:       collectionUrl(col.Id),
+			Public:    col.GetBool("public"),
+			ItemCount: int(count),
+		})
+	}
+
+	return renderPage(app, c, http.StatusOK, "Your account", "/account", pages.AccountPage(content))
+}
+
+func presentMenu(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	user := session.User(c)
+
+	var buff bytes.Buffer
+
+	if err := components.AccountMenu(user != nil, displayName(user)).Render(context.Background(), &buff); err != nil {
+		app.Logger().Error("Error rendering account menu", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	return c.HTML(http.StatusOK, buff.String())
+}
+
+// displayName returns the name of the user, or their email address when they did not give one.
+func displayName(user *core.Record) string {
+	if user == nil {
+		return ""
+	}
+
+	if name := user.GetString("name"); name != "" {
+		return name
+	}
+
+	return user.Email()
+}
+
+func renderPage(app *pocketbase.PocketBase, c *core.RequestEvent, status int, title string, pushUrl string, page templ.Component) error {
+	ctx := tmplUtils.DecorateContext(context.Background(), tmplUtils.TitleKey, title)
+
+	c.Response.Header().Set("HX-Push-Url", pushUrl)
+	c.Response.Header().Set("Cache-Control", "private, no-store")
+
+	var buff bytes.Buffer
+
+	if err := page.Render(ctx, &buff); err != nil {
+		app.Logger().Error("Error rendering account page", "title", title, "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	return c.HTML(status, buff.String())
+}
@@ -0,0 +1,523 @@
+package accounts
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"html"
+	"net/http"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/blackfyre/wga/internal/assets/templ/pages"
+	tmplUtils "github.com/blackfyre/wga/internal/assets/templ/utils"
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/blackfyre/wga/internal/utils/session"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	maxCollectionNameLength        = 100
+	maxCollectionDescriptionLength = 2000
+	maxCollectionNoteLength        = 1000
+)
+
+// exportKind selects how processCollectionExport renders a collection.
+type exportKind int
+
+const (
+	exportPostcards exportKind = iota
+	exportSlideshow
+)
+
+// errNotOwner is returned when a visitor tries to change a collection that is not theirs.
+var errNotOwner = errors.New("not the owner of the collection")
+
+type collectionForm struct {
+	Name        string `form:"name"`
+	Description string `form:"description"`
+	Public      bool   `form:"public"`
+}
+
+func collectionUrl(id string) string {
+	return "/collections/" + id
+}
+
+func findUserCollections(app core.App, user *core.Record) ([]*core.Record, error) {
+	return app.FindRecordsByFilter(
+		constants.CollectionUserCollections,
+		"owner = {:owner}",
+		"+name",
+		0,
+		0,
+		dbx.Params{"owner": user.Id},
+	)
+}
+
+// findCollectionItems returns the items of a collection in their display order.
+func findCollectionItems(app core.App, collectionId string) ([]*core.Record, error) {
+	return app.FindRecordsByFilter(
+		constants.CollectionUserCollectionItems,
+		"collection = {:collection}",
+		"+position,+created",
+		0,
+		0,
+		dbx.Params{"collection": collectionId},
+	)
+}
+
+// findViewableCollection returns the collection with the given id when the visitor may see it:
+// public collections are visible to anyone, private ones to their owner only.
+func findViewableCollection(app core.App, c *core.RequestEvent, id string) (*core.Record, error) {
+	col, err := app.FindRecordById(constants.CollectionUserCollections, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if col.GetBool("public") || isOwner(c, col) {
+		return col, nil
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+// findOwnedCollection returns the collection with the given id when it belongs to the signed in user.
+func findOwnedCollection(app core.App, c *core.RequestEvent, id string) (*core.Record, error) {
+	col, err := app.FindRecordById(constants.CollectionUserCollections, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isOwner(c, col) {
+		return nil, errNotOwner
+	}
+
+	return col, nil
+}
+
+func isOwner(c *core.RequestEvent, col *core.Record) bool {
+	user := session.User(c)
+	return user != nil && col.GetString("owner") == user.Id
+}
+
+func bindCollectionForm(c *core.RequestEvent) (collectionForm, bool) {
+	form := collectionForm{}
+
+	if err := c.BindBody(&form); err != nil {
+		return form, false
+	}
+
+	form.Name = strings.TrimSpace(form.Name)
+	form.Description = strings.TrimSpace(form.Description)
+
+	if form.Name == "" || len(form.Name) > maxCollectionNameLength || len(form.Description) > maxCollectionDescriptionLength {
+		return form, false
+	}
+
+	return form, true
+}
+
+func createCollection(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	user := session.User(c)
+	if user == nil {
+		return signInRedirect(c, "/account")
+	}
+
+	form, ok := bindCollectionForm(c)
+	if !ok {
+		return utils.BadRequestError(c)
+	}
+
+	collection, err := app.FindCollectionByNameOrId(constants.CollectionUserCollections)
+	if err != nil {
+		app.Logger().Error("User collections collection not found", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	col := core.NewRecord(collection)
+	col.Set("owner", user.Id)
+	col.Set("name", form.Name)
+	col.Set("description", form.Description)
+	col.Set("public", form.Public)
+
+	if err := app.Save(col); err != nil {
+		app.Logger().Error("Error creating collection", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	return c.Redirect(http.StatusSeeOther, collectionUrl(col.Id))
+}
+
+func updateCollection(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	col, err := findOwnedCollection(app, c, c.Request.PathValue("id"))
+	if err != nil {
+		return utils.NotFoundError(c)
+	}
+
+	form, ok := bindCollectionForm(c)
+	if !ok {
+		return utils.BadRequestError(c)
+	}
+
+	col.Set("name", form.Name)
+	col.Set("description", form.Description)
+	col.Set("public", form.Public)
+
+	if err := app.Save(col); err != nil {
+		app.Logger().Error("Error updating collection", "id", col.Id, "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	return c.Redirect(http.StatusSeeOther, collectionUrl(col.Id))
+}
+
+func deleteCollection(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	col, err := findOwnedCollection(app, c, c.Request.PathValue("id"))
+	if err != nil {
+		return utils.NotFoundError(c)
+	}
+
+	if err := app.Delete(col); err != nil {
+		app.Logger().Error("Error deleting collection", "id", col.Id, "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	return c.Redirect(http.StatusSeeOther, "/account")
+}
+
+// addCollectionItem appends an artwork to the end of a collection and re-renders
+// the artwork actions it was added from.
+func addCollectionItem(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	if session.User(c) == nil {
+		return signInRedirect(c, currentPath(c))
+	}
+
+	col, err := findOwnedCollection(app, c, c.Request.PathValue("id"))
+	if err != nil {
+		return utils.NotFoundError(c)
+	}
+
+	artworkId := c.Request.FormValue("artwork")
+
+	artwork, err := app.FindRecordById(constants.CollectionArtworks, artworkId)
+	if err != nil || !artwork.GetBool("published") {
+		return utils.NotFoundError(c)
+	}
+
+	_, err = app.FindFirstRecordByFilter(
+		constants.CollectionUserCollectionItems,
+		"collection = {:collection} && artwork = {:artwork}",
+		dbx.Params{"collection": col.Id, "artwork": artwork.Id},
+	)
+	if err == nil {
+		utils.SendToastMessage("This artwork is already in "+col.GetString("name"), "info", false, c, "")
+		return presentArtworkActions(app, c, artwork.Id)
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		app.Logger().Error("Error finding collection item", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	if err := appendCollectionItem(app, col, artwork); err != nil {
+		app.Logger().Error("Error adding collection item", "id", col.Id, "error", err.Error())
+		utils.SendToastMessage("Failed to add the artwork to the collection", "error", false, c, "")
+		return utils.ServerFaultError(c)
+	}
+
+	utils.SendToastMessage("Added to "+col.GetString("name"), "success", false, c, "")
+
+	return presentArtworkActions(app, c, artwork.Id)
+}
+
+func appendCollectionItem(app core.App, col *core.Record, artwork *core.Record) error {
+	collection, err := app.FindCollectionByNameOrId(constants.CollectionUserCollectionItems)
+	if err != nil {
+		return err
+	}
+
+	var last struct {
+		Position int `db:"position"`
+	}
+
+	err = app.DB().
+		Select("COALESCE(MAX([[position]]), 0) AS position").
+		From(constants.CollectionUserCollectionItems).
+		Where(dbx.HashExp{"collection": col.Id}).
+		One(&last)
+	if err != nil {
+		return err
+	}
+
+	item := core.NewRecord(collection)
+	item.Set("collection", col.Id)
+	item.Set("artwork", artwork.Id)
+	item.Set("position", last.Position+1)
+
+	return app.Save(item)
+}
+
+// findOwnedCollectionItem returns the item of the request when its collection belongs to the signed in user.
+func findOwnedCollectionItem(app core.App, c *core.RequestEvent) (*core.Record, *core.Record, error) {
+	col, err := findOwnedCollection(app, c, c.Request.PathValue("id"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	item, err := app.FindRecordById(constants.CollectionUserCollectionItems, c.Request.PathValue("itemId"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if item.GetString("collection") != col.Id {
+		return nil, nil, sql.ErrNoRows
+	}
+
+	return col, item, nil
+}
+
+func updateCollectionItem(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	col, item, err := findOwnedCollectionItem(app, c)
+	if err != nil {
+		return utils.NotFoundError(c)
+	}
+
+	note := strings.TrimSpace(c.Request.FormValue("note"))
+	if len(note) > maxCollectionNoteLength {
+		return utils.BadRequestError(c)
+	}
+
+	item.Set("note", note)
+
+	if err := app.Save(item); err != nil {
+		app.Logger().Error("Error updating collection item", "id", item.Id, "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	return c.Redirect(http.StatusSeeOther, collectionUrl(col.Id))
+}
+
+func moveCollectionItem(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	col, item, err := findOwnedCollectionItem(app, c)
+	if err != nil {
+		return utils.NotFoundError(c)
+	}
+
+	offset := 0
+	switch c.Request.FormValue("direction") {
+	case "up":
+		offset = -1
+	case "down":
+		offset = 1
+	default:
+		return utils.BadRequestError(c)
+	}
+
+	err = app.RunInTransaction(func(txApp core.App) error {
+		items, err := findCollectionItems(txApp, col.Id)
+		if err != nil {
+			return err
+		}
+
+		return saveOrder(txApp, moveItem(items, item.Id, offset))
+	})
+	if err != nil {
+		app.Logger().Error("Error moving collection item", "id", item.Id, "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	return c.Redirect(http.StatusSeeOther, collectionUrl(col.Id))
+}
+
+// moveItem moves the item with the given id by offset places, staying within the slice.
+func moveItem(items []*core.Record, id string, offset int) []*core.Record {
+	for i, item := range items {
+		if item.Id != id {
+			continue
+		}
+
+		j := i + offset
+		if j < 0 || j >= len(items) {
+			return items
+		}
+
+		items[i], items[j] = items[j], items[i]
+		return items
+	}
+
+	return items
+}
+
+// saveOrder numbers the items from 1 in the order given, saving only those whose position changed.
+func saveOrder(app core.App, items []*core.Record) error {
+	for i, item := range items {
+		if item.GetInt("position") == i+1 {
+			continue
+		}
+
+		item.Set("position", i+1)
+
+		if err := app.Save(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func removeCollectionItem(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	col, item, err := findOwnedCollectionItem(app, c)
+	if err != nil {
+		return utils.NotFoundError(c)
+	}
+
+	if err := app.Delete(item); err != nil {
+		app.Logger().Error("Error removing collection item", "id", item.Id, "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	return c.Redirect(http.StatusSeeOther, collectionUrl(col.Id))
+}
+
+// buildCollectionPage collects everything the collection pages show.
+func buildCollectionPage(app core.App, c *core.RequestEvent, col *core.Record) (pages.CollectionPageDTO, error) {
+	url := collectionUrl(col.Id)
+
+	content := pages.CollectionPageDTO{
+		Id:           col.Id,
+		Name:         col.GetString("name"),
+		Description:  col.GetString("description"),
+		OwnerName:    "a visitor",
+		Public:       col.GetBool("public"),
+		IsOwner:      isOwner(c, col),
+		Url:          url,
+		ShareUrl:     utils.AssetUrl(url),
+		PostcardsUrl: url + "/postcards",
+		SlideshowUrl: url + "/slideshow",
+	}
+
+	// The owner is named only by the name they chose, their email address stays private.
+	if owner, err := app.FindRecordById(constants.CollectionUsers, col.GetString("owner")); err == nil {
+		if name := owner.GetString("name"); name != "" {
+			content.OwnerName = name
+		}
+	}
+
+	items, err := findCollectionItems(app, col.Id)
+	if err != nil {
+		return content, err
+	}
+
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.GetString("artwork"))
+	}
+
+	images, err := artworkImages(app, ids)
+	if err != nil {
+		return content, err
+	}
+
+	for _, item := range items {
+		img, ok := images[item.GetString("artwork")]
+		if !ok {
+			continue
+		}
+
+		note := item.GetString("note")
+
+		// The image grid shows the note in place of the artwork's comment,
+		// which is trusted HTML, so the note has to be escaped.
+		gridImage := img
+		gridImage.Comment = html.EscapeString(note)
+		content.Images = append(content.Images, gridImage)
+
+		content.Items = append(content.Items, pages.CollectionItem{
+			Id:    item.Id,
+			Note:  note,
+			Image: img,
+		})
+	}
+
+	if len(content.Items) > 0 {
+		content.Items[0].First = true
+		content.Items[len(content.Items)-1].Last = true
+	}
+
+	return content, nil
+}
+
+func processCollection(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	col, err := findViewableCollection(app, c, c.Request.PathValue("id"))
+	if err != nil {
+		return utils.NotFoundError(c)
+	}
+
+	content, err := buildCollectionPage(app, c, col)
+	if err != nil {
+		app.Logger().Error("Error building collection page", "id", col.Id, "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	if content.IsOwner || !content.Public {
+		c.Response.Header().Set("Cache-Control", "private, no-store")
+	}
+
+	ctx := tmplUtils.DecorateContext(context.Background(), tmplUtils.TitleKey, content.Name)
+
+	c.Response.Header().Set("HX-Push-Url", content.Url)
+
+	var buff bytes.Buffer
+
+	if err := pages.CollectionPage(content).Render(ctx, &buff); err != nil {
+		app.Logger().Error("Error rendering collection page", "id", col.Id, "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	return c.HTML(http.StatusOK, buff.String())
+}
+
+// processCollectionExport renders the collection as printable postcards or as a slideshow.
+func processCollectionExport(app *pocketbase.PocketBase, c *core.RequestEvent, kind exportKind) error {
+	col, err := findViewableCollection(app, c, c.Request.PathValue("id"))
+	if err != nil {
+		return utils.NotFoundError(c)
+	}
+
+	content, err := buildCollectionPage(app, c, col)
+	if err != nil {
+		app.Logger().Error("Error building collection export", "id", col.Id, "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	var page templ.Component
+	title := content.Name
+
+	switch kind {
+	case exportPostcards:
+		page = pages.CollectionPostcardsPage(content)
+		title += " - postcards"
+	default:
+		page = pages.CollectionSlideshowPage(content)
+		title += " - slideshow"
+	}
+
+	if !content.Public {
+		c.Response.Header().Set("Cache-Control", "private, no-store")
+	}
+
+	ctx := tmplUtils.DecorateContext(context.Background(), tmplUtils.TitleKey, title)
+
+	var buff bytes.Buffer
+
+	if err := page.Render(ctx, &buff); err != nil {
+		app.Logger().Error("Error rendering collection export", "id", col.Id, "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	return c.HTML(http.StatusOK, buff.String())
+}
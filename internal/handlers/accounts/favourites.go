@@ -0,0 +1,123 @@
+package accounts
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/blackfyre/wga/internal/assets/templ/components"
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/blackfyre/wga/internal/utils/session"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// presentArtworkActions renders the favourite and collection controls of an artwork page.
+func presentArtworkActions(app *pocketbase.PocketBase, c *core.RequestEvent, artworkId string) error {
+	content := components.ArtworkActionsDTO{
+		ArtworkId: artworkId,
+		ReturnUrl: currentPath(c),
+	}
+
+	if user := session.User(c); user != nil {
+		content.SignedIn = true
+
+		favourite, err := findFavourite(app, user, artworkId)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			app.Logger().Error("Error finding favourite", "error", err.Error())
+			return utils.ServerFaultError(c)
+		}
+		content.Favourite = favourite != nil
+
+		collections, err := findUserCollections(app, user)
+		if err != nil {
+			app.Logger().Error("Error finding collections", "error", err.Error())
+			return utils.ServerFaultError(c)
+		}
+
+		for _, col := range collections {
+			_, err := app.FindFirstRecordByFilter(
+				constants.CollectionUserCollectionItems,
+				"collection = {:collection} && artwork = {:artwork}",
+				dbx.Params{"collection": col.Id, "artwork": artworkId},
+			)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				app.Logger().Error("Error finding collection item", "error", err.Error())
+				return utils.ServerFaultError(c)
+			}
+
+			content.Collections = append(content.Collections, components.CollectionOption{
+				Id:       col.Id,
+				Name:     col.GetString("name"),
+				Contains: err == nil,
+			})
+		}
+	}
+
+	var buff bytes.Buffer
+
+	if err := components.ArtworkActions(content).Render(context.Background(), &buff); err != nil {
+		app.Logger().Error("Error rendering artwork actions", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	c.Response.Header().Set("Cache-Control", "private, no-store")
+
+	return c.HTML(http.StatusOK, buff.String())
+}
+
+// toggleFavourite adds the artwork to the favourites of the user, or removes it
+// when it is already there.
+func toggleFavourite(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	user := session.User(c)
+	if user == nil {
+		return signInRedirect(c, currentPath(c))
+	}
+
+	artwork, err := app.FindRecordById(constants.CollectionArtworks, c.Request.PathValue("id"))
+	if err != nil || !artwork.GetBool("published") {
+		return utils.NotFoundError(c)
+	}
+
+	favourite, err := findFavourite(app, user, artwork.Id)
+
+	switch {
+	case err == nil:
+		err = app.Delete(favourite)
+	case errors.Is(err, sql.ErrNoRows):
+		err = saveFavourite(app, user, artwork)
+	}
+
+	if err != nil {
+		app.Logger().Error("Error updating favourite", "error", err.Error())
+		utils.SendToastMessage("Failed to update your favourites", "error", false, c, "")
+		return utils.ServerFaultError(c)
+	}
+
+	return presentArtworkActions(app, c, artwork.Id)
+}
+
+func findFavourite(app core.App, user *core.Record, artworkId string) (*core.Record, error) {
+	return app.FindFirstRecordByFilter(
+		constants.CollectionFavourites,
+		"user = {:user} && artwork = {:artwork}",
+		dbx.Params{"user": user.Id, "artwork": artworkId},
+	)
+}
+
+func saveFavourite(app core.App, user *core.Record, artwork *core.Record) error {
+	collection, err := app.FindCollectionByNameOrId(constants.CollectionFavourites)
+	if err != nil {
+		return err
+	}
+
+	favourite := core.NewRecord(collection)
+	favourite.Set("user", user.Id)
+	favourite.Set("artwork", artwork.Id)
+
+	return app.Save(favourite)
+}
@@ -0,0 +1,84 @@
+package accounts
+
+import (
+	"github.com/blackfyre/wga/internal/assets/templ/dto"
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/blackfyre/wga/internal/utils/url"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// artworkImages returns the grid images of the artworks with the given ids, by id.
+// Unpublished artworks, and artworks without a published author to link them
+// to, are left out.
+func artworkImages(app core.App, ids []string) (map[string]dto.Image, error) {
+	images := map[string]dto.Image{}
+	if len(ids) == 0 {
+		return images, nil
+	}
+
+	artworks, err := app.FindRecordsByIds(constants.CollectionArtworks, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	authorIds := []string{}
+	for _, aw := range artworks {
+		if authors := aw.GetStringSlice("author"); len(authors) > 0 {
+			authorIds = append(authorIds, authors[0])
+		}
+	}
+
+	authors, err := app.FindRecordsByIds(constants.CollectionArtists, authorIds)
+	if err != nil {
+		return nil, err
+	}
+
+	authorsById := map[string]*core.Record{}
+	for _, a := range authors {
+		if a.GetBool("published") {
+			authorsById[a.Id] = a
+		}
+	}
+
+	for _, aw := range artworks {
+		authorIds := aw.GetStringSlice("author")
+		if !aw.GetBool("published") || len(authorIds) == 0 {
+			continue
+		}
+
+		author, ok := authorsById[authorIds[0]]
+		if !ok {
+			continue
+		}
+
+		img := dto.Image{
+			Id:        aw.Id,
+			Title:     aw.GetString("title"),
+			Technique: aw.GetString("technique"),
+			Comment:   aw.GetString("comment"),
+			Url: url.GenerateFullArtworkUrl(url.ArtworkUrlDTO{
+				ArtistName:   author.GetString("name"),
+				ArtistId:     author.Id,
+				ArtworkTitle: aw.GetString("title"),
+				ArtworkId:    aw.Id,
+			}),
+			Artist: dto.Artist{
+				Id:   author.Id,
+				Name: author.GetString("name"),
+			},
+		}
+
+		if aw.GetString("image") != "" {
+			img.Image = url.GenerateFileUrl(constants.CollectionArtworks, aw.Id, aw.GetString("image"), "")
+			img.Thumb = url.GenerateThumbUrl(constants.CollectionArtworks, aw.Id, aw.GetString("image"), "320x240", "")
+		} else {
+			img.Image = utils.AssetUrl("/assets/images/no-image.png")
+			img.Thumb = utils.AssetUrl("/assets/images/no-image.png")
+		}
+
+		images[aw.Id] = img
+	}
+
+	return images, nil
+}
@@ -0,0 +1,93 @@
+package accounts
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// loginAttempts and signupAttempts bound the sign in and sign up attempts by client ip and by
+// email address, so passwords cannot be guessed and accounts cannot be created in bulk.
+var (
+	loginAttempts  = newAttemptLimiter(10, 15*time.Minute)
+	signupAttempts = newAttemptLimiter(5, time.Hour)
+)
+
+// attemptLimiter bounds the attempts made under a key, like a client ip or an email address,
+// to max in a window starting with the first attempt under the key.
+type attemptLimiter struct {
+	max    int
+	window time.Duration
+	now    func() time.Time
+
+	mu       sync.Mutex
+	attempts map[string]*attemptWindow
+	swept    time.Time
+}
+
+type attemptWindow struct {
+	start time.Time
+	count int
+}
+
+func newAttemptLimiter(max int, window time.Duration) *attemptLimiter {
+	return &attemptLimiter{
+		max:      max,
+		window:   window,
+		now:      time.Now,
+		attempts: map[string]*attemptWindow{},
+	}
+}
+
+// allow records an attempt under every key and reports whether none of them went over the
+// limit. Empty keys are ignored.
+func (l *attemptLimiter) allow(keys ...string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	// forget the windows that are over, so the keys do not pile up
+	if now.Sub(l.swept) >= l.window {
+		for key, w := range l.attempts {
+			if now.Sub(w.start) >= l.window {
+				delete(l.attempts, key)
+			}
+		}
+		l.swept = now
+	}
+
+	allowed := true
+
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+
+		w, ok := l.attempts[key]
+		if !ok || now.Sub(w.start) >= l.window {
+			w = &attemptWindow{start: now}
+			l.attempts[key] = w
+		}
+
+		w.count++
+		if w.count > l.max {
+			allowed = false
+		}
+	}
+
+	return allowed
+}
+
+// attemptKeys returns the keys the attempts of the request with the email are counted under.
+func attemptKeys(c *core.RequestEvent, email string) []string {
+	keys := []string{"ip:" + c.RealIP()}
+
+	if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+		keys = append(keys, "email:"+email)
+	}
+
+	return keys
+}
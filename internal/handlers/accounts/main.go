@@ -0,0 +1,141 @@
+package accounts
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/blackfyre/wga/internal/utils/session"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func RegisterHandlers(app *pocketbase.PocketBase) {
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		se.Router.BindFunc(session.LoadAuth)
+
+		ag := se.Router.Group("/account")
+
+		ag.GET("", func(c *core.RequestEvent) error {
+			return processAccount(app, c)
+		})
+
+		ag.GET("/login", func(c *core.RequestEvent) error {
+			return presentLogin(app, c)
+		})
+
+		ag.POST("/login", func(c *core.RequestEvent) error {
+			return processLogin(app, c)
+		})
+
+		ag.GET("/signup", func(c *core.RequestEvent) error {
+			return presentSignup(app, c)
+		})
+
+		ag.POST("/signup", func(c *core.RequestEvent) error {
+			return processSignup(app, c)
+		})
+
+		ag.POST("/logout", func(c *core.RequestEvent) error {
+			session.SignOut(c)
+			return c.Redirect(http.StatusSeeOther, "/")
+		})
+
+		ag.GET("/menu", func(c *core.RequestEvent) error {
+			return presentMenu(app, c)
+		}).BindFunc(utils.IsHtmxRequestMiddleware)
+
+		ag.GET("/artworks/{id}/actions", func(c *core.RequestEvent) error {
+			return presentArtworkActions(app, c, c.Request.PathValue("id"))
+		}).BindFunc(utils.IsHtmxRequestMiddleware)
+
+		se.Router.POST("/favourites/{id}", func(c *core.RequestEvent) error {
+			return toggleFavourite(app, c)
+		}).BindFunc(utils.IsHtmxRequestMiddleware)
+
+		cg := se.Router.Group("/collections")
+
+		cg.POST("", func(c *core.RequestEvent) error {
+			return createCollection(app, c)
+		})
+
+		cg.GET("/{id}", func(c *core.RequestEvent) error {
+			return processCollection(app, c)
+		})
+
+		cg.POST("/{id}", func(c *core.RequestEvent) error {
+			return updateCollection(app, c)
+		})
+
+		cg.POST("/{id}/delete", func(c *core.RequestEvent) error {
+			return deleteCollection(app, c)
+		})
+
+		cg.POST("/{id}/items", func(c *core.RequestEvent) error {
+			return addCollectionItem(app, c)
+		}).BindFunc(utils.IsHtmxRequestMiddleware)
+
+		cg.POST("/{id}/items/{itemId}", func(c *core.RequestEvent) error {
+			return updateCollectionItem(app, c)
+		})
+
+		cg.POST("/{id}/items/{itemId}/move", func(c *core.RequestEvent) error {
+			return moveCollectionItem(app, c)
+		})
+
+		cg.POST("/{id}/items/{itemId}/delete", func(c *core.RequestEvent) error {
+			return removeCollectionItem(app, c)
+		})
+
+		cg.GET("/{id}/postcards", func(c *core.RequestEvent) error {
+			return processCollectionExport(app, c, exportPostcards)
+		})
+
+		cg.GET("/{id}/slideshow", func(c *core.RequestEvent) error {
+			return processCollectionExport(app, c, exportSlideshow)
+		})
+
+		return se.Next()
+	})
+}
+
+// signInRedirect sends the visitor to the sign in page, coming back to next afterwards.
+// HTMX requests are redirected with a full page load.
+func signInRedirect(c *core.RequestEvent, next string) error {
+	target := "/account/login"
+	if next != "" {
+		target += "?" + url.Values{"next": {next}}.Encode()
+	}
+
+	if utils.IsHtmxRequest(c) {
+		c.Response.Header().Set("HX-Redirect", target)
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	return c.Redirect(http.StatusSeeOther, target)
+}
+
+// safeNext returns next when it is a path on this site, and the account page otherwise,
+// so the sign in form cannot be used to redirect to another site.
+func safeNext(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/account"
+	}
+
+	return next
+}
+
+// currentPath returns the path of the page an HTMX request was made from.
+func currentPath(c *core.RequestEvent) string {
+	u, err := url.Parse(c.Request.Header.Get("HX-Current-URL"))
+	if err != nil || u.Path == "" {
+		return ""
+	}
+
+	if u.RawQuery != "" {
+		return u.Path + "?" + u.RawQuery
+	}
+
+	return u.Path
+}
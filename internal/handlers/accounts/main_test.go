@@ -0,0 +1,190 @@
+package accounts
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestSafeNext(t *testing.T) {
+	cases := map[string]string{
+		"":                      "/account",
+		"/artists/botticelli":   "/artists/botticelli",
+		"/collections/abc?x=1":  "/collections/abc?x=1",
+		"https://example.com":   "/account",
+		"//example.com/path":    "/account",
+		"/\\example.com":        "/account",
+		"javascript:alert(1)":   "/account",
+		"artists/relative-path": "/account",
+	}
+
+	for next, want := range cases {
+		if got := safeNext(next); got != want {
+			t.Errorf("safeNext(%q) = %q, want %q", next, got, want)
+		}
+	}
+}
+
+func TestAttemptLimiterBoundsEveryKey(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	limiter := newAttemptLimiter(3, 10*time.Minute)
+	limiter.now = func() time.Time { return now }
+
+	for i := range 3 {
+		if !limiter.allow("ip:10.0.0.1", fmt.Sprintf("email:user%d@example.com", i)) {
+			t.Fatalf("attempt %d rejected within the limit", i)
+		}
+	}
+
+	// the ip is over its limit whatever the email
+	if limiter.allow("ip:10.0.0.1", "email:other@example.com") {
+		t.Fatalf("expected the attempts of the ip to be bounded")
+	}
+
+	// and an email is bounded whatever the ip
+	for i := range 3 {
+		limiter.allow(fmt.Sprintf("ip:10.0.1.%d", i), "email:victim@example.com")
+	}
+	if limiter.allow("ip:10.0.2.1", "email:victim@example.com") {
+		t.Fatalf("expected the attempts on the email to be bounded")
+	}
+
+	now = now.Add(10 * time.Minute)
+
+	if !limiter.allow("ip:10.0.0.1", "email:victim@example.com") {
+		t.Fatalf("expected the attempts to be allowed again in a new window")
+	}
+
+	if len(limiter.attempts) != 2 {
+		t.Fatalf("expected the windows that are over to be forgotten, got %d", len(limiter.attempts))
+	}
+}
+
+func TestMoveItemStaysWithinTheCollection(t *testing.T) {
+	collection := core.NewBaseCollection("items")
+
+	newItems := func() []*core.Record {
+		items := []*core.Record{}
+		for _, id := range []string{"a", "b", "c"} {
+			r := core.NewRecord(collection)
+			r.Id = id
+			items = append(items, r)
+		}
+		return items
+	}
+
+	ids := func(items []*core.Record) []string {
+		out := []string{}
+		for _, r := range items {
+			out = append(out, r.Id)
+		}
+		return out
+	}
+
+	cases := []struct {
+		id     string
+		offset int
+		want   []string
+	}{
+		{"b", -1, []string{"b", "a", "c"}},
+		{"b", 1, []string{"a", "c", "b"}},
+		{"a", -1, []string{"a", "b", "c"}},
+		{"c", 1, []string{"a", "b", "c"}},
+		{"missing", 1, []string{"a", "b", "c"}},
+	}
+
+	for _, tc := range cases {
+		if got := ids(moveItem(newItems(), tc.id, tc.offset)); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("moveItem(%q, %d) = %v, want %v", tc.id, tc.offset, got, tc.want)
+		}
+	}
+}
+
+func TestCollectionAccess(t *testing.T) {
+	app, err := tests.NewTestApp()
+	if err != nil {
+		t.Fatalf("create test app: %v", err)
+	}
+	t.Cleanup(app.Cleanup)
+
+	users, err := app.FindCollectionByNameOrId(constants.CollectionUsers)
+	if err != nil {
+		t.Fatalf("find users collection: %v", err)
+	}
+
+	collections := core.NewBaseCollection("User_collections")
+	collections.Id = constants.CollectionUserCollections
+	collections.Fields.Add(
+		&core.RelationField{Name: "owner", CollectionId: users.Id, MaxSelect: 1},
+		&core.TextField{Name: "name"},
+		&core.BoolField{Name: "public"},
+	)
+	if err := app.Save(collections); err != nil {
+		t.Fatalf("save collections collection: %v", err)
+	}
+
+	owner := newUser(t, app, users, "owner@example.test")
+	other := newUser(t, app, users, "other@example.test")
+
+	private := core.NewRecord(collections)
+	private.Set("owner", owner.Id)
+	private.Set("name", "Private")
+	if err := app.Save(private); err != nil {
+		t.Fatalf("save collection: %v", err)
+	}
+
+	public := core.NewRecord(collections)
+	public.Set("owner", owner.Id)
+	public.Set("name", "Public")
+	public.Set("public", true)
+	if err := app.Save(public); err != nil {
+		t.Fatalf("save collection: %v", err)
+	}
+
+	cases := []struct {
+		name     string
+		auth     *core.Record
+		id       string
+		viewable bool
+		owned    bool
+	}{
+		{"owner sees private", owner, private.Id, true, true},
+		{"other user cannot see private", other, private.Id, false, false},
+		{"visitor cannot see private", nil, private.Id, false, false},
+		{"visitor sees public", nil, public.Id, true, false},
+		{"other user cannot change public", other, public.Id, true, false},
+		{"owner changes public", owner, public.Id, true, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &core.RequestEvent{App: app, Auth: tc.auth}
+
+			if _, err := findViewableCollection(app, c, tc.id); (err == nil) != tc.viewable {
+				t.Errorf("viewable = %v, want %v", err == nil, tc.viewable)
+			}
+
+			if _, err := findOwnedCollection(app, c, tc.id); (err == nil) != tc.owned {
+				t.Errorf("owned = %v, want %v", err == nil, tc.owned)
+			}
+		})
+	}
+}
+
+func newUser(t *testing.T, app core.App, users *core.Collection, email string) *core.Record {
+	t.Helper()
+
+	user := core.NewRecord(users)
+	user.SetEmail(email)
+	user.SetPassword("correct horse battery")
+	if err := app.Save(user); err != nil {
+		t.Fatalf("save user: %v", err)
+	}
+
+	return user
+}
@@ -0,0 +1,224 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"maps"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/blackfyre/wga/internal/utils"
+	wgaUrl "github.com/blackfyre/wga/internal/utils/url"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+type Artist struct {
+	Id           string   `json:"id"`
+	Name         string   `json:"name"`
+	Url          string   `json:"url"`
+	Profession   string   `json:"profession"`
+	YearOfBirth  int      `json:"year_of_birth"`
+	YearOfDeath  int      `json:"year_of_death"`
+	PlaceOfBirth string   `json:"place_of_birth"`
+	PlaceOfDeath string   `json:"place_of_death"`
+	Bio          string   `json:"bio"`
+	Schools      []string `json:"schools"`
+	Periods      []string `json:"periods"`
+}
+
+// ArtistRef is the short form of an artist embedded in other resources.
+type ArtistRef struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	Url  string `json:"url"`
+}
+
+// ArtistNetwork is the graph of the relationships around an artist. The centre is the first node.
+type ArtistNetwork struct {
+	Nodes     []NetworkNode              `json:"nodes"`
+	Edges     []repositories.NetworkEdge `json:"edges"`
+	Truncated bool                       `json:"truncated"`
+}
+
+// NetworkNode is an artist of a network. Depth is the number of relationships from the centre.
+type NetworkNode struct {
+	Id    string `json:"id"`
+	Name  string `json:"name"`
+	Url   string `json:"url"`
+	Depth int    `json:"depth"`
+}
+
+// maxNetworkDepth bounds how many relationships away from the artist a network reaches.
+const maxNetworkDepth = 2
+
+func listArtists(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	q := c.Request.URL.Query()
+
+	limit, err := parseLimit(q.Get("limit"))
+	if err != nil {
+		return apis.NewBadRequestError(err.Error(), nil)
+	}
+
+	filter, params, err := artistFilter(app, q)
+	if err != nil {
+		app.Logger().Error("Failed to build API artist filter", "error", err.Error())
+		return apis.NewInternalServerError("", nil)
+	}
+
+	records, next, err := findPage(repositories.NewArtistsRepository(app).FindPage, repositories.RecordFilter{Filter: filter, Params: params}, q.Get("cursor"), limit)
+	if errors.Is(err, repositories.ErrInvalidCursor) {
+		return apis.NewBadRequestError("Invalid cursor.", nil)
+	}
+	if err != nil {
+		app.Logger().Error("Failed to get API artist records", "error", err.Error())
+		return apis.NewInternalServerError("", nil)
+	}
+
+	artists, err := newArtists(app, records)
+	if err != nil {
+		app.Logger().Error("Failed to resolve API artist relations", "error", err.Error())
+		return apis.NewInternalServerError("", nil)
+	}
+
+	return c.JSON(http.StatusOK, Page[Artist]{Data: artists, NextCursor: next})
+}
+
+func showArtist(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	record, err := app.FindRecordById(constants.CollectionArtists, c.Request.PathValue("id"))
+	if err != nil || !record.GetBool("published") {
+		return apis.NewNotFoundError("", nil)
+	}
+
+	artists, err := newArtists(app, []*core.Record{record})
+	if err != nil {
+		app.Logger().Error("Failed to resolve API artist relations", "error", err.Error())
+		return apis.NewInternalServerError("", nil)
+	}
+
+	return c.JSON(http.StatusOK, artists[0])
+}
+
+func showArtistNetwork(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	depth, err := parseDepth(c.Request.URL.Query().Get("depth"))
+	if err != nil {
+		return apis.NewBadRequestError(err.Error(), nil)
+	}
+
+	record, err := app.FindRecordById(constants.CollectionArtists, c.Request.PathValue("id"))
+	if err != nil || !record.GetBool("published") {
+		return apis.NewNotFoundError("", nil)
+	}
+
+	network, err := repositories.NewArtistsRepository(app).FindNetwork(record, depth)
+	if err != nil {
+		app.Logger().Error("Failed to find API artist network", "error", err.Error())
+		return apis.NewInternalServerError("", nil)
+	}
+
+	return c.JSON(http.StatusOK, newArtistNetwork(network))
+}
+
+// parseDepth returns the requested network depth, defaulting to 1.
+func parseDepth(raw string) (int, error) {
+	if raw == "" {
+		return 1, nil
+	}
+
+	depth, err := strconv.Atoi(raw)
+	if err != nil || depth < 1 || depth > maxNetworkDepth {
+		return 0, errors.New("depth must be between 1 and " + strconv.Itoa(maxNetworkDepth))
+	}
+
+	return depth, nil
+}
+
+func newArtistNetwork(network repositories.Network) ArtistNetwork {
+	nodes := make([]NetworkNode, 0, len(network.Nodes))
+	for _, n := range network.Nodes {
+		nodes = append(nodes, NetworkNode{
+			Id:    n.Id,
+			Name:  n.Name,
+			Url:   utils.AssetUrl("/artists/" + n.Slug + "-" + n.Id),
+			Depth: n.Depth,
+		})
+	}
+
+	return ArtistNetwork{Nodes: nodes, Edges: network.Edges, Truncated: network.Truncated}
+}
+
+// artistFilter builds the artist filter from the query. It accepts the artist
+// counterparts of the artwork search vocabulary: name, art_school and period.
+func artistFilter(app core.App, q url.Values) (string, dbx.Params, error) {
+	filter := "published = true"
+	params := dbx.Params{}
+
+	if name := q.Get("name"); name != "" {
+		filter = filter + " && " + repositories.ArtistNameFilter("", "~", "name")
+		params["name"] = name
+	}
+
+	if school := q.Get("art_school"); school != "" {
+		filter = filter + " && school.slug = {:art_school}"
+		params["art_school"] = school
+	}
+
+	if slug := q.Get("period"); slug != "" {
+		period, err := repositories.NewPeriodsRepository(app).FindPeriodBySlug(slug)
+		if errors.Is(err, sql.ErrNoRows) {
+			return filter + " && id = ''", params, nil
+		}
+		if err != nil {
+			return "", nil, err
+		}
+
+		filter = filter + " && " + repositories.ActiveInPeriodFilter("")
+		maps.Copy(params, period.FilterParams())
+	}
+
+	return filter, params, nil
+}
+
+func newArtists(app core.App, records []*core.Record) ([]Artist, error) {
+	schools, err := nameLookup(app, constants.CollectionSchools)
+	if err != nil {
+		return nil, err
+	}
+
+	periods, err := artPeriods(app)
+	if err != nil {
+		return nil, err
+	}
+
+	artists := make([]Artist, 0, len(records))
+	for _, r := range records {
+		artists = append(artists, Artist{
+			Id:           r.Id,
+			Name:         r.GetString("name"),
+			Url:          utils.AssetUrl(wgaUrl.GenerateArtistUrlFromRecord(r)),
+			Profession:   r.GetString("profession"),
+			YearOfBirth:  r.GetInt("year_of_birth"),
+			YearOfDeath:  r.GetInt("year_of_death"),
+			PlaceOfBirth: r.GetString("place_of_birth"),
+			PlaceOfDeath: r.GetString("place_of_death"),
+			Bio:          r.GetString("bio"),
+			Schools:      resolveNames(r.GetStringSlice("school"), schools),
+			Periods:      periodNames(periods, r.GetInt("year_of_birth"), r.GetInt("year_of_death")),
+		})
+	}
+
+	return artists, nil
+}
+
+func newArtistRef(r *core.Record) ArtistRef {
+	return ArtistRef{
+		Id:   r.Id,
+		Name: r.GetString("name"),
+		Url:  utils.AssetUrl(wgaUrl.GenerateArtistUrlFromRecord(r)),
+	}
+}
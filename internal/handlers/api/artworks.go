@@ -0,0 +1,180 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/handlers/artworks"
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/blackfyre/wga/internal/utils/dating"
+	wgaUrl "github.com/blackfyre/wga/internal/utils/url"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+type Artwork struct {
+	Id        string          `json:"id"`
+	Title     string          `json:"title"`
+	Url       string          `json:"url"`
+	ImageUrl  string          `json:"image_url"`
+	Technique string          `json:"technique"`
+	Comment   string          `json:"comment"`
+	Date      string          `json:"date"`
+	YearFrom  int             `json:"year_from"`
+	YearTo    int             `json:"year_to"`
+	Circa     bool            `json:"circa"`
+	Artists   []ArtworkArtist `json:"artists"`
+	Schools   []string        `json:"schools"`
+	Forms     []string        `json:"forms"`
+	Types     []string        `json:"types"`
+	Periods   []string        `json:"periods"`
+}
+
+// ArtworkArtist is an artist credited with an artwork. Role is the attribution role, like
+// "workshop", empty when the artwork is the artist's own.
+type ArtworkArtist struct {
+	ArtistRef
+	Role string `json:"role"`
+}
+
+func listArtworks(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	q := c.Request.URL.Query()
+
+	limit, err := parseLimit(q.Get("limit"))
+	if err != nil {
+		return apis.NewBadRequestError(err.Error(), nil)
+	}
+
+	filter, err := artworks.QueryFilter(app, q)
+	var queryErr *artworks.QueryError
+	if errors.As(err, &queryErr) {
+		return apis.NewBadRequestError(queryErr.Message, nil)
+	}
+	if err != nil {
+		app.Logger().Error("Failed to build API artwork filter", "error", err.Error())
+		return apis.NewInternalServerError("", nil)
+	}
+
+	records, next, err := findPage(repositories.NewArtworksRepository(app).FindPage, filter, q.Get("cursor"), limit)
+	if errors.Is(err, repositories.ErrInvalidCursor) {
+		return apis.NewBadRequestError("Invalid cursor.", nil)
+	}
+	if err != nil {
+		app.Logger().Error("Failed to get API artwork records", "error", err.Error())
+		return apis.NewInternalServerError("", nil)
+	}
+
+	data, err := newArtworks(app, records)
+	if err != nil {
+		app.Logger().Error("Failed to resolve API artwork relations", "error", err.Error())
+		return apis.NewInternalServerError("", nil)
+	}
+
+	return c.JSON(http.StatusOK, Page[Artwork]{Data: data, NextCursor: next})
+}
+
+func showArtwork(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	record, err := app.FindRecordById(constants.CollectionArtworks, c.Request.PathValue("id"))
+	if err != nil || !record.GetBool("published") {
+		return apis.NewNotFoundError("", nil)
+	}
+
+	data, err := newArtworks(app, []*core.Record{record})
+	if err != nil {
+		app.Logger().Error("Failed to resolve API artwork relations", "error", err.Error())
+		return apis.NewInternalServerError("", nil)
+	}
+
+	if len(data) == 0 {
+		return apis.NewNotFoundError("", nil)
+	}
+
+	return c.JSON(http.StatusOK, data[0])
+}
+
+// newArtworks builds the API artworks of the records. Only published authors are
+// listed, and artworks without one are left out, like on the search page.
+func newArtworks(app core.App, records []*core.Record) ([]Artwork, error) {
+	credits, err := repositories.NewArtworksRepository(app).FindAuthors(records)
+	if err != nil {
+		return nil, err
+	}
+
+	schools, err := nameLookup(app, constants.CollectionSchools)
+	if err != nil {
+		return nil, err
+	}
+
+	forms, err := nameLookup(app, constants.CollectionArtForms)
+	if err != nil {
+		return nil, err
+	}
+
+	types, err := nameLookup(app, constants.CollectionArtTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	periods, err := artPeriods(app)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]Artwork, 0, len(records))
+	for _, r := range records {
+		d := dating.FromRecord(r)
+
+		artwork := Artwork{
+			Id:        r.Id,
+			Title:     r.GetString("title"),
+			Technique: r.GetString("technique"),
+			Comment:   r.GetString("comment"),
+			Date:      d.String(),
+			YearFrom:  d.Earliest,
+			YearTo:    d.Latest,
+			Circa:     d.Circa,
+			Artists:   []ArtworkArtist{},
+			Schools:   resolveNames(r.GetStringSlice("school"), schools),
+			Forms:     resolveNames(r.GetStringSlice("form"), forms),
+			Types:     resolveNames(r.GetStringSlice("type"), types),
+			Periods:   []string{},
+		}
+
+		seenPeriods := map[string]bool{}
+		for _, credit := range credits[r.Id] {
+			author := credit.Artist
+
+			artwork.Artists = append(artwork.Artists, ArtworkArtist{ArtistRef: newArtistRef(author), Role: credit.Role})
+
+			for _, name := range periodNames(periods, author.GetInt("year_of_birth"), author.GetInt("year_of_death")) {
+				if !seenPeriods[name] {
+					seenPeriods[name] = true
+					artwork.Periods = append(artwork.Periods, name)
+				}
+			}
+		}
+
+		if len(artwork.Artists) == 0 {
+			continue
+		}
+
+		first := artwork.Artists[0]
+		artwork.Url = utils.AssetUrl(wgaUrl.GenerateFullArtworkUrl(wgaUrl.ArtworkUrlDTO{
+			ArtistName:   first.Name,
+			ArtistId:     first.Id,
+			ArtworkTitle: artwork.Title,
+			ArtworkId:    artwork.Id,
+		}))
+
+		if image := r.GetString("image"); image != "" {
+			artwork.ImageUrl = utils.AssetUrl(wgaUrl.GenerateFileUrl(constants.CollectionArtworks, r.Id, image, ""))
+		}
+
+		data = append(data, artwork)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	defaultLimit = 30
+	maxLimit     = 100
+)
+
+// lookupTTL is how long the id to name lookups of the related collections are cached.
+const lookupTTL = 6 * time.Hour
+
+// Page is the envelope of every list response. NextCursor is null on the last page.
+type Page[T any] struct {
+	Data       []T     `json:"data"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+// RegisterHandlers registers the read-only JSON API.
+// Routes are versioned so the response shapes can stay stable for existing clients.
+func RegisterHandlers(app *pocketbase.PocketBase) {
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		v1 := se.Router.Group("/api/v1")
+
+		v1.GET("/artists", func(c *core.RequestEvent) error {
+			return listArtists(app, c)
+		})
+
+		v1.GET("/artists/{id}", func(c *core.RequestEvent) error {
+			return showArtist(app, c)
+		})
+
+		v1.GET("/artists/{id}/network", func(c *core.RequestEvent) error {
+			return showArtistNetwork(app, c)
+		})
+
+		v1.GET("/artworks", func(c *core.RequestEvent) error {
+			return listArtworks(app, c)
+		})
+
+		v1.GET("/artworks/{id}", func(c *core.RequestEvent) error {
+			return showArtwork(app, c)
+		})
+
+		return se.Next()
+	})
+}
+
+// parseLimit returns the requested page size, defaulting to defaultLimit.
+func parseLimit(raw string) (int, error) {
+	if raw == "" {
+		return defaultLimit, nil
+	}
+
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 || limit > maxLimit {
+		return 0, errors.New("limit must be between 1 and " + strconv.Itoa(maxLimit))
+	}
+
+	return limit, nil
+}
+
+// findPage returns a page of the records matching the filter in the order of the list,
+// starting after the cursor, and the cursor of the next page, which is nil on the last page.
+// find is the FindPage method of the repository of the listed records.
+func findPage(find func(repositories.PageQuery) (repositories.Page, error), filter repositories.RecordFilter, cursor string, limit int) ([]*core.Record, *string, error) {
+	q := repositories.PageQuery{RecordFilter: filter, Limit: limit}
+	if err := q.SetCursors(cursor, ""); err != nil {
+		return nil, nil, err
+	}
+
+	page, err := find(q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !page.More {
+		return page.Records, nil, nil
+	}
+
+	next := page.Last.String()
+
+	return page.Records, &next, nil
+}
+
+// nameLookup returns the names of the records of a collection keyed by id.
+func nameLookup(app core.App, collection string) (map[string]string, error) {
+	return utils.GetOrLoadCachedValue(app, "api:names:"+collection, lookupTTL, func() (map[string]string, error) {
+		records, err := app.FindAllRecords(collection)
+		if err != nil {
+			return nil, err
+		}
+
+		names := make(map[string]string, len(records))
+		for _, r := range records {
+			names[r.Id] = r.GetString("name")
+		}
+
+		return names, nil
+	})
+}
+
+// resolveNames maps ids to names, skipping the ids that don't resolve.
+func resolveNames(ids []string, names map[string]string) []string {
+	resolved := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if name, ok := names[id]; ok {
+			resolved = append(resolved, name)
+		}
+	}
+
+	return resolved
+}
+
+// artPeriods returns every art period in chronological order.
+func artPeriods(app core.App) ([]repositories.ArtPeriod, error) {
+	return utils.GetOrLoadCachedValue(app, "api:art-periods", lookupTTL, func() ([]repositories.ArtPeriod, error) {
+		return repositories.NewPeriodsRepository(app).GetPeriods()
+	})
+}
+
+// periodNames returns the names of the periods an artist born and died in the given years was active in.
+func periodNames(periods []repositories.ArtPeriod, yearOfBirth int, yearOfDeath int) []string {
+	names := []string{}
+	for _, p := range periods {
+		if p.Includes(yearOfBirth, yearOfDeath) {
+			names = append(names, p.Name)
+		}
+	}
+
+	return names
+}
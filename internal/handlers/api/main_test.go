@@ -0,0 +1,224 @@
+package api
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestFindPageWalksPublishedArtistsByCursor(t *testing.T) {
+	app := newApiTestApp(t)
+
+	saveRecord(t, app, "artists", map[string]any{"id": "artist000000001", "name": "Botticelli", "published": true})
+	saveRecord(t, app, "artists", map[string]any{"id": "artist000000002", "name": "Hidden"})
+	saveRecord(t, app, "artists", map[string]any{"id": "artist000000003", "name": "Rubens", "published": true})
+	saveRecord(t, app, "artists", map[string]any{"id": "artist000000004", "name": "Titian", "published": true})
+
+	filter, params, err := artistFilter(app, url.Values{})
+	if err != nil {
+		t.Fatalf("artistFilter: %v", err)
+	}
+
+	ids := []string{}
+	cursor := ""
+	pages := 0
+	for ; pages < 5; pages++ {
+		records, next, err := findPage(repositories.NewArtistsRepository(app).FindPage, repositories.RecordFilter{Filter: filter, Params: params}, cursor, 2)
+		if err != nil {
+			t.Fatalf("findPage: %v", err)
+		}
+
+		for _, r := range records {
+			ids = append(ids, r.Id)
+		}
+
+		if next == nil {
+			break
+		}
+		cursor = *next
+	}
+
+	want := []string{"artist000000001", "artist000000003", "artist000000004"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Fatalf("paged ids = %v, want %v", ids, want)
+	}
+	if pages != 1 {
+		t.Fatalf("walked %d pages after the first, want 1", pages)
+	}
+
+	if _, _, err := findPage(repositories.NewArtistsRepository(app).FindPage, repositories.RecordFilter{Filter: filter, Params: params}, "%%%", 2); err != repositories.ErrInvalidCursor {
+		t.Fatalf("expected an invalid cursor error, got %v", err)
+	}
+}
+
+func TestArtistFilterByPeriod(t *testing.T) {
+	app := newApiTestApp(t)
+
+	saveRecord(t, app, "art_periods", map[string]any{"id": "period000000001", "name": "Baroque", "slug": "baroque", "start": 1600, "end": 1750})
+	saveRecord(t, app, "artists", map[string]any{"id": "artist000000001", "name": "Botticelli", "year_of_birth": 1445, "year_of_death": 1510, "published": true})
+	saveRecord(t, app, "artists", map[string]any{"id": "artist000000002", "name": "Rubens", "year_of_birth": 1577, "year_of_death": 1640, "published": true})
+
+	for slug, want := range map[string]int{"baroque": 1, "unknown": 0} {
+		filter, params, err := artistFilter(app, url.Values{"period": {slug}})
+		if err != nil {
+			t.Fatalf("artistFilter(%s): %v", slug, err)
+		}
+
+		records, _, err := findPage(repositories.NewArtistsRepository(app).FindPage, repositories.RecordFilter{Filter: filter, Params: params}, "", 10)
+		if err != nil {
+			t.Fatalf("findPage(%s): %v", slug, err)
+		}
+		if len(records) != want {
+			t.Errorf("period %s matched %d artists, want %d", slug, len(records), want)
+		}
+	}
+}
+
+func TestNewArtworksResolvesRelations(t *testing.T) {
+	app := newApiTestApp(t)
+
+	saveRecord(t, app, "art_periods", map[string]any{"id": "period000000001", "name": "Renaissance", "slug": "renaissance", "start": 1400, "end": 1600})
+	saveRecord(t, app, "schools", map[string]any{"id": "school000000001", "name": "Italian"})
+	saveRecord(t, app, "art_forms", map[string]any{"id": "artform00000001", "name": "painting"})
+	saveRecord(t, app, "art_types", map[string]any{"id": "arttype00000001", "name": "mythological"})
+	saveRecord(t, app, "artists", map[string]any{"id": "artist000000001", "name": "Botticelli", "year_of_birth": 1445, "year_of_death": 1510, "published": true})
+	saveRecord(t, app, "artists", map[string]any{"id": "artist000000002", "name": "Hidden workshop", "published": false})
+	saveRecord(t, app, "artists", map[string]any{"id": "artist000000003", "name": "Filippino Lippi", "published": true})
+	saveRecord(t, app, "artworks", map[string]any{
+		"id":        "artwork00000001",
+		"title":     "Birth of Venus",
+		"author":    []string{"artist000000001", "artist000000002", "artist000000003"},
+		"school":    []string{"school000000001"},
+		"form":      []string{"artform00000001"},
+		"type":      []string{"arttype00000001"},
+		"published": true,
+	})
+	saveRecord(t, app, "artwork_authors", map[string]any{"artwork": "artwork00000001", "artist": "artist000000003", "role": "workshop"})
+
+	record, err := app.FindRecordById("artworks", "artwork00000001")
+	if err != nil {
+		t.Fatalf("find artwork: %v", err)
+	}
+
+	data, err := newArtworks(app, []*core.Record{record})
+	if err != nil {
+		t.Fatalf("newArtworks: %v", err)
+	}
+	if len(data) != 1 {
+		t.Fatalf("expected one artwork, got %d", len(data))
+	}
+
+	got := data[0]
+	wantArtists := []ArtworkArtist{
+		{ArtistRef: ArtistRef{Id: "artist000000001", Name: "Botticelli"}},
+		{ArtistRef: ArtistRef{Id: "artist000000003", Name: "Filippino Lippi"}, Role: "workshop"},
+	}
+	for i := range got.Artists {
+		got.Artists[i].Url = ""
+	}
+	if !reflect.DeepEqual(got.Artists, wantArtists) {
+		t.Fatalf("artists = %+v, want the published authors in order with their roles", got.Artists)
+	}
+	if !reflect.DeepEqual(got.Schools, []string{"Italian"}) || !reflect.DeepEqual(got.Forms, []string{"painting"}) || !reflect.DeepEqual(got.Types, []string{"mythological"}) {
+		t.Fatalf("unresolved names: %+v", got)
+	}
+	if !reflect.DeepEqual(got.Periods, []string{"Renaissance"}) {
+		t.Fatalf("periods = %v, want [Renaissance]", got.Periods)
+	}
+}
+
+func newApiTestApp(t *testing.T) *tests.TestApp {
+	t.Helper()
+
+	app, err := tests.NewTestApp()
+	if err != nil {
+		t.Fatalf("create test app: %v", err)
+	}
+	t.Cleanup(app.Cleanup)
+
+	periods := core.NewBaseCollection("Art_periods")
+	periods.Id = "art_periods"
+	periods.Fields.Add(
+		&core.TextField{Name: "name"},
+		&core.TextField{Name: "slug"},
+		&core.NumberField{Name: "start"},
+		&core.NumberField{Name: "end"},
+		&core.TextField{Name: "description"},
+	)
+	saveCollection(t, app, periods)
+
+	for _, name := range []string{"schools", "art_forms", "art_types"} {
+		c := core.NewBaseCollection(name)
+		c.Id = name
+		c.Fields.Add(
+			&core.TextField{Name: "name"},
+			&core.TextField{Name: "slug"},
+		)
+		saveCollection(t, app, c)
+	}
+
+	artists := core.NewBaseCollection("Artists")
+	artists.Id = "artists"
+	artists.Fields.Add(
+		&core.TextField{Name: "name"},
+		&core.TextField{Name: "sort_name"},
+		&core.NumberField{Name: "year_of_birth"},
+		&core.NumberField{Name: "year_of_death"},
+		&core.RelationField{Name: "school", CollectionId: "schools", MaxSelect: 10},
+		&core.BoolField{Name: "published"},
+	)
+	saveCollection(t, app, artists)
+
+	artworks := core.NewBaseCollection("Artworks")
+	artworks.Id = "artworks"
+	artworks.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.RelationField{Name: "author", CollectionId: "artists", MaxSelect: 10},
+		&core.RelationField{Name: "school", CollectionId: "schools", MaxSelect: 10},
+		&core.RelationField{Name: "form", CollectionId: "art_forms", MaxSelect: 20},
+		&core.RelationField{Name: "type", CollectionId: "art_types", MaxSelect: 20},
+		&core.BoolField{Name: "published"},
+	)
+	saveCollection(t, app, artworks)
+
+	artworkAuthors := core.NewBaseCollection("Artwork_authors")
+	artworkAuthors.Id = "artwork_authors"
+	artworkAuthors.Fields.Add(
+		&core.RelationField{Name: "artwork", CollectionId: "artworks", MaxSelect: 1, CascadeDelete: true},
+		&core.RelationField{Name: "artist", CollectionId: "artists", MaxSelect: 1, CascadeDelete: true},
+		&core.TextField{Name: "role"},
+	)
+	saveCollection(t, app, artworkAuthors)
+
+	return app
+}
+
+func saveCollection(t *testing.T, app core.App, c *core.Collection) {
+	t.Helper()
+
+	if err := app.Save(c); err != nil {
+		t.Fatalf("save %s collection: %v", c.Name, err)
+	}
+}
+
+func saveRecord(t *testing.T, app core.App, collection string, values map[string]any) {
+	t.Helper()
+
+	c, err := app.FindCollectionByNameOrId(collection)
+	if err != nil {
+		t.Fatalf("find %s collection: %v", collection, err)
+	}
+
+	record := core.NewRecord(c)
+	for key, value := range values {
+		record.Set(key, value)
+	}
+
+	if err := app.Save(record); err != nil {
+		t.Fatalf("save %s record: %v", collection, err)
+	}
+}
@@ -3,6 +3,7 @@ package artists
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -12,6 +13,8 @@ import (
 	tmplUtils "github.com/blackfyre/wga/internal/assets/templ/utils"
 	"github.com/blackfyre/wga/internal/constants"
 	"github.com/blackfyre/wga/internal/errs"
+	"github.com/blackfyre/wga/internal/handlers/artworks"
+	"github.com/blackfyre/wga/internal/repositories"
 	"github.com/blackfyre/wga/internal/utils"
 	"github.com/blackfyre/wga/internal/utils/glossary"
 	"github.com/blackfyre/wga/internal/utils/jsonld"
@@ -37,9 +40,35 @@ func RenderArtistContent(app *pocketbase.PocketBase, c *core.RequestEvent, artis
 
 	schools := utils.RenderSchoolNames(app, artist.GetStringSlice("school"))
 
+	repo := repositories.NewArtistsRepository(app)
+
+	otherNames, err := repo.FindNames([]string{id})
+
+	if err != nil {
+		app.Logger().Error("Error finding other artist names", "error", err.Error())
+		return dto.Artist{}, err
+	}
+
+	artworksRepo := repositories.NewArtworksRepository(app)
+
+	credits, err := artworksRepo.FindAuthors(works)
+
+	if err != nil {
+		app.Logger().Error("Error finding artwork authors", "error", err.Error())
+		return dto.Artist{}, err
+	}
+
+	relations, err := repo.FindRelations(id)
+
+	if err != nil {
+		app.Logger().Error("Error finding artist relationships", "error", err.Error())
+		return dto.Artist{}, err
+	}
+
 	content := dto.Artist{
-		Name: artist.GetString("name"),
-		Bio:  artist.GetString("bio"),
+		Name:       artist.GetString("name"),
+		OtherNames: artistNames(otherNames[id]),
+		Bio:        artist.GetString("bio"),
 		BioExcerpt: utils.NormalizedBioExcerpt(utils.BioExcerptDTO{
 			YearOfBirth:       artist.GetInt("year_of_birth"),
 			ExactYearOfBirth:  artist.GetString("exact_year_of_birth"),
@@ -56,9 +85,19 @@ func RenderArtistContent(app *pocketbase.PocketBase, c *core.RequestEvent, artis
 		Url:             "/artists/" + expectedSlug,
 		HxTarget:        hxTarget,
 		ShowBreadcrumbs: showBreadcrumbs,
+		Relations:       relationGroups(relations),
+	}
+
+	if len(relations) > 0 {
+		content.NetworkUrl = content.Url + "/network"
+	}
+
+	// the locations of the works are read by their JSON-LD
+	if err := artworksRepo.PrefetchLocations(works); err != nil {
+		app.Logger().Warn("Failed to load the locations of the artworks of "+id, "error", err.Error())
 	}
 
-	JsonLd := jsonld.ArtistJsonLd(artist)
+	JsonLd := jsonld.ArtistJsonLd(artist, relatedPersons(relations)...)
 
 	marshalled, err := json.Marshal(JsonLd)
 
@@ -86,6 +125,7 @@ func RenderArtistContent(app *pocketbase.PocketBase, c *core.RequestEvent, artis
 		img.Title = w.GetString("title")
 		img.Comment = w.GetString("comment")
 		img.Technique = w.GetString("technique")
+		img.Authors = artworks.NewArtworkAuthors(credits[w.Id])
 
 		if w.GetString("image") != "" {
 			img.Image = url.GenerateFileUrl(constants.CollectionArtworks, w.GetString("id"), w.GetString("image"), "")
@@ -128,6 +168,11 @@ func processArtist(c *core.RequestEvent, app *pocketbase.PocketBase) error {
 
 	app.Logger().Info("Processing artist", "slug", slug)
 
+	if err != nil {
+		// the slug may be one of the other names of the artist, redirected below
+		artist, err = findArtistByNameSlug(app, slug)
+	}
+
 	if err != nil {
 		app.Logger().Error("Artist not found: ", slug, err)
 		return utils.NotFoundError(c)
@@ -167,3 +212,62 @@ func processArtist(c *core.RequestEvent, app *pocketbase.PocketBase) error {
 
 	return c.HTML(http.StatusOK, buff.String())
 }
+
+// findArtistByNameSlug finds the published artist known by another name of the slug, like
+// "tintoretto". It fails when no artist or more than one artist is known by the name.
+func findArtistByNameSlug(app *pocketbase.PocketBase, slug string) (*core.Record, error) {
+	ids, err := repositories.NewArtistsRepository(app).FindIdsByNameSlug(slug)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ids) != 1 {
+		return nil, sql.ErrNoRows
+	}
+
+	return app.FindRecordById(constants.CollectionArtists, ids[0])
+}
+
+// relationGroups groups the relationships of an artist by their label, keeping their order.
+func relationGroups(relations []repositories.ArtistRelation) []dto.ArtistRelationGroup {
+	groups := []dto.ArtistRelationGroup{}
+
+	for _, r := range relations {
+		link := dto.ArtistRelationLink{
+			Name:      r.Name,
+			Url:       relationUrl(r),
+			Source:    r.Source,
+			SourceUrl: r.SourceUrl,
+		}
+
+		if last := len(groups) - 1; last >= 0 && groups[last].Label == r.Label() {
+			groups[last].Artists = append(groups[last].Artists, link)
+			continue
+		}
+
+		groups = append(groups, dto.ArtistRelationGroup{Label: r.Label(), Artists: []dto.ArtistRelationLink{link}})
+	}
+
+	return groups
+}
+
+// relatedPersons returns the related artists of the JSON-LD of an artist.
+func relatedPersons(relations []repositories.ArtistRelation) []jsonld.Related {
+	related := []jsonld.Related{}
+
+	for _, r := range relations {
+		related = append(related, jsonld.Related{
+			Property: r.Property(),
+			Name:     r.Name,
+			Url:      utils.AssetUrl(relationUrl(r)),
+		})
+	}
+
+	return related
+}
+
+// relationUrl returns the canonical url of the other artist of a relationship.
+func relationUrl(r repositories.ArtistRelation) string {
+	return "/artists/" + r.Slug + "-" + r.ArtistId
+}
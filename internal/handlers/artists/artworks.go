@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"strings"
 
@@ -13,9 +14,14 @@ import (
 	tmplUtils "github.com/blackfyre/wga/internal/assets/templ/utils"
 	"github.com/blackfyre/wga/internal/constants"
 	"github.com/blackfyre/wga/internal/errs"
+	"github.com/blackfyre/wga/internal/handlers/artworks"
+	"github.com/blackfyre/wga/internal/repositories"
 	"github.com/blackfyre/wga/internal/utils"
+	"github.com/blackfyre/wga/internal/utils/dating"
+	"github.com/blackfyre/wga/internal/utils/dzi"
 	"github.com/blackfyre/wga/internal/utils/glossary"
 	"github.com/blackfyre/wga/internal/utils/jsonld"
+	"github.com/blackfyre/wga/internal/utils/palette"
 	"github.com/blackfyre/wga/internal/utils/url"
 	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/core"
@@ -41,6 +47,14 @@ func processArtwork(c *core.RequestEvent, app *pocketbase.PocketBase) error {
 
 	artist, err := app.FindRecordById(constants.CollectionArtists, artistId)
 
+	// The artist slug may be one of the other names of the artist, redirected below
+	if err != nil {
+		artist, err = findArtistByNameSlug(app, artistSlug)
+		if err == nil {
+			artistId = artist.Id
+		}
+	}
+
 	// If the artist is not found, return a not found error
 	if err != nil {
 		app.Logger().Error("Artist not found: ", artistSlug, err)
@@ -70,7 +84,19 @@ func processArtwork(c *core.RequestEvent, app *pocketbase.PocketBase) error {
 		}
 	}
 	if !belongsToArtist {
-		return errs.ErrArtworkNotFound
+		// The artwork may have been reattributed, the old url redirects to the current one
+		currentUrl, err := reattributedArtworkUrl(app, aw, artistId)
+
+		if err != nil {
+			app.Logger().Error("Error finding the attribution history of "+aw.Id, "error", err.Error())
+			return utils.ServerFaultError(c)
+		}
+
+		if currentUrl == "" {
+			return errs.ErrArtworkNotFound
+		}
+
+		return c.Redirect(http.StatusMovedPermanently, currentUrl)
 	}
 
 	// Generate the expected slug for the artwork
@@ -83,12 +109,33 @@ func processArtwork(c *core.RequestEvent, app *pocketbase.PocketBase) error {
 		return c.Redirect(http.StatusMovedPermanently, expectedPageUrl)
 	}
 
+	credits, err := repositories.NewArtworksRepository(app).FindAuthors([]*core.Record{aw})
+
+	if err != nil {
+		app.Logger().Error("Error finding artwork authors", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	authors := credits[aw.Id]
+
+	// The artwork is reachable under every credited artist, the url under the first author is the canonical one
+	canonicalUrl := expectedPageUrl
+	if len(authors) > 0 {
+		canonicalUrl = url.GenerateFullArtworkUrl(url.ArtworkUrlDTO{
+			ArtistName:   authors[0].Artist.GetString("name"),
+			ArtistId:     authors[0].Artist.Id,
+			ArtworkId:    aw.Id,
+			ArtworkTitle: aw.GetString("title"),
+		})
+	}
+
 	var img dto.Image
 
 	img.Id = aw.GetString("id")
 	img.Title = aw.GetString("title")
 	img.Comment = aw.GetString("comment")
 	img.Technique = aw.GetString("technique")
+	img.Authors = artworks.NewArtworkAuthors(authors)
 	if aw.GetString("image") != "" {
 		img.Image = url.GenerateFileUrl(constants.CollectionArtworks, aw.GetString("id"), aw.GetString("image"), "")
 		img.Thumb = url.GenerateThumbUrl(constants.CollectionArtworks, aw.GetString("id"), aw.GetString("image"), "320x240", "")
@@ -102,6 +149,7 @@ func processArtwork(c *core.RequestEvent, app *pocketbase.PocketBase) error {
 		Title:     aw.GetString("title"),
 		Comment:   aw.GetString("comment"),
 		Technique: aw.GetString("technique"),
+		Date:      dating.FromRecord(aw).String(),
 		Url: url.GenerateFullArtworkUrl(url.ArtworkUrlDTO{
 			ArtistName:   artist.GetString("name"),
 			ArtistId:     artist.Id,
@@ -123,6 +171,17 @@ func processArtwork(c *core.RequestEvent, app *pocketbase.PocketBase) error {
 		ShowBreadcrumbs: true,
 	}
 
+	if dzi.UpToDate(aw) {
+		content.TilesUrl = url.GenerateTilesUrl(aw.Id)
+	}
+
+	content.RelatedUrl = url.GenerateRelatedArtworksUrl(aw.Id)
+	content.Palette = paletteSwatches(aw)
+	content.AttributionHistory = attributionHistory(app, aw.Id)
+	content.Location = artworkLocation(app, aw)
+	content.Series = artworkSeries(app, aw)
+	content.Gallery, content.Comparison = artworkGallery(app, aw)
+
 	school := artist.GetStringSlice("school")
 
 	var schoolCollector []string
@@ -161,7 +220,7 @@ func processArtwork(c *core.RequestEvent, app *pocketbase.PocketBase) error {
 
 	ctx := tmplUtils.DecorateContext(context.Background(), tmplUtils.TitleKey, fmt.Sprintf("%s - %s", content.Title, content.Name))
 	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.DescriptionKey, aw.GetString("comment"))
-	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.CanonicalUrlKey, expectedPageUrl)
+	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.CanonicalUrlKey, canonicalUrl)
 	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.OgImageKey, utils.AssetUrl(content.Image.Image))
 
 	c.Response.Header().Set("HX-Push-Url", expectedPageUrl)
@@ -178,14 +237,46 @@ func processArtwork(c *core.RequestEvent, app *pocketbase.PocketBase) error {
 	return c.HTML(http.StatusOK, buff.String())
 }
 
+// reattributedArtworkUrl returns the url of the artwork under its current first author when the
+// artwork was credited to the artist before a reattribution, empty when it never was or has no
+// published author now.
+func reattributedArtworkUrl(app core.App, artwork *core.Record, artistId string) (string, error) {
+	repo := repositories.NewArtworksRepository(app)
+
+	wasAttributed, err := repo.WasAttributedTo(artwork.Id, artistId)
+	if err != nil || !wasAttributed {
+		return "", err
+	}
+
+	credits, err := repo.FindAuthors([]*core.Record{artwork})
+	if err != nil {
+		return "", err
+	}
+
+	authors := credits[artwork.Id]
+	if len(authors) == 0 {
+		return "", nil
+	}
+
+	return url.GenerateFullArtworkUrl(url.ArtworkUrlDTO{
+		ArtistName:   authors[0].Artist.GetString("name"),
+		ArtistId:     authors[0].Artist.Id,
+		ArtworkId:    artwork.Id,
+		ArtworkTitle: artwork.GetString("title"),
+	}), nil
+}
+
 func RenderArtworkContent(app *pocketbase.PocketBase, c *core.RequestEvent, artwork *core.Record, hxTarget string, showBreadcrumbs bool) (dto.Artwork, error) {
 
-	authorIDs := artwork.GetStringSlice("author")
-	artistId := ""
-	if len(authorIDs) > 0 {
-		artistId = authorIDs[0]
+	credits, err := repositories.NewArtworksRepository(app).FindAuthors([]*core.Record{artwork})
+
+	if err != nil {
+		app.Logger().Error("Error finding artwork authors", "error", err.Error())
+		return dto.Artwork{}, err
 	}
 
+	authors := credits[artwork.Id]
+
 	var artworkUrl string
 	var img dto.Image
 
@@ -193,6 +284,7 @@ func RenderArtworkContent(app *pocketbase.PocketBase, c *core.RequestEvent, artw
 	img.Title = artwork.GetString("title")
 	img.Comment = artwork.GetString("comment")
 	img.Technique = artwork.GetString("technique")
+	img.Authors = artworks.NewArtworkAuthors(authors)
 	if artwork.GetString("image") != "" {
 		img.Image = url.GenerateFileUrl("artworks", artwork.GetString("id"), artwork.GetString("image"), "")
 	} else {
@@ -204,20 +296,25 @@ func RenderArtworkContent(app *pocketbase.PocketBase, c *core.RequestEvent, artw
 		Title:           artwork.GetString("title"),
 		Comment:         artwork.GetString("comment"),
 		Technique:       artwork.GetString("technique"),
+		Date:            dating.FromRecord(artwork).String(),
 		Image:           img,
 		HxTarget:        hxTarget,
 		ShowBreadcrumbs: showBreadcrumbs,
 	}
 
-	if artistId != "" {
-		var artist *core.Record
+	if dzi.UpToDate(artwork) {
+		content.TilesUrl = url.GenerateTilesUrl(artwork.Id)
+	}
 
-		artist, err := app.FindRecordById(constants.CollectionArtists, artistId)
+	content.RelatedUrl = url.GenerateRelatedArtworksUrl(artwork.Id)
+	content.Palette = paletteSwatches(artwork)
+	content.AttributionHistory = attributionHistory(app, artwork.Id)
+	content.Location = artworkLocation(app, artwork)
+	content.Series = artworkSeries(app, artwork)
+	content.Gallery, content.Comparison = artworkGallery(app, artwork)
 
-		if err != nil {
-			app.Logger().Error(fmt.Sprintf("Error finding artist (%s) related to artwork (%s)", artistId, artwork.Id), "error", err.Error())
-			return dto.Artwork{}, err
-		}
+	if len(authors) > 0 {
+		artist := authors[0].Artist
 
 		artworkUrl = url.GenerateFullArtworkUrl(url.ArtworkUrlDTO{
 			ArtistName:   artist.GetString("name"),
@@ -258,3 +355,175 @@ func RenderArtworkContent(app *pocketbase.PocketBase, c *core.RequestEvent, artw
 
 	return content, nil
 }
+
+// attributionHistory returns the timeline of the reattributions of the artwork. The page is
+// rendered without it when the history cannot be read.
+func attributionHistory(app core.App, artworkId string) []dto.AttributionChange {
+	changes, err := repositories.NewArtworksRepository(app).FindAttributionHistory(artworkId)
+	if err != nil {
+		app.Logger().Warn("Failed to load the attribution history of "+artworkId, "error", err.Error())
+		return nil
+	}
+
+	history := make([]dto.AttributionChange, 0, len(changes))
+	for _, change := range changes {
+		history = append(history, dto.AttributionChange{
+			Date:     change.GetDateTime("created").Time().Format("2 January 2006"),
+			Previous: change.GetString("previous_credit"),
+			Current:  change.GetString("new_credit"),
+			Source:   change.GetString("source"),
+		})
+	}
+
+	return history
+}
+
+// artworkLocation returns where the artwork is held, nil when it is not known. The location is
+// left expanded on the record for the JSON-LD of the artwork.
+func artworkLocation(app core.App, artwork *core.Record) *dto.ArtworkLocation {
+	if err := repositories.NewArtworksRepository(app).PrefetchLocations([]*core.Record{artwork}); err != nil {
+		app.Logger().Warn("Failed to load the location of "+artwork.Id, "error", err.Error())
+		return nil
+	}
+
+	location := artwork.ExpandedOne("location")
+	if location == nil {
+		return nil
+	}
+
+	return &dto.ArtworkLocation{
+		Name:            location.GetString("name"),
+		Url:             url.GenerateLocationUrl(location.GetString("slug")),
+		City:            location.GetString("city"),
+		CityUrl:         url.GenerateCitySearchUrl(location.GetString("city")),
+		Room:            artwork.GetString("location_room"),
+		InventoryNumber: artwork.GetString("inventory_number"),
+	}
+}
+
+// artworkGallery returns the gallery of the artwork, its main image followed by its other images,
+// and the comparison of its first image before restoration with its first image after it, or
+// with the main image when there is none after it. The page is rendered with the main image only
+// when the artwork has no other images or they cannot be read.
+func artworkGallery(app core.App, artwork *core.Record) ([]dto.ArtworkImage, *dto.ImageComparison) {
+	records, err := repositories.NewArtworksRepository(app).FindImages(artwork.Id)
+	if err != nil {
+		app.Logger().Warn("Failed to load the images of "+artwork.Id, "error", err.Error())
+		return nil, nil
+	}
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	gallery := []dto.ArtworkImage{}
+
+	if name := artwork.GetString("image"); name != "" {
+		gallery = append(gallery, dto.ArtworkImage{
+			Kind:  "Main",
+			Image: url.GenerateFileUrl(constants.CollectionArtworks, artwork.Id, name, ""),
+			Thumb: url.GenerateThumbUrl(constants.CollectionArtworks, artwork.Id, name, "320x240", ""),
+		})
+	}
+
+	var before, after *dto.ArtworkImage
+
+	for _, r := range records {
+		name := r.GetString("image")
+		image := dto.ArtworkImage{
+			Kind:    repositories.ArtworkImageKindLabel(r.GetString("kind")),
+			Caption: r.GetString("caption"),
+			Image:   url.GenerateFileUrl(constants.CollectionArtworkImages, r.Id, name, ""),
+			Thumb:   url.GenerateThumbUrl(constants.CollectionArtworkImages, r.Id, name, "320x240", ""),
+		}
+
+		gallery = append(gallery, image)
+
+		switch kind := r.GetString("kind"); {
+		case kind == repositories.ArtworkImageBeforeRestoration && before == nil:
+			before = &image
+		case kind == repositories.ArtworkImageAfterRestoration && after == nil:
+			after = &image
+		}
+	}
+
+	if after == nil && artwork.GetString("image") != "" {
+		after = &gallery[0]
+	}
+
+	if before == nil || after == nil {
+		return gallery, nil
+	}
+
+	return gallery, &dto.ImageComparison{Before: *before, After: *after}
+}
+
+// artworkSeries returns the series the artwork is part of with its neighbouring and other
+// parts, nil when it is not part of one. The page is rendered without it when the series cannot
+// be read.
+func artworkSeries(app core.App, artwork *core.Record) *dto.ArtworkSeries {
+	seriesId := artwork.GetString("series")
+	if seriesId == "" {
+		return nil
+	}
+
+	series, err := app.FindRecordById(constants.CollectionSeries, seriesId)
+	if err != nil {
+		app.Logger().Warn("Failed to load the series of "+artwork.Id, "error", err.Error())
+		return nil
+	}
+
+	records, err := repositories.NewSeriesRepository(app).FindParts(seriesId)
+	if err != nil {
+		app.Logger().Warn("Failed to load the parts of the series "+seriesId, "error", err.Error())
+		return nil
+	}
+
+	credits, err := repositories.NewArtworksRepository(app).FindAuthors(records)
+	if err != nil {
+		app.Logger().Warn("Failed to load the authors of the series "+seriesId, "error", err.Error())
+		return nil
+	}
+
+	content := &dto.ArtworkSeries{
+		Title: series.GetString("title"),
+		Url:   url.GenerateSeriesUrl(series.GetString("title"), series.Id),
+		Part:  artwork.GetString("series_part"),
+		Parts: artworks.NewSeriesParts(records, credits, artwork.Id),
+	}
+
+	for i, part := range content.Parts {
+		if !part.Current {
+			continue
+		}
+
+		if i > 0 {
+			content.Previous = &content.Parts[i-1]
+		}
+
+		if i < len(content.Parts)-1 {
+			content.Next = &content.Parts[i+1]
+		}
+	}
+
+	return content
+}
+
+// paletteSwatches returns the swatches of the palette of the artwork's current image,
+// each linking to the artwork search for its colour.
+func paletteSwatches(artwork *core.Record) []dto.ColorSwatch {
+	if !palette.UpToDate(artwork) {
+		return nil
+	}
+
+	swatches := []dto.ColorSwatch{}
+	for _, s := range palette.FromRecord(artwork) {
+		swatches = append(swatches, dto.ColorSwatch{
+			Color:     s.Color,
+			Percent:   int(math.Round(s.Weight * 100)),
+			SearchUrl: url.GenerateColorSearchUrl(s.Color),
+		})
+	}
+
+	return swatches
+}
@@ -0,0 +1,83 @@
+package artists
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestArtworkGalleryComparesTheRestoration(t *testing.T) {
+	app, err := tests.NewTestApp()
+	if err != nil {
+		t.Fatalf("create test app: %v", err)
+	}
+	t.Cleanup(app.Cleanup)
+
+	artworks := core.NewBaseCollection("Artworks")
+	artworks.Id = "artworks"
+	artworks.Fields.Add(&core.TextField{Name: "image"})
+	if err := app.Save(artworks); err != nil {
+		t.Fatalf("save artworks collection: %v", err)
+	}
+
+	images := core.NewBaseCollection("Artwork_images")
+	images.Id = "artwork_images"
+	images.Fields.Add(
+		&core.RelationField{Name: "artwork", CollectionId: "artworks", MaxSelect: 1},
+		&core.TextField{Name: "image"},
+		&core.TextField{Name: "kind"},
+		&core.TextField{Name: "caption"},
+		&core.NumberField{Name: "position", OnlyInt: true},
+		&core.AutodateField{Name: "created", OnCreate: true},
+	)
+	if err := app.Save(images); err != nil {
+		t.Fatalf("save artwork images collection: %v", err)
+	}
+
+	save := func(collection *core.Collection, values map[string]any) *core.Record {
+		t.Helper()
+
+		record := core.NewRecord(collection)
+		record.Load(values)
+		if err := app.Save(record); err != nil {
+			t.Fatalf("save %s record: %v", collection.Name, err)
+		}
+
+		return record
+	}
+
+	artwork := save(artworks, map[string]any{"id": "artwork00000001", "image": "main.jpg"})
+
+	// an artwork with its main image only has no gallery
+	if gallery, comparison := artworkGallery(app, artwork); gallery != nil || comparison != nil {
+		t.Fatalf("gallery = %v, comparison = %v", gallery, comparison)
+	}
+
+	save(images, map[string]any{"id": "awimage00000001", "artwork": artwork.Id, "image": "verso.jpg", "kind": "verso", "position": 1})
+	save(images, map[string]any{"id": "awimage00000002", "artwork": artwork.Id, "image": "before.jpg", "kind": "before_restoration", "caption": "1990", "position": 2})
+
+	gallery, comparison := artworkGallery(app, artwork)
+
+	labels := []string{}
+	for _, image := range gallery {
+		labels = append(labels, image.Label())
+	}
+
+	if want := []string{"Main", "Verso", "Before restoration: 1990"}; !reflect.DeepEqual(labels, want) {
+		t.Fatalf("gallery = %v, want %v", labels, want)
+	}
+
+	// without an image after the restoration, the main image shows the artwork after it
+	if comparison == nil || comparison.Before.Image != gallery[2].Image || comparison.After.Image != gallery[0].Image {
+		t.Fatalf("comparison = %+v", comparison)
+	}
+
+	save(images, map[string]any{"id": "awimage00000003", "artwork": artwork.Id, "image": "after.jpg", "kind": "after_restoration", "position": 3})
+
+	gallery, comparison = artworkGallery(app, artwork)
+	if comparison == nil || comparison.After.Image != gallery[3].Image {
+		t.Fatalf("comparison = %+v", comparison)
+	}
+}
@@ -0,0 +1,192 @@
+package artists
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/blackfyre/wga/internal/assets/templ/dto"
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// firstCentury and lastCentury bound the centuries of activity the artist list can be filtered by.
+const (
+	firstCentury = 11
+	lastCentury  = 19
+)
+
+// artistFilters are the filters of the artist list. They are read from and written to
+// the query of the list, so every combination has its own url.
+type artistFilters struct {
+	Query  string
+	Letter string
+	// School is the slug of a school.
+	School     string
+	Profession string
+	// Century is the century the artists were active in, like 15 for the 1400s, 0 for any.
+	Century int
+	BornIn  string
+	DiedIn  string
+}
+
+// artistFiltersFromQuery reads the filters from a query, dropping unknown letters and centuries.
+func artistFiltersFromQuery(q url.Values) artistFilters {
+	f := artistFilters{
+		Query:      q.Get("q"),
+		School:     strings.TrimSpace(q.Get("art_school")),
+		Profession: strings.TrimSpace(q.Get("profession")),
+		BornIn:     strings.TrimSpace(q.Get("born")),
+		DiedIn:     strings.TrimSpace(q.Get("died")),
+	}
+
+	if letter := strings.ToUpper(strings.TrimSpace(q.Get("letter"))); slices.Contains(repositories.ArtistLetters, letter) {
+		f.Letter = letter
+	}
+
+	if century, err := strconv.Atoi(strings.TrimSpace(q.Get("century"))); err == nil && century >= firstCentury && century <= lastCentury {
+		f.Century = century
+	}
+
+	return f
+}
+
+func (f artistFilters) queryValues() url.Values {
+	values := url.Values{}
+
+	for _, v := range []struct{ name, value string }{
+		{"q", f.Query},
+		{"letter", f.Letter},
+		{"art_school", f.School},
+		{"profession", f.Profession},
+		{"born", f.BornIn},
+		{"died", f.DiedIn},
+	} {
+		if v.value != "" {
+			values.Set(v.name, v.value)
+		}
+	}
+
+	if f.Century != 0 {
+		values.Set("century", strconv.Itoa(f.Century))
+	}
+
+	return values
+}
+
+// url returns the url of the artist list with the filters.
+func (f artistFilters) url() string {
+	if values := f.queryValues(); len(values) > 0 {
+		return "/artists?" + values.Encode()
+	}
+
+	return "/artists"
+}
+
+// buildFilter returns the record filter of the published artists matching the filters,
+// except the query and the letter, which the list applies after counting the letters.
+func (f artistFilters) buildFilter() (string, dbx.Params) {
+	filter := "published = true"
+	params := dbx.Params{}
+
+	for _, c := range []struct {
+		value     string
+		condition string
+		param     string
+	}{
+		{f.School, "school.slug ?= {:art_school}", "art_school"},
+		{f.Profession, "profession ~ {:profession}", "profession"},
+		{f.BornIn, "place_of_birth ~ {:born}", "born"},
+		{f.DiedIn, "place_of_death ~ {:died}", "died"},
+	} {
+		if c.value == "" {
+			continue
+		}
+
+		filter = filter + " && " + c.condition
+		params[c.param] = c.value
+	}
+
+	if f.Century != 0 {
+		// active in the century, following the same rules as the periods
+		century := repositories.ArtPeriod{Start: (f.Century - 1) * 100, End: f.Century*100 - 1}
+		filter = filter + " && " + repositories.ActiveInPeriodFilter("")
+		maps.Copy(params, century.FilterParams())
+	}
+
+	return filter, params
+}
+
+// filterValues returns the filters as the artist list form shows them.
+func (f artistFilters) filterValues() dto.ArtistFilterValues {
+	values := dto.ArtistFilterValues{
+		Letter:     f.Letter,
+		School:     f.School,
+		Profession: f.Profession,
+		BornIn:     f.BornIn,
+		DiedIn:     f.DiedIn,
+	}
+
+	if f.Century != 0 {
+		values.Century = strconv.Itoa(f.Century)
+	}
+
+	return values
+}
+
+// letterIndex returns the A to Z index of the list, each letter linking to the list
+// with the other filters kept.
+func letterIndex(f artistFilters, counts map[string]int) []dto.ArtistLetter {
+	index := make([]dto.ArtistLetter, 0, len(repositories.ArtistLetters))
+
+	for _, letter := range repositories.ArtistLetters {
+		linked := f
+		linked.Letter = letter
+
+		index = append(index, dto.ArtistLetter{
+			Letter: letter,
+			Count:  counts[letter],
+			Url:    linked.url(),
+			Active: f.Letter == letter,
+		})
+	}
+
+	return index
+}
+
+// centuryOptions returns the centuries the list can be filtered by.
+func centuryOptions() []dto.SearchFacetOption {
+	options := make([]dto.SearchFacetOption, 0, lastCentury-firstCentury+1)
+
+	for century := firstCentury; century <= lastCentury; century++ {
+		options = append(options, dto.SearchFacetOption{
+			Value: strconv.Itoa(century),
+			Label: fmt.Sprintf("%dth century (%d–%d)", century, (century-1)*100, century*100-1),
+		})
+	}
+
+	return options
+}
+
+// schoolOptions returns the schools the list can be filtered by, ordered by name.
+func schoolOptions(app core.App) ([]dto.SearchFacetOption, error) {
+	schools, err := app.FindRecordsByFilter(constants.CollectionSchools, "", "+name", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	options := make([]dto.SearchFacetOption, 0, len(schools))
+	for _, school := range schools {
+		options = append(options, dto.SearchFacetOption{
+			Value: school.GetString("slug"),
+			Label: school.GetString("name"),
+		})
+	}
+
+	return options, nil
+}
@@ -0,0 +1,157 @@
+package artists
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestArtistFiltersRoundTripThroughQuery(t *testing.T) {
+	q, err := url.ParseQuery("q=dyck&letter=d&art_school=flemish&profession=+painter+&century=17&born=Antwerp&died=")
+	if err != nil {
+		t.Fatalf("parse query: %v", err)
+	}
+
+	f := artistFiltersFromQuery(q)
+
+	want := artistFilters{Query: "dyck", Letter: "D", School: "flemish", Profession: "painter", Century: 17, BornIn: "Antwerp"}
+	if f != want {
+		t.Fatalf("filters = %+v, want %+v", f, want)
+	}
+
+	if again := artistFiltersFromQuery(f.queryValues()); again != f {
+		t.Fatalf("query values do not round trip: %+v != %+v", again, f)
+	}
+
+	for _, query := range []string{"letter=AB", "letter=?", "century=5", "century=1600", "century=x"} {
+		q, _ := url.ParseQuery(query)
+		if f := artistFiltersFromQuery(q); f != (artistFilters{}) {
+			t.Errorf("%q: expected the value to be dropped, got %+v", query, f)
+		}
+	}
+}
+
+func TestLetterIndexKeepsTheOtherFilters(t *testing.T) {
+	index := letterIndex(artistFilters{Letter: "D", Profession: "painter"}, map[string]int{"D": 2, "#": 1})
+
+	if len(index) != 27 {
+		t.Fatalf("expected 27 letters, got %d", len(index))
+	}
+
+	d := index[3]
+	if d.Letter != "D" || d.Count != 2 || !d.Active || d.Url != "/artists?letter=D&profession=painter" {
+		t.Fatalf("letter D = %+v", d)
+	}
+
+	if other := index[26]; other.Letter != "#" || other.Count != 1 || other.Url != "/artists?letter=%23&profession=painter" {
+		t.Fatalf("other letter = %+v", other)
+	}
+}
+
+func TestArtistFiltersNarrowTheList(t *testing.T) {
+	app := newArtistFiltersTestApp(t)
+
+	cases := map[string][]string{
+		"":                         {"artist000000001", "artist000000002", "artist000000003"},
+		"art_school=flemish":       {"artist000000001"},
+		"profession=draughtsman":   {"artist000000002"},
+		"century=15":               {"artist000000002"},
+		"century=17":               {"artist000000001", "artist000000003"},
+		"born=antwerp&died=London": {"artist000000001"},
+		"century=18":               {},
+	}
+
+	for query, want := range cases {
+		q, _ := url.ParseQuery(query)
+		filter, params := artistFiltersFromQuery(q).buildFilter()
+
+		records, err := app.FindRecordsByFilter("artists", filter, "+id", 0, 0, params)
+		if err != nil {
+			t.Fatalf("%q: %v", query, err)
+		}
+
+		got := []string{}
+		for _, r := range records {
+			got = append(got, r.Id)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q matched %v, want %v", query, got, want)
+		}
+	}
+}
+
+func newArtistFiltersTestApp(t *testing.T) *tests.TestApp {
+	t.Helper()
+
+	app, err := tests.NewTestApp()
+	if err != nil {
+		t.Fatalf("create test app: %v", err)
+	}
+	t.Cleanup(app.Cleanup)
+
+	schools := core.NewBaseCollection("Schools")
+	schools.Id = "schools"
+	schools.Fields.Add(
+		&core.TextField{Name: "name"},
+		&core.TextField{Name: "slug"},
+	)
+	if err := app.Save(schools); err != nil {
+		t.Fatalf("save schools collection: %v", err)
+	}
+
+	artists := core.NewBaseCollection("Artists")
+	artists.Id = "artists"
+	artists.Fields.Add(
+		&core.TextField{Name: "name"},
+		&core.TextField{Name: "profession"},
+		&core.TextField{Name: "place_of_birth"},
+		&core.TextField{Name: "place_of_death"},
+		&core.NumberField{Name: "year_of_birth"},
+		&core.NumberField{Name: "year_of_death"},
+		&core.RelationField{Name: "school", CollectionId: "schools", MaxSelect: 10},
+		&core.BoolField{Name: "published"},
+	)
+	if err := app.Save(artists); err != nil {
+		t.Fatalf("save artists collection: %v", err)
+	}
+
+	for _, values := range []struct {
+		collection string
+		fields     map[string]any
+	}{
+		{"schools", map[string]any{"id": "school000000001", "name": "Flemish", "slug": "flemish"}},
+		{"schools", map[string]any{"id": "school000000002", "name": "German", "slug": "german"}},
+		{"artists", map[string]any{
+			"id": "artist000000001", "name": "DYCK, Anthony van", "profession": "painter", "published": true,
+			"place_of_birth": "Antwerp", "place_of_death": "London", "year_of_birth": 1599, "year_of_death": 1641,
+			"school": []string{"school000000001"},
+		}},
+		{"artists", map[string]any{
+			"id": "artist000000002", "name": "HOLBEIN, Hans the Younger", "profession": "painter and draughtsman", "published": true,
+			"place_of_birth": "Augsburg", "place_of_death": "London", "year_of_birth": 1497, "year_of_death": 1543,
+			"school": []string{"school000000002"},
+		}},
+		{"artists", map[string]any{
+			"id": "artist000000003", "name": "GRECO, El", "profession": "painter", "published": true,
+			"place_of_birth": "Candia", "place_of_death": "Toledo", "year_of_birth": 1541, "year_of_death": 1614,
+		}},
+		{"artists", map[string]any{"id": "artist000000004", "name": "Unpublished", "profession": "painter"}},
+	} {
+		c, err := app.FindCollectionByNameOrId(values.collection)
+		if err != nil {
+			t.Fatalf("find %s collection: %v", values.collection, err)
+		}
+
+		record := core.NewRecord(c)
+		record.Load(values.fields)
+		if err := app.Save(record); err != nil {
+			t.Fatalf("save %s record: %v", values.collection, err)
+		}
+	}
+
+	return app
+}
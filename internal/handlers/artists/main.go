@@ -13,9 +13,10 @@ import (
 	"github.com/blackfyre/wga/internal/assets/templ/dto"
 	"github.com/blackfyre/wga/internal/assets/templ/pages"
 	tmplUtils "github.com/blackfyre/wga/internal/assets/templ/utils"
+	"github.com/blackfyre/wga/internal/repositories"
 	"github.com/blackfyre/wga/internal/utils"
+	"github.com/blackfyre/wga/internal/utils/fulltext"
 	"github.com/blackfyre/wga/internal/utils/jsonld"
-	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
@@ -57,48 +58,114 @@ func processArtists(app *pocketbase.PocketBase, c *core.RequestEvent) error {
 
 	offset := (page - 1) * limit
 
-	filter := "published = true"
+	filters := artistFiltersFromQuery(queryParams)
+	filterString, params := filters.buildFilter()
 
-	if searchExpression != "" {
-		filter = filter + " && name ~ {:searchExpression}"
+	matchIds, err := matchArtists(app, searchExpression)
+
+	if err != nil {
+		app.Logger().Warn("Full-text artist search failed, falling back to substring match", "error", err.Error())
+	}
+
+	if matchIds == nil && searchExpression != "" {
+		filterString = filterString + " && " + repositories.ArtistNameFilter("", "~", "searchExpression")
+		params["searchExpression"] = searchExpression
 	}
 
-	records, err := app.FindRecordsByFilter(
-		"artists",
-		filter,
-		"+name",
-		limit,
-		offset,
-		dbx.Params{
-			"searchExpression": searchExpression,
-		},
-	)
+	filter := repositories.RecordFilter{Filter: filterString, Params: params, Ids: matchIds}
+	repo := repositories.NewArtistsRepository(app)
+
+	letterCounts, err := repo.CountByLetter(filter)
 
 	if err != nil {
-		app.Logger().Error("Failed to get artist records", "error", err.Error())
+		app.Logger().Error("Failed to count artists by letter", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	if filters.Letter != "" {
+		filter.Filter = filter.Filter + " && letter = {:letter}"
+		filter.Params["letter"] = filters.Letter
+	}
+
+	var records []*core.Record
+	recordsCount := 0
+	nextCursor, previousCursor := "", ""
+
+	if matchIds != nil {
+		// ranked matches are bounded, so they are ordered and paged in memory
+		matches, err := repo.FindPage(repositories.PageQuery{RecordFilter: filter})
+
+		if err != nil {
+			app.Logger().Error("Failed to get artist records", "error", err.Error())
+			return utils.ServerFaultError(c)
+		}
+
+		ranked := fulltext.OrderByIds(matches.Records, matchIds)
+		recordsCount = len(ranked)
+		records = fulltext.Page(ranked, offset, limit)
+	} else {
+		pageQuery := repositories.PageQuery{
+			RecordFilter: filter,
+			Limit:        limit,
+			Offset:       offset,
+		}
+
+		if err := pageQuery.SetCursors(queryParams.Get("after"), queryParams.Get("before")); err != nil {
+			return apis.NewBadRequestError("Invalid cursor", err)
+		}
+
+		result, err := repo.FindPage(pageQuery)
+
+		if err != nil {
+			app.Logger().Error("Failed to get artist records", "error", err.Error())
+			return utils.ServerFaultError(c)
+		}
+
+		records = result.Records
+
+		if result.Last != nil {
+			nextCursor = result.Last.String()
+			previousCursor = result.First.String()
+		}
+
+		recordsCount, err = repo.Count(filter)
+
+		if err != nil {
+			app.Logger().Error("Failed to count artist records", "error", err.Error())
+			return utils.ServerFaultError(c)
+		}
+	}
+
+	if err := repo.PrefetchRelations(records); err != nil {
+		app.Logger().Error("Failed to prefetch artist schools", "error", err.Error())
 		return utils.ServerFaultError(c)
 	}
 
-	totalRecords, err := app.FindRecordsByFilter(
-		"artists",
-		filter,
-		"+name",
-		0,
-		0,
-		dbx.Params{
-			"searchExpression": searchExpression,
-		},
-	)
+	ids := make([]string, 0, len(records))
+	for _, m := range records {
+		ids = append(ids, m.Id)
+	}
+
+	otherNames, err := repo.FindNames(ids)
 
 	if err != nil {
-		app.Logger().Error("Failed to get total records", "error", err.Error())
+		app.Logger().Error("Failed to find other artist names", "error", err.Error())
 		return utils.ServerFaultError(c)
 	}
 
-	recordsCount := len(totalRecords)
+	schools, err := schoolOptions(app)
+
+	if err != nil {
+		app.Logger().Error("Failed to get schools", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
 
 	content := dto.ArtistsView{
-		Count: strconv.Itoa(recordsCount),
+		Count:          strconv.Itoa(recordsCount),
+		Filters:        filters.filterValues(),
+		Letters:        letterIndex(filters, letterCounts),
+		SchoolOptions:  schools,
+		CenturyOptions: centuryOptions(),
 	}
 
 	if len(searchExpression) > 0 && searchExpressionPresent {
@@ -109,12 +176,11 @@ func processArtists(app *pocketbase.PocketBase, c *core.RequestEvent) error {
 
 	for _, m := range records {
 
-		// TODO: handle a.k.a. names
-
-		schools := utils.RenderSchoolNames(app, m.GetStringSlice("school"))
+		schools := repositories.SchoolNames(m)
 
 		content.Artists = append(content.Artists, dto.Artist{
 			Name:       m.GetString("name"),
+			OtherNames: artistNames(otherNames[m.Id]),
 			Url:        url.GenerateArtistUrlFromRecord(m),
 			Profession: m.GetString("profession"),
 			BornDied:   utils.NormalizedBirthDeathActivity(m),
@@ -134,7 +200,8 @@ func processArtists(app *pocketbase.PocketBase, c *core.RequestEvent) error {
 
 	content.Jsonld = fmt.Sprintf(`<script type="application/ld+json">%s</script>`, marshalledJsonLd)
 
-	pagination := utils.NewPagination(recordsCount, limit, page, "/artists?q="+searchExpression, "", "")
+	pagination := utils.NewPagination(recordsCount, limit, page, filters.url(), "", "")
+	pagination.SetCursors(nextCursor, previousCursor)
 
 	content.Pagination = string(pagination.Render())
 
@@ -156,6 +223,35 @@ func processArtists(app *pocketbase.PocketBase, c *core.RequestEvent) error {
 
 }
 
+// maxRankedArtistMatches bounds how many full-text matches an artist search considers.
+const maxRankedArtistMatches = 500
+
+// matchArtists resolves the search expression against the full-text index.
+// It returns nil ids when there is nothing to match or the index is unavailable,
+// in which case the caller falls back to a substring match.
+func matchArtists(app *pocketbase.PocketBase, searchExpression string) ([]string, error) {
+	if searchExpression == "" || !fulltext.IndexAvailable(app) {
+		return nil, nil
+	}
+
+	return fulltext.MatchIds(app, fulltext.KindArtist, searchExpression, maxRankedArtistMatches)
+}
+
+// artistNames converts the other names of an artist for the templates.
+func artistNames(names []repositories.ArtistName) []dto.ArtistName {
+	converted := make([]dto.ArtistName, 0, len(names))
+
+	for _, n := range names {
+		converted = append(converted, dto.ArtistName{
+			Name:    n.Name,
+			Lang:    n.Lang,
+			SortKey: n.Kind == repositories.ArtistNameSortKey,
+		})
+	}
+
+	return converted
+}
+
 func RegisterHandlers(app *pocketbase.PocketBase) {
 
 	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
@@ -172,6 +268,10 @@ func RegisterHandlers(app *pocketbase.PocketBase) {
 			return processArtist(e, app)
 		})
 
+		ag.GET("/{name}/network", func(e *core.RequestEvent) error {
+			return processArtistNetwork(e, app)
+		})
+
 		ag.GET("/{name}/{awid}", func(e *core.RequestEvent) error {
 			return processArtwork(e, app)
 		})
@@ -0,0 +1,84 @@
+package artists
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/blackfyre/wga/internal/assets/templ/dto"
+	"github.com/blackfyre/wga/internal/assets/templ/pages"
+	tmplUtils "github.com/blackfyre/wga/internal/assets/templ/utils"
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/blackfyre/wga/internal/utils/url"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// networkPageDepth is how many relationships away from the artist the network page reaches.
+const networkPageDepth = 2
+
+// processArtistNetwork renders the network of the teachers, pupils, workshops and family of an artist.
+// The graph is drawn from the JSON network endpoint, with the relationships listed below it for
+// readers without scripts. Like the artist page, it accepts the slugs of the other names of the
+// artist and redirects them to the canonical url.
+func processArtistNetwork(c *core.RequestEvent, app *pocketbase.PocketBase) error {
+	slug := c.Request.PathValue("name")
+
+	artist, err := app.FindRecordById(constants.CollectionArtists, utils.ExtractIdFromString(slug))
+
+	if err != nil {
+		artist, err = findArtistByNameSlug(app, slug)
+	}
+
+	if err != nil || !artist.GetBool("published") {
+		app.Logger().Error("Artist not found: ", slug, err)
+		return utils.NotFoundError(c)
+	}
+
+	expectedSlug := utils.GenerateArtistSlug(artist)
+	fullUrl := "/artists/" + expectedSlug + "/network"
+
+	if slug != expectedSlug {
+		return c.Redirect(http.StatusMovedPermanently, fullUrl)
+	}
+
+	relations, err := repositories.NewArtistsRepository(app).FindRelations(artist.Id)
+
+	if err != nil {
+		app.Logger().Error("Error finding artist relationships", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	content := dto.ArtistNetworkView{
+		Artist: dto.Artist{
+			Id:        artist.Id,
+			Name:      artist.GetString("name"),
+			Url:       "/artists/" + expectedSlug,
+			HxTarget:  "#mc-area",
+			Relations: relationGroups(relations),
+		},
+		DataUrl: url.GenerateArtistNetworkDataUrl(artist.Id, networkPageDepth),
+		Depth:   networkPageDepth,
+	}
+
+	ctx := tmplUtils.DecorateContext(context.Background(), tmplUtils.TitleKey, fmt.Sprintf("%s - Network", content.Artist.Name))
+	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.DescriptionKey, fmt.Sprintf("The teachers, pupils, workshops and family of %s.", content.Artist.Name))
+	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.OgUrlKey, fullUrl)
+
+	c.Response.Header().Set("HX-Push-Url", fullUrl)
+
+	var buff bytes.Buffer
+
+	err = pages.ArtistNetworkPage(content).Render(ctx, &buff)
+
+	if err != nil {
+		app.Logger().Error("Error rendering artist network page", "error", err.Error())
+
+		return utils.ServerFaultError(c)
+	}
+
+	return c.HTML(http.StatusOK, buff.String())
+}
@@ -0,0 +1,22 @@
+package artworks
+
+import (
+	"github.com/blackfyre/wga/internal/assets/templ/dto"
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/blackfyre/wga/internal/utils/url"
+)
+
+// NewArtworkAuthors converts the credited authors of an artwork for the templates.
+func NewArtworkAuthors(authors []repositories.ArtworkAuthor) []dto.ArtworkAuthor {
+	converted := make([]dto.ArtworkAuthor, 0, len(authors))
+
+	for _, a := range authors {
+		converted = append(converted, dto.ArtworkAuthor{
+			Name:      a.Artist.GetString("name"),
+			Url:       url.GenerateArtistUrlFromRecord(a.Artist),
+			Qualifier: a.Qualifier(),
+		})
+	}
+
+	return converted
+}
@@ -0,0 +1,266 @@
+package artworks
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/blackfyre/wga/internal/assets/templ/dto"
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/dbutils"
+)
+
+const artworkFacetCountsTTL = 10 * time.Minute
+
+const artworkFacetCountsCacheKeyPrefix = "artworks:search:facet-counts:"
+
+// maxArtistFacetOptions bounds the artist facet to the artists with the most matches.
+// Picked artists are listed on top of these.
+const maxArtistFacetOptions = 20
+
+// facetCounts holds the number of matching artworks per facet value:
+// school, art form and art type slugs, and artist names.
+type facetCounts struct {
+	Schools  map[string]int
+	ArtForms map[string]int
+	ArtTypes map[string]int
+	Artists  map[string]int
+}
+
+// getFacetCounts counts the artworks matching the filters for every facet value.
+// The counts of a facet ignore its own picked values, so they tell how many artworks
+// each value would match together with the other active filters.
+func getFacetCounts(app core.App, f *filters) (facetCounts, error) {
+	paged := *f
+	paged.Page = ""
+	cacheKey := artworkFacetCountsCacheKeyPrefix + paged.FingerPrint()
+	cacheable := facetCountsCacheable(&paged)
+
+	if cacheable {
+		if cached, ok := utils.GetCachedValue[facetCounts](app, cacheKey); ok {
+			return cached, nil
+		}
+	}
+
+	counts := facetCounts{}
+
+	for _, c := range []struct {
+		target  *map[string]int
+		without func(*filters)
+		field   string
+		related string
+		key     string
+		where   string
+	}{
+		{&counts.Schools, func(o *filters) { o.School = facet{} }, "school", constants.CollectionSchools, "slug", ""},
+		{&counts.ArtForms, func(o *filters) { o.ArtForm = facet{} }, "form", constants.CollectionArtForms, "slug", ""},
+		{&counts.ArtTypes, func(o *filters) { o.ArtType = facet{} }, "type", constants.CollectionArtTypes, "slug", ""},
+		{&counts.Artists, func(o *filters) { o.Artist = facet{} }, "author", constants.CollectionArtists, "name", "[[r.published]] = TRUE"},
+	} {
+		others := paged
+		c.without(&others)
+
+		result, err := countArtworksByRelation(app, others.BuildFilter(), c.field, c.related, c.key, c.where)
+		if err != nil {
+			return counts, err
+		}
+
+		*c.target = result
+	}
+
+	if cacheable {
+		utils.SetCachedValue(app, cacheKey, counts, artworkFacetCountsTTL)
+	}
+
+	return counts, nil
+}
+
+// facetCountsCacheable reports whether the facet counts of the filters may be cached.
+// Only the filters taking one of a few values are, so visitors cannot fill the cache
+// with counts of every text, slug or year they type.
+func facetCountsCacheable(f *filters) bool {
+	values := f.queryValues()
+	values.Del("page")
+	values.Del("attribution")
+	values.Del("collapse_series")
+
+	return len(values) == 0
+}
+
+// countArtworksByRelation counts the artworks matching the record filter for each record
+// they relate to through field, keyed by the key column of the related collection.
+// where optionally restricts the related records, which are aliased as r.
+func countArtworksByRelation(app core.App, filter repositories.RecordFilter, field string, related string, key string, where string) (map[string]int, error) {
+	collection, err := app.FindCollectionByNameOrId(constants.CollectionArtworks)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := repositories.FilterQuery(app, collection, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	matchesQuery := matches.Select("{{" + collection.Name + "}}.[[id]]").Build()
+
+	condition := ""
+	if where != "" {
+		condition = " AND " + where
+	}
+
+	rows := []struct {
+		Key   string `db:"key"`
+		Total int    `db:"total"`
+	}{}
+
+	err = app.DB().NewQuery(fmt.Sprintf(
+		"SELECT [[r.%s]] AS [[key]], COUNT(DISTINCT [[a.id]]) AS [[total]] "+
+			"FROM {{%s}} [[a]] "+
+			"JOIN %s [[v]] "+
+			"JOIN {{%s}} [[r]] ON [[r.id]] = [[v.value]]%s "+
+			"WHERE [[a.id]] IN (%s) "+
+			"GROUP BY [[r.%s]]",
+		key,
+		collection.Name,
+		dbutils.JSONEach("a."+field),
+		related,
+		condition,
+		matchesQuery.SQL(),
+		key,
+	)).Bind(matchesQuery.Params()).All(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Key] = row.Total
+	}
+
+	return counts, nil
+}
+
+// buildSearchFacets builds the school, art form, art type and artist facets of the search form.
+// It also returns the picked artist values that are not artist names, see newArtistFacet.
+func buildSearchFacets(app *pocketbase.PocketBase, f *filters) ([]dto.SearchFacet, []string, error) {
+	counts, err := getFacetCounts(app, f)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	schools, err := getArtSchoolOptions(app)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	forms, err := getArtFormOptions(app)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	types, err := getArtTypesOptions(app)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	artists, err := GetArtistNameList(app)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make(map[string]bool, len(artists))
+	for _, name := range artists {
+		names[name] = true
+	}
+
+	artistFacet, typed := newArtistFacet(counts.Artists, names, f.Artist)
+
+	return []dto.SearchFacet{
+		newSearchFacet("art_school", "School", schools, counts.Schools, f.School),
+		newSearchFacet("art_form", "Form", forms, counts.ArtForms, f.ArtForm),
+		newSearchFacet("art_type", "Type", types, counts.ArtTypes, f.ArtType),
+		artistFacet,
+	}, typed, nil
+}
+
+// newSearchFacet lists every option of a facet by label, with its count.
+// Picked values missing from the options are listed under their own value, so they stay picked.
+func newSearchFacet(name string, label string, options map[string]string, counts map[string]int, picked facet) dto.SearchFacet {
+	labels := map[string]string{}
+
+	for value, optionLabel := range options {
+		if value != "" {
+			labels[value] = optionLabel
+		}
+	}
+
+	for _, value := range slices.Concat(picked.Include, picked.Exclude) {
+		if _, ok := labels[value]; !ok {
+			labels[value] = value
+		}
+	}
+
+	result := dto.SearchFacet{Name: name, Label: label}
+
+	for value, optionLabel := range labels {
+		result.Options = append(result.Options, newSearchFacetOption(value, optionLabel, counts, picked))
+	}
+
+	slices.SortFunc(result.Options, func(a, b dto.SearchFacetOption) int {
+		return cmp.Or(cmp.Compare(a.Label, b.Label), cmp.Compare(a.Value, b.Value))
+	})
+
+	return result
+}
+
+// newArtistFacet lists the picked artists, then the artists with the most matches.
+// Artist values match names as substrings, so only the picked values that are artist names
+// are listed: the others are returned as typed, for the free text input.
+func newArtistFacet(counts map[string]int, names map[string]bool, picked facet) (dto.SearchFacet, []string) {
+	result := dto.SearchFacet{Name: "artist", Label: "Artist"}
+	typed := []string{}
+
+	for _, value := range picked.Include {
+		if names[value] {
+			result.Options = append(result.Options, newSearchFacetOption(value, value, counts, picked))
+		} else {
+			typed = append(typed, value)
+		}
+	}
+
+	for _, value := range picked.Exclude {
+		result.Options = append(result.Options, newSearchFacetOption(value, value, counts, picked))
+	}
+
+	top := make([]string, 0, len(counts))
+	for name := range counts {
+		if !slices.Contains(picked.Include, name) && !slices.Contains(picked.Exclude, name) {
+			top = append(top, name)
+		}
+	}
+
+	slices.SortFunc(top, func(a, b string) int {
+		return cmp.Or(cmp.Compare(counts[b], counts[a]), cmp.Compare(a, b))
+	})
+
+	for _, name := range top[:min(len(top), maxArtistFacetOptions)] {
+		result.Options = append(result.Options, newSearchFacetOption(name, name, counts, picked))
+	}
+
+	return result, typed
+}
+
+func newSearchFacetOption(value string, label string, counts map[string]int, picked facet) dto.SearchFacetOption {
+	return dto.SearchFacetOption{
+		Value:    value,
+		Label:    label,
+		Count:    counts[value],
+		Included: slices.Contains(picked.Include, value),
+		Excluded: slices.Contains(picked.Exclude, value),
+	}
+}
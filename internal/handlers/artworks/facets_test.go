@@ -0,0 +1,273 @@
+package artworks
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestFacetsRoundTripThroughQuery(t *testing.T) {
+	q, err := url.ParseQuery("art_school=italian&art_school=flemish&art_school_not=dutch&art_school=italian&art_form_not=sculpture&art_type=&artist=Botticelli&artist_not=Botticelli")
+	if err != nil {
+		t.Fatalf("parse query: %v", err)
+	}
+
+	f := filtersFromQuery(q)
+
+	if !reflect.DeepEqual(f.School, facet{Include: []string{"italian", "flemish"}, Exclude: []string{"dutch"}}) {
+		t.Fatalf("school facet = %+v", f.School)
+	}
+	if !reflect.DeepEqual(f.ArtForm, facet{Exclude: []string{"sculpture"}}) {
+		t.Fatalf("art form facet = %+v", f.ArtForm)
+	}
+	if f.ArtType.Active() {
+		t.Fatalf("expected blank values to be dropped, got %+v", f.ArtType)
+	}
+	if !reflect.DeepEqual(f.Artist, facet{Exclude: []string{"Botticelli"}}) {
+		t.Fatalf("expected a value both picked and excluded to be excluded, got %+v", f.Artist)
+	}
+
+	again := filtersFromQuery(f.queryValues())
+	if !reflect.DeepEqual(again, f) {
+		t.Fatalf("query values do not round trip: %+v != %+v", again, f)
+	}
+	if !f.AnyFilterActive() {
+		t.Fatal("expected the facets to be active")
+	}
+	if filtersFromQuery(url.Values{"page": {"2"}}).AnyFilterActive() {
+		t.Fatal("expected the page alone not to be a filter")
+	}
+}
+
+func TestFacetsNarrowTheSearch(t *testing.T) {
+	app := newFacetsTestApp(t)
+
+	cases := map[string][]string{
+		"":                                      {"artwork00000001", "artwork00000002", "artwork00000003"},
+		"art_school=italian":                    {"artwork00000001", "artwork00000002"},
+		"art_school=italian&art_school=flemish": {"artwork00000001", "artwork00000002", "artwork00000003"},
+		"art_school_not=italian":                {"artwork00000003"},
+		"art_form=painting&art_school_not=flemish": {"artwork00000001"},
+		"artist=Botti":      {"artwork00000001", "artwork00000002"},
+		"artist_not=Rubens": {"artwork00000001", "artwork00000002"},
+		// artists also match by their other names
+		"artist=Filipepi":       {"artwork00000001", "artwork00000002"},
+		"artist_not=Filipepi":   {"artwork00000003"},
+		"artist_not=Peter Paul": {"artwork00000001", "artwork00000002"},
+		"artist=Gogh":           {},
+	}
+
+	for query, want := range cases {
+		q, _ := url.ParseQuery(query)
+		filter := filtersFromQuery(q).BuildFilter()
+
+		records, err := app.FindRecordsByFilter("artworks", filter.Filter, "+id", 0, 0, filter.Params)
+		if err != nil {
+			t.Fatalf("%q: %v", query, err)
+		}
+
+		got := []string{}
+		for _, r := range records {
+			got = append(got, r.Id)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q matched %v, want %v", query, got, want)
+		}
+	}
+}
+
+func TestFacetCountsIgnoreTheirOwnFacet(t *testing.T) {
+	app := newFacetsTestApp(t)
+
+	q, _ := url.ParseQuery("art_school=italian&art_form=painting")
+
+	counts, err := getFacetCounts(app, filtersFromQuery(q))
+	if err != nil {
+		t.Fatalf("getFacetCounts: %v", err)
+	}
+
+	// schools are counted among the paintings, art forms among the Italian artworks
+	if want := map[string]int{"italian": 1, "flemish": 1}; !reflect.DeepEqual(counts.Schools, want) {
+		t.Errorf("school counts = %v, want %v", counts.Schools, want)
+	}
+	if want := map[string]int{"painting": 1, "sculpture": 1}; !reflect.DeepEqual(counts.ArtForms, want) {
+		t.Errorf("art form counts = %v, want %v", counts.ArtForms, want)
+	}
+	if want := map[string]int{"Botticelli": 1}; !reflect.DeepEqual(counts.Artists, want) {
+		t.Errorf("artist counts = %v, want %v, without unpublished artists", counts.Artists, want)
+	}
+}
+
+func TestFacetCountsAreOnlyCachedForBoundedFilters(t *testing.T) {
+	for query, want := range map[string]bool{
+		"":                                 true,
+		"page=3&attribution=certain":       true,
+		"collapse_series=1":                true,
+		"title=venus":                      false,
+		"art_school=italian":               false,
+		"artist=Botti&attribution=certain": false,
+		"year_from=1400":                   false,
+	} {
+		q, _ := url.ParseQuery(query)
+
+		if got := facetCountsCacheable(filtersFromQuery(q)); got != want {
+			t.Errorf("%q cacheable = %v, want %v", query, got, want)
+		}
+	}
+
+	app := newFacetsTestApp(t)
+
+	for query, want := range map[string]bool{"page=2": true, "title=venus": false} {
+		q, _ := url.ParseQuery(query)
+		f := filtersFromQuery(q)
+
+		if _, err := getFacetCounts(app, f); err != nil {
+			t.Fatalf("%q: getFacetCounts: %v", query, err)
+		}
+
+		paged := *f
+		paged.Page = ""
+		if got := app.Store().Has(artworkFacetCountsCacheKeyPrefix + paged.FingerPrint()); got != want {
+			t.Errorf("%q cached = %v, want %v", query, got, want)
+		}
+	}
+}
+
+func TestNewArtistFacetKeepsTypedNames(t *testing.T) {
+	counts := map[string]int{"Botticelli": 2, "Rubens": 5, "Titian": 1}
+	names := map[string]bool{"Botticelli": true, "Rubens": true, "Titian": true}
+
+	got, typed := newArtistFacet(counts, names, facet{Include: []string{"Titian", "Bru"}})
+
+	labels := []string{}
+	for _, o := range got.Options {
+		labels = append(labels, o.Label)
+	}
+
+	if !reflect.DeepEqual(labels, []string{"Titian", "Rubens", "Botticelli"}) {
+		t.Fatalf("options = %v, want the picked artist first, then by count", labels)
+	}
+	if !got.Options[0].Included || got.Options[0].Count != 1 {
+		t.Fatalf("picked option = %+v", got.Options[0])
+	}
+	if !reflect.DeepEqual(typed, []string{"Bru"}) {
+		t.Fatalf("typed = %v, want [Bru]", typed)
+	}
+}
+
+func newFacetsTestApp(t *testing.T) *tests.TestApp {
+	t.Helper()
+
+	app, err := tests.NewTestApp()
+	if err != nil {
+		t.Fatalf("create test app: %v", err)
+	}
+	t.Cleanup(app.Cleanup)
+
+	for _, name := range []string{"schools", "art_forms", "art_types"} {
+		c := core.NewBaseCollection(name)
+		c.Id = name
+		c.Fields.Add(
+			&core.TextField{Name: "name"},
+			&core.TextField{Name: "slug"},
+		)
+		saveTestCollection(t, app, c)
+	}
+
+	artists := core.NewBaseCollection("Artists")
+	artists.Id = "artists"
+	artists.Fields.Add(
+		&core.TextField{Name: "name"},
+		&core.BoolField{Name: "published"},
+	)
+	saveTestCollection(t, app, artists)
+
+	artistNames := core.NewBaseCollection("Artist_names")
+	artistNames.Id = "artist_names"
+	artistNames.Fields.Add(
+		&core.RelationField{Name: "artist", CollectionId: "artists", MaxSelect: 1, CascadeDelete: true},
+		&core.TextField{Name: "name"},
+	)
+	saveTestCollection(t, app, artistNames)
+
+	artworks := core.NewBaseCollection("Artworks")
+	artworks.Id = "artworks"
+	artworks.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.RelationField{Name: "author", CollectionId: "artists", MaxSelect: 10},
+		&core.RelationField{Name: "school", CollectionId: "schools", MaxSelect: 10},
+		&core.RelationField{Name: "form", CollectionId: "art_forms", MaxSelect: 20},
+		&core.RelationField{Name: "type", CollectionId: "art_types", MaxSelect: 20},
+		&core.BoolField{Name: "published"},
+		&core.NumberField{Name: "date_earliest", OnlyInt: true},
+		&core.NumberField{Name: "date_latest", OnlyInt: true},
+	)
+	saveTestCollection(t, app, artworks)
+
+	saveTestRecord(t, app, "schools", map[string]any{"id": "school000000001", "name": "Italian", "slug": "italian"})
+	saveTestRecord(t, app, "schools", map[string]any{"id": "school000000002", "name": "Flemish", "slug": "flemish"})
+	saveTestRecord(t, app, "art_forms", map[string]any{"id": "artform00000001", "name": "painting", "slug": "painting"})
+	saveTestRecord(t, app, "art_forms", map[string]any{"id": "artform00000002", "name": "sculpture", "slug": "sculpture"})
+	saveTestRecord(t, app, "artists", map[string]any{"id": "artist000000001", "name": "Botticelli", "published": true})
+	saveTestRecord(t, app, "artists", map[string]any{"id": "artist000000002", "name": "Rubens", "published": true})
+	saveTestRecord(t, app, "artists", map[string]any{"id": "artist000000003", "name": "Hidden workshop"})
+	saveTestRecord(t, app, "artist_names", map[string]any{"artist": "artist000000001", "name": "Sandro Filipepi"})
+	saveTestRecord(t, app, "artist_names", map[string]any{"artist": "artist000000002", "name": "Rubens, Peter Paul"})
+
+	saveTestRecord(t, app, "artworks", map[string]any{
+		"id": "artwork00000001", "title": "Birth of Venus", "published": true, "date_earliest": 1482, "date_latest": 1485,
+		"author": []string{"artist000000001", "artist000000003"}, "school": []string{"school000000001"}, "form": []string{"artform00000001"},
+	})
+	saveTestRecord(t, app, "artworks", map[string]any{
+		"id": "artwork00000002", "title": "Bust", "published": true, "date_earliest": 1490, "date_latest": 1490,
+		"author": []string{"artist000000001"}, "school": []string{"school000000001"}, "form": []string{"artform00000002"},
+	})
+	saveTestRecord(t, app, "artworks", map[string]any{
+		"id": "artwork00000003", "title": "Descent from the Cross", "published": true,
+		"author": []string{"artist000000002"}, "school": []string{"school000000002"}, "form": []string{"artform00000001"},
+	})
+	saveTestRecord(t, app, "artworks", map[string]any{
+		"id": "artwork00000004", "title": "Draft", "published": false,
+		"author": []string{"artist000000002"}, "school": []string{"school000000001"}, "form": []string{"artform00000001"},
+	})
+	saveTestRecord(t, app, "artworks", map[string]any{
+		"id": "artwork00000005", "title": "Workshop copy", "published": true,
+		"author": []string{"artist000000003"}, "school": []string{"school000000001"}, "form": []string{"artform00000001"},
+	})
+	saveTestRecord(t, app, "artworks", map[string]any{
+		"id": "artwork00000006", "title": "Anonymous", "published": true,
+		"school": []string{"school000000001"}, "form": []string{"artform00000001"},
+	})
+
+	return app
+}
+
+func saveTestCollection(t *testing.T, app core.App, c *core.Collection) {
+	t.Helper()
+
+	if err := app.Save(c); err != nil {
+		t.Fatalf("save %s collection: %v", c.Name, err)
+	}
+}
+
+func saveTestRecord(t *testing.T, app core.App, collection string, values map[string]any) {
+	t.Helper()
+
+	c, err := app.FindCollectionByNameOrId(collection)
+	if err != nil {
+		t.Fatalf("find %s collection: %v", collection, err)
+	}
+
+	record := core.NewRecord(c)
+	for key, value := range values {
+		record.Set(key, value)
+	}
+
+	if err := app.Save(record); err != nil {
+		t.Fatalf("save %s record: %v", collection, err)
+	}
+}
@@ -2,67 +2,319 @@ package artworks
 
 import (
 	"cmp"
+	"fmt"
+	"maps"
 	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/blackfyre/wga/internal/utils/dating"
 	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/core"
 )
 
+// facet holds the values picked for one search facet.
+// An artwork matches any of the included values and none of the excluded ones.
+type facet struct {
+	Include []string
+	Exclude []string
+}
+
+// Active checks if any value of the facet is picked.
+func (f facet) Active() bool {
+	return len(f.Include) > 0 || len(f.Exclude) > 0
+}
+
+// buildFilter builds the facet condition on field. Included values are compared with
+// the any-of operator op, excluded values with the all-of operator notOp, so an artwork
+// with several values is excluded if any of them is.
+func (f facet) buildFilter(field string, op string, notOp string, paramPrefix string) (string, dbx.Params) {
+	conditions := []string{}
+	params := dbx.Params{}
+
+	if len(f.Include) > 0 {
+		included := make([]string, 0, len(f.Include))
+
+		for index, value := range f.Include {
+			key := fmt.Sprintf("%s_%d", paramPrefix, index)
+			included = append(included, facetCondition(field, op, key))
+			params[key] = value
+		}
+
+		conditions = append(conditions, "("+strings.Join(included, " || ")+")")
+	}
+
+	for index, value := range f.Exclude {
+		key := fmt.Sprintf("%s_not_%d", paramPrefix, index)
+		conditions = append(conditions, facetCondition(field, notOp, key))
+		params[key] = value
+	}
+
+	return strings.Join(conditions, " && "), params
+}
+
+// artistNameField is the field of the artist facets. Artists also match by their other names.
+const artistNameField = "author.name"
+
+// facetCondition returns the condition matching a single value of a facet on the field.
+func facetCondition(field string, op string, key string) string {
+	if field == artistNameField {
+		return repositories.ArtistNameFilter("author.", op, key)
+	}
+
+	return fmt.Sprintf("%s %s {:%s}", field, op, key)
+}
+
+func (f facet) setQueryValues(values url.Values, name string) {
+	for _, v := range f.Include {
+		values.Add(name, v)
+	}
+
+	for _, v := range f.Exclude {
+		values.Add(name+"_not", v)
+	}
+}
+
+// facetFromQuery reads a facet from the name and name_not parameters of a query.
+// Blank and repeated values are dropped, and a value both included and excluded is excluded.
+func facetFromQuery(q url.Values, name string) facet {
+	f := facet{}
+
+	for _, v := range q[name+"_not"] {
+		if v = strings.TrimSpace(v); v != "" && !slices.Contains(f.Exclude, v) {
+			f.Exclude = append(f.Exclude, v)
+		}
+	}
+
+	for _, v := range q[name] {
+		if v = strings.TrimSpace(v); v != "" && !slices.Contains(f.Include, v) && !slices.Contains(f.Exclude, v) {
+			f.Include = append(f.Include, v)
+		}
+	}
+
+	return f
+}
+
+// Values of the attribution filter.
+const (
+	attributionCertain   = "certain"
+	attributionUncertain = "uncertain"
+)
+
 type filters struct {
-	Title         string
-	SchoolString  string
-	ArtFormString string
-	ArtTypeString string
-	ArtistString  string
-	Page          string
+	Title        string
+	School       facet
+	ArtForm      facet
+	ArtType      facet
+	Artist       facet
+	PeriodString string
+	// YearFrom and YearTo bound the years the artworks were made in, 0 when unset.
+	YearFrom int
+	YearTo   int
+	// Query is a search query typed in the query box, see parseQuery.
+	Query string
+	// Color is a colour the artworks show, as a hex code or a colour name, see palette.ParseColor.
+	Color string
+	// Attribution is attributionCertain or attributionUncertain to leave out or to keep only
+	// the artworks with an uncertain attribution, empty for every artwork.
+	Attribution string
+	// Location is the slug of the location holding the artworks.
+	Location string
+	// City is the city the artworks are held in, matched in part.
+	City string
+	// CollapseSeries shows every series once, by its cover or its first matching part,
+	// see repositories.RecordFilter.
+	CollapseSeries bool
+	Page           string
+	// TitleMatchIds holds the ranked full-text matches for Title.
+	// When nil, Title falls back to a substring match.
+	TitleMatchIds []string
+	// ColorMatchIds holds the artworks showing Color, the ones it covers the most first.
+	// When nil, a requested colour matches nothing.
+	ColorMatchIds []string
+	// Period holds the art period resolved from PeriodString.
+	// When nil, a requested period matches nothing.
+	Period *repositories.ArtPeriod
+	// QueryTerms holds the parsed Query. When nil, the query is ignored.
+	QueryTerms *queryTerms
 }
 
 // AnyFilterActive checks if any filter is active.
-// It returns true if any of the filter fields (Title, SchoolString, ArtFormString, ArtTypeString, ArtistString) is not empty.
+// It returns true if the query of the filters has any parameter besides the page.
 func (f *filters) AnyFilterActive() bool {
-	return f.Title != "" || f.SchoolString != "" || f.ArtFormString != "" || f.ArtTypeString != "" || f.ArtistString != ""
+	values := f.queryValues()
+	values.Del("page")
+
+	return len(values) > 0
+}
+
+// rankedIds returns the order of the matches of a ranked search: by colour when a colour
+// is searched, otherwise by full-text relevance. It is nil when the search is not ranked.
+func (f *filters) rankedIds() []string {
+	if f.Color != "" && f.ColorMatchIds != nil {
+		return f.ColorMatchIds
+	}
+
+	return f.TitleMatchIds
+}
+
+// periodSlug returns the period to search in, picked in the form or given in the query.
+func (f *filters) periodSlug() string {
+	if f.PeriodString == "" && f.QueryTerms != nil {
+		return f.QueryTerms.Period
+	}
+
+	return f.PeriodString
 }
 
 // FingerPrint returns a unique fingerprint string based on the filter values.
-// The fingerprint is generated by concatenating the title, school, art form, art type, and artist strings.
+// The fingerprint is the encoded query of the filters, page included.
 func (f *filters) FingerPrint() string {
-	return f.Title + ":" + f.SchoolString + ":" + f.ArtFormString + ":" + f.ArtTypeString + ":" + f.ArtistString + ":" + f.Page
+	return f.queryValues().Encode()
 }
 
-// BuildFilter builds a filter string and parameters based on the values of the filters struct.
-// The filter string is used to filter artworks based on various criteria such as title, school, art form, art type, and artist.
-// The parameters map contains the values to be substituted in the filter string.
-// The filter string and parameters are returned as a string and dbx.Params respectively.
-func (f *filters) BuildFilter() (string, dbx.Params) {
-	filterString := "published = true && author:length > 0"
+// BuildFilter builds a record filter based on the values of the filters struct.
+// The values of a facet are combined with OR, and its excluded values with AND NOT.
+// The parameters map contains the values to be substituted in the filter string,
+// and the ranked title and colour matches restrict the ids, see matchIds.
+func (f *filters) BuildFilter() repositories.RecordFilter {
+	filterString := "published = true && author.published ?= true"
 	params := dbx.Params{}
 
-	if f.Title != "" {
+	if f.Title != "" && f.TitleMatchIds == nil {
 		filterString = filterString + " && title ~ {:title}"
 		params["title"] = f.Title
 	}
 
-	if f.SchoolString != "" {
-		filterString = filterString + " && school.slug = {:art_school}"
-		params["art_school"] = f.SchoolString
+	for _, c := range []struct {
+		facet  facet
+		field  string
+		op     string
+		notOp  string
+		prefix string
+	}{
+		{f.School, "school.slug", "?=", "!=", "art_school"},
+		{f.ArtForm, "form.slug", "?=", "!=", "art_form"},
+		{f.ArtType, "type.slug", "?=", "!=", "art_type"},
+		{f.Artist, artistNameField, "?~", "!~", "artist"},
+	} {
+		if !c.facet.Active() {
+			continue
+		}
+
+		facetFilter, facetParams := c.facet.buildFilter(c.field, c.op, c.notOp, c.prefix)
+		filterString = filterString + " && " + facetFilter
+		maps.Copy(params, facetParams)
+	}
+
+	if f.YearFrom != 0 || f.YearTo != 0 {
+		filterString = filterString + " && " + dating.FieldEarliest + " > 0"
 	}
 
-	if f.ArtFormString != "" {
-		filterString = filterString + " && form.slug = {:art_form}"
-		params["art_form"] = f.ArtFormString
+	if f.YearFrom != 0 {
+		filterString = filterString + " && " + dating.FieldLatest + " >= {:year_from}"
+		params["year_from"] = f.YearFrom
 	}
 
-	if f.ArtTypeString != "" {
-		filterString = filterString + " && type.slug = {:art_type}"
-		params["art_type"] = f.ArtTypeString
+	if f.YearTo != 0 {
+		filterString = filterString + " && " + dating.FieldEarliest + " <= {:year_to}"
+		params["year_to"] = f.YearTo
 	}
 
-	if f.ArtistString != "" {
-		filterString = filterString + " && author.name ~ {:artist}"
-		params["artist"] = f.ArtistString
+	if f.Color != "" && f.ColorMatchIds == nil {
+		filterString = filterString + " && id = ''"
 	}
 
-	return filterString, params
+	switch f.Attribution {
+	case attributionCertain:
+		filterString = filterString + " && " + repositories.CertainAttributionFilter
+	case attributionUncertain:
+		filterString = filterString + " && " + repositories.UncertainAttributionFilter
+	}
+
+	if f.Location != "" {
+		filterString = filterString + " && " + repositories.LocationFilter
+		params["location"] = f.Location
+	}
+
+	if f.City != "" {
+		filterString = filterString + " && " + repositories.CityFilter
+		params["city"] = f.City
+	}
+
+	if f.QueryTerms != nil {
+		if queryFilter, queryParams := f.QueryTerms.buildFilter(); queryFilter != "" {
+			filterString = filterString + " && " + queryFilter
+			maps.Copy(params, queryParams)
+		}
+	}
+
+	if f.periodSlug() != "" && f.Period != nil {
+		filterString = filterString + " && " + repositories.ActiveInPeriodFilter("author.")
+		maps.Copy(params, f.Period.FilterParams())
+	} else if f.periodSlug() != "" {
+		filterString = filterString + " && id = ''"
+	}
+
+	return repositories.RecordFilter{Filter: filterString, Params: params, Ids: f.matchIds(), CollapseSeries: f.CollapseSeries}
+}
+
+// matchIds returns the artworks among both the title and the colour matches, nil when
+// neither the title nor the colour was resolved to ranked matches.
+func (f *filters) matchIds() []string {
+	var ids []string
+
+	for _, m := range []struct {
+		value   string
+		matches []string
+	}{
+		{f.Title, f.TitleMatchIds},
+		{f.Color, f.ColorMatchIds},
+	} {
+		if m.value == "" || m.matches == nil {
+			continue
+		}
+
+		if ids == nil {
+			ids = m.matches
+			continue
+		}
+
+		ids = slices.DeleteFunc(slices.Clone(ids), func(id string) bool {
+			return !slices.Contains(m.matches, id)
+		})
+	}
+
+	return ids
+}
+
+// QueryFilter builds the artwork record filter for the search parameters of a query,
+// resolving the title, the period and the colour the same way the search page does.
+// Other endpoints use it so they accept the same filter vocabulary.
+// A q that does not parse is reported as a *QueryError.
+func QueryFilter(app *pocketbase.PocketBase, q url.Values) (repositories.RecordFilter, error) {
+	f := filtersFromQuery(q)
+
+	if err := resolveQuery(f); err != nil {
+		return repositories.RecordFilter{}, err
+	}
+
+	if err := matchTitle(app, f); err != nil {
+		app.Logger().Warn("Full-text artwork search failed, falling back to substring match", "error", err.Error())
+	}
+
+	if err := resolvePeriod(app, f); err != nil {
+		return repositories.RecordFilter{}, err
+	}
+
+	if err := matchColor(app, f); err != nil {
+		return repositories.RecordFilter{}, err
+	}
+
+	return f.BuildFilter(), nil
 }
 
 // BuildFilterString builds a filter string based on the values of the filters struct.
@@ -88,20 +340,45 @@ func (f *filters) queryValues() url.Values {
 		values.Set("title", f.Title)
 	}
 
-	if f.SchoolString != "" {
-		values.Set("art_school", f.SchoolString)
+	f.School.setQueryValues(values, "art_school")
+	f.ArtForm.setQueryValues(values, "art_form")
+	f.ArtType.setQueryValues(values, "art_type")
+	f.Artist.setQueryValues(values, "artist")
+
+	if f.PeriodString != "" {
+		values.Set("period", f.PeriodString)
+	}
+
+	if f.YearFrom != 0 {
+		values.Set("year_from", strconv.Itoa(f.YearFrom))
+	}
+
+	if f.YearTo != 0 {
+		values.Set("year_to", strconv.Itoa(f.YearTo))
+	}
+
+	if f.Query != "" {
+		values.Set("q", f.Query)
+	}
+
+	if f.Color != "" {
+		values.Set("color", f.Color)
 	}
 
-	if f.ArtFormString != "" {
-		values.Set("art_form", f.ArtFormString)
+	if f.Attribution != "" {
+		values.Set("attribution", f.Attribution)
 	}
 
-	if f.ArtTypeString != "" {
-		values.Set("art_type", f.ArtTypeString)
+	if f.Location != "" {
+		values.Set("location", f.Location)
 	}
 
-	if f.ArtistString != "" {
-		values.Set("artist", f.ArtistString)
+	if f.City != "" {
+		values.Set("city", f.City)
+	}
+
+	if f.CollapseSeries {
+		values.Set("collapse_series", "1")
 	}
 
 	if f.Page != "" {
@@ -112,17 +389,43 @@ func (f *filters) queryValues() url.Values {
 }
 
 func buildFilters(c *core.RequestEvent) *filters {
+	return filtersFromQuery(c.Request.URL.Query())
+}
 
-	q := c.Request.URL.Query()
-
+func filtersFromQuery(q url.Values) *filters {
 	f := &filters{
-		Title:         cmp.Or(q.Get("title"), ""),
-		SchoolString:  cmp.Or(q.Get("art_school"), ""),
-		ArtFormString: cmp.Or(q.Get("art_form"), ""),
-		ArtTypeString: cmp.Or(q.Get("art_type"), ""),
-		ArtistString:  cmp.Or(q.Get("artist"), ""),
-		Page:          cmp.Or(q.Get("page"), ""),
+		Title:        cmp.Or(q.Get("title"), ""),
+		School:       facetFromQuery(q, "art_school"),
+		ArtForm:      facetFromQuery(q, "art_form"),
+		ArtType:      facetFromQuery(q, "art_type"),
+		Artist:       facetFromQuery(q, "artist"),
+		PeriodString: cmp.Or(q.Get("period"), ""),
+		YearFrom:     yearFromQuery(q, "year_from"),
+		YearTo:       yearFromQuery(q, "year_to"),
+		Query:        strings.TrimSpace(q.Get("q")),
+		Color:        strings.TrimSpace(q.Get("color")),
+		Location:     strings.TrimSpace(q.Get("location")),
+		City:         strings.TrimSpace(q.Get("city")),
+		Page:         cmp.Or(q.Get("page"), ""),
+	}
+
+	if collapse, err := strconv.ParseBool(q.Get("collapse_series")); err == nil {
+		f.CollapseSeries = collapse
+	}
+
+	if attribution := q.Get("attribution"); attribution == attributionCertain || attribution == attributionUncertain {
+		f.Attribution = attribution
 	}
 
 	return f
 }
+
+// yearFromQuery reads a year parameter of a query, 0 when it is missing or not a year.
+func yearFromQuery(q url.Values, name string) int {
+	year, err := strconv.Atoi(strings.TrimSpace(q.Get(name)))
+	if err != nil || year < 0 {
+		return 0
+	}
+
+	return year
+}
@@ -1,9 +1,12 @@
 package artworks
 
 import (
+	"slices"
 	"time"
 
+	"github.com/blackfyre/wga/internal/assets/templ/dto"
 	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/repositories"
 	"github.com/blackfyre/wga/internal/utils"
 	"github.com/blackfyre/wga/internal/utils/url"
 	"github.com/pocketbase/pocketbase"
@@ -15,7 +18,10 @@ const (
 	artTypesCacheKey    = "artworks:search:art-types"
 	artFormsCacheKey    = "artworks:search:art-forms"
 	artSchoolsCacheKey  = "artworks:search:art-schools"
+	artPeriodsCacheKey  = "artworks:search:art-periods"
 	artistNamesCacheKey = "artworks:search:artist-names"
+	locationsCacheKey   = "artworks:search:locations"
+	citiesCacheKey      = "artworks:search:cities"
 )
 
 // getArtTypesOptions returns a map of art type slugs and their corresponding names.
@@ -92,6 +98,30 @@ func getArtSchoolOptions(app *pocketbase.PocketBase) (map[string]string, error)
 	return options, nil
 }
 
+// getArtPeriodOptions returns a map of art period slugs to their corresponding names.
+func getArtPeriodOptions(app *pocketbase.PocketBase) (map[string]string, error) {
+	if cached, ok := utils.GetCachedValue[map[string]string](app, artPeriodsCacheKey); ok {
+		return cloneStringMap(cached), nil
+	}
+
+	options := map[string]string{
+		"": "Any",
+	}
+	periods, err := repositories.NewPeriodsRepository(app).GetPeriods()
+
+	if err != nil {
+		return options, err
+	}
+
+	for _, p := range periods {
+		options[p.Slug] = p.Name
+	}
+
+	utils.SetCachedValue(app, artPeriodsCacheKey, cloneStringMap(options), artworkSearchOptionsTTL)
+
+	return options, nil
+}
+
 func GetArtistNameList(app *pocketbase.PocketBase) (map[string]string, error) {
 	if cached, ok := utils.GetCachedValue[map[string]string](app, artistNamesCacheKey); ok {
 		return cloneStringMap(cached), nil
@@ -122,6 +152,48 @@ func GetArtistNameList(app *pocketbase.PocketBase) (map[string]string, error) {
 	return names, nil
 }
 
+// getLocationOptions returns the locations holding published artworks, ordered by country,
+// city and name, labelled with their city.
+func getLocationOptions(app *pocketbase.PocketBase) ([]dto.SearchFacetOption, error) {
+	if cached, ok := utils.GetCachedValue[[]dto.SearchFacetOption](app, locationsCacheKey); ok {
+		return slices.Clone(cached), nil
+	}
+
+	locations, err := repositories.NewLocationsRepository(app).GetLocations()
+	if err != nil {
+		return nil, err
+	}
+
+	options := make([]dto.SearchFacetOption, 0, len(locations))
+	for _, l := range locations {
+		options = append(options, dto.SearchFacetOption{
+			Value: l.Slug,
+			Label: l.Name + ", " + l.City,
+			Count: l.ArtworkCount,
+		})
+	}
+
+	utils.SetCachedValue(app, locationsCacheKey, slices.Clone(options), artworkSearchOptionsTTL)
+
+	return options, nil
+}
+
+// getCityNames returns the cities artworks are held in, in order.
+func getCityNames(app *pocketbase.PocketBase) ([]string, error) {
+	if cached, ok := utils.GetCachedValue[[]string](app, citiesCacheKey); ok {
+		return slices.Clone(cached), nil
+	}
+
+	cities, err := repositories.NewLocationsRepository(app).GetCities()
+	if err != nil {
+		return nil, err
+	}
+
+	utils.SetCachedValue(app, citiesCacheKey, slices.Clone(cities), artworkSearchOptionsTTL)
+
+	return cities, nil
+}
+
 func cloneStringMap(source map[string]string) map[string]string {
 	clone := make(map[string]string, len(source))
 
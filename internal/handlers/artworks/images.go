@@ -0,0 +1,49 @@
+package artworks
+
+import (
+	"github.com/blackfyre/wga/internal/assets/templ/dto"
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/blackfyre/wga/internal/utils/url"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// NewArtworkImage converts an artwork with its credited authors for the image grids.
+// The artwork is linked under its first author, so authors must not be empty.
+func NewArtworkImage(artwork *core.Record, authors []repositories.ArtworkAuthor) dto.Image {
+	artist := authors[0].Artist
+
+	imageURL := utils.AssetUrl("/assets/images/no-image.png")
+	thumbURL := imageURL
+
+	if imageName := artwork.GetString("image"); imageName != "" {
+		imageURL = url.GenerateFileUrl(constants.CollectionArtworks, artwork.Id, imageName, "")
+		thumbURL = url.GenerateThumbUrl(constants.CollectionArtworks, artwork.Id, imageName, "320x240", "")
+	}
+
+	return dto.Image{
+		Url: url.GenerateFullArtworkUrl(url.ArtworkUrlDTO{
+			ArtistName:   artist.GetString("name"),
+			ArtistId:     artist.Id,
+			ArtworkTitle: artwork.GetString("title"),
+			ArtworkId:    artwork.Id,
+		}),
+		Image:     imageURL,
+		Thumb:     thumbURL,
+		Comment:   artwork.GetString("comment"),
+		Title:     artwork.GetString("title"),
+		Technique: artwork.GetString("technique"),
+		Id:        artwork.Id,
+		Authors:   NewArtworkAuthors(authors),
+		Artist: dto.Artist{
+			Id:   artist.Id,
+			Name: artist.GetString("name"),
+			Url: url.GenerateArtistUrl(url.ArtistUrlDTO{
+				ArtistId:   artist.Id,
+				ArtistName: artist.GetString("name"),
+			}),
+			Profession: artist.GetString("profession"),
+		},
+	}
+}
@@ -4,18 +4,23 @@ import (
 	"bytes"
 	"cmp"
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"maps"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/blackfyre/wga/internal/assets/templ/dto"
 	"github.com/blackfyre/wga/internal/assets/templ/pages"
 	tmplUtils "github.com/blackfyre/wga/internal/assets/templ/utils"
-	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/repositories"
 	"github.com/blackfyre/wga/internal/utils"
+	"github.com/blackfyre/wga/internal/utils/fulltext"
+	"github.com/blackfyre/wga/internal/utils/palette"
 	"github.com/blackfyre/wga/internal/utils/url"
-	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/core"
 )
@@ -33,13 +38,6 @@ func searchPage(app *pocketbase.PocketBase, c *core.RequestEvent) error {
 	}
 
 	content := dto.ArtworkSearchDTO{
-		ActiveFilterValues: &dto.ArtworkSearchFilterValues{
-			Title:         filters.Title,
-			SchoolString:  filters.SchoolString,
-			ArtFormString: filters.ArtFormString,
-			ArtTypeString: filters.ArtTypeString,
-			ArtistString:  filters.ArtistString,
-		},
 		ClearUrl:        buildArtworkSearchClearPath(dualModeContext),
 		DualModeContext: dualModeContext,
 		HxTarget:        "#artwork-search-results",
@@ -48,14 +46,10 @@ func searchPage(app *pocketbase.PocketBase, c *core.RequestEvent) error {
 		},
 	}
 
-	content.ArtFormOptions, _ = getArtFormOptions(app)
-	content.ArtTypeOptions, _ = getArtTypesOptions(app)
-	content.ArtSchoolOptions, _ = getArtSchoolOptions(app)
-	content.ArtistNameList, _ = GetArtistNameList(app)
-	content.NewFilterValues = filters.BuildFilterString()
+	fillSearchForm(app, &content, filters)
 
 	ctx := tmplUtils.DecorateContext(context.Background(), tmplUtils.TitleKey, "Artworks Search")
-	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.DescriptionKey, "On this page you can search for artworks by title, artist, art form, art type and art school!")
+	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.DescriptionKey, "On this page you can search for artworks by title, artist, art form, art type, art school and period!")
 	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.OgUrlKey, fullUrl)
 
 	c.Response.Header().Set("HX-Push-Url", pushUrl)
@@ -97,38 +91,75 @@ func search(app *pocketbase.PocketBase, c *core.RequestEvent) error {
 	filters := buildFilters(c)
 	dualModeContext := getDualModeSearchContext(c)
 
-	filterString, filterParams := filters.BuildFilter()
+	if err := resolveQuery(filters); err != nil {
+		return searchQueryError(app, c, filters, dualModeContext, err)
+	}
 
-	records, err := app.FindRecordsByFilter(
-		constants.CollectionArtworks,
-		filterString,
-		"+title",
-		limit,
-		offset,
-		filterParams,
-	)
+	if err := matchTitle(app, filters); err != nil {
+		app.Logger().Warn("Full-text artwork search failed, falling back to substring match", "error", err.Error())
+	}
 
-	if err != nil {
-		app.Logger().Error("Failed to get artwork records", "error", err.Error())
+	if err := resolvePeriod(app, filters); err != nil {
+		app.Logger().Error("Failed to resolve art period", "period", filters.periodSlug(), "error", err.Error())
 		return utils.ServerFaultError(c)
 	}
 
-	// this could be replaced with a dedicated SQL query, but this is more convinient
-	totalRecords, err := app.FindRecordsByFilter(
-		constants.CollectionArtworks,
-		filterString,
-		"",
-		0,
-		0,
-		filterParams,
-	)
-
-	if err != nil {
-		app.Logger().Error("Failed to count artwork records", "error", err.Error())
+	if err := matchColor(app, filters); err != nil {
+		app.Logger().Error("Failed to match artwork colours", "color", filters.Color, "error", err.Error())
 		return utils.ServerFaultError(c)
 	}
 
-	recordsCount := len(totalRecords)
+	filter := filters.BuildFilter()
+	repo := repositories.NewArtworksRepository(app)
+
+	var records []*core.Record
+	recordsCount := 0
+	nextCursor, previousCursor := "", ""
+
+	if rankedIds := filters.rankedIds(); rankedIds != nil {
+		// ranked matches are bounded, so they are ordered and paged in memory
+		matches, err := repo.FindPage(repositories.PageQuery{RecordFilter: filter})
+
+		if err != nil {
+			app.Logger().Error("Failed to get artwork records", "error", err.Error())
+			return utils.ServerFaultError(c)
+		}
+
+		ranked := fulltext.OrderByIds(matches.Records, rankedIds)
+		recordsCount = len(ranked)
+		records = fulltext.Page(ranked, offset, limit)
+	} else {
+		pageQuery := repositories.PageQuery{
+			RecordFilter: filter,
+			Limit:        limit,
+			Offset:       offset,
+		}
+
+		if err := pageQuery.SetCursors(queryParams.Get("after"), queryParams.Get("before")); err != nil {
+			return utils.BadRequestError(c)
+		}
+
+		result, err := repo.FindPage(pageQuery)
+
+		if err != nil {
+			app.Logger().Error("Failed to get artwork records", "error", err.Error())
+			return utils.ServerFaultError(c)
+		}
+
+		records = result.Records
+
+		if result.Last != nil {
+			nextCursor = result.Last.String()
+			previousCursor = result.First.String()
+		}
+
+		recordsCount, err = repo.Count(filter)
+
+		if err != nil {
+			app.Logger().Error("Failed to count artwork records", "error", err.Error())
+			return utils.ServerFaultError(c)
+		}
+	}
 
 	content := dto.ArtworkSearchDTO{
 		HxTarget:        "#artwork-search-results",
@@ -137,13 +168,6 @@ func search(app *pocketbase.PocketBase, c *core.RequestEvent) error {
 		Results: dto.ArtworkSearchResultDTO{
 			Artworks: dto.ImageGrid{},
 		},
-		ActiveFilterValues: &dto.ArtworkSearchFilterValues{
-			Title:         filters.Title,
-			SchoolString:  filters.SchoolString,
-			ArtFormString: filters.ArtFormString,
-			ArtTypeString: filters.ArtTypeString,
-			ArtistString:  filters.ArtistString,
-		},
 	}
 
 	if dualModeContext != nil {
@@ -151,69 +175,45 @@ func search(app *pocketbase.PocketBase, c *core.RequestEvent) error {
 		content.Results.DualModeTarget = dualModeContext.Target
 	}
 
-	content.ArtFormOptions, _ = getArtFormOptions(app)
-	content.ArtTypeOptions, _ = getArtTypesOptions(app)
-	content.ArtSchoolOptions, _ = getArtSchoolOptions(app)
-	content.ArtistNameList, _ = GetArtistNameList(app)
-	content.NewFilterValues = filters.BuildFilterString()
+	fillSearchForm(app, &content, filters)
 	content.Results.ActiveFiltering = filters.AnyFilterActive()
 	content.Results.ResultCount = recordsCount
 	content.Results.ResultSummary = buildResultsSummary(recordsCount, filters.AnyFilterActive())
 
-	artistsByID, err := getArtistsByIDs(app, records)
+	if err := repo.PrefetchRelations(records); err != nil {
+		app.Logger().Error("Failed to prefetch artwork relations", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	credits, err := repo.FindAuthors(records)
 
 	if err != nil {
-		app.Logger().Error("Failed to batch load artists", "error", err.Error())
+		app.Logger().Error("Failed to find artwork authors", "error", err.Error())
 		return utils.ServerFaultError(c)
 	}
 
-	for _, v := range records {
+	var collapsed map[string]*dto.ImageSeries
 
-		artistIds := v.GetStringSlice("author")
+	if filters.CollapseSeries {
+		collapsed, err = collapsedSeries(app, records)
 
-		if len(artistIds) == 0 {
-			// waiting for the promised logging system by @pocketbase
-			continue
+		if err != nil {
+			app.Logger().Error("Failed to find the series of the artworks", "error", err.Error())
+			return utils.ServerFaultError(c)
 		}
+	}
+
+	for _, v := range records {
 
-		artist, ok := artistsByID[artistIds[0]]
+		authors := credits[v.Id]
 
-		if !ok {
+		if len(authors) == 0 {
 			// waiting for the promised logging system by @pocketbase
 			continue
 		}
 
-		imageURL := utils.AssetUrl("/assets/images/no-image.png")
-		thumbURL := imageURL
-
-		if imageName := v.GetString("image"); imageName != "" {
-			imageURL = url.GenerateFileUrl(constants.CollectionArtworks, v.GetString("id"), imageName, "")
-			thumbURL = url.GenerateThumbUrl(constants.CollectionArtworks, v.GetString("id"), imageName, "320x240", "")
-		}
-
-		artwork := dto.Image{
-			Url: url.GenerateFullArtworkUrl(url.ArtworkUrlDTO{
-				ArtistName:   artist.GetString("name"),
-				ArtistId:     artist.GetString("id"),
-				ArtworkTitle: v.GetString("title"),
-				ArtworkId:    v.GetString("id"),
-			}),
-			Image:     imageURL,
-			Thumb:     thumbURL,
-			Comment:   v.GetString("comment"),
-			Title:     v.GetString("title"),
-			Technique: v.GetString("technique"),
-			Id:        v.GetString("id"),
-			Artist: dto.Artist{
-				Id:   artist.GetString("id"),
-				Name: artist.GetString("name"),
-				Url: url.GenerateArtistUrl(url.ArtistUrlDTO{
-					ArtistId:   artist.Id,
-					ArtistName: artist.GetString("name"),
-				}),
-				Profession: artist.GetString("profession"),
-			},
-		}
+		artwork := NewArtworkImage(v, authors)
+		artwork.Series = collapsed[v.Id]
 
 		content.Results.Artworks = append(content.Results.Artworks, artwork)
 
@@ -226,19 +226,45 @@ func search(app *pocketbase.PocketBase, c *core.RequestEvent) error {
 	pHtmxUrl := buildArtworkSearchPath("/artworks/results", filters, dualModeContext)
 
 	pagination := utils.NewPagination(recordsCount, limit, page, pUrl, "artwork-search-results", pHtmxUrl)
+	pagination.SetCursors(nextCursor, previousCursor)
 
 	content.Results.Pagination = string(pagination.Render())
 
+	return renderSearchResults(app, c, content, pHtmxUrl)
+}
+
+// searchQueryError answers a search whose query does not parse with the search form
+// and the parse error in place of the results.
+func searchQueryError(app *pocketbase.PocketBase, c *core.RequestEvent, f *filters, dualModeContext *dto.ArtworkSearchDualModeDto, queryErr error) error {
+	content := dto.ArtworkSearchDTO{
+		HxTarget:        "#artwork-search-results",
+		ClearUrl:        buildArtworkSearchClearPath(dualModeContext),
+		DualModeContext: dualModeContext,
+		Results: dto.ArtworkSearchResultDTO{
+			ActiveFiltering: true,
+			QueryError:      queryErr.Error(),
+		},
+	}
+
+	fillSearchForm(app, &content, f)
+
+	return renderSearchResults(app, c, content, buildArtworkSearchPath("/artworks/results", f, dualModeContext))
+}
+
+// renderSearchResults renders the results with the facets for requests of the search form,
+// and the whole search page otherwise.
+func renderSearchResults(app *pocketbase.PocketBase, c *core.RequestEvent, content dto.ArtworkSearchDTO, pushUrl string) error {
 	ctx := tmplUtils.DecorateContext(context.Background(), tmplUtils.TitleKey, "Artworks Search")
-	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.DescriptionKey, "On this page you can search for artworks by title, artist, art form, art type and art school!")
-	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.OgUrlKey, pHtmxUrl)
+	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.DescriptionKey, "On this page you can search for artworks by title, artist, art form, art type, art school and period!")
+	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.OgUrlKey, pushUrl)
 
-	c.Response.Header().Set("HX-Push-Url", pHtmxUrl)
+	c.Response.Header().Set("HX-Push-Url", pushUrl)
 
 	var buff bytes.Buffer
+	var err error
 
 	if utils.IsHtmxRequest(c) {
-		err = pages.ArtworkSearchResults(content.Results).Render(ctx, &buff)
+		err = pages.ArtworkSearchUpdate(content).Render(ctx, &buff)
 	} else {
 		err = pages.ArtworkSearchPage(content).Render(ctx, &buff)
 	}
@@ -251,6 +277,135 @@ func search(app *pocketbase.PocketBase, c *core.RequestEvent) error {
 	return c.HTML(http.StatusOK, buff.String())
 }
 
+// fillSearchForm sets the option lists and the active values of the search form.
+// The facets are left empty when their counts cannot be loaded, the search itself still works.
+func fillSearchForm(app *pocketbase.PocketBase, content *dto.ArtworkSearchDTO, f *filters) {
+	facets, typedArtists, err := buildSearchFacets(app, f)
+	if err != nil {
+		app.Logger().Error("Failed to build artwork search facets", "error", err.Error())
+	}
+
+	content.Facets = facets
+	content.ActiveFilterValues = &dto.ArtworkSearchFilterValues{
+		Title:          f.Title,
+		PeriodString:   f.PeriodString,
+		Query:          f.Query,
+		Color:          f.Color,
+		Attribution:    f.Attribution,
+		Location:       f.Location,
+		City:           f.City,
+		CollapseSeries: f.CollapseSeries,
+	}
+
+	if f.YearFrom != 0 {
+		content.ActiveFilterValues.YearFrom = strconv.Itoa(f.YearFrom)
+	}
+
+	if f.YearTo != 0 {
+		content.ActiveFilterValues.YearTo = strconv.Itoa(f.YearTo)
+	}
+
+	if len(typedArtists) > 0 {
+		content.ActiveFilterValues.ArtistString = typedArtists[0]
+		content.ActiveFilterValues.ArtistExtra = typedArtists[1:]
+	}
+
+	content.ArtPeriodOptions, _ = getArtPeriodOptions(app)
+	content.ArtistNameList, _ = GetArtistNameList(app)
+	content.ColorNames = slices.Sorted(maps.Keys(palette.NamedColors))
+	content.LocationOptions, _ = getLocationOptions(app)
+	content.CityNames, _ = getCityNames(app)
+	content.NewFilterValues = f.BuildFilterString()
+}
+
+// maxRankedArtworkMatches bounds how many full-text matches a title search considers.
+const maxRankedArtworkMatches = 500
+
+// matchTitle resolves the title filter against the full-text index.
+// The filters are left untouched when the index is unavailable,
+// so BuildFilter falls back to a substring match.
+func matchTitle(app *pocketbase.PocketBase, f *filters) error {
+	if f.Title == "" || !fulltext.IndexAvailable(app) {
+		return nil
+	}
+
+	ids, err := fulltext.MatchIds(app, fulltext.KindArtwork, f.Title, maxRankedArtworkMatches)
+	if err != nil {
+		return err
+	}
+
+	f.TitleMatchIds = ids
+
+	return nil
+}
+
+// maxRankedColorMatches bounds how many artworks a colour search considers.
+const maxRankedColorMatches = 500
+
+// matchColor resolves the colour filter to the artworks showing the colour.
+// A colour that does not parse leaves ColorMatchIds nil, so the filter matches nothing.
+func matchColor(app *pocketbase.PocketBase, f *filters) error {
+	if f.Color == "" {
+		return nil
+	}
+
+	color, err := palette.ParseColor(f.Color)
+	if err != nil {
+		return nil
+	}
+
+	ids, err := palette.MatchIds(app, color, maxRankedColorMatches)
+	if err != nil {
+		return err
+	}
+
+	f.ColorMatchIds = ids
+
+	return nil
+}
+
+// resolveQuery parses the search query into QueryTerms.
+// A query that does not parse, or that asks for another period than the period filter,
+// is reported as a *QueryError and leaves QueryTerms nil.
+func resolveQuery(f *filters) error {
+	if f.Query == "" {
+		return nil
+	}
+
+	terms, err := parseQuery(f.Query)
+	if err != nil {
+		return err
+	}
+
+	if terms.Period != "" && f.PeriodString != "" && terms.Period != f.PeriodString {
+		return newQueryError("The query asks for another period than the one picked in the period filter.")
+	}
+
+	f.QueryTerms = terms
+
+	return nil
+}
+
+// resolvePeriod looks up the art period requested by the period filter or the query.
+// An unknown slug leaves Period nil, so the filter matches nothing.
+func resolvePeriod(app *pocketbase.PocketBase, f *filters) error {
+	if f.periodSlug() == "" {
+		return nil
+	}
+
+	period, err := repositories.NewPeriodsRepository(app).FindPeriodBySlug(f.periodSlug())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	f.Period = &period
+
+	return nil
+}
+
 func getDualModeSearchContext(c *core.RequestEvent) *dto.ArtworkSearchDualModeDto {
 	if c == nil || c.Request == nil || c.Request.URL == nil {
 		return nil
@@ -340,62 +495,6 @@ func buildResultsSummary(recordsCount int, hasActiveFilters bool) string {
 	return fmt.Sprintf("%d artworks found.", recordsCount)
 }
 
-func getArtistsByIDs(app *pocketbase.PocketBase, artworks []*core.Record) (map[string]*core.Record, error) {
-	artistIDs := uniqueArtistIDs(artworks)
-
-	if len(artistIDs) == 0 {
-		return map[string]*core.Record{}, nil
-	}
-
-	params := dbx.Params{}
-	conditions := make([]string, 0, len(artistIDs))
-
-	for index, artistID := range artistIDs {
-		key := fmt.Sprintf("artist_id_%d", index)
-		conditions = append(conditions, fmt.Sprintf("id = {:%s}", key))
-		params[key] = artistID
-	}
-
-	artists, err := app.FindRecordsByFilter(
-		constants.CollectionArtists,
-		strings.Join(conditions, " || "),
-		"+name",
-		0,
-		0,
-		params,
-	)
-
-	if err != nil {
-		return nil, err
-	}
-
-	artistsByID := make(map[string]*core.Record, len(artists))
-
-	for _, artist := range artists {
-		artistsByID[artist.Id] = artist
-	}
-
-	return artistsByID, nil
-}
-
-func uniqueArtistIDs(artworks []*core.Record) []string {
-	seen := map[string]struct{}{}
-	artistIDs := make([]string, 0, len(artworks))
-
-	for _, artwork := range artworks {
-		for _, artistID := range artwork.GetStringSlice("author") {
-			if _, exists := seen[artistID]; exists {
-				continue
-			}
-
-			seen[artistID] = struct{}{}
-			artistIDs = append(artistIDs, artistID)
-		}
-	}
-
-	return artistIDs
-}
-
 // RegisterArtworksHandlers registers search handlers to the given PocketBase app.
 func RegisterArtworksHandlers(app *pocketbase.PocketBase) {
 	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
@@ -406,6 +505,13 @@ func RegisterArtworksHandlers(app *pocketbase.PocketBase) {
 		se.Router.GET("/artworks/results", func(c *core.RequestEvent) error {
 			return search(app, c)
 		})
+
+		se.Router.GET("/artworks/{id}/related", func(c *core.RequestEvent) error {
+			return processRelated(app, c)
+		})
+
+		registerTilesHandlers(app, se)
+
 		return se.Next()
 	})
 }
@@ -1,11 +1,15 @@
 package artworks
 
 import (
+	"fmt"
 	"net/http/httptest"
 	"net/url"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/blackfyre/wga/internal/assets/templ/dto"
+	"github.com/blackfyre/wga/internal/repositories"
 	"github.com/pocketbase/pocketbase/core"
 )
 
@@ -109,3 +113,198 @@ func TestBuildDualModeArtworkURL(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildFilterPeriod(t *testing.T) {
+	unresolved := &filters{PeriodString: "unknown"}
+	filterString := unresolved.BuildFilter().Filter
+	if !strings.HasSuffix(filterString, " && id = ''") {
+		t.Fatalf("expected an unknown period to match nothing, got %q", filterString)
+	}
+
+	resolved := &filters{
+		PeriodString: "baroque",
+		Period:       &repositories.ArtPeriod{Slug: "baroque", Start: 1600, End: 1750},
+	}
+	filter := resolved.BuildFilter()
+	if !strings.Contains(filter.Filter, repositories.ActiveInPeriodFilter("author.")) {
+		t.Fatalf("expected the period filter to address the authors, got %q", filter.Filter)
+	}
+	if filter.Params["period_start"] != 1600 || filter.Params["period_end"] != 1750 {
+		t.Fatalf("unexpected period params %v", filter.Params)
+	}
+
+	if got := resolved.BuildFilterString(); got != "period=baroque" {
+		t.Fatalf("expected the period to be kept in the query string, got %q", got)
+	}
+}
+
+func TestYearRangeMatchesOverlappingDatings(t *testing.T) {
+	app := newFacetsTestApp(t)
+
+	cases := map[string][]string{
+		"year_from=1480&year_to=1500": {"artwork00000001", "artwork00000002"},
+		"year_from=1484&year_to=1486": {"artwork00000001"},
+		"year_from=1486":              {"artwork00000002"},
+		"year_to=1483":                {"artwork00000001"},
+		"year_from=1600":              {},
+		"year_from=not-a-year":        {"artwork00000001", "artwork00000002", "artwork00000003"},
+	}
+
+	for query, want := range cases {
+		q, _ := url.ParseQuery(query)
+		filter := filtersFromQuery(q).BuildFilter()
+
+		records, err := app.FindRecordsByFilter("artworks", filter.Filter, "+id", 0, 0, filter.Params)
+		if err != nil {
+			t.Fatalf("%q: %v", query, err)
+		}
+
+		got := []string{}
+		for _, r := range records {
+			got = append(got, r.Id)
+		}
+
+		if !slices.Equal(got, want) {
+			t.Errorf("%q matched %v, want %v", query, got, want)
+		}
+	}
+}
+
+func TestBuildFilterColor(t *testing.T) {
+	unresolved := &filters{Color: "ultramarine"}
+	filterString := unresolved.BuildFilter().Filter
+	if !strings.HasSuffix(filterString, " && id = ''") {
+		t.Fatalf("expected an unresolved colour to match nothing, got %q", filterString)
+	}
+
+	ranked := &filters{
+		Title:         "venus",
+		TitleMatchIds: []string{"artwork00000001", "artwork00000002", "artwork00000003"},
+		Color:         "blue",
+		ColorMatchIds: []string{"artwork00000002", "artwork00000001"},
+	}
+	if got := ranked.BuildFilter().Ids; !slices.Equal(got, []string{"artwork00000001", "artwork00000002"}) {
+		t.Fatalf("expected the artworks matching both the title and the colour, got %v", got)
+	}
+
+	if got := ranked.rankedIds(); !slices.Equal(got, ranked.ColorMatchIds) {
+		t.Fatalf("expected the colour to rank the results, got %v", got)
+	}
+
+	if got := ranked.BuildFilterString(); got != "color=blue&title=venus" {
+		t.Fatalf("expected the colour to be kept in the query string, got %q", got)
+	}
+}
+
+func TestRankedMatchesAreNotBoundByTheFilterExpressionLimit(t *testing.T) {
+	app := newFacetsTestApp(t)
+
+	// more matches than the filter expressions PocketBase allows
+	ids := []string{}
+	for i := range 300 {
+		id := fmt.Sprintf("madonna%08d", i)
+		ids = append(ids, id)
+
+		saveTestRecord(t, app, "artworks", map[string]any{
+			"id": id, "title": "Madonna", "published": true,
+			"author": []string{"artist000000001"}, "school": []string{"school000000001"},
+		})
+	}
+
+	f := &filters{Title: "madonna", TitleMatchIds: ids}
+	repo := repositories.NewArtworksRepository(app)
+
+	count, err := repo.Count(f.BuildFilter())
+	if err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if count != len(ids) {
+		t.Fatalf("count = %d, want %d", count, len(ids))
+	}
+
+	page, err := repo.FindPage(repositories.PageQuery{RecordFilter: f.BuildFilter(), Limit: 16})
+	if err != nil {
+		t.Fatalf("find page: %v", err)
+	}
+	if len(page.Records) != 16 {
+		t.Fatalf("page has %d artworks, want 16", len(page.Records))
+	}
+
+	counts, err := getFacetCounts(app, f)
+	if err != nil {
+		t.Fatalf("getFacetCounts: %v", err)
+	}
+	if counts.Schools["italian"] != len(ids) {
+		t.Fatalf("school counts = %v, want %d Italian artworks", counts.Schools, len(ids))
+	}
+}
+
+func TestBuildFilterAttribution(t *testing.T) {
+	for query, want := range map[string]string{
+		"attribution=certain":   repositories.CertainAttributionFilter,
+		"attribution=uncertain": repositories.UncertainAttributionFilter,
+	} {
+		q, _ := url.ParseQuery(query)
+		f := filtersFromQuery(q)
+
+		if filterString := f.BuildFilter().Filter; !strings.HasSuffix(filterString, " && "+want) {
+			t.Errorf("%q: expected the filter to end with %q, got %q", query, want, filterString)
+		}
+
+		if got := f.BuildFilterString(); got != query {
+			t.Errorf("%q: expected the attribution to be kept in the query string, got %q", query, got)
+		}
+	}
+
+	q, _ := url.ParseQuery("attribution=maybe")
+	if f := filtersFromQuery(q); f.Attribution != "" || f.AnyFilterActive() {
+		t.Fatalf("expected an unknown attribution to be dropped, got %q", f.Attribution)
+	}
+}
+
+func TestBuildFilterLocation(t *testing.T) {
+	q, _ := url.ParseQuery("city=+Madrid+&location=museo-del-prado-madrid")
+	f := filtersFromQuery(q)
+
+	if f.Location != "museo-del-prado-madrid" || f.City != "Madrid" || !f.AnyFilterActive() {
+		t.Fatalf("filters = %+v", f)
+	}
+
+	filter := f.BuildFilter()
+
+	for _, want := range []string{" && " + repositories.LocationFilter, " && " + repositories.CityFilter} {
+		if !strings.Contains(filter.Filter, want) {
+			t.Errorf("expected the filter to contain %q, got %q", want, filter.Filter)
+		}
+	}
+
+	if filter.Params["location"] != "museo-del-prado-madrid" || filter.Params["city"] != "Madrid" {
+		t.Errorf("params = %v", filter.Params)
+	}
+
+	if got := f.BuildFilterString(); got != "city=Madrid&location=museo-del-prado-madrid" {
+		t.Errorf("expected the location and the city to be kept in the query string, got %q", got)
+	}
+}
+
+func TestBuildFilterCollapseSeries(t *testing.T) {
+	q, _ := url.ParseQuery("collapse_series=1")
+	f := filtersFromQuery(q)
+
+	if !f.CollapseSeries || !f.AnyFilterActive() {
+		t.Fatalf("filters = %+v", f)
+	}
+
+	if !f.BuildFilter().CollapseSeries {
+		t.Errorf("expected the filter to collapse the series")
+	}
+
+	if got := f.BuildFilterString(); got != "collapse_series=1" {
+		t.Errorf("expected the collapse to be kept in the query string, got %q", got)
+	}
+
+	q, _ = url.ParseQuery("collapse_series=maybe")
+	if f := filtersFromQuery(q); f.CollapseSeries || f.AnyFilterActive() {
+		t.Errorf("expected an invalid collapse to be ignored, got %+v", f)
+	}
+}
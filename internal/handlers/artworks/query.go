@@ -0,0 +1,276 @@
+package artworks
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/pocketbase/dbx"
+)
+
+// QueryError is a syntax error in a search query, with a message meant for the visitor.
+type QueryError struct {
+	Message string
+}
+
+func (e *QueryError) Error() string {
+	return e.Message
+}
+
+func newQueryError(format string, args ...any) *QueryError {
+	return &QueryError{Message: fmt.Sprintf(format, args...)}
+}
+
+// queryFieldAliases maps the field names accepted in search queries to their canonical field.
+var queryFieldAliases = map[string]string{
+	"title":   "title",
+	"artist":  "artist",
+	"author":  "artist",
+	"by":      "artist",
+	"painter": "artist",
+	"school":  "school",
+	"form":    "form",
+	"medium":  "form",
+	"type":    "type",
+	"genre":   "type",
+	"period":  "period",
+	"era":     "period",
+}
+
+// maxQueryTerms and maxQueryFacetValues bound the values of a search query, in all and by
+// field, so its conditions stay within the expressions a record filter may have.
+const (
+	maxQueryTerms       = 20
+	maxQueryFacetValues = 10
+)
+
+// queryTerms is a parsed search query. Title holds words and phrases the title must contain,
+// or must not contain when excluded. The other facets work like the ones of the search form.
+type queryTerms struct {
+	Title   facet
+	School  facet
+	ArtForm facet
+	ArtType facet
+	Artist  facet
+	Period  string
+}
+
+// parseQuery parses a search query like
+//
+//	artist:botticelli school:italian type:religious "birth of venus" -fresco
+//
+// Terms are words or "quoted phrases", optionally prefixed with a field name and a colon,
+// and with a minus to exclude them. Terms without a field match the title.
+// Field values can be separated with commas to match any of them, and school, form and type
+// values can be given by name as well as by slug.
+func parseQuery(input string) (*queryTerms, error) {
+	terms := &queryTerms{}
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		start := i
+		negated := false
+
+		if runes[i] == '-' {
+			negated = true
+			i++
+
+			if i == len(runes) || unicode.IsSpace(runes[i]) {
+				return nil, newQueryError(`A "-" must be followed by the term to exclude, like -fresco.`)
+			}
+		}
+
+		field := ""
+		if end := fieldNameEnd(runes, i); end > i {
+			field = queryFieldAliases[strings.ToLower(string(runes[i:end]))]
+			i = end + 1
+		}
+
+		values := []string{}
+
+		if i < len(runes) && runes[i] == '"' {
+			end := slices.Index(runes[i+1:], '"')
+			if end < 0 {
+				return nil, newQueryError(`The phrase %s is missing its closing quote.`, string(runes[i:]))
+			}
+
+			values = append(values, strings.TrimSpace(string(runes[i+1:i+1+end])))
+			i += end + 2
+		} else {
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) {
+				end++
+			}
+
+			word := string(runes[i:end])
+			if field != "" {
+				values = strings.Split(word, ",")
+			} else {
+				values = append(values, word)
+			}
+			i = end
+		}
+
+		values = slices.DeleteFunc(values, func(v string) bool { return strings.TrimSpace(v) == "" })
+		if len(values) == 0 {
+			return nil, newQueryError(`"%s" is missing a value.`, string(runes[start:i]))
+		}
+
+		if err := terms.add(field, negated, values); err != nil {
+			return nil, err
+		}
+	}
+
+	return terms, nil
+}
+
+// fieldNameEnd returns the index of the colon ending the field name that starts at i,
+// or i when there is no field name there. Words ending in a colon that are not field
+// names, like the one of "Madonna: child", are left to the title.
+func fieldNameEnd(runes []rune, i int) int {
+	end := i
+	for end < len(runes) && unicode.IsLetter(runes[end]) {
+		end++
+	}
+
+	if end > i && end < len(runes) && runes[end] == ':' {
+		if _, ok := queryFieldAliases[strings.ToLower(string(runes[i:end]))]; ok {
+			return end
+		}
+	}
+
+	return i
+}
+
+func (t *queryTerms) add(field string, negated bool, values []string) error {
+	if t.count()+len(values) > maxQueryTerms {
+		return newQueryError("The query has too many terms, search with at most %d.", maxQueryTerms)
+	}
+
+	var target *facet
+
+	switch field {
+	case "", "title":
+		target = &t.Title
+	case "artist":
+		target = &t.Artist
+	case "school":
+		target = &t.School
+		values = slugifyAll(values)
+	case "form":
+		target = &t.ArtForm
+		values = slugifyAll(values)
+	case "type":
+		target = &t.ArtType
+		values = slugifyAll(values)
+	case "period":
+		if negated {
+			return newQueryError("A period cannot be excluded, pick the period to search in instead.")
+		}
+		period := utils.Slugify(values[0])
+		if len(values) > 1 || (t.Period != "" && t.Period != period) {
+			return newQueryError("Only one period can be searched at a time.")
+		}
+
+		t.Period = period
+
+		return nil
+	}
+
+	if len(target.Include)+len(target.Exclude)+len(values) > maxQueryFacetValues {
+		if field == "" {
+			field = "title"
+		}
+
+		return newQueryError("Too many %s terms, search with at most %d.", field, maxQueryFacetValues)
+	}
+
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+
+		if negated {
+			target.Include = slices.DeleteFunc(target.Include, func(i string) bool { return i == v })
+			if !slices.Contains(target.Exclude, v) {
+				target.Exclude = append(target.Exclude, v)
+			}
+		} else if !slices.Contains(target.Include, v) && !slices.Contains(target.Exclude, v) {
+			target.Include = append(target.Include, v)
+		}
+	}
+
+	return nil
+}
+
+// count returns the number of values of the terms.
+func (t *queryTerms) count() int {
+	count := 0
+	for _, f := range []facet{t.Title, t.School, t.ArtForm, t.ArtType, t.Artist} {
+		count += len(f.Include) + len(f.Exclude)
+	}
+
+	if t.Period != "" {
+		count++
+	}
+
+	return count
+}
+
+// buildFilter builds the conditions of the query, with parameters prefixed with q_
+// so they do not collide with the ones of the search form.
+// The title words and phrases must all match, unlike the values of the other facets.
+func (t *queryTerms) buildFilter() (string, dbx.Params) {
+	conditions := []string{}
+	params := dbx.Params{}
+
+	for index, value := range t.Title.Include {
+		key := fmt.Sprintf("q_title_%d", index)
+		conditions = append(conditions, fmt.Sprintf("title ~ {:%s}", key))
+		params[key] = value
+	}
+
+	for index, value := range t.Title.Exclude {
+		key := fmt.Sprintf("q_title_not_%d", index)
+		conditions = append(conditions, fmt.Sprintf("title !~ {:%s}", key))
+		params[key] = value
+	}
+
+	for _, c := range []struct {
+		facet  facet
+		field  string
+		op     string
+		notOp  string
+		prefix string
+	}{
+		{t.School, "school.slug", "?=", "!=", "q_art_school"},
+		{t.ArtForm, "form.slug", "?=", "!=", "q_art_form"},
+		{t.ArtType, "type.slug", "?=", "!=", "q_art_type"},
+		{t.Artist, artistNameField, "?~", "!~", "q_artist"},
+	} {
+		if !c.facet.Active() {
+			continue
+		}
+
+		facetFilter, facetParams := c.facet.buildFilter(c.field, c.op, c.notOp, c.prefix)
+		conditions = append(conditions, facetFilter)
+		maps.Copy(params, facetParams)
+	}
+
+	return strings.Join(conditions, " && "), params
+}
+
+func slugifyAll(values []string) []string {
+	slugs := make([]string, 0, len(values))
+	for _, v := range values {
+		slugs = append(slugs, utils.Slugify(v))
+	}
+
+	return slugs
+}
@@ -0,0 +1,168 @@
+package artworks
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	terms, err := parseQuery(`artist:botticelli school:Italian type:religious "birth of venus" -fresco by:"Fra Angelico" form:painting,Fresco period:early-renaissance`)
+	if err != nil {
+		t.Fatalf("parseQuery: %v", err)
+	}
+
+	want := &queryTerms{
+		Title:   facet{Include: []string{"birth of venus"}, Exclude: []string{"fresco"}},
+		School:  facet{Include: []string{"italian"}},
+		ArtForm: facet{Include: []string{"painting", "fresco"}},
+		ArtType: facet{Include: []string{"religious"}},
+		Artist:  facet{Include: []string{"botticelli", "Fra Angelico"}},
+		Period:  "early-renaissance",
+	}
+
+	if !reflect.DeepEqual(terms, want) {
+		t.Fatalf("parseQuery = %+v, want %+v", terms, want)
+	}
+}
+
+func TestParseQueryLeavesUnknownFieldsToTheTitle(t *testing.T) {
+	for input, want := range map[string][]string{
+		`Madonna: child`: {"Madonna:", "child"},
+		`colour:blue`:    {"colour:blue"},
+	} {
+		terms, err := parseQuery(input)
+		if err != nil {
+			t.Errorf("%q: %v", input, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(terms, &queryTerms{Title: facet{Include: want}}) {
+			t.Errorf("%q parsed to %+v, want the title words %q", input, terms, want)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for input, want := range map[string]string{
+		`"birth of venus`:           `The phrase "birth of venus is missing its closing quote.`,
+		`venus artist:`:             `"artist:" is missing a value.`,
+		`venus -`:                   `A "-" must be followed by the term to exclude, like -fresco.`,
+		`-period:baroque`:           `A period cannot be excluded, pick the period to search in instead.`,
+		`period:baroque era:rococo`: `Only one period can be searched at a time.`,
+	} {
+		_, err := parseQuery(input)
+
+		queryErr, ok := err.(*QueryError)
+		if !ok {
+			t.Errorf("%q: expected a query error, got %v", input, err)
+			continue
+		}
+
+		if queryErr.Message != want {
+			t.Errorf("%q: message %q, want %q", input, queryErr.Message, want)
+		}
+	}
+}
+
+func TestQueryTermsAreBounded(t *testing.T) {
+	terms := func(format string, n int) string {
+		parts := make([]string, 0, n)
+		for i := range n {
+			parts = append(parts, fmt.Sprintf(format, i))
+		}
+
+		return strings.Join(parts, " ")
+	}
+
+	for input, want := range map[string]string{
+		terms("-artist:master%d", 70): "Too many artist terms, search with at most 10.",
+		terms("word%d", 11):           "Too many title terms, search with at most 10.",
+		terms("word%d", 10) + " " + terms("school:school%d", 10) + " by:giotto": "The query has too many terms, search with at most 20.",
+	} {
+		_, err := parseQuery(input)
+
+		queryErr, ok := err.(*QueryError)
+		if !ok || queryErr.Message != want {
+			t.Errorf("%q: expected the query error %q, got %v", input, want, err)
+		}
+	}
+
+	// the most terms of the most expensive facets stay within the filter expression limit
+	app := newFacetsTestApp(t)
+
+	q := url.Values{"q": {terms("-artist:master%d", maxQueryFacetValues) + " " + terms("-word%d", maxQueryFacetValues)}}
+	f := filtersFromQuery(q)
+
+	if err := resolveQuery(f); err != nil {
+		t.Fatalf("resolveQuery: %v", err)
+	}
+
+	filter := f.BuildFilter()
+
+	if _, err := app.FindRecordsByFilter("artworks", filter.Filter, "+id", 0, 0, filter.Params); err != nil {
+		t.Fatalf("find artworks: %v", err)
+	}
+}
+
+func TestQueryNarrowsTheSearch(t *testing.T) {
+	app := newFacetsTestApp(t)
+
+	cases := map[string][]string{
+		`q=venus`:      {"artwork00000001"},
+		`q=-venus`:     {"artwork00000002", "artwork00000003"},
+		`q="birth of"`: {"artwork00000001"},
+		`q=by:botti school:italian -form:sculpture`: {"artwork00000001"},
+		`q=school:italian,flemish -author:rubens`:   {"artwork00000001", "artwork00000002"},
+		`q=by:filipepi`: {"artwork00000001", "artwork00000002"},
+		// the query is combined with the filters of the form
+		`q=school:italian&art_form=sculpture`: {"artwork00000002"},
+	}
+
+	for query, want := range cases {
+		q, _ := url.ParseQuery(query)
+		f := filtersFromQuery(q)
+
+		if err := resolveQuery(f); err != nil {
+			t.Fatalf("%q: %v", query, err)
+		}
+
+		filter := f.BuildFilter()
+
+		records, err := app.FindRecordsByFilter("artworks", filter.Filter, "+id", 0, 0, filter.Params)
+		if err != nil {
+			t.Fatalf("%q: %v", query, err)
+		}
+
+		got := []string{}
+		for _, r := range records {
+			got = append(got, r.Id)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q matched %v, want %v", query, got, want)
+		}
+	}
+}
+
+func TestQueryPeriodMustAgreeWithThePeriodFilter(t *testing.T) {
+	q, _ := url.ParseQuery("q=period:baroque&period=rococo")
+	f := filtersFromQuery(q)
+
+	if _, ok := resolveQuery(f).(*QueryError); !ok {
+		t.Fatalf("expected a query error for conflicting periods")
+	}
+
+	q, _ = url.ParseQuery("q=period:baroque")
+	f = filtersFromQuery(q)
+
+	if err := resolveQuery(f); err != nil {
+		t.Fatalf("resolveQuery: %v", err)
+	}
+
+	if f.periodSlug() != "baroque" {
+		t.Fatalf("periodSlug = %q, want the period of the query", f.periodSlug())
+	}
+}
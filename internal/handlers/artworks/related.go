@@ -0,0 +1,92 @@
+package artworks
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/blackfyre/wga/internal/assets/templ/dto"
+	"github.com/blackfyre/wga/internal/assets/templ/pages"
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// relatedArtworksLimit is the number of artworks of the "more like this" panel.
+const relatedArtworksLimit = 8
+
+const relatedArtworksTTL = time.Hour
+
+const relatedArtworksCacheKeyPrefix = "artworks:related:"
+
+// RelatedArtworks returns the artworks of the "more like this" panel of a published artwork,
+// ready to render, see ArtworksRepository.FindRelated.
+func RelatedArtworks(app *pocketbase.PocketBase, artworkId string) ([]dto.Image, error) {
+	return utils.GetOrLoadCachedValue(app, relatedArtworksCacheKeyPrefix+artworkId, relatedArtworksTTL, func() ([]dto.Image, error) {
+		artwork, err := app.FindRecordById(constants.CollectionArtworks, artworkId)
+		if errors.Is(err, sql.ErrNoRows) {
+			return []dto.Image{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !artwork.GetBool("published") {
+			return []dto.Image{}, nil
+		}
+
+		repo := repositories.NewArtworksRepository(app)
+
+		records, err := repo.FindRelated(artwork, relatedArtworksLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := repo.PrefetchRelations(records); err != nil {
+			return nil, err
+		}
+
+		credits, err := repo.FindAuthors(records)
+		if err != nil {
+			return nil, err
+		}
+
+		images := make([]dto.Image, 0, len(records))
+
+		for _, r := range records {
+			authors := credits[r.Id]
+			if len(authors) == 0 {
+				continue
+			}
+
+			images = append(images, NewArtworkImage(r, authors))
+		}
+
+		return images, nil
+	})
+}
+
+// processRelated renders the "more like this" fragment loaded by the artwork pages.
+// An empty fragment is returned when there is nothing related, so the page is left untouched.
+func processRelated(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	images, err := RelatedArtworks(app, c.Request.PathValue("id"))
+	if err != nil {
+		app.Logger().Error("Error finding related artworks", "id", c.Request.PathValue("id"), "error", err.Error())
+		images = []dto.Image{}
+	}
+
+	var buff bytes.Buffer
+
+	err = pages.RelatedArtworksBlock(images).Render(context.Background(), &buff)
+	if err != nil {
+		app.Logger().Error("Error rendering related artworks", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	return c.HTML(http.StatusOK, buff.String())
+}
@@ -0,0 +1,74 @@
+package artworks
+
+import (
+	"github.com/blackfyre/wga/internal/assets/templ/dto"
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/blackfyre/wga/internal/utils/url"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// NewSeriesParts converts the parts of a series with their credited authors for the series
+// navigation and overview, marking the part whose id is currentId. The parts without authors
+// are left out.
+func NewSeriesParts(parts []*core.Record, credits map[string][]repositories.ArtworkAuthor, currentId string) []dto.SeriesPart {
+	converted := make([]dto.SeriesPart, 0, len(parts))
+
+	for _, part := range parts {
+		authors := credits[part.Id]
+		if len(authors) == 0 {
+			continue
+		}
+
+		image := NewArtworkImage(part, authors)
+
+		converted = append(converted, dto.SeriesPart{
+			Id:      part.Id,
+			Title:   image.Title,
+			Label:   part.GetString("series_part"),
+			Credit:  image.Credit(),
+			Url:     image.Url,
+			Thumb:   image.Thumb,
+			Current: part.Id == currentId,
+		})
+	}
+
+	return converted
+}
+
+// collapsedSeries returns the series the artworks stand for in collapsed search results, by the
+// id of the artwork. Collapsed results hold one part of every series, its cover or its first
+// matching part.
+func collapsedSeries(app core.App, artworks []*core.Record) (map[string]*dto.ImageSeries, error) {
+	if err := repositories.NewArtworksRepository(app).PrefetchSeries(artworks); err != nil {
+		return nil, err
+	}
+
+	parts := map[string]*core.Record{}
+	seriesIds := []string{}
+
+	for _, artwork := range artworks {
+		series := artwork.ExpandedOne("series")
+		if series == nil {
+			continue
+		}
+
+		parts[artwork.Id] = series
+		seriesIds = append(seriesIds, series.Id)
+	}
+
+	counts, err := repositories.NewSeriesRepository(app).CountParts(seriesIds)
+	if err != nil {
+		return nil, err
+	}
+
+	collapsed := make(map[string]*dto.ImageSeries, len(parts))
+	for artworkId, series := range parts {
+		collapsed[artworkId] = &dto.ImageSeries{
+			Title: series.GetString("title"),
+			Url:   url.GenerateSeriesUrl(series.GetString("title"), series.Id),
+			Parts: counts[series.Id],
+		}
+	}
+
+	return collapsed, nil
+}
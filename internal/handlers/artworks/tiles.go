@@ -0,0 +1,58 @@
+package artworks
+
+import (
+	"strconv"
+
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/utils/dzi"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// processTiles serves the descriptor or a tile of the pyramid of a published artwork.
+// The urls do not change with the image, so responses are only cached for a day.
+func processTiles(app *pocketbase.PocketBase, c *core.RequestEvent, path string, contentType string) error {
+	artwork, err := app.FindRecordById(constants.CollectionArtworks, c.Request.PathValue("id"))
+	if err != nil || !artwork.GetBool("published") || !dzi.UpToDate(artwork) {
+		return apis.NewNotFoundError("", nil)
+	}
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		app.Logger().Error("Error opening filesystem for tiles", "error", err.Error())
+		return apis.NewInternalServerError("", nil)
+	}
+	defer fsys.Close()
+
+	c.Response.Header().Set("Content-Type", contentType)
+	c.Response.Header().Set("Cache-Control", "public, max-age=86400")
+
+	if err := fsys.Serve(c.Response, c.Request, dzi.Dir(artwork)+"/"+path, artwork.Id+"-"+path); err != nil {
+		c.Response.Header().Del("Content-Type")
+		c.Response.Header().Del("Cache-Control")
+		return apis.NewNotFoundError("", nil)
+	}
+
+	return nil
+}
+
+func processTile(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	level, err := strconv.Atoi(c.Request.PathValue("level"))
+	column, row, ok := dzi.ParseTileName(c.Request.PathValue("tile"))
+	if err != nil || level < 0 || !ok {
+		return apis.NewNotFoundError("", nil)
+	}
+
+	return processTiles(app, c, dzi.TilePath(level, column, row), "image/jpeg")
+}
+
+func registerTilesHandlers(app *pocketbase.PocketBase, se *core.ServeEvent) {
+	se.Router.GET("/artworks/{id}/tiles/"+dzi.DescriptorName, func(c *core.RequestEvent) error {
+		return processTiles(app, c, dzi.DescriptorName, "application/xml")
+	})
+
+	se.Router.GET("/artworks/{id}/tiles/"+dzi.TilesDirName+"/{level}/{tile}", func(c *core.RequestEvent) error {
+		return processTile(app, c)
+	})
+}
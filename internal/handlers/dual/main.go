@@ -17,7 +17,11 @@ import (
 	"github.com/blackfyre/wga/internal/constants"
 	"github.com/blackfyre/wga/internal/errs"
 	"github.com/blackfyre/wga/internal/handlers/artists"
+	"github.com/blackfyre/wga/internal/handlers/artworks"
+	"github.com/blackfyre/wga/internal/handlers/series"
+	"github.com/blackfyre/wga/internal/repositories"
 	"github.com/blackfyre/wga/internal/utils"
+	"github.com/blackfyre/wga/internal/utils/fulltext"
 	"github.com/blackfyre/wga/internal/utils/url"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase"
@@ -39,6 +43,7 @@ type panePathDto struct {
 
 const (
 	dualLookupPath              = "/dual-mode/lookup"
+	dualRelatedPath             = "/dual-mode/related"
 	dualLookupLimit             = 20
 	dualLookupMinimumQueryRunes = 2
 )
@@ -150,7 +155,7 @@ func resolveDualLookupKind(kind string) string {
 func getArtistLookupResults(app core.App, query string) ([]dto.DualLookupResultDto, error) {
 	records, err := app.FindRecordsByFilter(
 		constants.CollectionArtists,
-		"published = true && name ~ {:query}",
+		"published = true && "+repositories.ArtistNameFilter("", "~", "query"),
 		"+name,+id",
 		dualLookupLimit,
 		0,
@@ -161,6 +166,16 @@ func getArtistLookupResults(app core.App, query string) ([]dto.DualLookupResultD
 		return nil, err
 	}
 
+	ids := make([]string, 0, len(records))
+	for _, record := range records {
+		ids = append(ids, record.Id)
+	}
+
+	otherNames, err := repositories.NewArtistsRepository(app).FindNames(ids)
+	if err != nil {
+		return nil, err
+	}
+
 	results := make([]dto.DualLookupResultDto, 0, len(records))
 
 	for _, record := range records {
@@ -169,13 +184,31 @@ func getArtistLookupResults(app core.App, query string) ([]dto.DualLookupResultD
 				ArtistId:   record.Id,
 				ArtistName: record.GetString("name"),
 			}),
-			Label: record.GetString("name"),
+			Label:   record.GetString("name"),
+			Context: matchedOtherName(record.GetString("name"), otherNames[record.Id], query),
 		})
 	}
 
 	return results, nil
 }
 
+// matchedOtherName labels an artist found by another name with that name,
+// so the result does not look unrelated to the query.
+func matchedOtherName(name string, otherNames []repositories.ArtistName, query string) string {
+	folded := fulltext.Fold(query)
+	if strings.Contains(fulltext.Fold(name), folded) {
+		return ""
+	}
+
+	for _, other := range otherNames {
+		if strings.Contains(fulltext.Fold(other.Name), folded) {
+			return "a.k.a. " + other.Name
+		}
+	}
+
+	return ""
+}
+
 func getArtworkLookupResults(app core.App, query string) ([]dto.DualLookupResultDto, error) {
 	records, err := app.FindRecordsByFilter(
 		constants.CollectionArtworks,
@@ -447,6 +480,12 @@ func parsePanePath(raw string) (panePathDto, error) {
 			Id:      utils.ExtractIdFromString(parts[1]),
 			RelPath: normalized,
 		}, nil
+	case len(parts) == 2 && parts[0] == "series":
+		return panePathDto{
+			Kind:    "series",
+			Id:      utils.ExtractIdFromString(parts[1]),
+			RelPath: normalized,
+		}, nil
 	default:
 		return panePathDto{}, errs.ErrUnknownDualPane
 	}
@@ -508,6 +547,17 @@ func renderPane(side string, app *pocketbase.PocketBase, c *core.RequestEvent) (
 		}
 
 		pane.RelPath = resolvePaneRelPath(parsedPath.RelPath, artworkDto.Url)
+	case "series":
+		seriesDto, renderErr := renderSeriesPane(app, c, side, parsedPath.RelPath, parsedPath.Id, buf)
+		if renderErr != nil {
+			if errors.Is(renderErr, sql.ErrNoRows) {
+				return renderDefaultPane(side, renderTo)
+			}
+
+			return pane, renderErr
+		}
+
+		pane.RelPath = resolvePaneRelPath(parsedPath.RelPath, seriesDto.Url)
 	default:
 		return pane, errs.ErrUnsupportedPaneType
 	}
@@ -615,6 +665,10 @@ func renderArtworkPane(app *pocketbase.PocketBase, c *core.RequestEvent, side st
 		if artworkDto.Artist.Url != "" {
 			artworkDto.Artist.Url = buildDualModePaneURL(side, currentRelPath, artworkDto.Artist.Url, currentQueryValues)
 		}
+		if artworkDto.Series != nil {
+			dualSeriesUrls(artworkDto.Series, side, currentRelPath, currentQueryValues)
+		}
+		artworkDto.RelatedUrl = buildDualRelatedURL(side, currentQueryValues)
 	}
 
 	err = pages.ArtworkBlock(artworkDto).Render(context.Background(), buf)
@@ -627,11 +681,113 @@ func renderArtworkPane(app *pocketbase.PocketBase, c *core.RequestEvent, side st
 	return artworkDto, nil
 }
 
+// dualSeriesUrls points the series navigation of the artwork shown in a pane to the dual mode,
+// opening in the pane the pane links open in.
+func dualSeriesUrls(s *dto.ArtworkSeries, side string, currentRelPath string, queryValues map[string][]string) {
+	s.Url = buildDualModePaneURL(side, currentRelPath, s.Url, queryValues)
+
+	for idx := range s.Parts {
+		s.Parts[idx].Url = buildDualModePaneURL(side, currentRelPath, s.Parts[idx].Url, queryValues)
+	}
+}
+
+func renderSeriesPane(app *pocketbase.PocketBase, c *core.RequestEvent, side string, currentRelPath string, seriesId string, buf *bytes.Buffer) (pages.SeriesPageDTO, error) {
+	seriesModel, err := app.FindRecordById(constants.CollectionSeries, seriesId)
+
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			app.Logger().Error("Error finding series", "error", err.Error())
+		}
+
+		return pages.SeriesPageDTO{}, err
+	}
+
+	seriesDto, err := series.RenderSeriesContent(app, seriesModel, "#dual-area", false)
+
+	if err != nil {
+		app.Logger().Error("Error rendering series content", "error", err.Error())
+		return pages.SeriesPageDTO{}, err
+	}
+
+	if c.Request != nil && c.Request.URL != nil && c.Request.URL.Path == "/dual-mode" {
+		currentQueryValues := c.Request.URL.Query()
+		for idx := range seriesDto.Parts {
+			seriesDto.Parts[idx].Url = buildDualModePaneURL(side, currentRelPath, seriesDto.Parts[idx].Url, currentQueryValues)
+		}
+	}
+
+	err = pages.SeriesBlock(seriesDto).Render(context.Background(), buf)
+
+	if err != nil {
+		app.Logger().Error("Error rendering series page", "error", err.Error())
+		return seriesDto, err
+	}
+
+	return seriesDto, nil
+}
+
+// buildDualRelatedURL returns the url of the "more like this" fragment of the artwork
+// shown in a pane, whose links open in the pane the pane links open in.
+func buildDualRelatedURL(side string, queryValues map[string][]string) string {
+	relatedURL := url.GenerateDualModeUrl()
+	relatedURL.Path = dualRelatedPath
+	relatedQueryValues := relatedURL.Query()
+
+	for _, key := range []string{"left", "right", "left_render_to", "right_render_to"} {
+		if value := firstQueryValue(queryValues, key); value != "" {
+			relatedQueryValues.Set(key, value)
+		}
+	}
+	relatedQueryValues.Set("side", side)
+	relatedURL.RawQuery = relatedQueryValues.Encode()
+
+	return relatedURL.String()
+}
+
+// renderDualRelated renders the "more like this" fragment of the artwork shown in a pane,
+// with links that keep the other pane.
+func renderDualRelated(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	queryValues := c.Request.URL.Query()
+	side := queryValues.Get("side")
+	if side != "left" && side != "right" {
+		return utils.BadRequestError(c)
+	}
+
+	parsedPath, err := parsePanePath(queryValues.Get(side))
+	if err != nil || parsedPath.Kind != "artwork" {
+		return utils.BadRequestError(c)
+	}
+
+	related, err := artworks.RelatedArtworks(app, parsedPath.Id)
+	if err != nil {
+		app.Logger().Error("Error finding related artworks", "id", parsedPath.Id, "error", err.Error())
+		related = []dto.Image{}
+	}
+
+	images := make([]dto.Image, 0, len(related))
+	for _, image := range related {
+		image.Url = buildDualModePaneURL(side, parsedPath.RelPath, image.Url, queryValues)
+		images = append(images, image)
+	}
+
+	var buff bytes.Buffer
+
+	if err := pages.RelatedArtworksBlock(images).Render(context.Background(), &buff); err != nil {
+		app.Logger().Error("Error rendering related artworks", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	return c.HTML(http.StatusOK, buff.String())
+}
+
 func RegisterHandlers(app *pocketbase.PocketBase) {
 	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
 		se.Router.GET("/dual-mode", func(c *core.RequestEvent) error {
 			return renderDualModePage(app, c)
 		})
+		se.Router.GET(dualRelatedPath, func(c *core.RequestEvent) error {
+			return renderDualRelated(app, c)
+		})
 		se.Router.GET(dualLookupPath, func(c *core.RequestEvent) error {
 			return renderDualLookupResults(app, c)
 		})
@@ -56,6 +56,41 @@ func TestGetArtistLookupResultsIsBounded(t *testing.T) {
 	}
 }
 
+func TestGetArtistLookupResultsMatchesOtherNames(t *testing.T) {
+	app := newDualLookupTestApp(t)
+	artist := saveLookupArtist(t, app, "Jacopo Robusti", true)
+
+	collection, err := app.FindCollectionByNameOrId(constants.CollectionArtistNames)
+	if err != nil {
+		t.Fatalf("failed to find artist names collection: %v", err)
+	}
+
+	alias := core.NewRecord(collection)
+	alias.Set("artist", artist.Id)
+	alias.Set("name", "Tintoretto")
+	if err := app.Save(alias); err != nil {
+		t.Fatalf("failed to save artist name: %v", err)
+	}
+
+	content, err := getDualLookupResults(app, "artist", "tintor")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(content.Results) != 1 || content.Results[0].Label != "Jacopo Robusti" || content.Results[0].Context != "a.k.a. Tintoretto" {
+		t.Fatalf("unexpected alias lookup results: %#v", content.Results)
+	}
+
+	content, err = getDualLookupResults(app, "artist", "robusti")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(content.Results) != 1 || content.Results[0].Context != "" {
+		t.Fatalf("expected no alias context when the name matches, got %#v", content.Results)
+	}
+}
+
 func TestGetArtworkLookupResultsIsBounded(t *testing.T) {
 	app := newDualLookupTestApp(t)
 	artist := saveLookupArtist(t, app, "Lookup Artist", true)
@@ -173,6 +208,19 @@ func newDualLookupTestApp(t *testing.T) *tests.TestApp {
 		t.Fatalf("failed to create artists collection: %v", err)
 	}
 
+	artistNames := core.NewBaseCollection(constants.CollectionArtistNames)
+	artistNames.Fields.Add(
+		&core.RelationField{Name: "artist", CollectionId: artists.Id, MaxSelect: 1, CascadeDelete: true},
+		&core.TextField{Name: "name"},
+		&core.TextField{Name: "lang"},
+		&core.TextField{Name: "kind"},
+		&core.TextField{Name: "slug"},
+		&core.AutodateField{Name: "created", OnCreate: true},
+	)
+	if err := app.Save(artistNames); err != nil {
+		t.Fatalf("failed to create artist names collection: %v", err)
+	}
+
 	artworks := core.NewBaseCollection(constants.CollectionArtworks)
 	artworks.Fields.Add(
 		&core.TextField{Name: "title"},
@@ -305,6 +353,11 @@ func TestParsePanePath(t *testing.T) {
 			input: "/artworks/artwork-777",
 			want:  panePathDto{Kind: "artwork", Id: "777", RelPath: "/artworks/artwork-777"},
 		},
+		{
+			name:  "series path",
+			input: "/series/legend-of-st-francis-555/",
+			want:  panePathDto{Kind: "series", Id: "555", RelPath: "/series/legend-of-st-francis-555"},
+		},
 		{
 			name:    "unsupported path",
 			input:   "/pages/privacy-policy",
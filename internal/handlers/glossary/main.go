@@ -0,0 +1,315 @@
+package glossary
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/blackfyre/wga/internal/assets/templ/pages"
+	tmplUtils "github.com/blackfyre/wga/internal/assets/templ/utils"
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/blackfyre/wga/internal/utils/fulltext"
+	glossaryUtils "github.com/blackfyre/wga/internal/utils/glossary"
+	wgaUrl "github.com/blackfyre/wga/internal/utils/url"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// mentionLimit bounds the number of artworks and artists listed on a term page.
+const mentionLimit = 50
+
+// otherLetter groups the terms that don't start with a latin letter.
+const otherLetter = "#"
+
+func RegisterHandlers(app *pocketbase.PocketBase) {
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		se.Router.GET("/glossary", func(c *core.RequestEvent) error {
+			return processGlossary(app, c)
+		})
+
+		se.Router.GET("/glossary/{term}", func(c *core.RequestEvent) error {
+			return processTerm(app, c)
+		})
+
+		return se.Next()
+	})
+}
+
+func processGlossary(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	entries, err := glossaryUtils.GetGlossaryEntries(app)
+	if err != nil {
+		app.Logger().Error("Error getting glossary entries", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	entries = sortedEntries(entries)
+	query := strings.TrimSpace(c.Request.URL.Query().Get("q"))
+	letter := strings.ToUpper(c.Request.URL.Query().Get("letter"))
+
+	available := map[string]bool{}
+	for _, e := range entries {
+		available[entryLetter(e)] = true
+	}
+
+	if !available[letter] && len(entries) > 0 {
+		letter = entryLetter(entries[0])
+	}
+
+	content := pages.GlossaryPageDTO{
+		Query: query,
+	}
+
+	if query != "" {
+		content.Heading = fmt.Sprintf("Terms matching “%s”", query)
+		content.Entries = listItems(searchEntries(app, entries, query))
+	} else {
+		content.Heading = letter
+		content.Entries = listItems(slices.DeleteFunc(slices.Clone(entries), func(e glossaryUtils.GlossaryEntry) bool {
+			return entryLetter(e) != letter
+		}))
+	}
+
+	for _, l := range append(strings.Split("ABCDEFGHIJKLMNOPQRSTUVWXYZ", ""), otherLetter) {
+		content.Letters = append(content.Letters, pages.GlossaryLetter{
+			Letter:    l,
+			Url:       "/glossary?" + url.Values{"letter": {l}}.Encode(),
+			Active:    query == "" && l == letter,
+			Available: available[l],
+		})
+	}
+
+	ctx := tmplUtils.DecorateContext(context.Background(), tmplUtils.TitleKey, "Glossary")
+	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.DescriptionKey, "Explanations of the art historical terms used in the gallery.")
+	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.CanonicalUrlKey, tmplUtils.AssetUrl("/glossary"))
+
+	c.Response.Header().Set("HX-Push-Url", utils.GenerateCurrentRelativePageUrl(c))
+
+	var buff bytes.Buffer
+
+	err = pages.GlossaryPage(content).Render(ctx, &buff)
+	if err != nil {
+		app.Logger().Error("Error rendering glossary page", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	return c.HTML(http.StatusOK, buff.String())
+}
+
+func processTerm(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	slug := c.Request.PathValue("term")
+
+	entries, err := glossaryUtils.GetGlossaryEntries(app)
+	if err != nil {
+		app.Logger().Error("Error getting glossary entries", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	id := utils.ExtractIdFromString(slug)
+	index := slices.IndexFunc(entries, func(e glossaryUtils.GlossaryEntry) bool {
+		return e.Id == id
+	})
+	if index < 0 {
+		return utils.NotFoundError(c)
+	}
+	entry := entries[index]
+
+	if "/glossary/"+slug != entry.Url {
+		return c.Redirect(http.StatusMovedPermanently, entry.Url)
+	}
+
+	mentions, err := glossaryUtils.FindMentions(app, entry.MatchTerm, mentionLimit)
+	if err != nil {
+		app.Logger().Error("Error finding glossary term mentions", "term", entry.MatchTerm, "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	content := pages.GlossaryTermPageDTO{
+		Term:       entry.MatchTerm,
+		Expression: entry.Expression,
+		Definition: entry.Definition,
+		Url:        entry.Url,
+	}
+
+	content.Artworks, err = artworkMentions(app, mentions.ArtworkIds)
+	if err != nil {
+		app.Logger().Error("Error loading artworks mentioning glossary term", "term", entry.MatchTerm, "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	content.Artists, err = artistMentions(app, mentions.ArtistIds)
+	if err != nil {
+		app.Logger().Error("Error loading artists mentioning glossary term", "term", entry.MatchTerm, "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	ctx := tmplUtils.DecorateContext(context.Background(), tmplUtils.TitleKey, entry.MatchTerm+" - Glossary")
+	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.DescriptionKey, utils.StrippedHTML(entry.Definition))
+	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.CanonicalUrlKey, tmplUtils.AssetUrl(entry.Url))
+
+	c.Response.Header().Set("HX-Push-Url", entry.Url)
+
+	var buff bytes.Buffer
+
+	err = pages.GlossaryTermPage(content).Render(ctx, &buff)
+	if err != nil {
+		app.Logger().Error("Error rendering glossary term page", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	return c.HTML(http.StatusOK, buff.String())
+}
+
+// searchEntries returns the entries matching the query, ranked by the full-text
+// index when it is available and in alphabetical order otherwise.
+func searchEntries(app *pocketbase.PocketBase, entries []glossaryUtils.GlossaryEntry, query string) []glossaryUtils.GlossaryEntry {
+	if fulltext.IndexAvailable(app) {
+		ids, err := fulltext.MatchIds(app, fulltext.KindGlossary, query, len(entries))
+		if err == nil {
+			byId := make(map[string]glossaryUtils.GlossaryEntry, len(entries))
+			for _, e := range entries {
+				byId[e.Id] = e
+			}
+
+			matches := []glossaryUtils.GlossaryEntry{}
+			for _, id := range ids {
+				if e, ok := byId[id]; ok {
+					matches = append(matches, e)
+				}
+			}
+
+			return matches
+		}
+
+		app.Logger().Warn("Full-text glossary search failed, falling back to substring match", "error", err.Error())
+	}
+
+	folded := fulltext.Fold(query)
+
+	return slices.DeleteFunc(slices.Clone(entries), func(e glossaryUtils.GlossaryEntry) bool {
+		return !strings.Contains(fulltext.Fold(e.Expression), folded)
+	})
+}
+
+func artworkMentions(app *pocketbase.PocketBase, ids []string) ([]pages.GlossaryMention, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	artworks, err := app.FindRecordsByIds(constants.CollectionArtworks, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	authorIds := []string{}
+	for _, aw := range artworks {
+		if authors := aw.GetStringSlice("author"); len(authors) > 0 {
+			authorIds = append(authorIds, authors[0])
+		}
+	}
+
+	authors, err := app.FindRecordsByIds(constants.CollectionArtists, authorIds)
+	if err != nil {
+		return nil, err
+	}
+
+	authorsById := make(map[string]*core.Record, len(authors))
+	for _, a := range authors {
+		authorsById[a.Id] = a
+	}
+
+	mentions := []pages.GlossaryMention{}
+	for _, aw := range artworks {
+		authorIds := aw.GetStringSlice("author")
+		if len(authorIds) == 0 {
+			continue
+		}
+
+		author, ok := authorsById[authorIds[0]]
+		if !ok {
+			continue
+		}
+
+		mentions = append(mentions, pages.GlossaryMention{
+			Title:    aw.GetString("title"),
+			Subtitle: author.GetString("name"),
+			Url: wgaUrl.GenerateFullArtworkUrl(wgaUrl.ArtworkUrlDTO{
+				ArtistName:   author.GetString("name"),
+				ArtistId:     author.Id,
+				ArtworkTitle: aw.GetString("title"),
+				ArtworkId:    aw.Id,
+			}),
+		})
+	}
+
+	slices.SortFunc(mentions, func(a, b pages.GlossaryMention) int {
+		return strings.Compare(a.Title, b.Title)
+	})
+
+	return mentions, nil
+}
+
+func artistMentions(app *pocketbase.PocketBase, ids []string) ([]pages.GlossaryMention, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	artists, err := app.FindRecordsByIds(constants.CollectionArtists, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	mentions := make([]pages.GlossaryMention, 0, len(artists))
+	for _, a := range artists {
+		mentions = append(mentions, pages.GlossaryMention{
+			Title:    a.GetString("name"),
+			Subtitle: a.GetString("profession"),
+			Url:      wgaUrl.GenerateArtistUrlFromRecord(a),
+		})
+	}
+
+	slices.SortFunc(mentions, func(a, b pages.GlossaryMention) int {
+		return strings.Compare(a.Title, b.Title)
+	})
+
+	return mentions, nil
+}
+
+func listItems(entries []glossaryUtils.GlossaryEntry) []pages.GlossaryListItem {
+	items := make([]pages.GlossaryListItem, len(entries))
+	for i, e := range entries {
+		items[i] = pages.GlossaryListItem{
+			Term:       e.Expression,
+			Url:        e.Url,
+			Definition: e.Definition,
+		}
+	}
+
+	return items
+}
+
+// sortedEntries returns the entries in alphabetical order.
+// GetGlossaryEntries keeps them longest-first for matching.
+func sortedEntries(entries []glossaryUtils.GlossaryEntry) []glossaryUtils.GlossaryEntry {
+	sorted := slices.Clone(entries)
+	slices.SortStableFunc(sorted, func(a, b glossaryUtils.GlossaryEntry) int {
+		return strings.Compare(fulltext.Fold(a.MatchTerm), fulltext.Fold(b.MatchTerm))
+	})
+
+	return sorted
+}
+
+// entryLetter returns the upper-case initial of the term with diacritics removed,
+// or otherLetter for terms that don't start with a latin letter.
+func entryLetter(e glossaryUtils.GlossaryEntry) string {
+	folded := fulltext.Fold(e.MatchTerm)
+	if folded == "" || folded[0] < 'a' || folded[0] > 'z' {
+		return otherLetter
+	}
+
+	return strings.ToUpper(folded[:1])
+}
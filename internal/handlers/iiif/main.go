@@ -0,0 +1,248 @@
+package iiif
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"image"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/blackfyre/wga/internal/utils/iiif"
+	"github.com/disintegration/imaging"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	imageInfoContentType    = `application/ld+json;profile="` + iiif.ImageContext + `"`
+	presentationContentType = `application/ld+json;profile="` + iiif.PresentationContext + `"`
+)
+
+// imageSizeTTL is how long the dimensions of an artwork image are cached.
+// The key includes the file name, so a replaced image is measured again.
+const imageSizeTTL = 24 * time.Hour
+
+func RegisterHandlers(app *pocketbase.PocketBase) {
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		g := se.Router.Group("/iiif")
+
+		g.GET("/image/{id}", func(c *core.RequestEvent) error {
+			return c.Redirect(http.StatusSeeOther, serviceUrl(c.Request.PathValue("id"))+"/info.json")
+		})
+
+		g.GET("/image/{id}/info.json", func(c *core.RequestEvent) error {
+			return processImageInfo(app, c)
+		})
+
+		g.GET("/image/{id}/{region}/{size}/{rotation}/{file}", func(c *core.RequestEvent) error {
+			return processImage(app, c)
+		})
+
+		g.GET("/artworks/{id}/manifest.json", func(c *core.RequestEvent) error {
+			return processManifest(app, c)
+		})
+
+		g.GET("/artists/{id}/collection.json", func(c *core.RequestEvent) error {
+			return processCollection(app, c)
+		})
+
+		return se.Next()
+	})
+}
+
+func processImageInfo(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	artwork, err := findArtwork(app, c.Request.PathValue("id"))
+	if err != nil {
+		return apis.NewNotFoundError("", nil)
+	}
+
+	size, err := imageSize(app, artwork)
+	if err != nil {
+		app.Logger().Error("Error reading IIIF image size", "id", artwork.Id, "error", err.Error())
+		return apis.NewInternalServerError("", nil)
+	}
+
+	return writeJSON(c, imageInfoContentType, iiif.NewImageInfo(serviceUrl(artwork.Id), size.Width, size.Height))
+}
+
+func processImage(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	artwork, err := findArtwork(app, c.Request.PathValue("id"))
+	if err != nil {
+		return apis.NewNotFoundError("", nil)
+	}
+
+	size, err := imageSize(app, artwork)
+	if err != nil {
+		app.Logger().Error("Error reading IIIF image size", "id", artwork.Id, "error", err.Error())
+		return apis.NewInternalServerError("", nil)
+	}
+
+	req, err := iiif.ParseImageRequest(
+		c.Request.PathValue("region"),
+		c.Request.PathValue("size"),
+		c.Request.PathValue("rotation"),
+		c.Request.PathValue("file"),
+		size.Width,
+		size.Height,
+	)
+	if errors.Is(err, iiif.ErrUnsupported) {
+		return apis.NewApiError(http.StatusNotImplemented, err.Error(), nil)
+	}
+	if err != nil {
+		return apis.NewBadRequestError(err.Error(), nil)
+	}
+
+	select {
+	case imageWorkers <- struct{}{}:
+		defer func() { <-imageWorkers }()
+	case <-c.Request.Context().Done():
+		return c.Request.Context().Err()
+	}
+
+	src, err := sourceImage(app, artwork)
+	if err != nil {
+		app.Logger().Error("Error decoding IIIF source image", "id", artwork.Id, "error", err.Error())
+		return apis.NewInternalServerError("", nil)
+	}
+
+	var buff bytes.Buffer
+
+	if err := req.Encode(&buff, req.Apply(src)); err != nil {
+		app.Logger().Error("Error encoding IIIF image", "id", artwork.Id, "error", err.Error())
+		return apis.NewInternalServerError("", nil)
+	}
+
+	c.Response.Header().Set("Access-Control-Allow-Origin", "*")
+	c.Response.Header().Set("Cache-Control", "public, max-age=86400")
+
+	return c.Blob(http.StatusOK, req.ContentType(), buff.Bytes())
+}
+
+func processManifest(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	artwork, err := findArtwork(app, c.Request.PathValue("id"))
+	if err != nil {
+		return apis.NewNotFoundError("", nil)
+	}
+
+	authors, err := app.FindRecordsByIds(constants.CollectionArtists, artwork.GetStringSlice("author"))
+	if err != nil {
+		app.Logger().Error("Error finding IIIF manifest authors", "id", artwork.Id, "error", err.Error())
+		return apis.NewInternalServerError("", nil)
+	}
+
+	authors = publishedRecords(authors)
+	if len(authors) == 0 {
+		return apis.NewNotFoundError("", nil)
+	}
+
+	size, err := imageSize(app, artwork)
+	if err != nil {
+		app.Logger().Error("Error reading IIIF image size", "id", artwork.Id, "error", err.Error())
+		return apis.NewInternalServerError("", nil)
+	}
+
+	return writeJSON(c, presentationContentType, artworkManifest(artwork, authors, size))
+}
+
+func processCollection(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	artist, err := app.FindRecordById(constants.CollectionArtists, c.Request.PathValue("id"))
+	if err != nil || !artist.GetBool("published") {
+		return apis.NewNotFoundError("", nil)
+	}
+
+	artworks, err := utils.FindArtworksByAuthorID(app, artist.Id)
+	if err != nil {
+		app.Logger().Error("Error finding IIIF collection artworks", "id", artist.Id, "error", err.Error())
+		return apis.NewInternalServerError("", nil)
+	}
+
+	return writeJSON(c, presentationContentType, artistCollection(artist, artworks))
+}
+
+// findArtwork returns the published artwork with an image, which is what the IIIF
+// endpoints can describe.
+func findArtwork(app core.App, id string) (*core.Record, error) {
+	artwork, err := app.FindRecordById(constants.CollectionArtworks, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !artwork.GetBool("published") || artwork.GetString("image") == "" {
+		return nil, sql.ErrNoRows
+	}
+
+	return artwork, nil
+}
+
+// readImage opens the image file of an artwork from the PocketBase filesystem.
+func readImage(app core.App, artwork *core.Record, read func(r io.Reader) error) error {
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	reader, err := fsys.GetReader(artwork.BaseFilesPath() + "/" + artwork.GetString("image"))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	return read(reader)
+}
+
+// sourceImage returns the decoded image of an artwork, keyed by the file name like the size,
+// so a replaced image is decoded again.
+func sourceImage(app core.App, artwork *core.Record) (image.Image, error) {
+	return sources.get(artwork.Id+":"+artwork.GetString("image"), func() (image.Image, error) {
+		var src image.Image
+
+		err := readImage(app, artwork, func(r io.Reader) error {
+			var err error
+			src, err = imaging.Decode(r)
+			return err
+		})
+
+		return src, err
+	})
+}
+
+// imageSize returns the dimensions of the image of an artwork, reading only the image header.
+func imageSize(app core.App, artwork *core.Record) (iiif.Size, error) {
+	key := "iiif:size:" + artwork.Id + ":" + artwork.GetString("image")
+
+	return utils.GetOrLoadCachedValue(app, key, imageSizeTTL, func() (iiif.Size, error) {
+		size := iiif.Size{}
+
+		err := readImage(app, artwork, func(r io.Reader) error {
+			config, _, err := image.DecodeConfig(r)
+			size.Width, size.Height = config.Width, config.Height
+			return err
+		})
+
+		return size, err
+	})
+}
+
+func publishedRecords(records []*core.Record) []*core.Record {
+	published := make([]*core.Record, 0, len(records))
+	for _, r := range records {
+		if r.GetBool("published") {
+			published = append(published, r)
+		}
+	}
+
+	return published
+}
+
+func writeJSON(c *core.RequestEvent, contentType string, data any) error {
+	c.Response.Header().Set("Content-Type", contentType)
+	c.Response.Header().Set("Access-Control-Allow-Origin", "*")
+
+	return c.JSON(http.StatusOK, data)
+}
@@ -0,0 +1,121 @@
+package iiif
+
+import (
+	"strings"
+
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/blackfyre/wga/internal/utils/iiif"
+	wgaUrl "github.com/blackfyre/wga/internal/utils/url"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const attribution = "Web Gallery of Art"
+
+func serviceUrl(artworkId string) string {
+	return utils.AssetUrl("/iiif/image/" + artworkId)
+}
+
+func manifestUrl(artworkId string) string {
+	return utils.AssetUrl("/iiif/artworks/" + artworkId + "/manifest.json")
+}
+
+func collectionUrl(artistId string) string {
+	return utils.AssetUrl("/iiif/artists/" + artistId + "/collection.json")
+}
+
+func thumbnail(artwork *core.Record) []iiif.Resource {
+	return []iiif.Resource{{
+		Id:     utils.AssetUrl(wgaUrl.GenerateThumbUrl(constants.CollectionArtworks, artwork.Id, artwork.GetString("image"), "320x240", "")),
+		Type:   "Image",
+		Format: "image/jpeg",
+	}}
+}
+
+// artworkManifest returns the Presentation manifest of an artwork, with a single
+// canvas painted by the artwork's image service. The first author links the
+// manifest to the artwork page.
+func artworkManifest(artwork *core.Record, authors []*core.Record, size iiif.Size) iiif.Manifest {
+	id := manifestUrl(artwork.Id)
+	title := artwork.GetString("title")
+
+	names := make([]string, 0, len(authors))
+	for _, a := range authors {
+		names = append(names, a.GetString("name"))
+	}
+
+	manifest := iiif.Manifest{
+		Context: iiif.PresentationContext,
+		Id:      id,
+		Type:    "Manifest",
+		Label:   iiif.English(title),
+		Metadata: []iiif.MetadataEntry{
+			{Label: iiif.English("Artist"), Value: iiif.English(strings.Join(names, ", "))},
+		},
+		RequiredStatement: &iiif.MetadataEntry{
+			Label: iiif.English("Attribution"),
+			Value: iiif.English(attribution),
+		},
+		Homepage: []iiif.Resource{{
+			Id: utils.AssetUrl(wgaUrl.GenerateFullArtworkUrl(wgaUrl.ArtworkUrlDTO{
+				ArtistName:   authors[0].GetString("name"),
+				ArtistId:     authors[0].Id,
+				ArtworkTitle: title,
+				ArtworkId:    artwork.Id,
+			})),
+			Type:   "Text",
+			Label:  iiif.English(title),
+			Format: "text/html",
+		}},
+		Thumbnail: thumbnail(artwork),
+		Items: []iiif.Canvas{
+			iiif.ImageCanvas(utils.AssetUrl("/iiif/artworks/"+artwork.Id+"/canvas"), serviceUrl(artwork.Id), size.Width, size.Height),
+		},
+	}
+
+	if technique := artwork.GetString("technique"); technique != "" {
+		manifest.Metadata = append(manifest.Metadata, iiif.MetadataEntry{
+			Label: iiif.English("Technique"),
+			Value: iiif.English(technique),
+		})
+	}
+
+	if comment := strings.TrimSpace(utils.StrippedHTML(artwork.GetString("comment"))); comment != "" {
+		manifest.Summary = iiif.English(comment)
+	}
+
+	return manifest
+}
+
+// artistCollection returns the Presentation collection listing the manifests of
+// the published artworks with an image by the artist.
+func artistCollection(artist *core.Record, artworks []*core.Record) iiif.Collection {
+	collection := iiif.Collection{
+		Context: iiif.PresentationContext,
+		Id:      collectionUrl(artist.Id),
+		Type:    "Collection",
+		Label:   iiif.English(artist.GetString("name")),
+		Homepage: []iiif.Resource{{
+			Id:     utils.AssetUrl(wgaUrl.GenerateArtistUrlFromRecord(artist)),
+			Type:   "Text",
+			Label:  iiif.English(artist.GetString("name")),
+			Format: "text/html",
+		}},
+		Items: []iiif.ManifestRef{},
+	}
+
+	for _, aw := range artworks {
+		if !aw.GetBool("published") || aw.GetString("image") == "" {
+			continue
+		}
+
+		collection.Items = append(collection.Items, iiif.ManifestRef{
+			Id:        manifestUrl(aw.Id),
+			Type:      "Manifest",
+			Label:     iiif.English(aw.GetString("title")),
+			Thumbnail: thumbnail(aw),
+		})
+	}
+
+	return collection
+}
@@ -0,0 +1,70 @@
+package iiif
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/blackfyre/wga/internal/utils/iiif"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func newTestRecord(collection string, data map[string]any) *core.Record {
+	record := core.NewRecord(core.NewBaseCollection(collection))
+	for k, v := range data {
+		record.Set(k, v)
+	}
+
+	return record
+}
+
+func TestArtworkManifest(t *testing.T) {
+	artwork := newTestRecord("artworks", map[string]any{
+		"id":        "artwork00000001",
+		"title":     "Primavera",
+		"technique": "Tempera on panel",
+		"comment":   "<p>Painted for the Medici.</p>",
+		"image":     "primavera.jpg",
+	})
+	author := newTestRecord("artists", map[string]any{"id": "artist000000001", "name": "Botticelli"})
+
+	manifest := artworkManifest(artwork, []*core.Record{author}, iiif.Size{Width: 1200, Height: 800})
+
+	if !strings.HasSuffix(manifest.Id, "/iiif/artworks/artwork00000001/manifest.json") {
+		t.Fatalf("unexpected manifest id %q", manifest.Id)
+	}
+	if manifest.Label["en"][0] != "Primavera" || manifest.Summary["en"][0] != "Painted for the Medici." {
+		t.Fatalf("unexpected label or summary: %v %v", manifest.Label, manifest.Summary)
+	}
+	if len(manifest.Metadata) != 2 || manifest.Metadata[0].Value["en"][0] != "Botticelli" {
+		t.Fatalf("unexpected metadata %v", manifest.Metadata)
+	}
+
+	if len(manifest.Items) != 1 {
+		t.Fatalf("expected a single canvas, got %d", len(manifest.Items))
+	}
+
+	canvas := manifest.Items[0]
+	if canvas.Width != 1200 || canvas.Height != 800 {
+		t.Fatalf("canvas size = %dx%d", canvas.Width, canvas.Height)
+	}
+
+	body := canvas.Items[0].Items[0].Body
+	if !strings.HasSuffix(body.Service[0].Id, "/iiif/image/artwork00000001") {
+		t.Fatalf("unexpected image service %q", body.Service[0].Id)
+	}
+}
+
+func TestArtistCollectionSkipsArtworksWithoutImages(t *testing.T) {
+	artist := newTestRecord("artists", map[string]any{"id": "artist000000001", "name": "Botticelli", "slug": "botticelli"})
+	artworks := []*core.Record{
+		newTestRecord("artworks", map[string]any{"id": "artwork00000001", "title": "Primavera", "image": "a.jpg", "published": true}),
+		newTestRecord("artworks", map[string]any{"id": "artwork00000002", "title": "Hidden", "image": "b.jpg"}),
+		newTestRecord("artworks", map[string]any{"id": "artwork00000003", "title": "Lost", "published": true}),
+	}
+
+	collection := artistCollection(artist, artworks)
+
+	if len(collection.Items) != 1 || !strings.HasSuffix(collection.Items[0].Id, "/iiif/artworks/artwork00000001/manifest.json") {
+		t.Fatalf("unexpected collection items %+v", collection.Items)
+	}
+}
@@ -0,0 +1,65 @@
+package iiif
+
+import (
+	"image"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// sourceCacheSize is how many decoded source images are kept. A viewer requests many tiles
+// of the same artwork in a row, so the recent sources are the ones worth keeping.
+const sourceCacheSize = 8
+
+// imageWorkers bounds how many image requests are decoded and transformed at a time, as
+// each holds a full decoded source in memory.
+var imageWorkers = make(chan struct{}, runtime.GOMAXPROCS(0))
+
+// sources holds the recently decoded source images, keyed by artwork id and file name.
+var sources = &sourceCache{size: sourceCacheSize}
+
+// sourceCache keeps the most recently used decoded images, up to size. Concurrent requests
+// for the same image decode it once.
+type sourceCache struct {
+	size int
+
+	mu      sync.Mutex
+	entries []*sourceEntry // the most recently used last
+}
+
+type sourceEntry struct {
+	key  string
+	once sync.Once
+	img  image.Image
+	err  error
+}
+
+// get returns the cached image of the key, loading it when it is not cached. A failed load
+// is not kept, so the next request tries again.
+func (c *sourceCache) get(key string, load func() (image.Image, error)) (image.Image, error) {
+	c.mu.Lock()
+	var entry *sourceEntry
+	if i := slices.IndexFunc(c.entries, func(e *sourceEntry) bool { return e.key == key }); i >= 0 {
+		entry = c.entries[i]
+		c.entries = slices.Delete(c.entries, i, i+1)
+	} else {
+		entry = &sourceEntry{key: key}
+		if len(c.entries) >= c.size {
+			c.entries = slices.Delete(c.entries, 0, len(c.entries)-c.size+1)
+		}
+	}
+	c.entries = append(c.entries, entry)
+	c.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.img, entry.err = load()
+	})
+
+	if entry.err != nil {
+		c.mu.Lock()
+		c.entries = slices.DeleteFunc(c.entries, func(e *sourceEntry) bool { return e == entry })
+		c.mu.Unlock()
+	}
+
+	return entry.img, entry.err
+}
@@ -0,0 +1,62 @@
+package iiif
+
+import (
+	"errors"
+	"image"
+	"testing"
+)
+
+func TestSourceCacheKeepsTheRecentImages(t *testing.T) {
+	cache := &sourceCache{size: 2}
+	loads := map[string]int{}
+
+	get := func(key string) {
+		t.Helper()
+
+		_, err := cache.get(key, func() (image.Image, error) {
+			loads[key]++
+			return image.NewRGBA(image.Rect(0, 0, 1, 1)), nil
+		})
+		if err != nil {
+			t.Fatalf("get %s: %v", key, err)
+		}
+	}
+
+	get("a")
+	get("b")
+	get("a")
+	// c evicts b, the least recently used
+	get("c")
+	get("a")
+	get("b")
+
+	if loads["a"] != 1 || loads["b"] != 2 || loads["c"] != 1 {
+		t.Fatalf("loads = %v, want a once, b twice and c once", loads)
+	}
+
+	if len(cache.entries) != 2 {
+		t.Fatalf("expected 2 cached images, got %d", len(cache.entries))
+	}
+}
+
+func TestSourceCacheRetriesAFailedLoad(t *testing.T) {
+	cache := &sourceCache{size: 2}
+	loads := 0
+
+	load := func() (image.Image, error) {
+		loads++
+		if loads == 1 {
+			return nil, errors.New("unreadable")
+		}
+
+		return image.NewRGBA(image.Rect(0, 0, 1, 1)), nil
+	}
+
+	if _, err := cache.get("a", load); err == nil {
+		t.Fatal("expected the failed load to be returned")
+	}
+
+	if _, err := cache.get("a", load); err != nil || loads != 2 {
+		t.Fatalf("expected the image to be loaded again, got %d loads (%v)", loads, err)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/blackfyre/wga/internal/assets/templ/dto"
 	"github.com/blackfyre/wga/internal/assets/templ/pages"
 	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/handlers/artworks"
+	"github.com/blackfyre/wga/internal/repositories"
 	"github.com/blackfyre/wga/internal/utils"
 	"github.com/blackfyre/wga/internal/utils/url"
 	"github.com/pocketbase/dbx"
@@ -26,6 +28,13 @@ func inspirationHandler(app *pocketbase.PocketBase, c *core.RequestEvent) error
 		return utils.ServerFaultError(c)
 	}
 
+	credits, err := repositories.NewArtworksRepository(app).FindAuthors(artPieces)
+
+	if err != nil {
+		app.Logger().Error("Error getting the authors of random artworks", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
 	content := dto.ImageGrid{}
 
 	for _, artPiece := range artPieces {
@@ -34,27 +43,14 @@ func inspirationHandler(app *pocketbase.PocketBase, c *core.RequestEvent) error
 		}
 
 		artworkId := artPiece.GetString("id")
-		authorIds := artPiece.GetStringSlice("author")
-		authorId := ""
-
-		for _, id := range authorIds {
-			if id != "" {
-				authorId = id
-				break
-			}
-		}
+		authors := credits[artworkId]
 
-		if authorId == "" {
+		if len(authors) == 0 {
 			app.Logger().Warn("Skipping artwork without author", "artworkId", artworkId)
 			continue
 		}
 
-		artist, err := app.FindRecordById(constants.CollectionArtists, authorId)
-
-		if err != nil {
-			app.Logger().Error("Error getting artist for artwork", "artworkId", artworkId, "error", err.Error())
-			continue
-		}
+		artist := authors[0].Artist
 
 		imageUrl := utils.AssetUrl("/assets/images/no-image.png")
 		thumbUrl := imageUrl
@@ -78,6 +74,7 @@ func inspirationHandler(app *pocketbase.PocketBase, c *core.RequestEvent) error
 			Title:     artPiece.GetString("title"),
 			Technique: artPiece.GetString("technique"),
 			Id:        artworkId,
+			Authors:   artworks.NewArtworkAuthors(authors),
 			Artist: dto.Artist{
 				Id:   artist.Id,
 				Name: artist.GetString("name"),
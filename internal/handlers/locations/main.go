@@ -0,0 +1,201 @@
+package locations
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/blackfyre/wga/internal/assets/templ/dto"
+	"github.com/blackfyre/wga/internal/assets/templ/pages"
+	tmplUtils "github.com/blackfyre/wga/internal/assets/templ/utils"
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/handlers/artworks"
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/blackfyre/wga/internal/utils/jsonld"
+	wgaUrl "github.com/blackfyre/wga/internal/utils/url"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const artworksPerPage = 24
+
+// unknownCountry groups the locations whose country is not recorded.
+const unknownCountry = "Other"
+
+func RegisterHandlers(app *pocketbase.PocketBase) {
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		se.Router.GET("/locations", func(c *core.RequestEvent) error {
+			return processLocations(app, c)
+		})
+
+		se.Router.GET("/locations/{slug}", func(c *core.RequestEvent) error {
+			return processLocation(app, c)
+		})
+
+		return se.Next()
+	})
+}
+
+func processLocations(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	locations, err := repositories.NewLocationsRepository(app).GetLocations()
+	if err != nil {
+		app.Logger().Error("Error getting locations", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	content := pages.LocationsPageDTO{Countries: groupLocations(locations)}
+
+	ctx := tmplUtils.DecorateContext(context.Background(), tmplUtils.TitleKey, "Locations")
+	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.DescriptionKey, "Browse the artworks of the gallery by the museums and churches holding them.")
+	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.CanonicalUrlKey, tmplUtils.AssetUrl("/locations"))
+
+	c.Response.Header().Set("HX-Push-Url", "/locations")
+
+	var buff bytes.Buffer
+
+	err = pages.LocationsPage(content).Render(ctx, &buff)
+	if err != nil {
+		app.Logger().Error("Error rendering locations page", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	return c.HTML(http.StatusOK, buff.String())
+}
+
+// groupLocations groups the locations by country and city, keeping the order they come in.
+func groupLocations(locations []repositories.Location) []pages.LocationCountry {
+	countries := []pages.LocationCountry{}
+
+	for _, l := range locations {
+		country := l.Country
+		if country == "" {
+			country = unknownCountry
+		}
+
+		if len(countries) == 0 || countries[len(countries)-1].Name != country {
+			countries = append(countries, pages.LocationCountry{Name: country})
+		}
+
+		cities := &countries[len(countries)-1].Cities
+		if len(*cities) == 0 || (*cities)[len(*cities)-1].Name != l.City {
+			*cities = append(*cities, pages.LocationCity{Name: l.City, SearchUrl: wgaUrl.GenerateCitySearchUrl(l.City)})
+		}
+
+		city := &(*cities)[len(*cities)-1]
+		city.Locations = append(city.Locations, pages.LocationListItem{
+			Name:         l.Name,
+			Kind:         l.KindLabel(),
+			Url:          wgaUrl.GenerateLocationUrl(l.Slug),
+			ArtworkCount: l.ArtworkCount,
+		})
+	}
+
+	return countries
+}
+
+func processLocation(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	slug := c.Request.PathValue("slug")
+
+	page := 1
+	if raw := c.Request.URL.Query().Get("page"); raw != "" {
+		var err error
+		page, err = strconv.Atoi(raw)
+		if err != nil || page < 1 {
+			return utils.BadRequestError(c)
+		}
+	}
+
+	location, err := app.FindFirstRecordByData(constants.CollectionLocations, "slug", slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return utils.NotFoundError(c)
+	}
+	if err != nil {
+		app.Logger().Error("Error finding location", "slug", slug, "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	filter := "published = true && author:length > 0 && location = {:location}"
+	params := dbx.Params{"location": location.Id}
+
+	records, err := app.FindRecordsByFilter(
+		constants.CollectionArtworks,
+		filter,
+		"+title",
+		artworksPerPage,
+		(page-1)*artworksPerPage,
+		params,
+	)
+	if err != nil {
+		app.Logger().Error("Error getting artworks of location", "slug", slug, "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	repo := repositories.NewArtworksRepository(app)
+
+	total, err := repo.Count(repositories.RecordFilter{Filter: filter, Params: params})
+	if err != nil {
+		app.Logger().Error("Error counting artworks of location", "slug", slug, "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	credits, err := repo.FindAuthors(records)
+	if err != nil {
+		app.Logger().Error("Error finding artwork authors", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	pageUrl := wgaUrl.GenerateLocationUrl(slug)
+	city := location.GetString("city")
+
+	content := pages.LocationPageDTO{
+		Name:        location.GetString("name"),
+		Kind:        repositories.LocationKindLabel(location.GetString("kind")),
+		City:        city,
+		CityUrl:     wgaUrl.GenerateCitySearchUrl(city),
+		Country:     location.GetString("country"),
+		Website:     location.GetString("website"),
+		Url:         pageUrl,
+		ArtworksUrl: "/artworks?" + url.Values{"location": {slug}}.Encode(),
+		Count:       total,
+		Artworks:    dto.ImageGrid{},
+	}
+
+	for _, r := range records {
+		if authors := credits[r.Id]; len(authors) > 0 {
+			content.Artworks = append(content.Artworks, artworks.NewArtworkImage(r, authors))
+		}
+	}
+
+	content.Pagination = string(utils.NewPagination(total, artworksPerPage, page, pageUrl, "", pageUrl).Render())
+
+	marshalled, err := json.Marshal(jsonld.LocationPlace(location))
+	if err != nil {
+		app.Logger().Error("Error marshalling location jsonld for "+location.Id, "error", err.Error())
+	}
+
+	content.Jsonld = fmt.Sprintf(`<script type="application/ld+json">%s</script>`, marshalled)
+
+	ctx := tmplUtils.DecorateContext(context.Background(), tmplUtils.TitleKey, content.Name)
+	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.DescriptionKey, fmt.Sprintf("Artworks held at %s, %s.", content.Name, city))
+	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.CanonicalUrlKey, tmplUtils.AssetUrl(pageUrl))
+
+	c.Response.Header().Set("HX-Push-Url", utils.GenerateCurrentRelativePageUrl(c))
+
+	var buff bytes.Buffer
+
+	err = pages.LocationPage(content).Render(ctx, &buff)
+	if err != nil {
+		app.Logger().Error("Error rendering location page", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	return c.HTML(http.StatusOK, buff.String())
+}
@@ -2,14 +2,22 @@ package handlers
 
 import (
 	"github.com/blackfyre/wga/internal/config"
+	"github.com/blackfyre/wga/internal/handlers/accounts"
+	"github.com/blackfyre/wga/internal/handlers/api"
 	"github.com/blackfyre/wga/internal/handlers/artists"
 	"github.com/blackfyre/wga/internal/handlers/artworks"
 	"github.com/blackfyre/wga/internal/handlers/contributors"
 	"github.com/blackfyre/wga/internal/handlers/dual"
 	"github.com/blackfyre/wga/internal/handlers/feedback"
+	"github.com/blackfyre/wga/internal/handlers/glossary"
 	"github.com/blackfyre/wga/internal/handlers/guestbook"
+	"github.com/blackfyre/wga/internal/handlers/iiif"
 	"github.com/blackfyre/wga/internal/handlers/inspire"
 	"github.com/blackfyre/wga/internal/handlers/landing"
+	"github.com/blackfyre/wga/internal/handlers/locations"
+	"github.com/blackfyre/wga/internal/handlers/music"
+	"github.com/blackfyre/wga/internal/handlers/periods"
+	"github.com/blackfyre/wga/internal/handlers/series"
 	"github.com/blackfyre/wga/internal/handlers/static"
 	"github.com/blackfyre/wga/internal/handlers/statistics"
 
@@ -28,7 +36,11 @@ func RegisterHandlers(app *pocketbase.PocketBase, captcha config.Captcha) {
 	p := bluemonday.NewPolicy()
 
 	feedback.RegisterHandlers(app)
-	// registerMusicHandlers(app)
+	music.RegisterHandlers(app)
+	periods.RegisterHandlers(app)
+	locations.RegisterHandlers(app)
+	series.RegisterHandlers(app)
+	glossary.RegisterHandlers(app)
 	guestbook.RegisterHandlers(app)
 	artists.RegisterHandlers(app)
 	postcards.RegisterPostcardHandlers(app, p, captcha)
@@ -39,4 +51,7 @@ func RegisterHandlers(app *pocketbase.PocketBase, captcha config.Captcha) {
 	landing.RegisterHandlers(app)
 	statistics.RegisterHandlers(app)
 	dual.RegisterHandlers(app)
+	api.RegisterHandlers(app)
+	iiif.RegisterHandlers(app)
+	accounts.RegisterHandlers(app)
 }
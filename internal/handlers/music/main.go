@@ -0,0 +1,315 @@
+package music
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/blackfyre/wga/internal/assets/templ/pages"
+	tmplUtils "github.com/blackfyre/wga/internal/assets/templ/utils"
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// suggestionLimit is the number of tracks offered next to an artwork or a track.
+const suggestionLimit = 5
+
+// minCentury and maxCentury mirror the values of the composer century select field.
+const (
+	minCentury = 12
+	maxCentury = 21
+)
+
+func RegisterHandlers(app *pocketbase.PocketBase) {
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		se.Router.GET("/music", func(c *core.RequestEvent) error {
+			return processMusicList(app, c)
+		})
+
+		se.Router.GET("/music/suggestions", func(c *core.RequestEvent) error {
+			return processSuggestions(app, c)
+		})
+
+		se.Router.GET("/music/{slug}", func(c *core.RequestEvent) error {
+			return processTrack(app, c)
+		})
+
+		se.Router.GET("/music/{slug}/stream", func(c *core.RequestEvent) error {
+			return streamTrack(app, c)
+		})
+
+		return se.Next()
+	})
+}
+
+func processMusicList(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	tracks, err := repositories.NewMusicRepository(app).GetTracks()
+	if err != nil {
+		app.Logger().Error("Error getting music tracks", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	content := pages.MusicPageDTO{
+		Centuries: groupTracks(tracks),
+	}
+
+	ctx := tmplUtils.DecorateContext(context.Background(), tmplUtils.TitleKey, "Music")
+	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.DescriptionKey, "Classical music to listen to while viewing the works of the old masters.")
+	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.CanonicalUrlKey, tmplUtils.AssetUrl("/music"))
+
+	c.Response.Header().Set("HX-Push-Url", "/music")
+
+	var buff bytes.Buffer
+
+	err = pages.MusicPage(content).Render(ctx, &buff)
+	if err != nil {
+		app.Logger().Error("Error rendering music page", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	return c.HTML(http.StatusOK, buff.String())
+}
+
+func processTrack(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	slug := c.Request.PathValue("slug")
+	repo := repositories.NewMusicRepository(app)
+
+	track, err := repo.FindTrack(utils.ExtractIdFromString(slug))
+	if errors.Is(err, sql.ErrNoRows) {
+		return utils.NotFoundError(c)
+	}
+	if err != nil {
+		app.Logger().Error("Error finding music track", "slug", slug, "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	expectedUrl := trackUrl(track)
+
+	if "/music/"+slug != expectedUrl {
+		return c.Redirect(http.StatusMovedPermanently, expectedUrl)
+	}
+
+	suggestions, err := repo.GetTracksByCentury(track.Century, track.Id, suggestionLimit)
+	if err != nil {
+		app.Logger().Warn("Error getting music suggestions", "century", track.Century, "error", err.Error())
+	}
+
+	content := pages.MusicTrackPageDTO{
+		Title:        track.Title,
+		ComposerName: track.ComposerName,
+		CenturyLabel: centuryLabel(track.Century),
+		Language:     track.Language,
+		Url:          expectedUrl,
+		StreamUrl:    expectedUrl + "/stream",
+		Suggestions:  trackLinks(suggestions),
+	}
+
+	ctx := tmplUtils.DecorateContext(context.Background(), tmplUtils.TitleKey, fmt.Sprintf("%s - %s", track.Title, track.ComposerName))
+	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.DescriptionKey, fmt.Sprintf("Listen to %s by %s.", track.Title, track.ComposerName))
+	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.CanonicalUrlKey, tmplUtils.AssetUrl(expectedUrl))
+
+	c.Response.Header().Set("HX-Push-Url", expectedUrl)
+
+	var buff bytes.Buffer
+
+	err = pages.MusicTrackPage(content).Render(ctx, &buff)
+	if err != nil {
+		app.Logger().Error("Error rendering music track page", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	return c.HTML(http.StatusOK, buff.String())
+}
+
+// streamTrack serves the stored source file of a track.
+// The filesystem serves it through http.ServeContent, so Range requests
+// are honoured and the audio element can seek without downloading the whole file.
+func streamTrack(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	record, err := app.FindRecordById(constants.CollectionMusicSongs, utils.ExtractIdFromString(c.Request.PathValue("slug")))
+	if err != nil {
+		return utils.NotFoundError(c)
+	}
+
+	source := record.GetString("source")
+	if source == "" {
+		return utils.NotFoundError(c)
+	}
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		app.Logger().Error("Error opening the filesystem", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+	defer fsys.Close()
+
+	err = fsys.Serve(c.Response, c.Request, record.BaseFilesPath()+"/"+source, source)
+	if err != nil {
+		app.Logger().Error("Error streaming music track", "id", record.Id, "error", err.Error())
+		return utils.NotFoundError(c)
+	}
+
+	return nil
+}
+
+// processSuggestions renders the "listen while viewing" fragment.
+// The century is either given directly or derived from the first author of an artwork.
+// An empty fragment is returned when there is nothing to suggest, so the
+// artwork page is left untouched.
+func processSuggestions(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	century := c.Request.URL.Query().Get("century")
+
+	if artworkId := c.Request.URL.Query().Get("artwork"); artworkId != "" {
+		century = artworkCentury(app, artworkId)
+	}
+
+	var tracks []repositories.MusicTrack
+
+	if isValidCentury(century) {
+		var err error
+		tracks, err = repositories.NewMusicRepository(app).GetTracksByCentury(century, "", suggestionLimit)
+		if err != nil {
+			app.Logger().Error("Error getting music suggestions", "century", century, "error", err.Error())
+			return utils.ServerFaultError(c)
+		}
+	}
+
+	var buff bytes.Buffer
+
+	err := pages.MusicSuggestionsBlock(centuryLabel(century), trackLinks(tracks)).Render(context.Background(), &buff)
+	if err != nil {
+		app.Logger().Error("Error rendering music suggestions", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	return c.HTML(http.StatusOK, buff.String())
+}
+
+// artworkCentury returns the century of the first author of the artwork,
+// or an empty string when it can't be determined.
+func artworkCentury(app *pocketbase.PocketBase, artworkId string) string {
+	artwork, err := app.FindRecordById(constants.CollectionArtworks, artworkId)
+	if err != nil || !artwork.GetBool("published") {
+		return ""
+	}
+
+	authors := artwork.GetStringSlice("author")
+	if len(authors) == 0 {
+		return ""
+	}
+
+	artist, err := app.FindRecordById(constants.CollectionArtists, authors[0])
+	if err != nil {
+		return ""
+	}
+
+	return centuryForYears(artist.GetInt("year_of_birth"), artist.GetInt("year_of_death"))
+}
+
+// centuryForYears returns the century an artist was active in.
+// When both years are known the middle of the lifetime is used.
+func centuryForYears(born, died int) string {
+	year := 0
+
+	switch {
+	case born > 0 && died > 0:
+		year = (born + died) / 2
+	case born > 0:
+		year = born
+	case died > 0:
+		year = died
+	default:
+		return ""
+	}
+
+	return strconv.Itoa((year-1)/100 + 1)
+}
+
+func isValidCentury(century string) bool {
+	n, err := strconv.Atoi(century)
+	if err != nil {
+		return false
+	}
+
+	return n >= minCentury && n <= maxCentury
+}
+
+// centuryLabel turns a century number into a label like "16th century".
+func centuryLabel(century string) string {
+	n, err := strconv.Atoi(century)
+	if err != nil {
+		return ""
+	}
+
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+
+	return fmt.Sprintf("%d%s century", n, suffix)
+}
+
+func trackUrl(track repositories.MusicTrack) string {
+	return "/music/" + utils.Slugify(track.Title) + "-" + track.Id
+}
+
+func trackLinks(tracks []repositories.MusicTrack) []pages.MusicTrackLink {
+	links := make([]pages.MusicTrackLink, len(tracks))
+	for i, track := range tracks {
+		links[i] = pages.MusicTrackLink{
+			Title:        track.Title,
+			ComposerName: track.ComposerName,
+			Url:          trackUrl(track),
+		}
+	}
+
+	return links
+}
+
+// groupTracks groups the tracks by century, then by language and composer.
+// The tracks are expected in the order returned by MusicRepository.GetTracks.
+func groupTracks(tracks []repositories.MusicTrack) []pages.MusicCentury {
+	centuries := []pages.MusicCentury{}
+
+	for _, track := range tracks {
+		if len(centuries) == 0 || centuries[len(centuries)-1].Century != track.Century {
+			centuries = append(centuries, pages.MusicCentury{
+				Century: track.Century,
+				Label:   centuryLabel(track.Century),
+			})
+		}
+		century := &centuries[len(centuries)-1]
+
+		language := track.Language
+		if language == "" {
+			language = "Other"
+		}
+
+		if len(century.Languages) == 0 || century.Languages[len(century.Languages)-1].Language != language {
+			century.Languages = append(century.Languages, pages.MusicLanguage{Language: language})
+		}
+		lang := &century.Languages[len(century.Languages)-1]
+
+		if len(lang.Composers) == 0 || lang.Composers[len(lang.Composers)-1].Id != track.ComposerId {
+			lang.Composers = append(lang.Composers, pages.MusicComposer{Id: track.ComposerId, Name: track.ComposerName})
+		}
+		composer := &lang.Composers[len(lang.Composers)-1]
+
+		composer.Tracks = append(composer.Tracks, trackLinks([]repositories.MusicTrack{track})...)
+	}
+
+	return centuries
+}
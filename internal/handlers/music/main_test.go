@@ -0,0 +1,71 @@
+package music
+
+import (
+	"testing"
+
+	"github.com/blackfyre/wga/internal/repositories"
+)
+
+func TestCenturyForYears(t *testing.T) {
+	tests := []struct {
+		name        string
+		born, died  int
+		wantCentury string
+	}{
+		{name: "middle of the lifetime", born: 1445, died: 1510, wantCentury: "15"},
+		{name: "turn of the century", born: 1571, died: 1630, wantCentury: "16"},
+		{name: "only birth year", born: 1606, wantCentury: "17"},
+		{name: "only death year", died: 1700, wantCentury: "17"},
+		{name: "unknown years", wantCentury: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := centuryForYears(test.born, test.died); got != test.wantCentury {
+				t.Errorf("centuryForYears(%d, %d) = %q, want %q", test.born, test.died, got, test.wantCentury)
+			}
+		})
+	}
+}
+
+func TestCenturyLabel(t *testing.T) {
+	tests := map[string]string{
+		"12":  "12th century",
+		"16":  "16th century",
+		"21":  "21st century",
+		"abc": "",
+	}
+
+	for century, want := range tests {
+		if got := centuryLabel(century); got != want {
+			t.Errorf("centuryLabel(%q) = %q, want %q", century, got, want)
+		}
+	}
+}
+
+func TestGroupTracksByCenturyLanguageAndComposer(t *testing.T) {
+	tracks := []repositories.MusicTrack{
+		{Id: "t1", Title: "Missa Papae Marcelli", ComposerId: "c1", ComposerName: "Palestrina", Century: "16", Language: "Italian"},
+		{Id: "t2", Title: "Sicut cervus", ComposerId: "c1", ComposerName: "Palestrina", Century: "16", Language: "Italian"},
+		{Id: "t3", Title: "L'Orfeo", ComposerId: "c2", ComposerName: "Monteverdi", Century: "17", Language: "Italian"},
+		{Id: "t4", Title: "Dido and Aeneas", ComposerId: "c3", ComposerName: "Purcell", Century: "17", Language: ""},
+	}
+
+	got := groupTracks(tracks)
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 centuries, got %d", len(got))
+	}
+	if got[0].Label != "16th century" || len(got[0].Languages) != 1 {
+		t.Fatalf("unexpected first century: %+v", got[0])
+	}
+	if composers := got[0].Languages[0].Composers; len(composers) != 1 || len(composers[0].Tracks) != 2 {
+		t.Fatalf("expected one composer with two tracks, got %+v", composers)
+	}
+	if url := got[0].Languages[0].Composers[0].Tracks[0].Url; url != "/music/missa-papae-marcelli-t1" {
+		t.Errorf("unexpected track url %q", url)
+	}
+	if languages := got[1].Languages; len(languages) != 2 || languages[1].Language != "Other" {
+		t.Fatalf("expected tracks without a language to be grouped under Other, got %+v", languages)
+	}
+}
@@ -0,0 +1,166 @@
+package periods
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/blackfyre/wga/internal/assets/templ/dto"
+	"github.com/blackfyre/wga/internal/assets/templ/pages"
+	tmplUtils "github.com/blackfyre/wga/internal/assets/templ/utils"
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/blackfyre/wga/internal/utils"
+	wgaUrl "github.com/blackfyre/wga/internal/utils/url"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const artistsPerPage = 30
+
+func RegisterHandlers(app *pocketbase.PocketBase) {
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		se.Router.GET("/periods", func(c *core.RequestEvent) error {
+			return processPeriods(app, c)
+		})
+
+		se.Router.GET("/periods/{slug}", func(c *core.RequestEvent) error {
+			return processPeriod(app, c)
+		})
+
+		return se.Next()
+	})
+}
+
+func processPeriods(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	periods, err := repositories.NewPeriodsRepository(app).GetPeriods()
+	if err != nil {
+		app.Logger().Error("Error getting art periods", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	content := pages.PeriodsPageDTO{}
+
+	for _, p := range periods {
+		content.Periods = append(content.Periods, pages.PeriodListItem{
+			Name:        p.Name,
+			Years:       periodYears(p),
+			Description: p.Description,
+			Url:         periodUrl(p),
+			ArtistCount: p.ArtistCount,
+		})
+	}
+
+	ctx := tmplUtils.DecorateContext(context.Background(), tmplUtils.TitleKey, "Art periods")
+	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.DescriptionKey, "Browse the artists of the gallery by art period.")
+	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.CanonicalUrlKey, tmplUtils.AssetUrl("/periods"))
+
+	c.Response.Header().Set("HX-Push-Url", "/periods")
+
+	var buff bytes.Buffer
+
+	err = pages.PeriodsPage(content).Render(ctx, &buff)
+	if err != nil {
+		app.Logger().Error("Error rendering art periods page", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	return c.HTML(http.StatusOK, buff.String())
+}
+
+func processPeriod(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	slug := c.Request.PathValue("slug")
+
+	page := 1
+	if raw := c.Request.URL.Query().Get("page"); raw != "" {
+		var err error
+		page, err = strconv.Atoi(raw)
+		if err != nil || page < 1 {
+			return utils.BadRequestError(c)
+		}
+	}
+
+	repo := repositories.NewPeriodsRepository(app)
+
+	period, err := repo.FindPeriodBySlug(slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return utils.NotFoundError(c)
+	}
+	if err != nil {
+		app.Logger().Error("Error finding art period", "slug", slug, "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	filter := "published = true && " + repositories.ActiveInPeriodFilter("")
+
+	records, err := app.FindRecordsByFilter(
+		constants.CollectionArtists,
+		filter,
+		"+name",
+		artistsPerPage,
+		(page-1)*artistsPerPage,
+		period.FilterParams(),
+	)
+	if err != nil {
+		app.Logger().Error("Error getting artists of art period", "slug", slug, "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	total, err := repo.CountActiveArtists(period.Id)
+	if err != nil {
+		app.Logger().Error("Error counting artists of art period", "slug", slug, "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	pageUrl := periodUrl(period)
+
+	content := pages.PeriodPageDTO{
+		Name:        period.Name,
+		Years:       periodYears(period),
+		Description: period.Description,
+		Url:         pageUrl,
+		ArtworksUrl: "/artworks/results?" + url.Values{"period": {period.Slug}}.Encode(),
+		Count:       total,
+	}
+
+	for _, m := range records {
+		content.Artists = append(content.Artists, dto.Artist{
+			Name:       m.GetString("name"),
+			Url:        wgaUrl.GenerateArtistUrlFromRecord(m),
+			Profession: m.GetString("profession"),
+			BornDied:   utils.NormalizedBirthDeathActivity(m),
+			Schools:    utils.RenderSchoolNames(app, m.GetStringSlice("school")),
+		})
+	}
+
+	content.Pagination = string(utils.NewPagination(total, artistsPerPage, page, pageUrl, "", pageUrl).Render())
+
+	ctx := tmplUtils.DecorateContext(context.Background(), tmplUtils.TitleKey, period.Name)
+	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.DescriptionKey, fmt.Sprintf("Artists active during the %s (%s).", period.Name, periodYears(period)))
+	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.CanonicalUrlKey, tmplUtils.AssetUrl(pageUrl))
+
+	c.Response.Header().Set("HX-Push-Url", utils.GenerateCurrentRelativePageUrl(c))
+
+	var buff bytes.Buffer
+
+	err = pages.PeriodPage(content).Render(ctx, &buff)
+	if err != nil {
+		app.Logger().Error("Error rendering art period page", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	return c.HTML(http.StatusOK, buff.String())
+}
+
+func periodUrl(p repositories.ArtPeriod) string {
+	return "/periods/" + p.Slug
+}
+
+func periodYears(p repositories.ArtPeriod) string {
+	return fmt.Sprintf("%d–%d", p.Start, p.End)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/blackfyre/wga/internal/constants"
 	"github.com/blackfyre/wga/internal/logging"
 	"github.com/blackfyre/wga/internal/utils"
+	"github.com/blackfyre/wga/internal/utils/postcard"
 	"github.com/blackfyre/wga/internal/utils/url"
 	"github.com/pocketbase/pocketbase/core"
 )
@@ -39,6 +40,24 @@ func viewPostcard(app core.App, c *core.RequestEvent) error {
 		return utils.NotFoundError(c)
 	}
 
+	if token := c.Request.URL.Query().Get("r"); token != "" {
+		pickedUp, err := postcard.MarkOpened(app, r, token)
+		if err != nil {
+			// A failed pickup record must not keep the recipient from their postcard.
+			logger.Error("Postcard pickup record update failed",
+				"event", "postcard.view.pickup",
+				"outcome", "record_update_failed",
+				"error_type", logging.ErrorType(err),
+				"error", logging.Redact(err),
+			)
+		} else if pickedUp {
+			logger.Info("Postcard picked up",
+				"event", "postcard.view.pickup",
+				"outcome", "picked_up",
+			)
+		}
+	}
+
 	if errs := app.ExpandRecord(r, []string{"image_id"}, nil); len(errs) > 0 {
 		logger.Error("Postcard view expansion failed",
 			"event", "postcard.view.failed",
@@ -0,0 +1,99 @@
+package series
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/blackfyre/wga/internal/assets/templ/pages"
+	tmplUtils "github.com/blackfyre/wga/internal/assets/templ/utils"
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/handlers/artworks"
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/blackfyre/wga/internal/utils/url"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func RegisterHandlers(app *pocketbase.PocketBase) {
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		se.Router.GET("/series/{slug}", func(c *core.RequestEvent) error {
+			return processSeries(app, c)
+		})
+
+		return se.Next()
+	})
+}
+
+func processSeries(app *pocketbase.PocketBase, c *core.RequestEvent) error {
+	slug := c.Request.PathValue("slug")
+
+	series, err := app.FindRecordById(constants.CollectionSeries, utils.ExtractIdFromString(slug))
+	if errors.Is(err, sql.ErrNoRows) {
+		return utils.NotFoundError(c)
+	}
+	if err != nil {
+		app.Logger().Error("Error finding series", "slug", slug, "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	expectedUrl := url.GenerateSeriesUrl(series.GetString("title"), series.Id)
+
+	// Redirect to the correct URL if the slug is not the one of the current title
+	if c.Request.URL.Path != expectedUrl {
+		return c.Redirect(http.StatusMovedPermanently, expectedUrl)
+	}
+
+	content, err := RenderSeriesContent(app, series, "#mc-area", true)
+	if err != nil {
+		app.Logger().Error("Error getting the parts of series "+series.Id, "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	ctx := tmplUtils.DecorateContext(context.Background(), tmplUtils.TitleKey, content.Title)
+	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.DescriptionKey, series.GetString("description"))
+	ctx = tmplUtils.DecorateContext(ctx, tmplUtils.CanonicalUrlKey, tmplUtils.AssetUrl(expectedUrl))
+	if len(content.Parts) > 0 {
+		ctx = tmplUtils.DecorateContext(ctx, tmplUtils.OgImageKey, utils.AssetUrl(content.Parts[0].Thumb))
+	}
+
+	c.Response.Header().Set("HX-Push-Url", expectedUrl)
+
+	var buff bytes.Buffer
+
+	err = pages.SeriesPage(content).Render(ctx, &buff)
+	if err != nil {
+		app.Logger().Error("Error rendering series page", "error", err.Error())
+		return utils.ServerFaultError(c)
+	}
+
+	return c.HTML(http.StatusOK, buff.String())
+}
+
+// RenderSeriesContent returns the content of the page of a series with its published parts, for
+// the series page and the dual mode panes.
+func RenderSeriesContent(app core.App, series *core.Record, hxTarget string, showBreadcrumbs bool) (pages.SeriesPageDTO, error) {
+	parts, err := repositories.NewSeriesRepository(app).FindParts(series.Id)
+	if err != nil {
+		return pages.SeriesPageDTO{}, err
+	}
+
+	credits, err := repositories.NewArtworksRepository(app).FindAuthors(parts)
+	if err != nil {
+		return pages.SeriesPageDTO{}, err
+	}
+
+	return pages.SeriesPageDTO{
+		Id:              series.Id,
+		Title:           series.GetString("title"),
+		Kind:            repositories.SeriesKindLabel(series.GetString("kind")),
+		Description:     series.GetString("description"),
+		Url:             url.GenerateSeriesUrl(series.GetString("title"), series.Id),
+		Parts:           artworks.NewSeriesParts(parts, credits, ""),
+		HxTarget:        hxTarget,
+		ShowBreadcrumbs: showBreadcrumbs,
+	}, nil
+}
@@ -0,0 +1,20 @@
+package hooks
+
+import (
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// artistNameSlugHook keeps the slug of the other names of the artists in step with
+// the name, so old and alternative artist urls can be redirected.
+func artistNameSlugHook(app core.App) {
+	setSlug := func(e *core.RecordEvent) error {
+		e.Record.Set("slug", repositories.ArtistNameSlug(e.Record.GetString("name")))
+
+		return e.Next()
+	}
+
+	app.OnRecordCreate(constants.CollectionArtistNames).BindFunc(setSlug)
+	app.OnRecordUpdate(constants.CollectionArtistNames).BindFunc(setSlug)
+}
@@ -0,0 +1,42 @@
+package hooks
+
+import (
+	"errors"
+
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// artistSortNameHook keeps the sort name and index letter of the artists in step with the name,
+// so the artist list and its A to Z index skip particles like "van" and "da".
+func artistSortNameHook(app core.App) {
+	setSortName := func(e *core.RecordEvent) error {
+		sortName := repositories.ArtistSortName(e.Record.GetString("name"))
+		e.Record.Set("sort_name", sortName)
+		e.Record.Set("letter", repositories.ArtistLetter(sortName))
+
+		return e.Next()
+	}
+
+	app.OnRecordCreate(constants.CollectionArtists).BindFunc(setSortName)
+	app.OnRecordUpdate(constants.CollectionArtists).BindFunc(setSortName)
+}
+
+// errSelfRelationship rejects a relationship of an artist with themselves.
+var errSelfRelationship = errors.New("an artist cannot be related to themselves")
+
+// artistRelationshipHook rejects the relationships that would link an artist to themselves,
+// which would draw a loop on the network page.
+func artistRelationshipHook(app core.App) {
+	rejectSelf := func(e *core.RecordEvent) error {
+		if e.Record.GetString("artist") == e.Record.GetString("related_artist") {
+			return errSelfRelationship
+		}
+
+		return e.Next()
+	}
+
+	app.OnRecordCreate(constants.CollectionArtistRelationships).BindFunc(rejectSelf)
+	app.OnRecordUpdate(constants.CollectionArtistRelationships).BindFunc(rejectSelf)
+}
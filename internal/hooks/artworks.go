@@ -0,0 +1,166 @@
+package hooks
+
+import (
+	"slices"
+
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+)
+
+// artworkAuthorsHook keeps the attribution roles in step with the authors of the artworks:
+// crediting an artist with a role adds them to the authors of the artwork, and removing an
+// author drops their role. The order of the authors stays with the author field.
+func artworkAuthorsHook(app core.App) {
+	addAuthor := func(e *core.RecordEvent) error {
+		artwork, err := e.App.FindRecordById(constants.CollectionArtworks, e.Record.GetString("artwork"))
+		if err != nil {
+			return err
+		}
+
+		if artist := e.Record.GetString("artist"); !slices.Contains(artwork.GetStringSlice("author"), artist) {
+			artwork.Set("author+", artist)
+
+			if err := e.App.Save(artwork); err != nil {
+				return err
+			}
+		}
+
+		return e.Next()
+	}
+
+	dropRoles := func(e *core.RecordEvent) error {
+		authors := []any{}
+		for _, id := range e.Record.GetStringSlice("author") {
+			authors = append(authors, id)
+		}
+
+		stale, err := e.App.FindAllRecords(
+			constants.CollectionArtworkAuthors,
+			dbx.HashExp{"artwork": e.Record.Id},
+			dbx.Not(dbx.In("artist", authors...)),
+		)
+		if err != nil {
+			return err
+		}
+
+		for _, r := range stale {
+			if err := e.App.Delete(r); err != nil {
+				return err
+			}
+		}
+
+		return e.Next()
+	}
+
+	app.OnRecordCreateExecute(constants.CollectionArtworkAuthors).BindFunc(addAuthor)
+	app.OnRecordUpdateExecute(constants.CollectionArtworkAuthors).BindFunc(addAuthor)
+	app.OnRecordAfterUpdateSuccess(constants.CollectionArtworks).BindFunc(dropRoles)
+}
+
+// attributionEditorKey holds the email of the editor saving an artwork through the api for the
+// attribution history. It is kept with the record only, not stored.
+const attributionEditorKey = "@attributionEditor"
+
+// attributionHistoryHook writes an attribution change when the authors of an artwork change,
+// with the credits before and after, the citation of the new attribution when it was changed
+// in the same save, and the editor when the artwork was saved through the api. Reordering
+// the authors is not a reattribution.
+func attributionHistoryHook(app core.App) {
+	storedArtworkHook(app)
+
+	app.OnRecordUpdateRequest(constants.CollectionArtworks).BindFunc(func(e *core.RecordRequestEvent) error {
+		if e.Auth != nil {
+			e.Record.SetRaw(attributionEditorKey, e.Auth.Email())
+		}
+
+		return e.Next()
+	})
+
+	app.OnRecordUpdateExecute(constants.CollectionArtworks).BindFunc(func(e *core.RecordEvent) error {
+		stored := storedArtwork(e)
+
+		previous := stored.GetStringSlice("author")
+		current := e.Record.GetStringSlice("author")
+
+		if sameArtists(previous, current) {
+			return e.Next()
+		}
+
+		collection, err := e.App.FindCollectionByNameOrId(constants.CollectionAttributionChanges)
+		if err != nil {
+			return err
+		}
+
+		repo := repositories.NewArtworksRepository(e.App)
+
+		previousCredit, err := repo.AttributionCredit(e.Record.Id, previous)
+		if err != nil {
+			return err
+		}
+
+		newCredit, err := repo.AttributionCredit(e.Record.Id, current)
+		if err != nil {
+			return err
+		}
+
+		change := core.NewRecord(collection)
+		change.Set("artwork", e.Record.Id)
+		change.Set("previous_artists", previous)
+		change.Set("previous_credit", previousCredit)
+		change.Set("new_artists", current)
+		change.Set("new_credit", newCredit)
+		change.Set("editor", e.Record.GetString(attributionEditorKey))
+
+		if source := e.Record.GetString("attribution_source"); source != stored.GetString("attribution_source") {
+			change.Set("source", source)
+		}
+
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		return e.App.Save(change)
+	})
+}
+
+// storedArtworkKey holds the artwork as it is stored before an update, for the hooks comparing
+// the saved artwork with it. It is kept with the record only, not stored.
+const storedArtworkKey = "@storedArtwork"
+
+// storedArtworkHook loads the stored artwork once ahead of every update of an artwork. The
+// original of the record cannot be compared with, as it is not refreshed when a created record
+// is saved again. The handler is bound by id, so the hooks needing it can all register it.
+func storedArtworkHook(app core.App) {
+	app.OnRecordUpdateExecute(constants.CollectionArtworks).Bind(&hook.Handler[*core.RecordEvent]{
+		Id:       "storedArtwork",
+		Priority: -1,
+		Func: func(e *core.RecordEvent) error {
+			stored, err := e.App.FindRecordById(constants.CollectionArtworks, e.Record.Id)
+			if err != nil {
+				return err
+			}
+
+			e.Record.SetRaw(storedArtworkKey, stored)
+
+			return e.Next()
+		},
+	})
+}
+
+// storedArtwork returns the artwork loaded by storedArtworkHook for the update being executed.
+func storedArtwork(e *core.RecordEvent) *core.Record {
+	stored, _ := e.Record.GetRaw(storedArtworkKey).(*core.Record)
+
+	return stored
+}
+
+// sameArtists reports whether both lists hold the same artists, in any order.
+func sameArtists(a []string, b []string) bool {
+	a = slices.Sorted(slices.Values(a))
+	b = slices.Sorted(slices.Values(b))
+
+	return slices.Equal(a, b)
+}
@@ -0,0 +1,202 @@
+package hooks
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/blackfyre/wga/internal/testutils"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func TestArtworkAuthorsHookKeepsRolesWithTheAuthors(t *testing.T) {
+	app := testutils.NewTestApp(t)
+	artworkAuthorsHook(app)
+
+	artworks := newArtworkAuthorsTestCollections(t, app)
+
+	artwork := core.NewRecord(artworks)
+	artwork.Id = "artwork00000001"
+	artwork.Set("author", []string{"artist000000001"})
+	if err := app.Save(artwork); err != nil {
+		t.Fatalf("save artwork: %v", err)
+	}
+
+	artworkAuthors, err := app.FindCollectionByNameOrId("artwork_authors")
+	if err != nil {
+		t.Fatalf("find artwork authors collection: %v", err)
+	}
+
+	// crediting an artist with a role adds them to the authors
+	role := core.NewRecord(artworkAuthors)
+	role.Set("artwork", artwork.Id)
+	role.Set("artist", "artist000000002")
+	role.Set("role", "workshop")
+	if err := app.Save(role); err != nil {
+		t.Fatalf("save role: %v", err)
+	}
+
+	artwork, err = app.FindRecordById("artworks", artwork.Id)
+	if err != nil {
+		t.Fatalf("find artwork: %v", err)
+	}
+	if got := artwork.GetStringSlice("author"); !reflect.DeepEqual(got, []string{"artist000000001", "artist000000002"}) {
+		t.Fatalf("authors = %v", got)
+	}
+
+	// removing the author drops their role
+	artwork.Set("author", []string{"artist000000001"})
+	if err := app.Save(artwork); err != nil {
+		t.Fatalf("save artwork: %v", err)
+	}
+
+	if _, err := app.FindRecordById("artwork_authors", role.Id); err == nil {
+		t.Fatal("expected the role of the removed author to be dropped")
+	}
+}
+
+func TestAttributionHistoryHookRecordsReattributions(t *testing.T) {
+	app := testutils.NewTestApp(t)
+	artworkAuthorsHook(app)
+	attributionHistoryHook(app)
+
+	artworks := newArtworkAuthorsTestCollections(t, app)
+
+	artwork := core.NewRecord(artworks)
+	artwork.Id = "artwork00000001"
+	artwork.Set("author", []string{"artist000000001"})
+	if err := app.Save(artwork); err != nil {
+		t.Fatalf("save artwork: %v", err)
+	}
+
+	// saving the other fields is not a reattribution
+	artwork.Set("attribution_source", "Catalogue 1990")
+	if err := app.Save(artwork); err != nil {
+		t.Fatalf("save artwork: %v", err)
+	}
+
+	artwork.Set("author", []string{"artist000000002"})
+	artwork.Set("attribution_source", "Catalogue 2020, no. 12")
+	artwork.SetRaw(attributionEditorKey, "editor@example.com")
+	if err := app.Save(artwork); err != nil {
+		t.Fatalf("save artwork: %v", err)
+	}
+
+	// a later change without a new citation has no source
+	artwork.Set("author", []string{"artist000000002", "artist000000001"})
+	artwork.SetRaw(attributionEditorKey, "")
+	if err := app.Save(artwork); err != nil {
+		t.Fatalf("save artwork: %v", err)
+	}
+
+	changes, err := app.FindRecordsByFilter("attribution_changes", "artwork = 'artwork00000001'", "+created", 0, 0)
+	if err != nil {
+		t.Fatalf("find changes: %v", err)
+	}
+
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %d", len(changes))
+	}
+
+	first := changes[0]
+	if got := first.GetStringSlice("previous_artists"); !reflect.DeepEqual(got, []string{"artist000000001"}) {
+		t.Errorf("previous artists = %v", got)
+	}
+	if got := first.GetStringSlice("new_artists"); !reflect.DeepEqual(got, []string{"artist000000002"}) {
+		t.Errorf("new artists = %v", got)
+	}
+	if got := first.GetString("previous_credit") + " / " + first.GetString("new_credit"); got != "RUBENS, Peter Paul / DYCK, Anthony van" {
+		t.Errorf("credits = %q", got)
+	}
+	if got := first.GetString("source"); got != "Catalogue 2020, no. 12" {
+		t.Errorf("source = %q", got)
+	}
+	if got := first.GetString("editor"); got != "editor@example.com" {
+		t.Errorf("editor = %q", got)
+	}
+
+	second := changes[1]
+	if got := second.GetString("new_credit"); got != "DYCK, Anthony van; RUBENS, Peter Paul" {
+		t.Errorf("new credit = %q", got)
+	}
+	if second.GetString("source") != "" || second.GetString("editor") != "" {
+		t.Errorf("expected no source and editor, got %q and %q", second.GetString("source"), second.GetString("editor"))
+	}
+
+	// swapping the order of the authors is not a reattribution
+	artwork.Set("author", []string{"artist000000001", "artist000000002"})
+	if err := app.Save(artwork); err != nil {
+		t.Fatalf("save artwork: %v", err)
+	}
+
+	if total, err := app.CountRecords("attribution_changes"); err != nil || total != 2 {
+		t.Fatalf("expected the reorder not to be recorded, got %d changes (%v)", total, err)
+	}
+}
+
+func TestStoredArtworkHookIsBoundOnce(t *testing.T) {
+	app := testutils.NewTestApp(t)
+	before := app.OnRecordUpdateExecute().Length()
+
+	attributionHistoryHook(app)
+	seriesCoverHook(app)
+
+	// one handler each, and the stored artwork loaded once for both
+	if got := app.OnRecordUpdateExecute().Length() - before; got != 3 {
+		t.Fatalf("expected 3 update handlers, got %d", got)
+	}
+}
+
+// newArtworkAuthorsTestCollections creates the artists, artworks, artwork authors and attribution
+// changes collections with two artists, returning the artworks collection.
+func newArtworkAuthorsTestCollections(t *testing.T, app core.App) *core.Collection {
+	t.Helper()
+
+	artists := core.NewBaseCollection("Artists")
+	artists.Id = "artists"
+	artists.Fields.Add(&core.TextField{Name: "name"})
+
+	artworks := core.NewBaseCollection("Artworks")
+	artworks.Id = "artworks"
+	artworks.Fields.Add(
+		&core.RelationField{Name: "author", CollectionId: "artists", MaxSelect: 10},
+		&core.TextField{Name: "attribution_source"},
+	)
+
+	artworkAuthors := core.NewBaseCollection("Artwork_authors")
+	artworkAuthors.Id = "artwork_authors"
+	artworkAuthors.Fields.Add(
+		&core.RelationField{Name: "artwork", CollectionId: "artworks", MaxSelect: 1, CascadeDelete: true},
+		&core.RelationField{Name: "artist", CollectionId: "artists", MaxSelect: 1, CascadeDelete: true},
+		&core.TextField{Name: "role"},
+	)
+
+	attributionChanges := core.NewBaseCollection("Attribution_changes")
+	attributionChanges.Id = "attribution_changes"
+	attributionChanges.Fields.Add(
+		&core.RelationField{Name: "artwork", CollectionId: "artworks", MaxSelect: 1, CascadeDelete: true},
+		&core.RelationField{Name: "previous_artists", CollectionId: "artists", MaxSelect: 10},
+		&core.TextField{Name: "previous_credit"},
+		&core.RelationField{Name: "new_artists", CollectionId: "artists", MaxSelect: 10},
+		&core.TextField{Name: "new_credit"},
+		&core.TextField{Name: "source"},
+		&core.TextField{Name: "editor"},
+		&core.AutodateField{Name: "created", OnCreate: true},
+	)
+
+	for _, c := range []*core.Collection{artists, artworks, artworkAuthors, attributionChanges} {
+		if err := app.Save(c); err != nil {
+			t.Fatalf("save %s collection: %v", c.Name, err)
+		}
+	}
+
+	for id, name := range map[string]string{"artist000000001": "RUBENS, Peter Paul", "artist000000002": "DYCK, Anthony van"} {
+		artist := core.NewRecord(artists)
+		artist.Id = id
+		artist.Set("name", name)
+		if err := app.Save(artist); err != nil {
+			t.Fatalf("save artist: %v", err)
+		}
+	}
+
+	return artworks
+}
@@ -0,0 +1,86 @@
+package hooks
+
+import (
+	"image"
+
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/logging"
+	"github.com/blackfyre/wga/internal/utils/imagehash"
+	"github.com/blackfyre/wga/internal/utils/palette"
+	"github.com/disintegration/imaging"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+// artworkImageHook analyses artwork images as they are uploaded, decoding each image once:
+// it stores the hashes and the palette of the image with the record, and records the
+// artworks whose image is a near-duplicate of it. The upload goes through either way.
+func artworkImageHook(app core.App) {
+	analyse := func(e *core.RecordRequestEvent) error {
+		files := e.Record.GetUnsavedFiles("image")
+		if len(files) == 0 {
+			return e.Next()
+		}
+
+		img, err := decodeUpload(files[0].Reader)
+		if err != nil {
+			e.App.Logger().Warn("Uploaded image could not be decoded",
+				"event", "artwork.image.decode",
+				"record_id", e.Record.Id,
+				"outcome", "failed",
+				"error_type", logging.ErrorType(err),
+				"error", logging.Redact(err),
+			)
+			return e.Next()
+		}
+
+		palette.Set(e.Record, files[0].Name, palette.Extract(img))
+		recordDuplicates(e, files[0].Name, imagehash.Compute(img))
+
+		return e.Next()
+	}
+
+	app.OnRecordCreateRequest(constants.CollectionArtworks).BindFunc(analyse)
+	app.OnRecordUpdateRequest(constants.CollectionArtworks).BindFunc(analyse)
+}
+
+// recordDuplicates stores the hashes of the uploaded image with the record, and lists
+// the artworks whose image is a near-duplicate of it on the record, where the editors see
+// them in the admin UI. The near-duplicates are logged too.
+func recordDuplicates(e *core.RecordRequestEvent, image string, hashes imagehash.Hashes) {
+	imagehash.Set(e.Record, image, hashes)
+
+	matches, err := imagehash.FindNear(e.App, imagehash.PHash, hashes.P, imagehash.DefaultDistance, e.Record.Id)
+	if err != nil {
+		e.App.Logger().Error("Failed to look for duplicate images", "error", err.Error())
+		return
+	}
+
+	ids := make([]string, 0, len(matches))
+	for _, m := range matches[:min(len(matches), imagehash.MaxRecordedDuplicates)] {
+		ids = append(ids, m.Id)
+	}
+
+	e.Record.Set(imagehash.DuplicatesField, ids)
+
+	if len(matches) == 0 {
+		return
+	}
+
+	e.App.Logger().Warn("Uploaded image is a near-duplicate",
+		"event", "artwork.image_hash.duplicate",
+		"record_id", e.Record.Id,
+		"duplicate_ids", ids,
+		"distance", matches[0].Distance,
+	)
+}
+
+func decodeUpload(reader filesystem.FileReader) (image.Image, error) {
+	file, err := reader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return imaging.Decode(file)
+}
@@ -0,0 +1,139 @@
+package hooks
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"slices"
+	"testing"
+
+	"github.com/blackfyre/wga/internal/testutils"
+	"github.com/blackfyre/wga/internal/utils/imagehash"
+	"github.com/blackfyre/wga/internal/utils/palette"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+func TestArtworkImageHookAnalysesTheUpload(t *testing.T) {
+	app := newImagesTestApp(t)
+	artworkImageHook(app)
+
+	artworks, err := app.FindCollectionByNameOrId("artworks")
+	if err != nil {
+		t.Fatalf("find artworks collection: %v", err)
+	}
+
+	record := core.NewRecord(artworks)
+	record.Id = "artwork00000001"
+	record.Set("image", testUpload(t, "venus.png"))
+
+	saveThroughRequest(t, app, record)
+
+	saved, err := app.FindRecordById("artworks", record.Id)
+	if err != nil {
+		t.Fatalf("find artwork: %v", err)
+	}
+
+	if saved.GetString("image") == "" {
+		t.Fatal("expected the image to be saved")
+	}
+	if !palette.UpToDate(saved) || len(palette.FromRecord(saved)) == 0 {
+		t.Fatalf("expected the palette of the uploaded image, got %q from %q", saved.GetString(palette.Field), saved.GetString(palette.SourceField))
+	}
+	if !imagehash.UpToDate(saved) || saved.GetString(imagehash.PHashField) == "" {
+		t.Fatalf("expected the hashes of the uploaded image, got %q from %q", saved.GetString(imagehash.PHashField), saved.GetString(imagehash.SourceField))
+	}
+	if got := saved.GetStringSlice(imagehash.DuplicatesField); len(got) != 0 {
+		t.Fatalf("duplicates = %v, want none", got)
+	}
+
+	duplicate := core.NewRecord(artworks)
+	duplicate.Id = "artwork00000002"
+	duplicate.Set("image", testUpload(t, "venus-copy.png"))
+
+	saveThroughRequest(t, app, duplicate)
+
+	saved, err = app.FindRecordById("artworks", duplicate.Id)
+	if err != nil {
+		t.Fatalf("find artwork: %v", err)
+	}
+
+	if got := saved.GetStringSlice(imagehash.DuplicatesField); !slices.Equal(got, []string{record.Id}) {
+		t.Fatalf("duplicates = %v, want [%s]", got, record.Id)
+	}
+}
+
+// saveThroughRequest saves the record through the create request hooks, the way the
+// records API does.
+func saveThroughRequest(t *testing.T, app core.App, record *core.Record) {
+	t.Helper()
+
+	event := &core.RecordRequestEvent{RequestEvent: &core.RequestEvent{App: app}, Record: record}
+	event.Collection = record.Collection()
+
+	err := app.OnRecordCreateRequest(record.Collection().Name).Trigger(event, func(e *core.RecordRequestEvent) error {
+		return e.App.Save(e.Record)
+	})
+	if err != nil {
+		t.Fatalf("save %s through the request hooks: %v", record.Id, err)
+	}
+}
+
+// testUpload returns a small blue and white png to upload.
+func testUpload(t *testing.T, name string) *filesystem.File {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	for x := range 32 {
+		for y := range 32 {
+			c := color.RGBA{R: 30, G: 60, B: 200, A: 255}
+			if x > 24 {
+				c = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+
+	buf := bytes.Buffer{}
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode image: %v", err)
+	}
+
+	file, err := filesystem.NewFileFromBytes(buf.Bytes(), name)
+	if err != nil {
+		t.Fatalf("create upload: %v", err)
+	}
+
+	return file
+}
+
+func newImagesTestApp(t *testing.T) *tests.TestApp {
+	t.Helper()
+
+	app := testutils.NewTestApp(t)
+
+	artworks := core.NewBaseCollection("Artworks")
+	artworks.Id = "artworks"
+	artworks.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.FileField{Name: "image", MaxSelect: 1, MaxSize: 5 << 20},
+		&core.TextField{Name: imagehash.AHashField},
+		&core.TextField{Name: imagehash.DHashField},
+		&core.TextField{Name: imagehash.PHashField},
+		&core.TextField{Name: imagehash.SourceField},
+		&core.JSONField{Name: palette.Field},
+		&core.TextField{Name: palette.SourceField},
+	)
+	if err := app.Save(artworks); err != nil {
+		t.Fatalf("save artworks collection: %v", err)
+	}
+
+	artworks.Fields.Add(&core.RelationField{Name: imagehash.DuplicatesField, CollectionId: artworks.Id, MaxSelect: imagehash.MaxRecordedDuplicates})
+	if err := app.Save(artworks); err != nil {
+		t.Fatalf("save artworks duplicates: %v", err)
+	}
+
+	return app
+}
@@ -0,0 +1,22 @@
+package hooks
+
+import (
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// locationSlugHook gives the locations without a slug one made of their name and city. A slug
+// is kept when the location is renamed, so its url stays the same.
+func locationSlugHook(app core.App) {
+	setSlug := func(e *core.RecordEvent) error {
+		if e.Record.GetString("slug") == "" {
+			e.Record.Set("slug", repositories.LocationSlug(e.Record.GetString("name"), e.Record.GetString("city")))
+		}
+
+		return e.Next()
+	}
+
+	app.OnRecordCreate(constants.CollectionLocations).BindFunc(setSlug)
+	app.OnRecordUpdate(constants.CollectionLocations).BindFunc(setSlug)
+}
@@ -4,6 +4,16 @@ import "github.com/pocketbase/pocketbase/core"
 
 func RegisterHooks(app core.App) {
 	app.Logger().Debug("Registering hooks...")
+	artistNameSlugHook(app)
+	artistRelationshipHook(app)
+	artistSortNameHook(app)
+	artworkAuthorsHook(app)
+	artworkImageHook(app)
+	attributionHistoryHook(app)
 	fileDownloadHook(app)
 	guestbookYearsCacheHook(app)
+	locationSlugHook(app)
+	searchIndexHook(app)
+	seriesCoverHook(app)
+	tilePyramidHook(app)
 }
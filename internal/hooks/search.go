@@ -0,0 +1,71 @@
+package hooks
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/logging"
+	"github.com/blackfyre/wga/internal/utils/fulltext"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// searchIndexHook keeps the full-text search index in step with the indexed collections.
+func searchIndexHook(app core.App) {
+	reindex := func(e *core.RecordEvent) error {
+		if fulltext.IndexAvailable(e.App) {
+			if err := fulltext.IndexRecord(e.App, e.Record); err != nil {
+				logSearchIndexFailure(e.App, e.Record, err)
+			}
+		}
+
+		return e.Next()
+	}
+
+	remove := func(e *core.RecordEvent) error {
+		if kind, ok := fulltext.KindForCollection(e.Record.Collection()); ok && fulltext.IndexAvailable(e.App) {
+			if err := fulltext.RemoveRecord(e.App, kind, e.Record.Id); err != nil {
+				logSearchIndexFailure(e.App, e.Record, err)
+			}
+		}
+
+		return e.Next()
+	}
+
+	// the other names of an artist are indexed with the artist
+	reindexArtist := func(e *core.RecordEvent) error {
+		if fulltext.IndexAvailable(e.App) {
+			artist, err := e.App.FindRecordById(constants.CollectionArtists, e.Record.GetString("artist"))
+			if err == nil {
+				err = fulltext.IndexRecord(e.App, artist)
+			}
+			// the artist itself was deleted
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				logSearchIndexFailure(e.App, e.Record, err)
+			}
+		}
+
+		return e.Next()
+	}
+
+	for _, collection := range []string{constants.CollectionArtworks, constants.CollectionArtists, constants.CollectionGlossary} {
+		app.OnRecordAfterCreateSuccess(collection).BindFunc(reindex)
+		app.OnRecordAfterUpdateSuccess(collection).BindFunc(reindex)
+		app.OnRecordAfterDeleteSuccess(collection).BindFunc(remove)
+	}
+
+	app.OnRecordAfterCreateSuccess(constants.CollectionArtistNames).BindFunc(reindexArtist)
+	app.OnRecordAfterUpdateSuccess(constants.CollectionArtistNames).BindFunc(reindexArtist)
+	app.OnRecordAfterDeleteSuccess(constants.CollectionArtistNames).BindFunc(reindexArtist)
+}
+
+func logSearchIndexFailure(app core.App, record *core.Record, err error) {
+	app.Logger().Warn("Search index update failed",
+		"event", "search.index.update",
+		"collection", record.Collection().Name,
+		"record_id", record.Id,
+		"outcome", "failed",
+		"error_type", logging.ErrorType(err),
+		"error", logging.Redact(err),
+	)
+}
@@ -0,0 +1,65 @@
+package hooks
+
+import (
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// seriesCoverHook keeps the cover of a series one of its published parts when an artwork joins
+// or leaves the series, is moved, published or withdrawn, or is deleted, so the collapsed
+// artwork search shows every series once.
+func seriesCoverHook(app core.App) {
+	storedArtworkHook(app)
+
+	refresh := func(app core.App, seriesIds ...string) error {
+		repo := repositories.NewSeriesRepository(app)
+
+		for _, id := range seriesIds {
+			if id == "" {
+				continue
+			}
+
+			if err := repo.RefreshCover(id); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	app.OnRecordCreateExecute(constants.CollectionArtworks).BindFunc(func(e *core.RecordEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		return refresh(e.App, e.Record.GetString("series"))
+	})
+
+	app.OnRecordUpdateExecute(constants.CollectionArtworks).BindFunc(func(e *core.RecordEvent) error {
+		stored := storedArtwork(e)
+
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		previous := stored.GetString("series")
+		current := e.Record.GetString("series")
+
+		if previous == current {
+			return refresh(e.App, current)
+		}
+
+		return refresh(e.App, previous, current)
+	})
+
+	app.OnRecordDeleteExecute(constants.CollectionArtworks).BindFunc(func(e *core.RecordEvent) error {
+		series := e.Record.GetString("series")
+
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		return refresh(e.App, series)
+	})
+}
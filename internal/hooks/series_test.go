@@ -0,0 +1,127 @@
+package hooks
+
+import (
+	"testing"
+
+	"github.com/blackfyre/wga/internal/testutils"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func TestSeriesCoverHookKeepsACoverAmongTheParts(t *testing.T) {
+	app := testutils.NewTestApp(t)
+	seriesCoverHook(app)
+
+	artists := core.NewBaseCollection("Artists")
+	artists.Id = "artists"
+	if err := app.Save(artists); err != nil {
+		t.Fatalf("save artists collection: %v", err)
+	}
+
+	series := core.NewBaseCollection("Series")
+	series.Id = "series"
+	series.Fields.Add(&core.TextField{Name: "title"})
+	if err := app.Save(series); err != nil {
+		t.Fatalf("save series collection: %v", err)
+	}
+
+	artworks := core.NewBaseCollection("Artworks")
+	artworks.Id = "artworks"
+	artworks.Fields.Add(
+		&core.BoolField{Name: "published"},
+		&core.RelationField{Name: "author", CollectionId: "artists", MaxSelect: 10},
+		&core.RelationField{Name: "series", CollectionId: "series", MaxSelect: 1},
+		&core.NumberField{Name: "series_position", OnlyInt: true},
+		&core.AutodateField{Name: "created", OnCreate: true},
+	)
+	if err := app.Save(artworks); err != nil {
+		t.Fatalf("save artworks collection: %v", err)
+	}
+
+	series.Fields.Add(&core.RelationField{Name: "cover", CollectionId: "artworks", MaxSelect: 1})
+	if err := app.Save(series); err != nil {
+		t.Fatalf("save series collection: %v", err)
+	}
+
+	artist := core.NewRecord(artists)
+	artist.Id = "artist000000001"
+	if err := app.Save(artist); err != nil {
+		t.Fatalf("save artist: %v", err)
+	}
+
+	for _, id := range []string{"series000000001", "series000000002"} {
+		record := core.NewRecord(series)
+		record.Id = id
+		if err := app.Save(record); err != nil {
+			t.Fatalf("save series: %v", err)
+		}
+	}
+
+	assertCover := func(seriesId string, want string) {
+		t.Helper()
+
+		record, err := app.FindRecordById("series", seriesId)
+		if err != nil {
+			t.Fatalf("find series: %v", err)
+		}
+
+		if got := record.GetString("cover"); got != want {
+			t.Fatalf("cover of %s = %q, want %q", seriesId, got, want)
+		}
+	}
+
+	parts := map[string]*core.Record{}
+	for _, values := range []struct {
+		id       string
+		position int
+	}{{"artwork00000002", 2}, {"artwork00000001", 1}} {
+		part := core.NewRecord(artworks)
+		part.Id = values.id
+		part.Set("published", true)
+		part.Set("author", []string{artist.Id})
+		part.Set("series", "series000000001")
+		part.Set("series_position", values.position)
+		if err := app.Save(part); err != nil {
+			t.Fatalf("save part: %v", err)
+		}
+
+		parts[part.Id] = part
+	}
+
+	// the first part added becomes the cover and stays it when a part is put before it
+	assertCover("series000000001", "artwork00000002")
+
+	// the cover moving to another series leaves the first part the cover of the series
+	parts["artwork00000002"].Set("series", "series000000002")
+	if err := app.Save(parts["artwork00000002"]); err != nil {
+		t.Fatalf("save part: %v", err)
+	}
+
+	assertCover("series000000001", "artwork00000001")
+	assertCover("series000000002", "artwork00000002")
+
+	// a withdrawn cover is replaced
+	parts["artwork00000002"].Set("series", "series000000001")
+	if err := app.Save(parts["artwork00000002"]); err != nil {
+		t.Fatalf("save part: %v", err)
+	}
+
+	parts["artwork00000001"].Set("published", false)
+	if err := app.Save(parts["artwork00000001"]); err != nil {
+		t.Fatalf("save part: %v", err)
+	}
+
+	assertCover("series000000001", "artwork00000002")
+	assertCover("series000000002", "")
+
+	// a deleted cover is replaced
+	parts["artwork00000001"].Set("published", true)
+	if err := app.Save(parts["artwork00000001"]); err != nil {
+		t.Fatalf("save part: %v", err)
+	}
+
+	if err := app.Delete(parts["artwork00000002"]); err != nil {
+		t.Fatalf("delete part: %v", err)
+	}
+
+	assertCover("series000000001", "artwork00000001")
+}
@@ -0,0 +1,84 @@
+package hooks
+
+import (
+	"sync"
+
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/logging"
+	"github.com/blackfyre/wga/internal/utils/dzi"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// tileQueueSize bounds the artworks waiting for a pyramid. Artworks that do not
+// fit are picked up by the tiles cron job.
+const tileQueueSize = 64
+
+// tilePyramidHook queues a deep zoom pyramid build when an artwork gets a new image.
+// Pyramids are built one at a time by a background worker, outside the request. The
+// worker runs while the app serves, with the app rather than the app of the event
+// queueing the build, which can be a transaction that ends before the build.
+func tilePyramidHook(app core.App) {
+	queue := make(chan string, tileQueueSize)
+	done := make(chan struct{})
+	var stop sync.Once
+
+	app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		go buildTilePyramids(app, queue, done)
+
+		return e.Next()
+	})
+
+	app.OnTerminate().BindFunc(func(e *core.TerminateEvent) error {
+		stop.Do(func() { close(done) })
+
+		return e.Next()
+	})
+
+	enqueue := func(e *core.RecordEvent) error {
+		if e.Record.GetString("image") != "" && !dzi.UpToDate(e.Record) {
+			select {
+			case queue <- e.Record.Id:
+			default:
+				e.App.Logger().Warn("Tile pyramid queue is full",
+					"event", "artwork.tiles.queue",
+					"record_id", e.Record.Id,
+					"outcome", "skipped",
+				)
+			}
+		}
+
+		return e.Next()
+	}
+
+	app.OnRecordAfterCreateSuccess(constants.CollectionArtworks).BindFunc(enqueue)
+	app.OnRecordAfterUpdateSuccess(constants.CollectionArtworks).BindFunc(enqueue)
+}
+
+// buildTilePyramids builds the pyramids of the queued artworks until done is closed.
+func buildTilePyramids(app core.App, queue <-chan string, done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case id := <-queue:
+			record, err := app.FindRecordById(constants.CollectionArtworks, id)
+			if err != nil || dzi.UpToDate(record) || record.GetString("image") == "" {
+				continue
+			}
+
+			if err := dzi.Generate(app, record); err != nil {
+				logTilePyramidFailure(app, record, err)
+			}
+		}
+	}
+}
+
+func logTilePyramidFailure(app core.App, record *core.Record, err error) {
+	app.Logger().Warn("Tile pyramid build failed",
+		"event", "artwork.tiles.build",
+		"record_id", record.Id,
+		"outcome", "failed",
+		"error_type", logging.ErrorType(err),
+		"error", logging.Redact(err),
+	)
+}
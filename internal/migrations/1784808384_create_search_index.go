@@ -0,0 +1,15 @@
+package migrations
+
+import (
+	"github.com/blackfyre/wga/internal/utils/fulltext"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		return fulltext.Rebuild(app)
+	}, func(app core.App) error {
+		return fulltext.DropIndex(app)
+	})
+}
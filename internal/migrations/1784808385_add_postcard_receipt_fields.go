@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("postcards")
+		if err != nil {
+			return err
+		}
+
+		collection.Fields.Add(
+			&core.JSONField{
+				Id:   "postcard_opened_at",
+				Name: "opened_at",
+			},
+			&core.DateField{
+				Id:   "postcard_receipt_sent_at",
+				Name: "receipt_sent_at",
+			},
+		)
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("postcards")
+		if err != nil {
+			return err
+		}
+
+		collection.Fields.RemoveById("postcard_opened_at")
+		collection.Fields.RemoveById("postcard_receipt_sent_at")
+
+		return app.Save(collection)
+	})
+}
@@ -0,0 +1,105 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		postcards, err := app.FindCollectionByNameOrId("postcards")
+		if err != nil {
+			return err
+		}
+
+		if status, ok := postcards.Fields.GetById("postcard_status").(*core.SelectField); ok {
+			status.Values = []string{"queued", "sent", "received", "failed"}
+		}
+
+		if err := app.Save(postcards); err != nil {
+			return err
+		}
+
+		collection := core.NewBaseCollection("Postcard_deliveries")
+
+		collection.Name = "Postcard_deliveries"
+		collection.Id = "postcard_deliveries"
+		collection.MarkAsNew()
+
+		collection.Fields.Add(
+			&core.RelationField{
+				Id:            "postcard_delivery_postcard",
+				Name:          "postcard",
+				CollectionId:  postcards.Id,
+				CascadeDelete: true,
+				MinSelect:     1,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.TextField{
+				Id:          "postcard_delivery_recipient",
+				Name:        "recipient",
+				Required:    true,
+				Presentable: true,
+			},
+			&core.NumberField{
+				Id:      "postcard_delivery_position",
+				Name:    "position",
+				OnlyInt: true,
+			},
+			&core.SelectField{
+				Id:          "postcard_delivery_status",
+				Name:        "status",
+				Values:      []string{"pending", "sent", "failed"},
+				MaxSelect:   1,
+				Required:    true,
+				Presentable: true,
+			},
+			&core.NumberField{
+				Id:      "postcard_delivery_attempts",
+				Name:    "attempts",
+				OnlyInt: true,
+			},
+			&core.TextField{
+				Id:   "postcard_delivery_last_error_type",
+				Name: "last_error_type",
+			},
+			&core.DateField{
+				Id:   "postcard_delivery_next_attempt_at",
+				Name: "next_attempt_at",
+			},
+			&core.DateField{
+				Id:   "postcard_delivery_sent_at",
+				Name: "sent_at",
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+			&core.AutodateField{
+				Name:     "updated",
+				OnCreate: true,
+				OnUpdate: true,
+			},
+		)
+
+		collection.AddIndex("pbx_postcard_delivery_postcard", false, "postcard, position", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		if err := deleteCollection(app, "postcard_deliveries"); err != nil {
+			return err
+		}
+
+		postcards, err := app.FindCollectionByNameOrId("postcards")
+		if err != nil {
+			return err
+		}
+
+		if status, ok := postcards.Fields.GetById("postcard_status").(*core.SelectField); ok {
+			status.Values = []string{"queued", "sent", "received"}
+		}
+
+		return app.Save(postcards)
+	})
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("artworks")
+		if err != nil {
+			return err
+		}
+
+		collection.Fields.Add(&core.TextField{
+			Id:   "artworks_tiles_image",
+			Name: "tiles_image",
+		})
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("artworks")
+		if err != nil {
+			return err
+		}
+
+		collection.Fields.RemoveById("artworks_tiles_image")
+
+		return app.Save(collection)
+	})
+}
@@ -0,0 +1,159 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		favourites := core.NewBaseCollection("Favourites")
+
+		favourites.Name = "Favourites"
+		favourites.Id = "favourites"
+		favourites.MarkAsNew()
+
+		favourites.Fields.Add(
+			&core.RelationField{
+				Id:            "favourite_user",
+				Name:          "user",
+				CollectionId:  users.Id,
+				CascadeDelete: true,
+				MinSelect:     1,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.RelationField{
+				Id:            "favourite_artwork",
+				Name:          "artwork",
+				CollectionId:  "artworks",
+				CascadeDelete: true,
+				MinSelect:     1,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+		)
+
+		favourites.AddIndex("pbx_favourite_user_artwork", true, "user, artwork", "")
+
+		if err := app.Save(favourites); err != nil {
+			return err
+		}
+
+		collections := core.NewBaseCollection("User_collections")
+
+		collections.Name = "User_collections"
+		collections.Id = "user_collections"
+		collections.MarkAsNew()
+
+		collections.Fields.Add(
+			&core.RelationField{
+				Id:            "user_collection_owner",
+				Name:          "owner",
+				CollectionId:  users.Id,
+				CascadeDelete: true,
+				MinSelect:     1,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.TextField{
+				Id:          "user_collection_name",
+				Name:        "name",
+				Required:    true,
+				Max:         100,
+				Presentable: true,
+			},
+			&core.TextField{
+				Id:   "user_collection_description",
+				Name: "description",
+				Max:  2000,
+			},
+			&core.BoolField{
+				Id:   "user_collection_public",
+				Name: "public",
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+			&core.AutodateField{
+				Name:     "updated",
+				OnCreate: true,
+				OnUpdate: true,
+			},
+		)
+
+		collections.AddIndex("pbx_user_collection_owner", false, "owner", "")
+
+		if err := app.Save(collections); err != nil {
+			return err
+		}
+
+		items := core.NewBaseCollection("User_collection_items")
+
+		items.Name = "User_collection_items"
+		items.Id = "user_collection_items"
+		items.MarkAsNew()
+
+		items.Fields.Add(
+			&core.RelationField{
+				Id:            "user_collection_item_collection",
+				Name:          "collection",
+				CollectionId:  collections.Id,
+				CascadeDelete: true,
+				MinSelect:     1,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.RelationField{
+				Id:            "user_collection_item_artwork",
+				Name:          "artwork",
+				CollectionId:  "artworks",
+				CascadeDelete: true,
+				MinSelect:     1,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.NumberField{
+				Id:      "user_collection_item_position",
+				Name:    "position",
+				OnlyInt: true,
+			},
+			&core.TextField{
+				Id:   "user_collection_item_note",
+				Name: "note",
+				Max:  1000,
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+			&core.AutodateField{
+				Name:     "updated",
+				OnCreate: true,
+				OnUpdate: true,
+			},
+		)
+
+		items.AddIndex("pbx_user_collection_item_artwork", true, "collection, artwork", "")
+
+		return app.Save(items)
+	}, func(app core.App) error {
+		for _, name := range []string{"user_collection_items", "user_collections", "favourites"} {
+			if err := deleteCollection(app, name); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
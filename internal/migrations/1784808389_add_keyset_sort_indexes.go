@@ -0,0 +1,52 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// keysetSortIndexes back the keyset pagination of the artwork search and the artist list,
+// which order by title and name, then by id. The artist list orders by sort name since
+// 1784808394_add_artist_sort_fields.go, which replaces the artist index.
+var keysetSortIndexes = []struct {
+	collection string
+	name       string
+	columns    string
+}{
+	{"artworks", "pbx_artwork_title_id", "title, id"},
+	{"artists", "pbx_artist_name_id", "name, id"},
+}
+
+func init() {
+	m.Register(func(app core.App) error {
+		for _, i := range keysetSortIndexes {
+			collection, err := app.FindCollectionByNameOrId(i.collection)
+			if err != nil {
+				return err
+			}
+
+			collection.AddIndex(i.name, false, i.columns, "")
+
+			if err := app.Save(collection); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func(app core.App) error {
+		for _, i := range keysetSortIndexes {
+			collection, err := app.FindCollectionByNameOrId(i.collection)
+			if err != nil {
+				return err
+			}
+
+			collection.RemoveIndex(i.name)
+
+			if err := app.Save(collection); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
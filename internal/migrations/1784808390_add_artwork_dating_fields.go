@@ -0,0 +1,98 @@
+package migrations
+
+import (
+	"github.com/blackfyre/wga/internal/utils/dating"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("artworks")
+		if err != nil {
+			return err
+		}
+
+		collection.Fields.Add(
+			&core.NumberField{
+				Id:      "artworks_date_earliest",
+				Name:    dating.FieldEarliest,
+				OnlyInt: true,
+			},
+			&core.NumberField{
+				Id:      "artworks_date_latest",
+				Name:    dating.FieldLatest,
+				OnlyInt: true,
+			},
+			&core.BoolField{
+				Id:   "artworks_date_circa",
+				Name: dating.FieldCirca,
+			},
+			&core.TextField{
+				Id:   "artworks_date_display",
+				Name: dating.FieldDisplay,
+			},
+		)
+
+		collection.AddIndex("pbx_artwork_dating", false, dating.FieldEarliest+", "+dating.FieldLatest, "")
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		return backfillArtworkDating(app)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("artworks")
+		if err != nil {
+			return err
+		}
+
+		collection.RemoveIndex("pbx_artwork_dating")
+
+		for _, id := range []string{"artworks_date_earliest", "artworks_date_latest", "artworks_date_circa", "artworks_date_display"} {
+			collection.Fields.RemoveById(id)
+		}
+
+		return app.Save(collection)
+	})
+}
+
+// backfillArtworkDating dates the artworks from their comment, or their technique
+// when the comment has no date. Artworks without a recognisable date stay undated.
+func backfillArtworkDating(app core.App) error {
+	rows := []struct {
+		Id        string `db:"id"`
+		Comment   string `db:"comment"`
+		Technique string `db:"technique"`
+	}{}
+
+	err := app.DB().Select("id", "comment", "technique").From("artworks").All(&rows)
+	if err != nil {
+		return err
+	}
+
+	return app.RunInTransaction(func(txApp core.App) error {
+		for _, row := range rows {
+			d, ok := dating.Parse(row.Comment)
+			if !ok {
+				d, ok = dating.Parse(row.Technique)
+			}
+			if !ok {
+				continue
+			}
+
+			_, err := txApp.DB().Update("artworks", dbx.Params{
+				dating.FieldEarliest: d.Earliest,
+				dating.FieldLatest:   d.Latest,
+				dating.FieldCirca:    d.Circa,
+				dating.FieldDisplay:  d.Display,
+			}, dbx.HashExp{"id": row.Id}).Execute()
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
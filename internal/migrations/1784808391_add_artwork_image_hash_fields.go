@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"github.com/blackfyre/wga/internal/utils/imagehash"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("artworks")
+		if err != nil {
+			return err
+		}
+
+		for _, name := range []string{imagehash.AHashField, imagehash.DHashField, imagehash.PHashField, imagehash.SourceField} {
+			collection.Fields.Add(&core.TextField{
+				Id:   "artworks_" + name,
+				Name: name,
+			})
+		}
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("artworks")
+		if err != nil {
+			return err
+		}
+
+		for _, name := range []string{imagehash.AHashField, imagehash.DHashField, imagehash.PHashField, imagehash.SourceField} {
+			collection.Fields.RemoveById("artworks_" + name)
+		}
+
+		return app.Save(collection)
+	})
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"github.com/blackfyre/wga/internal/utils/palette"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("artworks")
+		if err != nil {
+			return err
+		}
+
+		collection.Fields.Add(
+			&core.JSONField{
+				Id:   "artworks_palette",
+				Name: palette.Field,
+			},
+			&core.TextField{
+				Id:   "artworks_palette_image",
+				Name: palette.SourceField,
+			},
+		)
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("artworks")
+		if err != nil {
+			return err
+		}
+
+		collection.Fields.RemoveById("artworks_palette")
+		collection.Fields.RemoveById("artworks_palette_image")
+
+		return app.Save(collection)
+	})
+}
@@ -0,0 +1,107 @@
+package migrations
+
+import (
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection := core.NewBaseCollection("Artist_names")
+
+		collection.Name = "Artist_names"
+		collection.Id = "artist_names"
+		collection.MarkAsNew()
+
+		collection.Fields.Add(
+			&core.RelationField{
+				Id:            "artist_name_artist",
+				Name:          "artist",
+				CollectionId:  "artists",
+				CascadeDelete: true,
+				MinSelect:     1,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.TextField{
+				Id:          "artist_name_name",
+				Name:        "name",
+				Required:    true,
+				Max:         200,
+				Presentable: true,
+			},
+			&core.TextField{
+				Id:      "artist_name_lang",
+				Name:    "lang",
+				Max:     35,
+				Pattern: `^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`,
+			},
+			&core.SelectField{
+				Id:        "artist_name_kind",
+				Name:      "kind",
+				Required:  true,
+				MaxSelect: 1,
+				Values:    []string{repositories.ArtistNameAlias, repositories.ArtistNameVariant, repositories.ArtistNameSortKey},
+			},
+			&core.TextField{
+				Id:     "artist_name_slug",
+				Name:   "slug",
+				Max:    200,
+				Hidden: true,
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+		)
+
+		collection.AddIndex("pbx_artist_name_artist", false, "artist", "")
+		collection.AddIndex("pbx_artist_name_slug", false, "slug", "")
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		return backfillArtistNames(app, collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("artist_names")
+		if err != nil {
+			return err
+		}
+
+		return app.Delete(collection)
+	})
+}
+
+// backfillArtistNames turns the also_known_as relations of the artists into aliases,
+// named after the related artists.
+func backfillArtistNames(app core.App, collection *core.Collection) error {
+	artists, err := app.FindRecordsByFilter("artists", "also_known_as != ''", "", 0, 0)
+	if err != nil {
+		return err
+	}
+
+	return app.RunInTransaction(func(txApp core.App) error {
+		for _, artist := range artists {
+			for _, id := range artist.GetStringSlice("also_known_as") {
+				aka, err := txApp.FindRecordById("artists", id)
+				if err != nil || aka.Id == artist.Id || aka.GetString("name") == "" {
+					continue
+				}
+
+				record := core.NewRecord(collection)
+				record.Set("artist", artist.Id)
+				record.Set("name", aka.GetString("name"))
+				record.Set("kind", repositories.ArtistNameAlias)
+				record.Set("slug", repositories.ArtistNameSlug(aka.GetString("name")))
+
+				if err := txApp.Save(record); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
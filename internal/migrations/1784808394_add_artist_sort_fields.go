@@ -0,0 +1,84 @@
+package migrations
+
+import (
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("artists")
+		if err != nil {
+			return err
+		}
+
+		collection.Fields.Add(
+			&core.TextField{
+				Id:   "artist_sort_name",
+				Name: "sort_name",
+			},
+			&core.TextField{
+				Id:   "artist_letter",
+				Name: "letter",
+				Max:  1,
+			},
+		)
+
+		// the artist list is paged by sort name instead of name, and the index counts artists by letter
+		collection.RemoveIndex("pbx_artist_name_id")
+		collection.AddIndex("pbx_artist_sort_name_id", false, "sort_name, id", "")
+		collection.AddIndex("pbx_artist_letter", false, "letter", "")
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		return backfillArtistSortNames(app)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("artists")
+		if err != nil {
+			return err
+		}
+
+		collection.RemoveIndex("pbx_artist_sort_name_id")
+		collection.RemoveIndex("pbx_artist_letter")
+		collection.AddIndex("pbx_artist_name_id", false, "name, id", "")
+
+		for _, id := range []string{"artist_sort_name", "artist_letter"} {
+			collection.Fields.RemoveById(id)
+		}
+
+		return app.Save(collection)
+	})
+}
+
+// backfillArtistSortNames sets the sort name and index letter of the existing artists.
+func backfillArtistSortNames(app core.App) error {
+	rows := []struct {
+		Id   string `db:"id"`
+		Name string `db:"name"`
+	}{}
+
+	err := app.DB().Select("id", "name").From("artists").All(&rows)
+	if err != nil {
+		return err
+	}
+
+	return app.RunInTransaction(func(txApp core.App) error {
+		for _, row := range rows {
+			sortName := repositories.ArtistSortName(row.Name)
+
+			_, err := txApp.DB().Update("artists", dbx.Params{
+				"sort_name": sortName,
+				"letter":    repositories.ArtistLetter(sortName),
+			}, dbx.HashExp{"id": row.Id}).Execute()
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
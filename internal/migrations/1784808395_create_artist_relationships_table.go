@@ -0,0 +1,70 @@
+package migrations
+
+import (
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection := core.NewBaseCollection("Artist_relationships")
+
+		collection.Name = "Artist_relationships"
+		collection.Id = "artist_relationships"
+		collection.MarkAsNew()
+
+		collection.Fields.Add(
+			&core.RelationField{
+				Id:            "artist_relationship_artist",
+				Name:          "artist",
+				CollectionId:  "artists",
+				CascadeDelete: true,
+				MinSelect:     1,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.SelectField{
+				Id:        "artist_relationship_type",
+				Name:      "type",
+				Required:  true,
+				MaxSelect: 1,
+				Values:    repositories.RelationshipTypeValues(),
+			},
+			&core.RelationField{
+				Id:            "artist_relationship_related_artist",
+				Name:          "related_artist",
+				CollectionId:  "artists",
+				CascadeDelete: true,
+				MinSelect:     1,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.TextField{
+				Id:   "artist_relationship_source",
+				Name: "source",
+				Max:  500,
+			},
+			&core.URLField{
+				Id:   "artist_relationship_source_url",
+				Name: "source_url",
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+		)
+
+		collection.AddIndex("pbx_artist_relationship_unique", true, "artist, type, related_artist", "")
+		collection.AddIndex("pbx_artist_relationship_related", false, "related_artist", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("artist_relationships")
+		if err != nil {
+			return err
+		}
+
+		return app.Delete(collection)
+	})
+}
@@ -0,0 +1,60 @@
+package migrations
+
+import (
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection := core.NewBaseCollection("Artwork_authors")
+
+		collection.Name = "Artwork_authors"
+		collection.Id = "artwork_authors"
+		collection.MarkAsNew()
+
+		collection.Fields.Add(
+			&core.RelationField{
+				Id:            "artwork_author_artwork",
+				Name:          "artwork",
+				CollectionId:  "artworks",
+				CascadeDelete: true,
+				MinSelect:     1,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.RelationField{
+				Id:            "artwork_author_artist",
+				Name:          "artist",
+				CollectionId:  "artists",
+				CascadeDelete: true,
+				MinSelect:     1,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.SelectField{
+				Id:        "artwork_author_role",
+				Name:      "role",
+				Required:  true,
+				MaxSelect: 1,
+				Values:    repositories.AttributionRoleValues(),
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+		)
+
+		collection.AddIndex("pbx_artwork_author_unique", true, "artwork, artist", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("artwork_authors")
+		if err != nil {
+			return err
+		}
+
+		return app.Delete(collection)
+	})
+}
@@ -0,0 +1,96 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		artworks, err := app.FindCollectionByNameOrId("artworks")
+		if err != nil {
+			return err
+		}
+
+		artworks.Fields.Add(&core.TextField{
+			Id:   "artworks_attribution_source",
+			Name: "attribution_source",
+		})
+
+		if err := app.Save(artworks); err != nil {
+			return err
+		}
+
+		collection := core.NewBaseCollection("Attribution_changes")
+
+		collection.Name = "Attribution_changes"
+		collection.Id = "attribution_changes"
+		collection.MarkAsNew()
+
+		collection.Fields.Add(
+			&core.RelationField{
+				Id:            "attribution_change_artwork",
+				Name:          "artwork",
+				CollectionId:  "artworks",
+				CascadeDelete: true,
+				MinSelect:     1,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.RelationField{
+				Id:           "attribution_change_previous_artists",
+				Name:         "previous_artists",
+				CollectionId: "artists",
+				MaxSelect:    10,
+			},
+			&core.TextField{
+				Id:   "attribution_change_previous_credit",
+				Name: "previous_credit",
+			},
+			&core.RelationField{
+				Id:           "attribution_change_new_artists",
+				Name:         "new_artists",
+				CollectionId: "artists",
+				MaxSelect:    10,
+			},
+			&core.TextField{
+				Id:   "attribution_change_new_credit",
+				Name: "new_credit",
+			},
+			&core.TextField{
+				Id:   "attribution_change_source",
+				Name: "source",
+			},
+			&core.TextField{
+				Id:   "attribution_change_editor",
+				Name: "editor",
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+		)
+
+		collection.AddIndex("pbx_attribution_change_artwork", false, "artwork, created", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("attribution_changes")
+		if err != nil {
+			return err
+		}
+
+		if err := app.Delete(collection); err != nil {
+			return err
+		}
+
+		artworks, err := app.FindCollectionByNameOrId("artworks")
+		if err != nil {
+			return err
+		}
+
+		artworks.Fields.RemoveById("artworks_attribution_source")
+
+		return app.Save(artworks)
+	})
+}
@@ -0,0 +1,117 @@
+package migrations
+
+import (
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection := core.NewBaseCollection("Locations")
+
+		collection.Name = "Locations"
+		collection.Id = "locations"
+		collection.MarkAsNew()
+
+		collection.Fields.Add(
+			&core.TextField{
+				Id:          "location_name",
+				Name:        "name",
+				Required:    true,
+				Presentable: true,
+			},
+			&core.TextField{
+				Id:   "location_slug",
+				Name: "slug",
+			},
+			&core.SelectField{
+				Id:        "location_kind",
+				Name:      "kind",
+				MaxSelect: 1,
+				Values:    repositories.LocationKindValues(),
+			},
+			&core.TextField{
+				Id:       "location_city",
+				Name:     "city",
+				Required: true,
+			},
+			&core.TextField{
+				Id:   "location_country",
+				Name: "country",
+			},
+			&core.URLField{
+				Id:   "location_website",
+				Name: "website",
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+			&core.AutodateField{
+				Name:     "updated",
+				OnCreate: true,
+				OnUpdate: true,
+			},
+		)
+
+		collection.AddIndex("pbx_location_slug", true, "slug", "slug != ''")
+		collection.AddIndex("pbx_location_city", false, "city", "")
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		artworks, err := app.FindCollectionByNameOrId("artworks")
+		if err != nil {
+			return err
+		}
+
+		artworks.Fields.Add(
+			&core.RelationField{
+				Id:           "artworks_location",
+				Name:         "location",
+				CollectionId: "locations",
+				MaxSelect:    1,
+			},
+			&core.TextField{
+				Id:   "artworks_inventory_number",
+				Name: "inventory_number",
+			},
+			&core.TextField{
+				Id:   "artworks_location_room",
+				Name: "location_room",
+			},
+			&core.BoolField{
+				Id:   "artworks_in_situ",
+				Name: "in_situ",
+			},
+			&core.TextField{
+				Id:   "artworks_depicted_place",
+				Name: "depicted_place",
+			},
+		)
+
+		return app.Save(artworks)
+	}, func(app core.App) error {
+		artworks, err := app.FindCollectionByNameOrId("artworks")
+		if err != nil {
+			return err
+		}
+
+		for _, id := range []string{"artworks_location", "artworks_inventory_number", "artworks_location_room", "artworks_in_situ", "artworks_depicted_place"} {
+			artworks.Fields.RemoveById(id)
+		}
+
+		if err := app.Save(artworks); err != nil {
+			return err
+		}
+
+		collection, err := app.FindCollectionByNameOrId("locations")
+		if err != nil {
+			return err
+		}
+
+		return app.Delete(collection)
+	})
+}
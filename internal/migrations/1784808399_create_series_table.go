@@ -0,0 +1,110 @@
+package migrations
+
+import (
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection := core.NewBaseCollection("Series")
+
+		collection.Name = "Series"
+		collection.Id = "series"
+		collection.MarkAsNew()
+
+		collection.Fields.Add(
+			&core.TextField{
+				Id:          "series_title",
+				Name:        "title",
+				Required:    true,
+				Presentable: true,
+			},
+			&core.SelectField{
+				Id:        "series_kind",
+				Name:      "kind",
+				MaxSelect: 1,
+				Values:    repositories.SeriesKindValues(),
+			},
+			&core.EditorField{
+				Id:   "series_description",
+				Name: "description",
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+			&core.AutodateField{
+				Name:     "updated",
+				OnCreate: true,
+				OnUpdate: true,
+			},
+		)
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		artworks, err := app.FindCollectionByNameOrId("artworks")
+		if err != nil {
+			return err
+		}
+
+		artworks.Fields.Add(
+			&core.RelationField{
+				Id:           "artworks_series",
+				Name:         "series",
+				CollectionId: "series",
+				MaxSelect:    1,
+			},
+			&core.NumberField{
+				Id:      "artworks_series_position",
+				Name:    "series_position",
+				OnlyInt: true,
+			},
+			&core.TextField{
+				Id:   "artworks_series_part",
+				Name: "series_part",
+			},
+		)
+
+		artworks.AddIndex("pbx_artwork_series", false, "series, series_position", "")
+
+		if err := app.Save(artworks); err != nil {
+			return err
+		}
+
+		// the cover relates the series back to the artworks, so it is added once both exist
+		collection.Fields.Add(&core.RelationField{
+			Id:           "series_cover",
+			Name:         "cover",
+			CollectionId: "artworks",
+			MaxSelect:    1,
+		})
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		artworks, err := app.FindCollectionByNameOrId("artworks")
+		if err != nil {
+			return err
+		}
+
+		for _, id := range []string{"artworks_series", "artworks_series_position", "artworks_series_part"} {
+			artworks.Fields.RemoveById(id)
+		}
+
+		artworks.RemoveIndex("pbx_artwork_series")
+
+		if err := app.Save(artworks); err != nil {
+			return err
+		}
+
+		collection, err := app.FindCollectionByNameOrId("series")
+		if err != nil {
+			return err
+		}
+
+		return app.Delete(collection)
+	})
+}
@@ -0,0 +1,75 @@
+package migrations
+
+import (
+	"github.com/blackfyre/wga/internal/repositories"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection := core.NewBaseCollection("Artwork_images")
+
+		collection.Name = "Artwork_images"
+		collection.Id = "artwork_images"
+		collection.MarkAsNew()
+
+		collection.Fields.Add(
+			&core.RelationField{
+				Id:            "artwork_image_artwork",
+				Name:          "artwork",
+				CollectionId:  "artworks",
+				CascadeDelete: true,
+				MinSelect:     1,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.FileField{
+				Id:       "artwork_image_image",
+				Name:     "image",
+				Required: true,
+				MimeTypes: []string{
+					"image/jpeg", "image/png",
+				},
+				MaxSize: 1024 * 1024 * 5,
+				Thumbs:  []string{"100x100", "320x240"},
+			},
+			&core.SelectField{
+				Id:        "artwork_image_kind",
+				Name:      "kind",
+				Required:  true,
+				MaxSelect: 1,
+				Values:    repositories.ArtworkImageKindValues(),
+			},
+			&core.TextField{
+				Id:   "artwork_image_caption",
+				Name: "caption",
+			},
+			&core.NumberField{
+				Id:      "artwork_image_position",
+				Name:    "position",
+				OnlyInt: true,
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+			&core.AutodateField{
+				Name:     "updated",
+				OnCreate: true,
+				OnUpdate: true,
+			},
+		)
+
+		collection.AddIndex("pbx_artwork_image_artwork", false, "artwork, position", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("artwork_images")
+		if err != nil {
+			return err
+		}
+
+		return app.Delete(collection)
+	})
+}
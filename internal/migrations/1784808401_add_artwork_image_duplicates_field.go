@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"github.com/blackfyre/wga/internal/utils/imagehash"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("artworks")
+		if err != nil {
+			return err
+		}
+
+		collection.Fields.Add(&core.RelationField{
+			Id:           "artworks_" + imagehash.DuplicatesField,
+			Name:         imagehash.DuplicatesField,
+			CollectionId: collection.Id,
+			MaxSelect:    imagehash.MaxRecordedDuplicates,
+		})
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("artworks")
+		if err != nil {
+			return err
+		}
+
+		collection.Fields.RemoveById("artworks_" + imagehash.DuplicatesField)
+
+		return app.Save(collection)
+	})
+}
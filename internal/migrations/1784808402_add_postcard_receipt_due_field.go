@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("postcards")
+		if err != nil {
+			return err
+		}
+
+		collection.Fields.Add(&core.DateField{
+			Id:   "postcard_receipt_due_at",
+			Name: "receipt_due_at",
+		})
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("postcards")
+		if err != nil {
+			return err
+		}
+
+		collection.Fields.RemoveById("postcard_receipt_due_at")
+
+		return app.Save(collection)
+	})
+}
@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"strings"
+
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/blackfyre/wga/internal/utils/fulltext"
+	"github.com/pocketbase/dbx"
+)
+
+// Kinds of the names an artist is known by besides their name.
+const (
+	// ArtistNameAlias is another name of the artist, like "Il Tintoretto" for Jacopo Robusti.
+	ArtistNameAlias = "alias"
+	// ArtistNameVariant is a spelling or a translation of the name, like "Sandro Filipepi".
+	ArtistNameVariant = "variant"
+	// ArtistNameSortKey is the name in index order, like "Gogh, Vincent van".
+	ArtistNameSortKey = "sort_key"
+)
+
+// ArtistName is a name an artist is known by besides their name.
+type ArtistName struct {
+	Name string `db:"name"`
+	// Lang is the language of the name as a BCP 47 tag, like "it", empty when it does not matter.
+	Lang string `db:"lang"`
+	Kind string `db:"kind"`
+	Slug string `db:"slug"`
+}
+
+// ArtistNameSlug returns the url slug of a name. Accents are dropped rather than the
+// accented letters, so "Frédéric" becomes "frederic".
+func ArtistNameSlug(name string) string {
+	return utils.Slugify(strings.Join(strings.Fields(fulltext.Fold(name)), " "))
+}
+
+// ArtistNameFilter returns a record filter matching the artists, addressed by prefix like "author.",
+// whose name or any other name matches the param with the any-of operator op, like "?~".
+// With a negated operator, like "!~", it matches the artists none of whose names match.
+func ArtistNameFilter(prefix string, op string, param string) string {
+	names := prefix + constants.CollectionArtistNames + "_via_artist.name"
+
+	if strings.HasPrefix(op, "!") {
+		// an artist without other names has a single blank one, which never matches
+		return "(" + prefix + "name " + op + " {:" + param + "} && (" + names + " " + op + " {:" + param + "} || " +
+			prefix + constants.CollectionArtistNames + "_via_artist.id = ''))"
+	}
+
+	anyOp := op
+	if !strings.HasPrefix(anyOp, "?") {
+		anyOp = "?" + anyOp
+	}
+
+	return "(" + prefix + "name " + op + " {:" + param + "} || " + names + " " + anyOp + " {:" + param + "})"
+}
+
+// FindNames returns the other names of the artists, by artist id, in the order they were added.
+func (r *ArtistsRepository) FindNames(artistIds []string) (map[string][]ArtistName, error) {
+	names := map[string][]ArtistName{}
+	if len(artistIds) == 0 {
+		return names, nil
+	}
+
+	ids := make([]any, 0, len(artistIds))
+	for _, id := range artistIds {
+		ids = append(ids, id)
+	}
+
+	rows := []struct {
+		ArtistName
+		Artist string `db:"artist"`
+	}{}
+
+	err := r.app.DB().
+		Select("artist", "name", "lang", "kind", "slug").
+		From(constants.CollectionArtistNames).
+		Where(dbx.In("artist", ids...)).
+		OrderBy("created", "id").
+		All(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		names[row.Artist] = append(names[row.Artist], row.ArtistName)
+	}
+
+	return names, nil
+}
+
+// FindIdsByNameSlug returns the ids of the published artists with another name of the slug.
+func (r *ArtistsRepository) FindIdsByNameSlug(slug string) ([]string, error) {
+	ids := []string{}
+
+	err := r.app.DB().
+		Select("n.artist").
+		Distinct(true).
+		From(constants.CollectionArtistNames+" n").
+		InnerJoin(constants.CollectionArtists+" a", dbx.NewExp("a.id = n.artist")).
+		Where(dbx.HashExp{"n.slug": slug, "a.published": true}).
+		Column(&ids)
+
+	return ids, err
+}
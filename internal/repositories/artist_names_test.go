@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestArtistNameSlug(t *testing.T) {
+	for name, want := range map[string]string{
+		"Il Tintoretto":           "il-tintoretto",
+		"Gogh, Vincent van":       "gogh-vincent-van",
+		"Frédéric  Bazille":       "frederic-bazille",
+		"Dürer, Albrecht":         "durer-albrecht",
+		"Sandro Filipepi (Pseud)": "sandro-filipepi-pseud",
+	} {
+		if got := ArtistNameSlug(name); got != want {
+			t.Errorf("ArtistNameSlug(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestArtistNamesFindAndFilter(t *testing.T) {
+	app := newArtistNamesTestApp(t)
+	repo := NewArtistsRepository(app)
+
+	names, err := repo.FindNames([]string{"artist000000001", "artist000000002", "artist000000003", "artist000000004"})
+	if err != nil {
+		t.Fatalf("FindNames: %v", err)
+	}
+
+	want := map[string][]ArtistName{
+		"artist000000001": {
+			{Name: "Il Tintoretto", Lang: "it", Kind: ArtistNameAlias, Slug: "il-tintoretto"},
+			{Name: "Robusti, Jacopo", Kind: ArtistNameSortKey, Slug: "robusti-jacopo"},
+		},
+		"artist000000002": {
+			{Name: "Tintoretto", Kind: ArtistNameAlias, Slug: "tintoretto"},
+		},
+		"artist000000003": {
+			{Name: "Domenico Robusti", Kind: ArtistNameVariant, Slug: "domenico-robusti"},
+		},
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("FindNames = %+v, want %+v", names, want)
+	}
+
+	for slug, want := range map[string][]string{
+		"il-tintoretto": {"artist000000001"},
+		"tintoretto":    {"artist000000002"},
+		// the names of unpublished artists do not resolve
+		"domenico-robusti": {},
+		"nobody":           {},
+	} {
+		ids, err := repo.FindIdsByNameSlug(slug)
+		if err != nil {
+			t.Fatalf("FindIdsByNameSlug(%q): %v", slug, err)
+		}
+		if !reflect.DeepEqual(ids, want) {
+			t.Errorf("FindIdsByNameSlug(%q) = %v, want %v", slug, ids, want)
+		}
+	}
+
+	for _, c := range []struct {
+		op    string
+		value string
+		want  []string
+	}{
+		{"~", "jacopo", []string{"artist000000001"}},
+		{"~", "tintoretto", []string{"artist000000001", "artist000000002", "artist000000003"}},
+		{"!~", "tintoretto", []string{"artist000000004"}},
+		// artists without other names are matched by their name alone
+		{"!~", "jacopo", []string{"artist000000002", "artist000000003", "artist000000004"}},
+	} {
+		records, err := app.FindRecordsByFilter(
+			constants.CollectionArtists,
+			ArtistNameFilter("", c.op, "value"),
+			"+id",
+			0,
+			0,
+			dbx.Params{"value": c.value},
+		)
+		if err != nil {
+			t.Fatalf("%s %q: %v", c.op, c.value, err)
+		}
+
+		if got := recordIds(records); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s %q matched %v, want %v", c.op, c.value, got, c.want)
+		}
+	}
+}
+
+func newArtistNamesTestApp(t *testing.T) *tests.TestApp {
+	t.Helper()
+
+	app := newArtworksTestApp(t)
+
+	saveRecordValues(t, app, "artists", map[string]any{"id": "artist000000001", "name": "Jacopo Robusti", "published": true})
+	saveRecordValues(t, app, "artists", map[string]any{"id": "artist000000002", "name": "Marietta Robusti", "published": true})
+	saveRecordValues(t, app, "artists", map[string]any{"id": "artist000000003", "name": "Domenico Tintoretto"})
+	saveRecordValues(t, app, "artists", map[string]any{"id": "artist000000004", "name": "Paolo Veronese", "published": true})
+
+	for i, values := range []map[string]any{
+		{"artist": "artist000000001", "name": "Il Tintoretto", "lang": "it", "kind": ArtistNameAlias},
+		{"artist": "artist000000001", "name": "Robusti, Jacopo", "kind": ArtistNameSortKey},
+		{"artist": "artist000000002", "name": "Tintoretto", "kind": ArtistNameAlias},
+		{"artist": "artist000000003", "name": "Domenico Robusti", "kind": ArtistNameVariant},
+	} {
+		// the slugs are set by a hook outside of the tests, the ids keep the order stable
+		values["id"] = fmt.Sprintf("artistname%05d", i)
+		values["slug"] = ArtistNameSlug(values["name"].(string))
+		saveRecordValues(t, app, "artist_names", values)
+	}
+
+	return app
+}
@@ -0,0 +1,269 @@
+package repositories
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Types of the relationships between artists. A relationship reads from its artist to
+// its related artist, like "the artist was the teacher of the related artist".
+const (
+	RelationshipTeacher      = "teacher"
+	RelationshipWorkshop     = "workshop_member"
+	RelationshipParent       = "parent"
+	RelationshipCollaborator = "collaborator"
+	RelationshipInfluence    = "influenced_by"
+)
+
+// RelationshipType describes a type of relationship from both of its ends.
+type RelationshipType struct {
+	Value string
+	// Label describes the related artist from the artist, like "Teacher of".
+	Label string
+	// InverseLabel describes the artist from the related artist, like "Pupil of".
+	InverseLabel string
+	// Property and InverseProperty are the schema.org Person properties the relationship maps to,
+	// empty when the relationship has none.
+	Property        string
+	InverseProperty string
+}
+
+// RelationshipTypes are the types of relationships between artists.
+var RelationshipTypes = []RelationshipType{
+	{RelationshipTeacher, "Teacher of", "Pupil of", "knows", "knows"},
+	{RelationshipWorkshop, "Worked in the workshop of", "Workshop head of", "colleague", "colleague"},
+	{RelationshipParent, "Parent of", "Child of", "children", "parent"},
+	{RelationshipCollaborator, "Collaborated with", "Collaborated with", "colleague", "colleague"},
+	{RelationshipInfluence, "Influenced by", "Influenced", "knowsAbout", ""},
+}
+
+// RelationshipTypeValues returns the values of the relationship types.
+func RelationshipTypeValues() []string {
+	values := make([]string, 0, len(RelationshipTypes))
+	for _, t := range RelationshipTypes {
+		values = append(values, t.Value)
+	}
+
+	return values
+}
+
+// FindRelationshipType returns the relationship type of the value.
+func FindRelationshipType(value string) (RelationshipType, bool) {
+	for _, t := range RelationshipTypes {
+		if t.Value == value {
+			return t, true
+		}
+	}
+
+	return RelationshipType{}, false
+}
+
+// ArtistRelation is a relationship of an artist, seen from the artist.
+type ArtistRelation struct {
+	// Id is the id of the relationship record.
+	Id   string `db:"id"`
+	Type string `db:"type"`
+	// Outgoing is set when the artist is the artist of the relationship rather than the related artist.
+	Outgoing  bool   `db:"-"`
+	ArtistId  string `db:"artist_id"`
+	Name      string `db:"name"`
+	Slug      string `db:"slug"`
+	Source    string `db:"source"`
+	SourceUrl string `db:"source_url"`
+}
+
+// Label describes the other artist from the artist, like "Pupil of".
+func (r ArtistRelation) Label() string {
+	t, ok := FindRelationshipType(r.Type)
+	if !ok {
+		return r.Type
+	}
+
+	if r.Outgoing {
+		return t.Label
+	}
+
+	return t.InverseLabel
+}
+
+// Property is the schema.org property of the other artist on the artist, empty when there is none.
+func (r ArtistRelation) Property() string {
+	t, _ := FindRelationshipType(r.Type)
+	if r.Outgoing {
+		return t.Property
+	}
+
+	return t.InverseProperty
+}
+
+// FindRelations returns the relationships of the artists with published artists, in both directions,
+// ordered by type and the name of the other artist.
+func (r *ArtistsRepository) FindRelations(artistId string) ([]ArtistRelation, error) {
+	relations, err := r.findRelations([]string{artistId})
+	if err != nil {
+		return nil, err
+	}
+
+	if relations[artistId] == nil {
+		return []ArtistRelation{}, nil
+	}
+
+	return relations[artistId], nil
+}
+
+// findRelations returns the relationships of every artist keyed by artist id, like FindRelations,
+// with one query for each direction.
+func (r *ArtistsRepository) findRelations(artistIds []string) (map[string][]ArtistRelation, error) {
+	relations := map[string][]ArtistRelation{}
+	if len(artistIds) == 0 {
+		return relations, nil
+	}
+
+	ids := make([]any, 0, len(artistIds))
+	for _, id := range artistIds {
+		ids = append(ids, id)
+	}
+
+	for _, side := range []struct {
+		own, other string
+		outgoing   bool
+	}{
+		{"artist", "related_artist", true},
+		{"related_artist", "artist", false},
+	} {
+		rows := []struct {
+			ArtistRelation
+			OwnId string `db:"own_id"`
+		}{}
+
+		err := r.app.DB().
+			Select("r.id", "r.type", "r.source", "r.source_url", "r."+side.own+" AS own_id", "a.id AS artist_id", "a.name", "a.slug").
+			From(constants.CollectionArtistRelationships+" r").
+			InnerJoin(constants.CollectionArtists+" a", dbx.NewExp("a.id = r."+side.other)).
+			Where(dbx.In("r."+side.own, ids...)).
+			AndWhere(dbx.HashExp{"a.published": true}).
+			All(&rows)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			row.Outgoing = side.outgoing
+			relations[row.OwnId] = append(relations[row.OwnId], row.ArtistRelation)
+		}
+	}
+
+	order := map[string]int{}
+	for i, t := range RelationshipTypes {
+		order[t.Value] = i
+	}
+
+	for _, list := range relations {
+		slices.SortStableFunc(list, func(a, b ArtistRelation) int {
+			return cmp.Or(
+				cmp.Compare(order[a.Type], order[b.Type]),
+				cmp.Compare(a.Label(), b.Label()),
+				cmp.Compare(a.Name, b.Name),
+			)
+		})
+	}
+
+	return relations, nil
+}
+
+// maxNetworkNodes bounds the artists of a network, so a well connected artist stays readable.
+const maxNetworkNodes = 100
+
+// NetworkNode is an artist of a network. Depth is the number of relationships from the centre.
+type NetworkNode struct {
+	Id    string `json:"id"`
+	Name  string `json:"name"`
+	Slug  string `json:"-"`
+	Depth int    `json:"depth"`
+}
+
+// NetworkEdge is a relationship of a network, reading from Source to Target.
+type NetworkEdge struct {
+	Id        string `json:"id"`
+	Source    string `json:"source"`
+	Target    string `json:"target"`
+	Type      string `json:"type"`
+	Label     string `json:"label"`
+	SourceRef string `json:"source_ref,omitempty"`
+	SourceUrl string `json:"source_url,omitempty"`
+}
+
+// Network is the graph of the relationships around an artist.
+type Network struct {
+	Nodes []NetworkNode `json:"nodes"`
+	Edges []NetworkEdge `json:"edges"`
+	// Truncated is set when the network was cut at maxNetworkNodes artists.
+	Truncated bool `json:"truncated"`
+}
+
+// FindNetwork returns the artists within depth relationships of the artist, with the relationships
+// that lead to them. The centre is the first node. The relationships are found a level at a time.
+func (r *ArtistsRepository) FindNetwork(artist *core.Record, depth int) (Network, error) {
+	network := Network{
+		Nodes: []NetworkNode{{Id: artist.Id, Name: artist.GetString("name"), Slug: artist.GetString("slug")}},
+		Edges: []NetworkEdge{},
+	}
+
+	seenNodes := map[string]bool{artist.Id: true}
+	seenEdges := map[string]bool{}
+	frontier := []string{artist.Id}
+
+	for level := 1; level <= depth && len(frontier) > 0; level++ {
+		next := []string{}
+
+		relations, err := r.findRelations(frontier)
+		if err != nil {
+			return Network{}, err
+		}
+
+		for _, id := range frontier {
+			for _, relation := range relations[id] {
+				if !seenNodes[relation.ArtistId] {
+					if len(network.Nodes) >= maxNetworkNodes {
+						network.Truncated = true
+						continue
+					}
+
+					seenNodes[relation.ArtistId] = true
+					network.Nodes = append(network.Nodes, NetworkNode{Id: relation.ArtistId, Name: relation.Name, Slug: relation.Slug, Depth: level})
+					next = append(next, relation.ArtistId)
+				}
+
+				if seenEdges[relation.Id] {
+					continue
+				}
+				seenEdges[relation.Id] = true
+
+				edge := NetworkEdge{
+					Id:        relation.Id,
+					Source:    id,
+					Target:    relation.ArtistId,
+					Type:      relation.Type,
+					SourceRef: relation.Source,
+					SourceUrl: relation.SourceUrl,
+				}
+				if !relation.Outgoing {
+					edge.Source, edge.Target = relation.ArtistId, id
+				}
+				if t, ok := FindRelationshipType(relation.Type); ok {
+					edge.Label = t.Label
+				}
+
+				network.Edges = append(network.Edges, edge)
+			}
+		}
+
+		frontier = next
+	}
+
+	return network, nil
+}
@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestFindRelationsReadsBothDirections(t *testing.T) {
+	app := newArtistRelationshipsTestApp(t)
+
+	relations, err := NewArtistsRepository(app).FindRelations("artist000000002")
+	if err != nil {
+		t.Fatalf("FindRelations: %v", err)
+	}
+
+	type labelled struct{ Label, Name, Property string }
+
+	got := []labelled{}
+	for _, r := range relations {
+		got = append(got, labelled{r.Label(), r.Name, r.Property()})
+	}
+
+	// the unpublished pupil is left out
+	want := []labelled{
+		{"Pupil of", "Verrocchio", "knows"},
+		{"Teacher of", "Raphael", "knows"},
+		{"Child of", "Giovanni Santi", "parent"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("relations = %+v, want %+v", got, want)
+	}
+
+	if relations[0].Source != "Vasari, Lives" || relations[0].SourceUrl != "https://example.com/vasari" {
+		t.Errorf("source = %q %q", relations[0].Source, relations[0].SourceUrl)
+	}
+}
+
+func TestFindRelationsOfSeveralArtists(t *testing.T) {
+	app := newArtistRelationshipsTestApp(t)
+
+	relations, err := NewArtistsRepository(app).findRelations([]string{"artist000000001", "artist000000002", "artist000000003"})
+	if err != nil {
+		t.Fatalf("findRelations: %v", err)
+	}
+
+	got := map[string][]string{}
+	for id, list := range relations {
+		for _, r := range list {
+			got[id] = append(got[id], r.Label()+" "+r.Name)
+		}
+	}
+
+	want := map[string][]string{
+		"artist000000001": {"Teacher of Perugino"},
+		"artist000000002": {"Pupil of Verrocchio", "Teacher of Raphael", "Child of Giovanni Santi"},
+		"artist000000003": {"Pupil of Perugino"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("relations = %v, want %v", got, want)
+	}
+}
+
+func TestFindNetworkFollowsTheDepth(t *testing.T) {
+	app := newArtistRelationshipsTestApp(t)
+	repo := NewArtistsRepository(app)
+
+	centre, err := app.FindRecordById("artists", "artist000000001")
+	if err != nil {
+		t.Fatalf("find artist: %v", err)
+	}
+
+	for depth, want := range map[int][]string{
+		1: {"artist000000001", "artist000000002"},
+		2: {"artist000000001", "artist000000002", "artist000000003", "artist000000004"},
+	} {
+		network, err := repo.FindNetwork(centre, depth)
+		if err != nil {
+			t.Fatalf("FindNetwork(%d): %v", depth, err)
+		}
+
+		got := []string{}
+		for _, n := range network.Nodes {
+			got = append(got, n.Id)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("depth %d nodes = %v, want %v", depth, got, want)
+		}
+
+		if len(network.Edges) != len(want)-1 {
+			t.Errorf("depth %d has %d edges, want %d", depth, len(network.Edges), len(want)-1)
+		}
+	}
+
+	network, err := repo.FindNetwork(centre, 1)
+	if err != nil {
+		t.Fatalf("FindNetwork: %v", err)
+	}
+
+	// edges read from the teacher to the pupil whichever end the network started from
+	edge := network.Edges[0]
+	if edge.Source != "artist000000001" || edge.Target != "artist000000002" || edge.Label != "Teacher of" {
+		t.Fatalf("edge = %+v", edge)
+	}
+}
+
+func newArtistRelationshipsTestApp(t *testing.T) *tests.TestApp {
+	t.Helper()
+
+	app := newArtworksTestApp(t)
+
+	for _, values := range []map[string]any{
+		{"id": "artist000000001", "name": "Verrocchio", "slug": "verrocchio", "published": true},
+		{"id": "artist000000002", "name": "Perugino", "slug": "perugino", "published": true},
+		{"id": "artist000000003", "name": "Raphael", "slug": "raphael", "published": true},
+		{"id": "artist000000004", "name": "Giovanni Santi", "slug": "giovanni-santi", "published": true},
+		{"id": "artist000000005", "name": "Hidden pupil", "slug": "hidden-pupil"},
+	} {
+		saveRecordValues(t, app, "artists", values)
+	}
+
+	for _, values := range []map[string]any{
+		{"id": "relation0000001", "artist": "artist000000001", "type": RelationshipTeacher, "related_artist": "artist000000002", "source": "Vasari, Lives", "source_url": "https://example.com/vasari"},
+		{"id": "relation0000002", "artist": "artist000000002", "type": RelationshipTeacher, "related_artist": "artist000000003"},
+		{"id": "relation0000003", "artist": "artist000000004", "type": RelationshipParent, "related_artist": "artist000000002"},
+		{"id": "relation0000004", "artist": "artist000000002", "type": RelationshipTeacher, "related_artist": "artist000000005"},
+	} {
+		saveRecordValues(t, app, "artist_relationships", values)
+	}
+
+	return app
+}
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/utils/fulltext"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// ArtistOtherLetter is the index letter of the artists whose sort name does not start with A to Z.
+const ArtistOtherLetter = "#"
+
+// ArtistLetters are the letters of the artist index, in order.
+var ArtistLetters = strings.Split("ABCDEFGHIJKLMNOPQRSTUVWXYZ"+ArtistOtherLetter, "")
+
+// nameParticles are the words skipped at the start of a name when sorting, so "van Dyck"
+// sorts under D. They are compared in lower case, without accents.
+var nameParticles = map[string]bool{
+	"d": true, "da": true, "dal": true, "dalla": true, "de": true, "degli": true,
+	"dei": true, "del": true, "della": true, "den": true, "der": true, "des": true, "di": true,
+	"du": true, "il": true, "l": true, "la": true, "le": true, "lo": true, "ten": true,
+	"ter": true, "van": true, "vom": true, "von": true, "zu": true,
+}
+
+type ArtistsRepository struct {
+	app    core.App
+	lister keysetLister
+}
+
+func NewArtistsRepository(app core.App) *ArtistsRepository {
+	return &ArtistsRepository{
+		app:    app,
+		lister: keysetLister{app: app, collection: constants.CollectionArtists, sortField: "sort_name"},
+	}
+}
+
+// Count returns the number of artists matching the record filter.
+func (r *ArtistsRepository) Count(f RecordFilter) (int, error) {
+	return r.lister.count(f)
+}
+
+// FindPage returns a page of the artists matching the record filter, ordered by sort name.
+func (r *ArtistsRepository) FindPage(q PageQuery) (Page, error) {
+	return r.lister.findPage(q)
+}
+
+// PrefetchRelations loads the schools of the artists,
+// so they can be read with ExpandedAll instead of a lookup per artist.
+func (r *ArtistsRepository) PrefetchRelations(artists []*core.Record) error {
+	return expandRelations(r.app, artists, "school")
+}
+
+// SchoolNames returns the names of the prefetched schools of an artist, comma separated.
+func SchoolNames(artist *core.Record) string {
+	names := []string{}
+
+	for _, school := range artist.ExpandedAll("school") {
+		names = append(names, school.GetString("name"))
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// CountByLetter returns the number of artists matching the record filter by index letter.
+// Letters without artists are left out.
+func (r *ArtistsRepository) CountByLetter(f RecordFilter) (map[string]int, error) {
+	q, collection, err := r.lister.query(f)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []struct {
+		Letter string `db:"letter"`
+		Count  int    `db:"count"`
+	}{}
+
+	err = q.Distinct(false).
+		Select("{{"+collection.Name+"}}.[[letter]] AS letter", "COUNT(DISTINCT {{"+collection.Name+"}}.[[id]]) AS count").
+		GroupBy("letter").
+		All(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	for _, row := range rows {
+		counts[row.Letter] += row.Count
+	}
+
+	return counts, nil
+}
+
+// ArtistSortName returns the key the artists are sorted by: the name in lower case, without
+// accents, punctuation and leading particles, so "van Dyck, Anthony" becomes "dyck anthony".
+func ArtistSortName(name string) string {
+	words := strings.FieldsFunc(fulltext.Fold(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	// a name made of particles only, like "Le", keeps its last word
+	for len(words) > 1 && nameParticles[words[0]] {
+		words = words[1:]
+	}
+
+	return strings.Join(words, " ")
+}
+
+// ArtistLetter returns the index letter of a sort name, ArtistOtherLetter when it does not start with A to Z.
+func ArtistLetter(sortName string) string {
+	if sortName == "" || sortName[0] < 'a' || sortName[0] > 'z' {
+		return ArtistOtherLetter
+	}
+
+	return strings.ToUpper(sortName[:1])
+}
@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+)
+
+func TestArtistSortName(t *testing.T) {
+	for name, want := range map[string]string{
+		"van Dyck, Anthony":     "dyck anthony",
+		"DYCK, Anthony van":     "dyck anthony van",
+		"Gogh, Vincent van":     "gogh vincent van",
+		"da Messina, Antonello": "messina antonello",
+		"de la Tour, Georges":   "tour georges",
+		"d'Arpino, Cavaliere":   "arpino cavaliere",
+		"Dürer, Albrecht":       "durer albrecht",
+		"Le":                    "le",
+		"":                      "",
+	} {
+		if got := ArtistSortName(name); got != want {
+			t.Errorf("ArtistSortName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestArtistLetter(t *testing.T) {
+	for sortName, want := range map[string]string{
+		"dyck anthony": "D",
+		"zurbaran":     "Z",
+		"1st master":   ArtistOtherLetter,
+		"":             ArtistOtherLetter,
+	} {
+		if got := ArtistLetter(sortName); got != want {
+			t.Errorf("ArtistLetter(%q) = %q, want %q", sortName, got, want)
+		}
+	}
+}
+
+func TestArtistsRepositoryCountByLetter(t *testing.T) {
+	app := newArtworksTestApp(t)
+
+	for _, values := range []map[string]any{
+		{"id": "artist000000001", "name": "van Dyck, Anthony", "letter": "D", "profession": "painter", "published": true},
+		{"id": "artist000000002", "name": "Dürer, Albrecht", "letter": "D", "profession": "engraver", "published": true},
+		{"id": "artist000000003", "name": "Zurbarán, Francisco de", "letter": "Z", "profession": "painter", "published": true},
+		{"id": "artist000000004", "name": "Hidden", "letter": "H", "profession": "painter"},
+	} {
+		saveRecordValues(t, app, "artists", values)
+	}
+
+	counts, err := NewArtistsRepository(app).CountByLetter(RecordFilter{Filter: "published = true && profession ~ {:profession}", Params: dbx.Params{"profession": "painter"}})
+	if err != nil {
+		t.Fatalf("CountByLetter: %v", err)
+	}
+
+	if want := map[string]int{"D": 1, "Z": 1}; !reflect.DeepEqual(counts, want) {
+		t.Fatalf("CountByLetter = %v, want %v", counts, want)
+	}
+}
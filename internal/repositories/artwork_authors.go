@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Attribution roles qualify how an artwork is credited to one of its authors. An author
+// without a role made the artwork, alone or together with the other authors.
+const (
+	AttributionAttributed = "attributed"
+	AttributionWorkshop   = "workshop"
+	AttributionCircle     = "circle"
+	AttributionFollower   = "follower"
+	AttributionCopyAfter  = "copy_after"
+)
+
+// AttributionRole is an attribution role with the qualifier shown before the name of the artist.
+type AttributionRole struct {
+	Value string
+	Label string
+}
+
+// AttributionRoles are the roles an author of an artwork can be credited with.
+var AttributionRoles = []AttributionRole{
+	{AttributionAttributed, "Attributed to"},
+	{AttributionWorkshop, "Workshop of"},
+	{AttributionCircle, "Circle of"},
+	{AttributionFollower, "Follower of"},
+	{AttributionCopyAfter, "Copy after"},
+}
+
+// Filters of the artworks with and without an uncertain attribution: an artwork is uncertain
+// when any of its authors is credited with a role.
+const (
+	CertainAttributionFilter   = "artwork_authors_via_artwork.id = ''"
+	UncertainAttributionFilter = "artwork_authors_via_artwork.id != ''"
+)
+
+// AttributionRoleValues returns the values of the attribution roles.
+func AttributionRoleValues() []string {
+	values := make([]string, 0, len(AttributionRoles))
+	for _, r := range AttributionRoles {
+		values = append(values, r.Value)
+	}
+
+	return values
+}
+
+// AttributionLabel returns the qualifier of the role, empty for the authors without one.
+func AttributionLabel(role string) string {
+	for _, r := range AttributionRoles {
+		if r.Value == role {
+			return r.Label
+		}
+	}
+
+	return ""
+}
+
+// ArtworkAuthor is a published artist credited with an artwork.
+type ArtworkAuthor struct {
+	Artist *core.Record
+	// Role is the attribution role of the artist, empty when the artwork is their own.
+	Role string
+}
+
+// Qualifier returns the qualifier shown before the name of the artist, like "Workshop of".
+func (a ArtworkAuthor) Qualifier() string {
+	return AttributionLabel(a.Role)
+}
+
+type artworkAuthorRole struct {
+	Artwork string `db:"artwork"`
+	Artist  string `db:"artist"`
+	Role    string `db:"role"`
+}
+
+// FindAuthors returns the published authors of the artworks keyed by artwork id, in the order
+// they are credited in. The first author is the one the canonical url of the artwork is under.
+func (r *ArtworksRepository) FindAuthors(artworks []*core.Record) (map[string][]ArtworkAuthor, error) {
+	authors := map[string][]ArtworkAuthor{}
+	if len(artworks) == 0 {
+		return authors, nil
+	}
+
+	artworkIds := make([]any, 0, len(artworks))
+	artistIds := []string{}
+	for _, artwork := range artworks {
+		artworkIds = append(artworkIds, artwork.Id)
+		artistIds = append(artistIds, artwork.GetStringSlice("author")...)
+	}
+
+	artists, err := r.app.FindRecordsByIds(constants.CollectionArtists, artistIds)
+	if err != nil {
+		return nil, err
+	}
+
+	published := make(map[string]*core.Record, len(artists))
+	for _, artist := range artists {
+		if artist.GetBool("published") {
+			published[artist.Id] = artist
+		}
+	}
+
+	rows := []artworkAuthorRole{}
+
+	err = r.app.DB().
+		Select("artwork", "artist", "role").
+		From(constants.CollectionArtworkAuthors).
+		Where(dbx.In("artwork", artworkIds...)).
+		All(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := map[string]string{}
+	for _, row := range rows {
+		roles[row.Artwork+"/"+row.Artist] = row.Role
+	}
+
+	for _, artwork := range artworks {
+		for _, id := range artwork.GetStringSlice("author") {
+			if artist, ok := published[id]; ok {
+				authors[artwork.Id] = append(authors[artwork.Id], ArtworkAuthor{Artist: artist, Role: roles[artwork.Id+"/"+id]})
+			}
+		}
+	}
+
+	return authors, nil
+}
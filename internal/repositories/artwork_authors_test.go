@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestFindAuthorsKeepsTheCreditedOrder(t *testing.T) {
+	app := newArtworkAuthorsTestApp(t)
+
+	artworks, err := app.FindRecordsByIds("artworks", []string{"artwork00000001", "artwork00000002", "artwork00000003"})
+	if err != nil {
+		t.Fatalf("find artworks: %v", err)
+	}
+
+	authors, err := NewArtworksRepository(app).FindAuthors(artworks)
+	if err != nil {
+		t.Fatalf("FindAuthors: %v", err)
+	}
+
+	type credit struct{ Id, Qualifier string }
+
+	got := map[string][]credit{}
+	for id, list := range authors {
+		for _, a := range list {
+			got[id] = append(got[id], credit{a.Artist.Id, a.Qualifier()})
+		}
+	}
+
+	// the unpublished author is left out, the others keep the order of the author field
+	want := map[string][]credit{
+		"artwork00000001": {{"artist000000002", ""}, {"artist000000001", "Workshop of"}},
+		"artwork00000002": {{"artist000000001", "Attributed to"}},
+		"artwork00000003": {{"artist000000001", ""}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("authors = %+v, want %+v", got, want)
+	}
+}
+
+func TestAttributionFilters(t *testing.T) {
+	app := newArtworkAuthorsTestApp(t)
+
+	for filter, want := range map[string][]string{
+		CertainAttributionFilter:   {"artwork00000003"},
+		UncertainAttributionFilter: {"artwork00000001", "artwork00000002"},
+	} {
+		records, err := app.FindRecordsByFilter("artworks", filter, "+id", 0, 0)
+		if err != nil {
+			t.Fatalf("%s: %v", filter, err)
+		}
+
+		if got := recordIds(records); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s matched %v, want %v", filter, got, want)
+		}
+	}
+}
+
+func newArtworkAuthorsTestApp(t *testing.T) *tests.TestApp {
+	t.Helper()
+
+	app := newArtworksTestApp(t)
+
+	for _, values := range []map[string]any{
+		{"id": "artist000000001", "name": "Rubens", "published": true},
+		{"id": "artist000000002", "name": "Jan Brueghel", "published": true},
+		{"id": "artist000000003", "name": "Hidden assistant"},
+	} {
+		saveRecordValues(t, app, "artists", values)
+	}
+
+	for _, values := range []map[string]any{
+		{"id": "artwork00000001", "title": "Garden of Eden", "author": []string{"artist000000003", "artist000000002", "artist000000001"}},
+		{"id": "artwork00000002", "title": "Head of a Boy", "author": []string{"artist000000001"}},
+		{"id": "artwork00000003", "title": "Descent from the Cross", "author": []string{"artist000000001"}},
+	} {
+		saveRecordValues(t, app, "artworks", values)
+	}
+
+	for _, values := range []map[string]any{
+		{"artwork": "artwork00000001", "artist": "artist000000001", "role": AttributionWorkshop},
+		{"artwork": "artwork00000002", "artist": "artist000000001", "role": AttributionAttributed},
+	} {
+		saveRecordValues(t, app, "artwork_authors", values)
+	}
+
+	// the head of a boy was given to Rubens from Jan Brueghel
+	saveRecordValues(t, app, "attribution_changes", map[string]any{
+		"artwork":          "artwork00000002",
+		"previous_artists": []string{"artist000000002"},
+		"previous_credit":  "Jan Brueghel",
+		"new_artists":      []string{"artist000000001"},
+		"new_credit":       "Attributed to Rubens",
+	})
+
+	return app
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Kinds of the images of an artwork besides its main image, which is the image of the artwork.
+const (
+	ArtworkImageDetail            = "detail"
+	ArtworkImageVerso             = "verso"
+	ArtworkImageInfrared          = "infrared"
+	ArtworkImageXRay              = "x_ray"
+	ArtworkImageBeforeRestoration = "before_restoration"
+	ArtworkImageAfterRestoration  = "after_restoration"
+	ArtworkImageOther             = "other"
+)
+
+// ArtworkImageKind is a kind of artwork image with the label the pages show.
+type ArtworkImageKind struct {
+	Value string
+	Label string
+}
+
+// ArtworkImageKinds are the kinds an image of an artwork can be.
+var ArtworkImageKinds = []ArtworkImageKind{
+	{ArtworkImageDetail, "Detail"},
+	{ArtworkImageVerso, "Verso"},
+	{ArtworkImageInfrared, "Infrared"},
+	{ArtworkImageXRay, "X-ray"},
+	{ArtworkImageBeforeRestoration, "Before restoration"},
+	{ArtworkImageAfterRestoration, "After restoration"},
+	{ArtworkImageOther, "Other"},
+}
+
+// ArtworkImageKindValues returns the values of the artwork image kinds.
+func ArtworkImageKindValues() []string {
+	values := make([]string, 0, len(ArtworkImageKinds))
+	for _, k := range ArtworkImageKinds {
+		values = append(values, k.Value)
+	}
+
+	return values
+}
+
+// ArtworkImageKindLabel returns the label of the kind, empty for an unknown kind.
+func ArtworkImageKindLabel(kind string) string {
+	for _, k := range ArtworkImageKinds {
+		if k.Value == kind {
+			return k.Label
+		}
+	}
+
+	return ""
+}
+
+// artworkImagesSort orders the images of an artwork by position, the images at the same
+// position in the order they were added.
+const artworkImagesSort = "+position,+created,+id"
+
+// FindImages returns the images of the artwork besides its main image, in order.
+func (r *ArtworksRepository) FindImages(artworkId string) ([]*core.Record, error) {
+	return r.app.FindRecordsByFilter(
+		constants.CollectionArtworkImages,
+		"artwork = {:artwork}",
+		artworkImagesSort,
+		0,
+		0,
+		dbx.Params{"artwork": artworkId},
+	)
+}
+
+// FindAllImages returns the images of every artwork besides their main images, in order,
+// by the id of the artwork.
+func (r *ArtworksRepository) FindAllImages() (map[string][]*core.Record, error) {
+	records, err := r.app.FindRecordsByFilter(constants.CollectionArtworkImages, "", artworkImagesSort, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	images := map[string][]*core.Record{}
+	for _, record := range records {
+		artworkId := record.GetString("artwork")
+		images[artworkId] = append(images[artworkId], record)
+	}
+
+	return images, nil
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"reflect"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+func TestArtworksRepositoryFindsTheImagesInOrder(t *testing.T) {
+	app := newArtworkImagesTestApp(t)
+	repo := NewArtworksRepository(app)
+
+	images, err := repo.FindImages("artwork00000001")
+	if err != nil {
+		t.Fatalf("find images: %v", err)
+	}
+
+	if got, want := recordIds(images), []string{"awimage00000002", "awimage00000001", "awimage00000003"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("images = %v, want %v", got, want)
+	}
+
+	all, err := repo.FindAllImages()
+	if err != nil {
+		t.Fatalf("find all images: %v", err)
+	}
+
+	got := map[string][]string{}
+	for artworkId, records := range all {
+		got[artworkId] = recordIds(records)
+	}
+
+	want := map[string][]string{
+		"artwork00000001": {"awimage00000002", "awimage00000001", "awimage00000003"},
+		"artwork00000002": {"awimage00000004"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("all images = %v, want %v", got, want)
+	}
+}
+
+func TestArtworkImageKindLabel(t *testing.T) {
+	if got := ArtworkImageKindLabel(ArtworkImageXRay); got != "X-ray" {
+		t.Errorf("label of %q = %q", ArtworkImageXRay, got)
+	}
+
+	if got := ArtworkImageKindLabel("hologram"); got != "" {
+		t.Errorf("label of an unknown kind = %q", got)
+	}
+}
+
+func newArtworkImagesTestApp(t *testing.T) *tests.TestApp {
+	t.Helper()
+
+	app := newArtworksTestApp(t)
+
+	for _, values := range []map[string]any{
+		{"id": "artwork00000001", "title": "Ghent Altarpiece"},
+		{"id": "artwork00000002", "title": "Arnolfini Portrait"},
+	} {
+		saveRecordValues(t, app, "artworks", values)
+	}
+
+	for _, values := range []map[string]any{
+		{"id": "awimage00000001", "artwork": "artwork00000001", "image": newImageFile(t, "lamb.png"), "kind": ArtworkImageDetail, "position": 2},
+		{"id": "awimage00000002", "artwork": "artwork00000001", "image": newImageFile(t, "closed.png"), "kind": ArtworkImageVerso, "position": 1},
+		{"id": "awimage00000003", "artwork": "artwork00000001", "image": newImageFile(t, "ir.png"), "kind": ArtworkImageInfrared, "position": 2},
+		{"id": "awimage00000004", "artwork": "artwork00000002", "image": newImageFile(t, "mirror.png"), "kind": ArtworkImageDetail},
+	} {
+		saveRecordValues(t, app, "artwork_images", values)
+	}
+
+	return app
+}
+
+// newImageFile creates a one pixel png upload, the image field accepts only image files.
+func newImageFile(t *testing.T, name string) *filesystem.File {
+	t.Helper()
+
+	buf := bytes.Buffer{}
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatalf("encode image: %v", err)
+	}
+
+	file, err := filesystem.NewFileFromBytes(buf.Bytes(), name)
+	if err != nil {
+		t.Fatalf("create upload: %v", err)
+	}
+
+	return file
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+type ArtworksRepository struct {
+	app    core.App
+	lister keysetLister
+}
+
+func NewArtworksRepository(app core.App) *ArtworksRepository {
+	return &ArtworksRepository{
+		app:    app,
+		lister: keysetLister{app: app, collection: constants.CollectionArtworks, sortField: "title"},
+	}
+}
+
+// Count returns the number of artworks matching the record filter.
+func (r *ArtworksRepository) Count(f RecordFilter) (int, error) {
+	return r.lister.count(f)
+}
+
+// FindPage returns a page of the artworks matching the record filter, ordered by title.
+func (r *ArtworksRepository) FindPage(q PageQuery) (Page, error) {
+	return r.lister.findPage(q)
+}
+
+// PrefetchRelations loads the authors, schools, forms and types of the artworks,
+// so they can be read with ExpandedAll instead of a lookup per artwork.
+func (r *ArtworksRepository) PrefetchRelations(artworks []*core.Record) error {
+	return expandRelations(r.app, artworks, "author", "school", "form", "type")
+}
+
+// PrefetchLocations loads the locations holding the artworks, so they can be read with
+// ExpandedOne("location").
+func (r *ArtworksRepository) PrefetchLocations(artworks []*core.Record) error {
+	return expandRelations(r.app, artworks, "location")
+}
+
+// PrefetchSeries loads the series the artworks are parts of, so they can be read with
+// ExpandedOne("series").
+func (r *ArtworksRepository) PrefetchSeries(artworks []*core.Record) error {
+	return expandRelations(r.app, artworks, "series")
+}
@@ -0,0 +1,455 @@
+package repositories
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/blackfyre/wga/internal/testutils"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	want := Cursor{Key: "Birth of Venus", Id: "artwork00000001"}
+
+	got, err := ParseCursor(want.String())
+	if err != nil || got != want {
+		t.Fatalf("ParseCursor(String()) = %+v, %v", got, err)
+	}
+
+	for _, value := range []string{"", "not a cursor!", Cursor{Key: "no id"}.String()} {
+		if _, err := ParseCursor(value); err != ErrInvalidCursor {
+			t.Errorf("expected %q to be rejected, got %v", value, err)
+		}
+	}
+}
+
+func TestArtworksRepositoryCount(t *testing.T) {
+	app := newArtworksTestApp(t)
+	seedArtworks(t, app, 25)
+
+	repo := NewArtworksRepository(app)
+
+	for _, c := range []struct {
+		filter string
+		params dbx.Params
+	}{
+		{"", nil},
+		{"published = true", nil},
+		// relation filters join the related tables, which must not inflate the count
+		{"published = true && school.slug ?= {:school}", dbx.Params{"school": "school-1"}},
+		{"author.name ?~ {:artist}", dbx.Params{"artist": "Artist 1"}},
+	} {
+		count, err := repo.Count(RecordFilter{Filter: c.filter, Params: c.params})
+		if err != nil {
+			t.Fatalf("Count(%q): %v", c.filter, err)
+		}
+
+		records, err := app.FindRecordsByFilter("artworks", c.filter, "", 0, 0, c.params)
+		if err != nil {
+			t.Fatalf("FindRecordsByFilter(%q): %v", c.filter, err)
+		}
+
+		if count != len(records) {
+			t.Errorf("Count(%q) = %d, want %d", c.filter, count, len(records))
+		}
+	}
+}
+
+func TestArtworksRepositoryKeysetPagesMatchOffsetPages(t *testing.T) {
+	app := newArtworksTestApp(t)
+	// titles repeat every 4 artworks, so pages split records sharing a sort key
+	seedArtworks(t, app, 23)
+
+	repo := NewArtworksRepository(app)
+	filter := "published = true"
+	limit := 5
+
+	total, err := repo.Count(RecordFilter{Filter: filter})
+	if err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+
+	offsetPages := [][]string{}
+	for offset := 0; offset < total; offset += limit {
+		page, err := repo.FindPage(PageQuery{RecordFilter: RecordFilter{Filter: filter}, Limit: limit, Offset: offset})
+		if err != nil {
+			t.Fatalf("FindPage(offset %d): %v", offset, err)
+		}
+		offsetPages = append(offsetPages, recordIds(page.Records))
+	}
+
+	forward := [][]string{}
+	pages := []Page{}
+	var after *Cursor
+	for {
+		page, err := repo.FindPage(PageQuery{RecordFilter: RecordFilter{Filter: filter}, Limit: limit, After: after})
+		if err != nil {
+			t.Fatalf("FindPage(after): %v", err)
+		}
+		if len(page.Records) == 0 {
+			break
+		}
+		forward = append(forward, recordIds(page.Records))
+		pages = append(pages, page)
+		after = page.Last
+
+		if last := len(forward)*limit >= total; page.More == last {
+			t.Fatalf("page %d More = %v with %d records in total", len(forward), page.More, total)
+		}
+	}
+
+	if !reflect.DeepEqual(forward, offsetPages) {
+		t.Fatalf("keyset pages %v, offset pages %v", forward, offsetPages)
+	}
+
+	for i := len(pages) - 1; i > 0; i-- {
+		previous, err := repo.FindPage(PageQuery{RecordFilter: RecordFilter{Filter: filter}, Limit: limit, Before: pages[i].First})
+		if err != nil {
+			t.Fatalf("FindPage(before): %v", err)
+		}
+
+		if got := recordIds(previous.Records); !reflect.DeepEqual(got, offsetPages[i-1]) {
+			t.Fatalf("page before %d = %v, want %v", i, got, offsetPages[i-1])
+		}
+		if previous.More != (i > 1) {
+			t.Fatalf("page before %d More = %v", i, previous.More)
+		}
+	}
+}
+
+func TestArtworksRepositoryPrefetchRelations(t *testing.T) {
+	app := newArtworksTestApp(t)
+	seedArtworks(t, app, 6)
+
+	repo := NewArtworksRepository(app)
+
+	page, err := repo.FindPage(PageQuery{Limit: 6})
+	if err != nil {
+		t.Fatalf("FindPage: %v", err)
+	}
+
+	if err := repo.PrefetchRelations(page.Records); err != nil {
+		t.Fatalf("PrefetchRelations: %v", err)
+	}
+
+	for _, artwork := range page.Records {
+		for _, field := range []string{"author", "school", "form", "type"} {
+			if got, want := len(artwork.ExpandedAll(field)), len(artwork.GetStringSlice(field)); got != want {
+				t.Errorf("%s of %s: %d expanded, want %d", field, artwork.Id, got, want)
+			}
+		}
+	}
+}
+
+func TestArtistsRepositorySchoolNames(t *testing.T) {
+	app := newArtworksTestApp(t)
+	seedArtworks(t, app, 1)
+
+	repo := NewArtistsRepository(app)
+
+	page, err := repo.FindPage(PageQuery{RecordFilter: RecordFilter{Filter: "published = true"}, Limit: 10})
+	if err != nil {
+		t.Fatalf("FindPage: %v", err)
+	}
+
+	if err := repo.PrefetchRelations(page.Records); err != nil {
+		t.Fatalf("PrefetchRelations: %v", err)
+	}
+
+	if got := SchoolNames(page.Records[0]); got != "School 0" {
+		t.Fatalf("SchoolNames = %q, want %q", got, "School 0")
+	}
+}
+
+// benchmarkArtworks is the size of the synthetic dataset of the benchmarks,
+// in the order of magnitude of the gallery.
+const benchmarkArtworks = 50000
+
+func BenchmarkArtworksCount(b *testing.B) {
+	app := newArtworksTestApp(b)
+	seedArtworks(b, app, benchmarkArtworks)
+	repo := NewArtworksRepository(app)
+
+	b.Run("count", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := repo.Count(RecordFilter{Filter: "published = true"}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("load all", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := app.FindRecordsByFilter("artworks", "published = true", "", 0, 0); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkArtworksDeepPage(b *testing.B) {
+	app := newArtworksTestApp(b)
+	seedArtworks(b, app, benchmarkArtworks)
+	repo := NewArtworksRepository(app)
+
+	const limit = 16
+	offset := benchmarkArtworks * 3 / 4
+
+	previous, err := repo.FindPage(PageQuery{RecordFilter: RecordFilter{Filter: "published = true"}, Limit: limit, Offset: offset - limit})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("offset", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := repo.FindPage(PageQuery{RecordFilter: RecordFilter{Filter: "published = true"}, Limit: limit, Offset: offset}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("keyset", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := repo.FindPage(PageQuery{RecordFilter: RecordFilter{Filter: "published = true"}, Limit: limit, After: previous.Last}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkArtworksPrefetchRelations(b *testing.B) {
+	app := newArtworksTestApp(b)
+	seedArtworks(b, app, benchmarkArtworks)
+	repo := NewArtworksRepository(app)
+
+	page, err := repo.FindPage(PageQuery{RecordFilter: RecordFilter{Filter: "published = true"}, Limit: 16})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for b.Loop() {
+		if err := repo.PrefetchRelations(page.Records); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// newArtworksTestApp creates the collections the repositories read, with the field types
+// of the migrations. The required flags are left out, so that every test seeds only the
+// fields it reads.
+func newArtworksTestApp(t testing.TB) *tests.TestApp {
+	t.Helper()
+
+	app := testutils.NewTestApp(t)
+
+	save := func(c *core.Collection) {
+		t.Helper()
+
+		if err := app.Save(c); err != nil {
+			t.Fatalf("save %s collection: %v", c.Id, err)
+		}
+	}
+
+	for _, name := range []string{"schools", "art_forms", "art_types"} {
+		c := core.NewBaseCollection(name)
+		c.Id = name
+		c.Fields.Add(
+			&core.TextField{Name: "name"},
+			&core.TextField{Name: "slug"},
+		)
+		save(c)
+	}
+
+	artists := core.NewBaseCollection("Artists")
+	artists.Id = "artists"
+	artists.Fields.Add(
+		&core.TextField{Name: "name"},
+		&core.TextField{Name: "slug"},
+		&core.TextField{Name: "sort_name"},
+		&core.TextField{Name: "letter", Max: 1},
+		&core.TextField{Name: "profession"},
+		&core.RelationField{Name: "school", CollectionId: "schools", MaxSelect: 10},
+		&core.BoolField{Name: "published"},
+	)
+	artists.AddIndex("pbx_artist_sort_name_id", false, "sort_name, id", "")
+	save(artists)
+
+	artistNames := core.NewBaseCollection("Artist_names")
+	artistNames.Id = "artist_names"
+	artistNames.Fields.Add(
+		&core.RelationField{Name: "artist", CollectionId: "artists", MaxSelect: 1, CascadeDelete: true},
+		&core.TextField{Name: "name"},
+		&core.TextField{Name: "lang"},
+		&core.SelectField{Name: "kind", MaxSelect: 1, Values: []string{ArtistNameAlias, ArtistNameVariant, ArtistNameSortKey}},
+		&core.TextField{Name: "slug"},
+		&core.AutodateField{Name: "created", OnCreate: true},
+	)
+	save(artistNames)
+
+	relationships := core.NewBaseCollection("Artist_relationships")
+	relationships.Id = "artist_relationships"
+	relationships.Fields.Add(
+		&core.RelationField{Name: "artist", CollectionId: "artists", MaxSelect: 1, CascadeDelete: true},
+		&core.SelectField{Name: "type", MaxSelect: 1, Values: RelationshipTypeValues()},
+		&core.RelationField{Name: "related_artist", CollectionId: "artists", MaxSelect: 1, CascadeDelete: true},
+		&core.TextField{Name: "source"},
+		&core.URLField{Name: "source_url"},
+		&core.AutodateField{Name: "created", OnCreate: true},
+	)
+	save(relationships)
+
+	locations := core.NewBaseCollection("Locations")
+	locations.Id = "locations"
+	locations.Fields.Add(
+		&core.TextField{Name: "name"},
+		&core.TextField{Name: "slug"},
+		&core.SelectField{Name: "kind", MaxSelect: 1, Values: LocationKindValues()},
+		&core.TextField{Name: "city"},
+		&core.TextField{Name: "country"},
+		&core.URLField{Name: "website"},
+	)
+	save(locations)
+
+	series := core.NewBaseCollection("Series")
+	series.Id = "series"
+	series.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.SelectField{Name: "kind", MaxSelect: 1, Values: SeriesKindValues()},
+	)
+	save(series)
+
+	artworks := core.NewBaseCollection("Artworks")
+	artworks.Id = "artworks"
+	artworks.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.RelationField{Name: "author", CollectionId: "artists", MaxSelect: 10},
+		&core.RelationField{Name: "school", CollectionId: "schools", MaxSelect: 10},
+		&core.RelationField{Name: "form", CollectionId: "art_forms", MaxSelect: 20},
+		&core.RelationField{Name: "type", CollectionId: "art_types", MaxSelect: 20},
+		&core.BoolField{Name: "published"},
+		&core.TextField{Name: "technique"},
+		&core.NumberField{Name: "date_earliest", OnlyInt: true},
+		&core.NumberField{Name: "date_latest", OnlyInt: true},
+		&core.TextField{Name: "attribution_source"},
+		&core.RelationField{Name: "location", CollectionId: "locations", MaxSelect: 1},
+		&core.RelationField{Name: "series", CollectionId: "series", MaxSelect: 1},
+		&core.NumberField{Name: "series_position", OnlyInt: true},
+		&core.TextField{Name: "series_part"},
+		&core.AutodateField{Name: "created", OnCreate: true},
+	)
+	artworks.AddIndex("pbx_artwork_title_id", false, "title, id", "")
+	save(artworks)
+
+	// the cover relates the series back to the artworks, as in the migration
+	series.Fields.Add(&core.RelationField{Name: "cover", CollectionId: "artworks", MaxSelect: 1})
+	save(series)
+
+	artworkAuthors := core.NewBaseCollection("Artwork_authors")
+	artworkAuthors.Id = "artwork_authors"
+	artworkAuthors.Fields.Add(
+		&core.RelationField{Name: "artwork", CollectionId: "artworks", MaxSelect: 1, CascadeDelete: true},
+		&core.RelationField{Name: "artist", CollectionId: "artists", MaxSelect: 1, CascadeDelete: true},
+		&core.SelectField{Name: "role", MaxSelect: 1, Values: AttributionRoleValues()},
+	)
+	save(artworkAuthors)
+
+	attributionChanges := core.NewBaseCollection("Attribution_changes")
+	attributionChanges.Id = "attribution_changes"
+	attributionChanges.Fields.Add(
+		&core.RelationField{Name: "artwork", CollectionId: "artworks", MaxSelect: 1, CascadeDelete: true},
+		&core.RelationField{Name: "previous_artists", CollectionId: "artists", MaxSelect: 10},
+		&core.TextField{Name: "previous_credit"},
+		&core.RelationField{Name: "new_artists", CollectionId: "artists", MaxSelect: 10},
+		&core.TextField{Name: "new_credit"},
+		&core.TextField{Name: "source"},
+		&core.TextField{Name: "editor"},
+		&core.AutodateField{Name: "created", OnCreate: true},
+	)
+	save(attributionChanges)
+
+	images := core.NewBaseCollection("Artwork_images")
+	images.Id = "artwork_images"
+	images.Fields.Add(
+		&core.RelationField{Name: "artwork", CollectionId: "artworks", MaxSelect: 1, CascadeDelete: true},
+		&core.FileField{Name: "image", MaxSelect: 1, MimeTypes: []string{"image/jpeg", "image/png"}, MaxSize: 1024 * 1024 * 5},
+		&core.SelectField{Name: "kind", MaxSelect: 1, Values: ArtworkImageKindValues()},
+		&core.TextField{Name: "caption"},
+		&core.NumberField{Name: "position", OnlyInt: true},
+		&core.AutodateField{Name: "created", OnCreate: true},
+	)
+	save(images)
+
+	return app
+}
+
+// seedArtworks inserts n synthetic artworks with raw inserts, which is fast enough for
+// benchmark sized datasets. There is one artist per 10 artworks, titles repeat every 4
+// artworks, every 10th artwork is unpublished and the taxonomies have a few values each.
+func seedArtworks(t testing.TB, app core.App, n int) {
+	t.Helper()
+
+	err := app.RunInTransaction(func(txApp core.App) error {
+		for i := range 5 {
+			for _, taxonomy := range []struct{ table, prefix, label string }{
+				{"schools", "scho", "School"},
+				{"art_forms", "form", "Form"},
+				{"art_types", "type", "Type"},
+			} {
+				_, err := txApp.DB().Insert(taxonomy.table, dbx.Params{
+					"id":   fmt.Sprintf("%s%d", taxonomy.prefix, i),
+					"name": fmt.Sprintf("%s %d", taxonomy.label, i),
+					"slug": fmt.Sprintf("%s-%d", strings.ToLower(taxonomy.label), i),
+				}).Execute()
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		for i := range max(1, n/10) {
+			_, err := txApp.DB().Insert("artists", dbx.Params{
+				"id":        fmt.Sprintf("artist%09d", i),
+				"name":      fmt.Sprintf("Artist %d", i),
+				"sort_name": fmt.Sprintf("artist %d", i),
+				"letter":    "A",
+				"school":    fmt.Sprintf(`["scho%d"]`, i%5),
+				"published": true,
+			}).Execute()
+			if err != nil {
+				return err
+			}
+		}
+
+		for i := range n {
+			_, err := txApp.DB().Insert("artworks", dbx.Params{
+				"id":        fmt.Sprintf("artwork%08d", i),
+				"title":     fmt.Sprintf("Title %d", i/4),
+				"author":    fmt.Sprintf(`["artist%09d"]`, i/10),
+				"school":    fmt.Sprintf(`["scho%d"]`, i%5),
+				"form":      fmt.Sprintf(`["form%d"]`, i%5),
+				"type":      fmt.Sprintf(`["type%d"]`, (i+1)%5),
+				"published": i%10 != 9,
+			}).Execute()
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("seed artworks: %v", err)
+	}
+}
+
+func recordIds(records []*core.Record) []string {
+	ids := make([]string, 0, len(records))
+	for _, r := range records {
+		ids = append(ids, r.Id)
+	}
+	return ids
+}
@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// AttributionCredit returns the artists credited with the artwork as the attribution history
+// keeps them, like "RUBENS, Peter Paul; Workshop of BRUEGHEL, Jan", in the order of the ids.
+// Unpublished artists are credited too, the history is not shown for them alone.
+func (r *ArtworksRepository) AttributionCredit(artworkId string, artistIds []string) (string, error) {
+	if len(artistIds) == 0 {
+		return "", nil
+	}
+
+	artists, err := r.app.FindRecordsByIds(constants.CollectionArtists, artistIds)
+	if err != nil {
+		return "", err
+	}
+
+	names := make(map[string]string, len(artists))
+	for _, artist := range artists {
+		names[artist.Id] = artist.GetString("name")
+	}
+
+	rows := []artworkAuthorRole{}
+
+	err = r.app.DB().
+		Select("artwork", "artist", "role").
+		From(constants.CollectionArtworkAuthors).
+		Where(dbx.HashExp{"artwork": artworkId}).
+		All(&rows)
+	if err != nil {
+		return "", err
+	}
+
+	roles := map[string]string{}
+	for _, row := range rows {
+		roles[row.Artist] = row.Role
+	}
+
+	labels := []string{}
+	for _, id := range artistIds {
+		name, ok := names[id]
+		if !ok {
+			continue
+		}
+
+		if qualifier := AttributionLabel(roles[id]); qualifier != "" {
+			name = qualifier + " " + name
+		}
+
+		labels = append(labels, name)
+	}
+
+	return strings.Join(labels, "; "), nil
+}
+
+// FindAttributionHistory returns the attribution changes of the artwork, the latest first.
+func (r *ArtworksRepository) FindAttributionHistory(artworkId string) ([]*core.Record, error) {
+	return r.app.FindRecordsByFilter(
+		constants.CollectionAttributionChanges,
+		"artwork = {:artwork}",
+		"-created",
+		0,
+		0,
+		dbx.Params{"artwork": artworkId},
+	)
+}
+
+// WasAttributedTo reports whether the artwork was credited to the artist before one of its
+// reattributions.
+func (r *ArtworksRepository) WasAttributedTo(artworkId string, artistId string) (bool, error) {
+	_, err := r.app.FindFirstRecordByFilter(
+		constants.CollectionAttributionChanges,
+		"artwork = {:artwork} && previous_artists.id ?= {:artist}",
+		dbx.Params{"artwork": artworkId, "artist": artistId},
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import "testing"
+
+func TestAttributionCreditKeepsEveryArtist(t *testing.T) {
+	app := newArtworkAuthorsTestApp(t)
+
+	credit, err := NewArtworksRepository(app).AttributionCredit("artwork00000001", []string{"artist000000003", "artist000000002", "artist000000001"})
+	if err != nil {
+		t.Fatalf("AttributionCredit: %v", err)
+	}
+
+	// unpublished artists are credited too, deleted ones are left out
+	if want := "Hidden assistant; Jan Brueghel; Workshop of Rubens"; credit != want {
+		t.Fatalf("credit = %q, want %q", credit, want)
+	}
+
+	if credit, err := NewArtworksRepository(app).AttributionCredit("artwork00000001", []string{"artist000000009"}); err != nil || credit != "" {
+		t.Fatalf("credit of a deleted artist = %q (%v)", credit, err)
+	}
+}
+
+func TestWasAttributedTo(t *testing.T) {
+	app := newArtworkAuthorsTestApp(t)
+	repo := NewArtworksRepository(app)
+
+	for _, c := range []struct {
+		artwork, artist string
+		want            bool
+	}{
+		{"artwork00000002", "artist000000002", true},
+		{"artwork00000002", "artist000000001", false},
+		{"artwork00000003", "artist000000002", false},
+	} {
+		got, err := repo.WasAttributedTo(c.artwork, c.artist)
+		if err != nil {
+			t.Fatalf("WasAttributedTo(%s, %s): %v", c.artwork, c.artist, err)
+		}
+
+		if got != c.want {
+			t.Errorf("WasAttributedTo(%s, %s) = %v, want %v", c.artwork, c.artist, got, c.want)
+		}
+	}
+
+	history, err := repo.FindAttributionHistory("artwork00000002")
+	if err != nil {
+		t.Fatalf("FindAttributionHistory: %v", err)
+	}
+
+	if len(history) != 1 || history[0].GetString("new_credit") != "Attributed to Rubens" {
+		t.Fatalf("history = %v", history)
+	}
+}
@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"strings"
+
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/blackfyre/wga/internal/utils/fulltext"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Kinds of the places artworks are held in.
+const (
+	LocationMuseum            = "museum"
+	LocationChurch            = "church"
+	LocationPalace            = "palace"
+	LocationLibrary           = "library"
+	LocationPrivateCollection = "private_collection"
+	LocationOther             = "other"
+)
+
+// LocationKind is a kind of location with the label the pages show.
+type LocationKind struct {
+	Value string
+	Label string
+}
+
+// LocationKinds are the kinds a location can be.
+var LocationKinds = []LocationKind{
+	{LocationMuseum, "Museum"},
+	{LocationChurch, "Church"},
+	{LocationPalace, "Palace"},
+	{LocationLibrary, "Library"},
+	{LocationPrivateCollection, "Private collection"},
+	{LocationOther, "Other"},
+}
+
+// LocationKindValues returns the values of the location kinds.
+func LocationKindValues() []string {
+	values := make([]string, 0, len(LocationKinds))
+	for _, k := range LocationKinds {
+		values = append(values, k.Value)
+	}
+
+	return values
+}
+
+// LocationKindLabel returns the label of the kind, empty for an unknown kind.
+func LocationKindLabel(kind string) string {
+	for _, k := range LocationKinds {
+		if k.Value == kind {
+			return k.Label
+		}
+	}
+
+	return ""
+}
+
+// LocationSlug returns the url slug of a location, made of its name and city so the many
+// churches of the same name get their own, like "san-francesco-assisi".
+func LocationSlug(name string, city string) string {
+	return utils.Slugify(strings.Join(strings.Fields(fulltext.Fold(name+" "+city)), " "))
+}
+
+// Filters of the artworks by where they are held, expecting the location slug and the city
+// as the location and city params. The city matches in part and in any case.
+const (
+	LocationFilter = "location.slug = {:location}"
+	CityFilter     = "location.city ~ {:city}"
+)
+
+type LocationsRepository struct {
+	app core.App
+}
+
+// Location is a museum, church or other place holding artworks.
+type Location struct {
+	Id      string `db:"id"`
+	Name    string `db:"name"`
+	Slug    string `db:"slug"`
+	Kind    string `db:"kind"`
+	City    string `db:"city"`
+	Country string `db:"country"`
+	Website string `db:"website"`
+	// ArtworkCount is the number of published artworks held at the location.
+	ArtworkCount int `db:"artwork_count"`
+}
+
+// KindLabel returns the label of the kind of the location, like "Museum".
+func (l Location) KindLabel() string {
+	return LocationKindLabel(l.Kind)
+}
+
+func NewLocationsRepository(app core.App) *LocationsRepository {
+	return &LocationsRepository{app: app}
+}
+
+// GetLocations returns the locations holding published artworks ordered by country, city
+// and name, the ones without a country last, with the number of artworks each holds.
+func (r *LocationsRepository) GetLocations() ([]Location, error) {
+	rows := []Location{}
+	err := r.app.DB().NewQuery(`
+		SELECT l.id AS id, l.name AS name, l.slug AS slug, l.kind AS kind, l.city AS city,
+			l.country AS country, l.website AS website, COUNT(a.id) AS artwork_count
+		FROM Locations l
+		JOIN Artworks a ON a.location = l.id AND a.published IS true
+		WHERE l.slug != ''
+		GROUP BY l.id
+		ORDER BY l.country = '', l.country, l.city, l.name`).All(&rows)
+
+	return rows, err
+}
+
+// GetCities returns the cities of the locations holding published artworks, in order.
+func (r *LocationsRepository) GetCities() ([]string, error) {
+	cities := []string{}
+	err := r.app.DB().NewQuery(`
+		SELECT DISTINCT l.city
+		FROM Locations l
+		JOIN Artworks a ON a.location = l.id AND a.published IS true
+		WHERE l.city != ''
+		ORDER BY l.city`).Column(&cities)
+
+	return cities, err
+}
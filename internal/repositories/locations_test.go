@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestLocationSlug(t *testing.T) {
+	for name, want := range map[[2]string]string{
+		{"San Francesco", "Assisi"}:       "san-francesco-assisi",
+		{"Museo del Prado", "Madrid"}:     "museo-del-prado-madrid",
+		{"Musée du Louvre", "Paris"}:      "musee-du-louvre-paris",
+		{"  Kunsthistorisches  ", "Wien"}: "kunsthistorisches-wien",
+	} {
+		if got := LocationSlug(name[0], name[1]); got != want {
+			t.Errorf("LocationSlug(%q, %q) = %q, want %q", name[0], name[1], got, want)
+		}
+	}
+}
+
+func TestLocationsRepositoryListsTheHoldings(t *testing.T) {
+	app := newLocationsTestApp(t)
+	repo := NewLocationsRepository(app)
+
+	locations, err := repo.GetLocations()
+	if err != nil {
+		t.Fatalf("get locations: %v", err)
+	}
+
+	type holding struct {
+		Slug  string
+		Count int
+	}
+
+	got := []holding{}
+	for _, l := range locations {
+		got = append(got, holding{l.Slug, l.ArtworkCount})
+	}
+
+	// the locations without published artworks are left out, the ones without a country come last
+	want := []holding{{"san-francesco-assisi", 1}, {"museo-del-prado-madrid", 2}, {"private-collection-london", 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("locations = %v, want %v", got, want)
+	}
+
+	cities, err := repo.GetCities()
+	if err != nil {
+		t.Fatalf("get cities: %v", err)
+	}
+
+	if want := []string{"Assisi", "London", "Madrid"}; !reflect.DeepEqual(cities, want) {
+		t.Fatalf("cities = %v, want %v", cities, want)
+	}
+}
+
+func TestLocationFilters(t *testing.T) {
+	app := newLocationsTestApp(t)
+
+	for _, c := range []struct {
+		filter string
+		params dbx.Params
+		want   []string
+	}{
+		{LocationFilter, dbx.Params{"location": "museo-del-prado-madrid"}, []string{"artwork00000001", "artwork00000002"}},
+		{CityFilter, dbx.Params{"city": "assisi"}, []string{"artwork00000003"}},
+		{CityFilter, dbx.Params{"city": "Rome"}, []string{}},
+	} {
+		records, err := app.FindRecordsByFilter("artworks", "published = true && "+c.filter, "+id", 0, 0, c.params)
+		if err != nil {
+			t.Fatalf("%s: %v", c.filter, err)
+		}
+
+		if got := recordIds(records); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s %v matched %v, want %v", c.filter, c.params, got, c.want)
+		}
+	}
+}
+
+func newLocationsTestApp(t *testing.T) *tests.TestApp {
+	t.Helper()
+
+	app := newArtworksTestApp(t)
+
+	for _, values := range []map[string]any{
+		{"id": "location0000001", "name": "Museo del Prado", "slug": "museo-del-prado-madrid", "kind": LocationMuseum, "city": "Madrid", "country": "Spain"},
+		{"id": "location0000002", "name": "San Francesco", "slug": "san-francesco-assisi", "kind": LocationChurch, "city": "Assisi", "country": "Italy"},
+		{"id": "location0000003", "name": "Private collection", "slug": "private-collection-london", "kind": LocationPrivateCollection, "city": "London"},
+		{"id": "location0000004", "name": "Empty museum", "slug": "empty-museum-paris", "kind": LocationMuseum, "city": "Paris", "country": "France"},
+	} {
+		saveRecordValues(t, app, "locations", values)
+	}
+
+	for _, values := range []map[string]any{
+		{"id": "artwork00000001", "title": "Las Meninas", "published": true, "location": "location0000001"},
+		{"id": "artwork00000002", "title": "The Garden of Earthly Delights", "published": true, "location": "location0000001"},
+		{"id": "artwork00000003", "title": "Legend of St Francis", "published": true, "location": "location0000002"},
+		{"id": "artwork00000004", "title": "Portrait", "published": true, "location": "location0000003"},
+		{"id": "artwork00000005", "title": "Unpublished", "location": "location0000004"},
+	} {
+		saveRecordValues(t, app, "artworks", values)
+	}
+
+	return app
+}
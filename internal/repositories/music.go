@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+type MusicRepository struct {
+	app core.App
+}
+
+// MusicTrack is a single song joined with the composer it is credited to.
+// Songs credited to several composers are returned once per composer.
+type MusicTrack struct {
+	Id           string `db:"id"`
+	Title        string `db:"title"`
+	Source       string `db:"source"`
+	ComposerId   string `db:"composer_id"`
+	ComposerName string `db:"composer_name"`
+	Century      string `db:"century"`
+	Language     string `db:"language"`
+}
+
+const musicTrackSelect = `
+	SELECT s.id AS id, s.title AS title, s.source AS source,
+		c.id AS composer_id, c.name AS composer_name, c.century AS century, c.language AS language
+	FROM Music_song s
+	CROSS JOIN json_each(s.composer) je
+	JOIN Music_composer c ON je.value = c.id`
+
+func NewMusicRepository(app core.App) *MusicRepository {
+	return &MusicRepository{app: app}
+}
+
+// GetTracks returns every track ordered by century, language, composer and title.
+func (r *MusicRepository) GetTracks() ([]MusicTrack, error) {
+	rows := []MusicTrack{}
+	err := r.app.DB().NewQuery(musicTrackSelect + `
+		ORDER BY CAST(c.century AS INTEGER), c.language, c.name, c.id, s.title`).All(&rows)
+
+	return rows, err
+}
+
+// FindTrack returns the track with the given id, credited to its first composer.
+func (r *MusicRepository) FindTrack(id string) (MusicTrack, error) {
+	row := MusicTrack{}
+	err := r.app.DB().NewQuery(musicTrackSelect + `
+		WHERE s.id = {:id}
+		ORDER BY je.key
+		LIMIT 1`).Bind(dbx.Params{"id": id}).One(&row)
+
+	return row, err
+}
+
+// GetTracksByCentury returns up to limit random tracks composed in the given
+// century, leaving out the track with the excluded id.
+func (r *MusicRepository) GetTracksByCentury(century string, excludeId string, limit int) ([]MusicTrack, error) {
+	rows := []MusicTrack{}
+	err := r.app.DB().NewQuery(musicTrackSelect + `
+		WHERE c.century = {:century} AND s.id != {:exclude}
+		GROUP BY s.id
+		ORDER BY RANDOM()
+		LIMIT {:limit}`).Bind(dbx.Params{
+		"century": century,
+		"exclude": excludeId,
+		"limit":   limit,
+	}).All(&rows)
+
+	return rows, err
+}
@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/blackfyre/wga/internal/testutils"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestMusicRepositoryListsTracksWithComposers(t *testing.T) {
+	app := newMusicTestApp(t)
+
+	saveMusicComposer(t, app, "composer0000001", "Monteverdi", "17", "Italian")
+	saveMusicComposer(t, app, "composer0000002", "Palestrina", "16", "Italian")
+	saveMusicComposer(t, app, "composer0000003", "Purcell", "17", "English")
+
+	saveMusicTrack(t, app, "track0000000001", "L'Orfeo", "composer0000001")
+	saveMusicTrack(t, app, "track0000000002", "Sicut cervus", "composer0000002")
+	saveMusicTrack(t, app, "track0000000003", "Dido and Aeneas", "composer0000003")
+
+	repo := NewMusicRepository(app)
+
+	tracks, err := repo.GetTracks()
+	if err != nil {
+		t.Fatalf("get tracks: %v", err)
+	}
+
+	want := []string{"track0000000002", "track0000000003", "track0000000001"}
+	if len(tracks) != len(want) {
+		t.Fatalf("expected %d tracks, got %d", len(want), len(tracks))
+	}
+	for i, id := range want {
+		if tracks[i].Id != id {
+			t.Errorf("track %d = %s, want %s", i, tracks[i].Id, id)
+		}
+	}
+
+	track, err := repo.FindTrack("track0000000001")
+	if err != nil {
+		t.Fatalf("find track: %v", err)
+	}
+	if track.ComposerName != "Monteverdi" || track.Century != "17" {
+		t.Errorf("unexpected track: %+v", track)
+	}
+
+	suggestions, err := repo.GetTracksByCentury("17", "track0000000001", 5)
+	if err != nil {
+		t.Fatalf("get tracks by century: %v", err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Id != "track0000000003" {
+		t.Errorf("expected only the other 17th century track, got %+v", suggestions)
+	}
+}
+
+func newMusicTestApp(t *testing.T) *tests.TestApp {
+	t.Helper()
+
+	app := testutils.NewTestApp(t)
+
+	composers := core.NewBaseCollection("Music_composer")
+	composers.Id = "music_composer"
+	composers.Fields.Add(
+		&core.TextField{Name: "name"},
+		&core.SelectField{Name: "century", Values: []string{"16", "17"}, MaxSelect: 1},
+		&core.TextField{Name: "language"},
+	)
+	if err := app.Save(composers); err != nil {
+		t.Fatalf("save composers collection: %v", err)
+	}
+
+	songs := core.NewBaseCollection("Music_song")
+	songs.Id = "music_song"
+	songs.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.RelationField{Name: "composer", CollectionId: composers.Id, MaxSelect: 20},
+		&core.TextField{Name: "source"},
+	)
+	if err := app.Save(songs); err != nil {
+		t.Fatalf("save songs collection: %v", err)
+	}
+
+	return app
+}
+
+func saveMusicComposer(t *testing.T, app core.App, id, name, century, language string) {
+	t.Helper()
+
+	collection, err := app.FindCollectionByNameOrId("music_composer")
+	if err != nil {
+		t.Fatalf("find composers collection: %v", err)
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("id", id)
+	record.Set("name", name)
+	record.Set("century", century)
+	record.Set("language", language)
+	if err := app.Save(record); err != nil {
+		t.Fatalf("save composer: %v", err)
+	}
+}
+
+func saveMusicTrack(t *testing.T, app core.App, id, title, composerId string) {
+	t.Helper()
+
+	collection, err := app.FindCollectionByNameOrId("music_song")
+	if err != nil {
+		t.Fatalf("find songs collection: %v", err)
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("id", id)
+	record.Set("title", title)
+	record.Set("composer", []string{composerId})
+	if err := app.Save(record); err != nil {
+		t.Fatalf("save track: %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+type PeriodsRepository struct {
+	app core.App
+}
+
+type ArtPeriod struct {
+	Id          string `db:"id"`
+	Name        string `db:"name"`
+	Slug        string `db:"slug"`
+	Start       int    `db:"start"`
+	End         int    `db:"end"`
+	Description string `db:"description"`
+	ArtistCount int    `db:"artist_count"`
+}
+
+// activeInPeriodSQL matches the artists who were alive during the period.
+// Artists without a known year of death count when they were born within the period.
+const activeInPeriodSQL = `a.published IS true
+	AND a.year_of_birth > 0
+	AND a.year_of_birth <= p."end"
+	AND (a.year_of_death >= p.start OR (a.year_of_death = 0 AND a.year_of_birth >= p.start))`
+
+func NewPeriodsRepository(app core.App) *PeriodsRepository {
+	return &PeriodsRepository{app: app}
+}
+
+// GetPeriods returns every period in chronological order, with the number of
+// published artists active in each.
+func (r *PeriodsRepository) GetPeriods() ([]ArtPeriod, error) {
+	rows := []ArtPeriod{}
+	err := r.app.DB().NewQuery(`
+		SELECT p.id AS id, p.name AS name, p.slug AS slug, p.start AS start, p."end" AS "end",
+			p.description AS description, COUNT(a.id) AS artist_count
+		FROM Art_periods p
+		LEFT JOIN Artists a ON ` + activeInPeriodSQL + `
+		GROUP BY p.id
+		ORDER BY p.start, p."end"`).All(&rows)
+
+	return rows, err
+}
+
+func (r *PeriodsRepository) FindPeriodBySlug(slug string) (ArtPeriod, error) {
+	row := ArtPeriod{}
+	err := r.app.DB().NewQuery(`
+		SELECT id, name, slug, start, "end", description
+		FROM Art_periods
+		WHERE slug = {:slug}
+		LIMIT 1`).Bind(dbx.Params{"slug": slug}).One(&row)
+
+	return row, err
+}
+
+// CountActiveArtists returns the number of published artists active during the period.
+func (r *PeriodsRepository) CountActiveArtists(periodId string) (int, error) {
+	row := countRow{}
+	err := r.app.DB().NewQuery(`
+		SELECT COUNT(a.id) AS c
+		FROM Art_periods p
+		JOIN Artists a ON ` + activeInPeriodSQL + `
+		WHERE p.id = {:id}`).Bind(dbx.Params{"id": periodId}).One(&row)
+
+	return row.Count, err
+}
+
+// ActiveInPeriodFilter returns a record filter expression that matches artists
+// active during a period, following the same rules as the period artist counts.
+// The prefix addresses the artist fields through a relation, e.g. "author.".
+// The expression expects the params returned by ArtPeriod.FilterParams.
+func ActiveInPeriodFilter(prefix string) string {
+	return "(" + prefix + "year_of_birth > 0" +
+		" && " + prefix + "year_of_birth <= {:period_end}" +
+		" && (" + prefix + "year_of_death >= {:period_start} || (" + prefix + "year_of_death = 0 && " + prefix + "year_of_birth >= {:period_start})))"
+}
+
+// Includes reports whether an artist born and died in the given years was active
+// during the period, following the same rules as ActiveInPeriodFilter.
+// A zero year of death means it is unknown.
+func (p ArtPeriod) Includes(yearOfBirth int, yearOfDeath int) bool {
+	if yearOfBirth <= 0 || yearOfBirth > p.End {
+		return false
+	}
+
+	return yearOfDeath >= p.Start || (yearOfDeath == 0 && yearOfBirth >= p.Start)
+}
+
+func (p ArtPeriod) FilterParams() dbx.Params {
+	return dbx.Params{
+		"period_start": p.Start,
+		"period_end":   p.End,
+	}
+}
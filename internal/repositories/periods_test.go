@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/blackfyre/wga/internal/testutils"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestPeriodsRepositoryCountsActiveArtists(t *testing.T) {
+	app := newPeriodsTestApp(t)
+
+	savePeriod(t, app, "period000000001", "Renaissance", "renaissance", 1400, 1600)
+	savePeriod(t, app, "period000000002", "Baroque", "baroque", 1600, 1750)
+
+	saveRecordValues(t, app, "artists", map[string]any{"id": "artist000000001", "name": "Botticelli", "year_of_birth": 1445, "year_of_death": 1510, "published": true})
+	saveRecordValues(t, app, "artists", map[string]any{"id": "artist000000002", "name": "Rubens", "year_of_birth": 1577, "year_of_death": 1640, "published": true})
+	saveRecordValues(t, app, "artists", map[string]any{"id": "artist000000003", "name": "Unknown death", "year_of_birth": 1620, "published": true})
+	saveRecordValues(t, app, "artists", map[string]any{"id": "artist000000004", "name": "Unpublished", "year_of_birth": 1450, "year_of_death": 1500})
+	saveRecordValues(t, app, "artists", map[string]any{"id": "artist000000005", "name": "Undated", "published": true})
+
+	repo := NewPeriodsRepository(app)
+
+	periods, err := repo.GetPeriods()
+	if err != nil {
+		t.Fatalf("get periods: %v", err)
+	}
+
+	got := map[string]int{}
+	for _, p := range periods {
+		got[p.Slug] = p.ArtistCount
+	}
+	want := map[string]int{"renaissance": 2, "baroque": 2}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("artist counts = %v, want %v", got, want)
+	}
+
+	baroque, err := repo.FindPeriodBySlug("baroque")
+	if err != nil {
+		t.Fatalf("find period: %v", err)
+	}
+
+	count, err := repo.CountActiveArtists(baroque.Id)
+	if err != nil {
+		t.Fatalf("count active artists: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 baroque artists, got %d", count)
+	}
+
+	artists, err := app.FindRecordsByFilter("artists", "published = true && "+ActiveInPeriodFilter(""), "+name", 0, 0, baroque.FilterParams())
+	if err != nil {
+		t.Fatalf("filter artists: %v", err)
+	}
+	if len(artists) != count {
+		t.Fatalf("record filter matched %d artists, SQL count is %d", len(artists), count)
+	}
+
+	included := 0
+	for _, born := range [][2]int{{1445, 1510}, {1577, 1640}, {1620, 0}, {0, 0}} {
+		if baroque.Includes(born[0], born[1]) {
+			included++
+		}
+	}
+	if included != count {
+		t.Fatalf("Includes matched %d published artists, SQL count is %d", included, count)
+	}
+}
+
+func TestActiveInPeriodFilterThroughRelation(t *testing.T) {
+	app := newPeriodsTestApp(t)
+
+	saveRecordValues(t, app, "artists", map[string]any{"id": "artist000000001", "name": "Botticelli", "year_of_birth": 1445, "year_of_death": 1510})
+	saveRecordValues(t, app, "artists", map[string]any{"id": "artist000000002", "name": "Rubens", "year_of_birth": 1577, "year_of_death": 1640})
+	saveRecordValues(t, app, "artworks", map[string]any{"id": "artwork00000001", "author": []string{"artist000000001"}})
+	saveRecordValues(t, app, "artworks", map[string]any{"id": "artwork00000002", "author": []string{"artist000000002"}})
+
+	period := ArtPeriod{Start: 1400, End: 1500}
+
+	artworks, err := app.FindRecordsByFilter("artworks", ActiveInPeriodFilter("author."), "", 0, 0, period.FilterParams())
+	if err != nil {
+		t.Fatalf("filter artworks: %v", err)
+	}
+	if len(artworks) != 1 || artworks[0].Id != "artwork00000001" {
+		t.Fatalf("expected only the 15th century artwork, got %v", artworks)
+	}
+}
+
+func newPeriodsTestApp(t *testing.T) *tests.TestApp {
+	t.Helper()
+
+	app := testutils.NewTestApp(t)
+
+	periods := core.NewBaseCollection("Art_periods")
+	periods.Id = "art_periods"
+	periods.Fields.Add(
+		&core.TextField{Name: "name"},
+		&core.TextField{Name: "slug"},
+		&core.NumberField{Name: "start"},
+		&core.NumberField{Name: "end"},
+		&core.TextField{Name: "description"},
+	)
+	if err := app.Save(periods); err != nil {
+		t.Fatalf("save periods collection: %v", err)
+	}
+
+	artists := core.NewBaseCollection("Artists")
+	artists.Id = "artists"
+	artists.Fields.Add(
+		&core.TextField{Name: "name"},
+		&core.NumberField{Name: "year_of_birth"},
+		&core.NumberField{Name: "year_of_death"},
+		&core.BoolField{Name: "published"},
+	)
+	if err := app.Save(artists); err != nil {
+		t.Fatalf("save artists collection: %v", err)
+	}
+
+	artworks := core.NewBaseCollection("Artworks")
+	artworks.Id = "artworks"
+	artworks.Fields.Add(&core.RelationField{Name: "author", CollectionId: artists.Id, MaxSelect: 10})
+	if err := app.Save(artworks); err != nil {
+		t.Fatalf("save artworks collection: %v", err)
+	}
+
+	return app
+}
+
+func savePeriod(t *testing.T, app core.App, id, name, slug string, start, end int) {
+	t.Helper()
+
+	saveRecordValues(t, app, "art_periods", map[string]any{
+		"id":    id,
+		"name":  name,
+		"slug":  slug,
+		"start": start,
+		"end":   end,
+	})
+}
+
+func saveRecordValues(t *testing.T, app core.App, collection string, values map[string]any) {
+	t.Helper()
+
+	c, err := app.FindCollectionByNameOrId(collection)
+	if err != nil {
+		t.Fatalf("find %s collection: %v", collection, err)
+	}
+
+	record := core.NewRecord(c)
+	for key, value := range values {
+		record.Set(key, value)
+	}
+
+	if err := app.Save(record); err != nil {
+		t.Fatalf("save %s record: %v", collection, err)
+	}
+}
@@ -0,0 +1,263 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks a record in a keyset ordered list by its sort key and id.
+type Cursor struct {
+	Key string
+	Id  string
+}
+
+// String encodes the cursor for use in urls.
+func (c Cursor) String() string {
+	raw, _ := json.Marshal([2]string{c.Key, c.Id})
+
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// ParseCursor decodes a cursor encoded by Cursor.String.
+func ParseCursor(value string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	parts := [2]string{}
+	if err := json.Unmarshal(raw, &parts); err != nil || parts[1] == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return Cursor{Key: parts[0], Id: parts[1]}, nil
+}
+
+// RecordFilter matches the records of a collection by a filter expression and its params.
+// When Ids is not nil, only the records with the listed ids match. The ids are compared in
+// SQL, as a filter expression per id would exceed the expression limit of the filters.
+// CollapseSeries keeps one artwork of every series among the matches, see seriesCollapseExpr.
+type RecordFilter struct {
+	Filter         string
+	Params         dbx.Params
+	Ids            []string
+	CollapseSeries bool
+}
+
+// PageQuery selects a page of the records matching a record filter.
+// The page starts after After or ends before Before when either is set,
+// and at Offset otherwise.
+type PageQuery struct {
+	RecordFilter
+	Limit  int
+	Offset int
+	After  *Cursor
+	Before *Cursor
+}
+
+// SetCursors sets After and Before from their encoded form, leaving empty ones unset.
+func (q *PageQuery) SetCursors(after string, before string) error {
+	for _, c := range []struct {
+		value  string
+		target **Cursor
+	}{
+		{after, &q.After},
+		{before, &q.Before},
+	} {
+		if c.value == "" {
+			continue
+		}
+
+		cursor, err := ParseCursor(c.value)
+		if err != nil {
+			return err
+		}
+
+		*c.target = &cursor
+	}
+
+	return nil
+}
+
+// Page is a page of records with the cursors of its first and last record,
+// which are nil when the page is empty. More reports whether more records follow
+// the page in the direction it was read, before it when it was read from Before.
+type Page struct {
+	Records []*core.Record
+	First   *Cursor
+	Last    *Cursor
+	More    bool
+}
+
+// keysetLister pages the records of a collection ordered by sortField, then by id,
+// so records with the same sort key keep a stable order.
+type keysetLister struct {
+	app        core.App
+	collection string
+	sortField  string
+}
+
+// FilterQuery selects the records of the collection matching the filter, with the joins
+// the filter needs.
+func FilterQuery(app core.App, collection *core.Collection, f RecordFilter) (*dbx.SelectQuery, error) {
+	q := app.RecordQuery(collection)
+
+	resolver := core.NewRecordFieldResolver(app, collection, nil, true)
+
+	if f.Filter != "" {
+		expr, err := search.FilterData(f.Filter).BuildExpr(resolver, f.Params)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter expression: %w", err)
+		}
+		q.AndWhere(expr)
+	}
+
+	if f.Ids != nil {
+		ids := make([]any, 0, len(f.Ids))
+		for _, id := range f.Ids {
+			ids = append(ids, id)
+		}
+
+		q.AndWhere(dbx.In("{{"+collection.Name+"}}.[[id]]", ids...))
+	}
+
+	if f.CollapseSeries {
+		expr, err := seriesCollapseExpr(app, collection, f)
+		if err != nil {
+			return nil, err
+		}
+		q.AndWhere(expr)
+	}
+
+	if err := resolver.UpdateQuery(q); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// query selects the records matching the filter, with the joins the filter needs.
+func (l keysetLister) query(f RecordFilter) (*dbx.SelectQuery, *core.Collection, error) {
+	collection, err := l.app.FindCollectionByNameOrId(l.collection)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	q, err := FilterQuery(l.app, collection, f)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return q, collection, nil
+}
+
+// count returns the number of records matching the filter with a single COUNT query.
+func (l keysetLister) count(f RecordFilter) (int, error) {
+	q, collection, err := l.query(f)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = q.Distinct(false).
+		Select("COUNT(DISTINCT {{" + collection.Name + "}}.[[id]])").
+		Row(&count)
+
+	return count, err
+}
+
+func (l keysetLister) findPage(pq PageQuery) (Page, error) {
+	q, collection, err := l.query(pq.RecordFilter)
+	if err != nil {
+		return Page{}, err
+	}
+
+	sortColumn := "{{" + collection.Name + "}}.[[" + l.sortField + "]]"
+	idColumn := "{{" + collection.Name + "}}.[[id]]"
+
+	var cursor *Cursor
+	operator := ">"
+	direction := "ASC"
+
+	switch {
+	case pq.After != nil:
+		cursor = pq.After
+	case pq.Before != nil:
+		// walk backwards from the cursor, the page is reversed below
+		cursor = pq.Before
+		operator = "<"
+		direction = "DESC"
+	}
+
+	if cursor != nil {
+		// a row value comparison, unlike the equivalent OR of comparisons,
+		// lets SQLite seek the (sort field, id) index instead of scanning it
+		q.AndWhere(dbx.NewExp(
+			fmt.Sprintf("(%s, %s) %s ({:cursor_key}, {:cursor_id})", sortColumn, idColumn, operator),
+			dbx.Params{"cursor_key": cursor.Key, "cursor_id": cursor.Id},
+		))
+	} else if pq.Offset > 0 {
+		q.Offset(int64(pq.Offset))
+	}
+
+	q.OrderBy(sortColumn+" "+direction, idColumn+" "+direction)
+
+	if pq.Limit > 0 {
+		// one record more than the page tells whether the list goes on
+		q.Limit(int64(pq.Limit + 1))
+	}
+
+	records := []*core.Record{}
+	if err := q.All(&records); err != nil {
+		return Page{}, err
+	}
+
+	more := pq.Limit > 0 && len(records) > pq.Limit
+	if more {
+		records = records[:pq.Limit]
+	}
+
+	if pq.After == nil && pq.Before != nil {
+		slices.Reverse(records)
+	}
+
+	page := Page{Records: records, More: more}
+
+	if len(records) > 0 {
+		page.First = l.cursorOf(records[0])
+		page.Last = l.cursorOf(records[len(records)-1])
+	}
+
+	return page, nil
+}
+
+func (l keysetLister) cursorOf(record *core.Record) *Cursor {
+	return &Cursor{Key: record.GetString(l.sortField), Id: record.Id}
+}
+
+// expandRelations loads the related records of every relation field into the records'
+// expand data, with one query per relation whatever the number of records.
+func expandRelations(app core.App, records []*core.Record, fields ...string) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	failed := app.ExpandRecords(records, fields, nil)
+
+	errs := make([]error, 0, len(failed))
+	for _, field := range slices.Sorted(maps.Keys(failed)) {
+		errs = append(errs, fmt.Errorf("expand %s: %w", field, failed[field]))
+	}
+
+	return errors.Join(errs...)
+}
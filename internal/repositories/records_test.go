@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/blackfyre/wga/internal/testutils"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func TestFilterQueryComparesIdsInSQL(t *testing.T) {
+	app := testutils.NewTestApp(t)
+
+	collection := core.NewBaseCollection("artworks")
+	collection.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.BoolField{Name: "published"},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatalf("save collection: %v", err)
+	}
+
+	// more ids than the filter expressions PocketBase allows
+	ids := []string{}
+	for i := range 300 {
+		record := core.NewRecord(collection)
+		record.Id = fmt.Sprintf("madonna%08d", i)
+		record.Set("title", "Madonna")
+		record.Set("published", i%2 == 0)
+		if err := app.Save(record); err != nil {
+			t.Fatalf("save record: %v", err)
+		}
+		ids = append(ids, record.Id)
+	}
+
+	records, err := findRecords(t, app, collection, RecordFilter{
+		Filter: "published = {:published}",
+		Params: dbx.Params{"published": true},
+		Ids:    ids[:250],
+	})
+	if err != nil {
+		t.Fatalf("FilterQuery: %v", err)
+	}
+	if len(records) != 125 {
+		t.Fatalf("found %d records, want the 125 published ones among the ids", len(records))
+	}
+
+	records, err = findRecords(t, app, collection, RecordFilter{Ids: []string{}})
+	if err != nil || len(records) != 0 {
+		t.Fatalf("expected an empty id list to match nothing, got %d, %v", len(records), err)
+	}
+
+	records, err = findRecords(t, app, collection, RecordFilter{Filter: "title = 'Madonna'"})
+	if err != nil || !slices.ContainsFunc(records, func(r *core.Record) bool { return r.Id == ids[299] }) {
+		t.Fatalf("expected nil ids not to restrict the records, got %d, %v", len(records), err)
+	}
+}
+
+func findRecords(t *testing.T, app core.App, collection *core.Collection, f RecordFilter) ([]*core.Record, error) {
+	t.Helper()
+
+	q, err := FilterQuery(app, collection, f)
+	if err != nil {
+		return nil, err
+	}
+
+	records := []*core.Record{}
+	err = q.All(&records)
+
+	return records, err
+}
@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/utils/dating"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/dbutils"
+)
+
+// Points a related artwork scores for each author, school, form and type it shares
+// with the artwork, and for each keyword of the technique it shares.
+const (
+	relatedAuthorPoints  = 8
+	relatedSchoolPoints  = 3
+	relatedFormPoints    = 2
+	relatedTypePoints    = 2
+	relatedKeywordPoints = 1
+)
+
+// relatedDatePoints are scored by a related artwork dated within relatedDateStep years
+// of the artwork, one point less for every further step.
+const (
+	relatedDatePoints = 3
+	relatedDateStep   = 10
+)
+
+// techniqueStopWords are left out of the technique keywords, they do not tell techniques apart.
+var techniqueStopWords = map[string]bool{
+	"and": true, "on": true, "in": true, "of": true, "with": true, "the": true, "cm": true, "mm": true,
+}
+
+// FindRelated returns up to limit published artworks related to the artwork, the most related first.
+// Artworks score points for what they share with it: authors, schools, forms, types and
+// technique keywords, and a date close to its date when both are dated.
+// Artworks sharing nothing are never related.
+func (r *ArtworksRepository) FindRelated(artwork *core.Record, limit int) ([]*core.Record, error) {
+	collection, err := r.app.FindCollectionByNameOrId(constants.CollectionArtworks)
+	if err != nil {
+		return nil, err
+	}
+
+	column := func(name string) string {
+		return "{{" + collection.Name + "}}.[[" + name + "]]"
+	}
+
+	terms := []string{}
+	params := dbx.Params{"related_id": artwork.Id}
+
+	for _, relation := range []struct {
+		field  string
+		points int
+	}{
+		{"author", relatedAuthorPoints},
+		{"school", relatedSchoolPoints},
+		{"form", relatedFormPoints},
+		{"type", relatedTypePoints},
+	} {
+		ids := artwork.GetStringSlice(relation.field)
+		if len(ids) == 0 {
+			continue
+		}
+
+		placeholders := make([]string, 0, len(ids))
+		for index, id := range ids {
+			key := fmt.Sprintf("related_%s_%d", relation.field, index)
+			placeholders = append(placeholders, "{:"+key+"}")
+			params[key] = id
+		}
+
+		terms = append(terms, fmt.Sprintf(
+			"%d * (SELECT COUNT(*) FROM %s WHERE [[value]] IN (%s))",
+			relation.points,
+			dbutils.JSONEach(collection.Name+"."+relation.field),
+			strings.Join(placeholders, ", "),
+		))
+	}
+
+	for index, keyword := range TechniqueKeywords(artwork.GetString("technique")) {
+		key := fmt.Sprintf("related_keyword_%d", index)
+		terms = append(terms, fmt.Sprintf("%d * (%s LIKE {:%s})", relatedKeywordPoints, column("technique"), key))
+		params[key] = "%" + keyword + "%"
+	}
+
+	if d := dating.FromRecord(artwork); !d.IsZero() {
+		// midpoints are doubled to stay in integers
+		terms = append(terms, fmt.Sprintf(
+			"(CASE WHEN %s > 0 THEN MAX(0, %d - ABS(%s + %s - {:related_midpoint}) / %d) ELSE 0 END)",
+			column(dating.FieldEarliest),
+			relatedDatePoints,
+			column(dating.FieldEarliest),
+			column(dating.FieldLatest),
+			2*relatedDateStep,
+		))
+		params["related_midpoint"] = d.Earliest + d.Latest
+	}
+
+	if len(terms) == 0 {
+		return []*core.Record{}, nil
+	}
+
+	score := "(" + strings.Join(terms, " + ") + ")"
+
+	records := []*core.Record{}
+
+	err = r.app.RecordQuery(collection).
+		AndWhere(dbx.NewExp(column("published")+" = TRUE AND "+column("id")+" != {:related_id}", params)).
+		AndWhere(dbx.NewExp(dbutils.JSONArrayLength(collection.Name+".author")+" > 0")).
+		AndWhere(dbx.NewExp(score+" > 0")).
+		OrderBy(score+" DESC", column("title"), column("id")).
+		Limit(int64(limit)).
+		All(&records)
+
+	return records, err
+}
+
+// TechniqueKeywords returns the distinct lowercase words of a technique, like oil and canvas
+// for "Oil on canvas, 101 x 201 cm", without numbers and stop words.
+func TechniqueKeywords(technique string) []string {
+	keywords := []string{}
+	seen := map[string]bool{}
+
+	for _, word := range strings.FieldsFunc(strings.ToLower(technique), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		if len([]rune(word)) < 2 || techniqueStopWords[word] || seen[word] {
+			continue
+		}
+
+		seen[word] = true
+		keywords = append(keywords, word)
+	}
+
+	return keywords
+}
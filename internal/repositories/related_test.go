@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindRelatedRanksWhatIsShared(t *testing.T) {
+	app := newArtworksTestApp(t)
+	seedArtworks(t, app, 0)
+	saveRecordValues(t, app, "artists", map[string]any{"id": "artist000000001", "name": "Workshop", "published": true})
+
+	for _, values := range []map[string]any{
+		{"id": "related00000001", "title": "Birth of Venus", "author": []string{"artist000000000"}, "school": []string{"scho1"}, "technique": "Tempera on canvas", "date_earliest": 1485, "date_latest": 1486},
+		// same author
+		{"id": "related00000002", "title": "Primavera", "author": []string{"artist000000000"}, "technique": "Tempera on panel"},
+		// same school and technique, close in date
+		{"id": "related00000003", "title": "Annunciation", "school": []string{"scho1"}, "technique": "Tempera on canvas", "date_earliest": 1489, "date_latest": 1490},
+		// same school and technique, a century apart
+		{"id": "related00000004", "title": "Adoration", "school": []string{"scho1"}, "technique": "Tempera on canvas", "date_earliest": 1585, "date_latest": 1585},
+		// nothing shared
+		{"id": "related00000005", "title": "Still life", "school": []string{"scho2"}, "technique": "Watercolour"},
+		// unpublished
+		{"id": "related00000006", "title": "Draft", "author": []string{"artist000000000"}, "published": false},
+	} {
+		if _, ok := values["published"]; !ok {
+			values["published"] = true
+		}
+		if _, ok := values["author"]; !ok {
+			values["author"] = []string{"artist000000001"}
+		}
+		saveRecordValues(t, app, "artworks", values)
+	}
+
+	repo := NewArtworksRepository(app)
+
+	artwork, err := app.FindRecordById("artworks", "related00000001")
+	if err != nil {
+		t.Fatalf("find artwork: %v", err)
+	}
+
+	related, err := repo.FindRelated(artwork, 10)
+	if err != nil {
+		t.Fatalf("FindRelated: %v", err)
+	}
+
+	want := []string{"related00000002", "related00000003", "related00000004"}
+	if got := recordIds(related); !reflect.DeepEqual(got, want) {
+		t.Fatalf("related = %v, want %v", got, want)
+	}
+
+	limited, err := repo.FindRelated(artwork, 1)
+	if err != nil || len(limited) != 1 {
+		t.Fatalf("FindRelated with a limit = %v, %v", recordIds(limited), err)
+	}
+}
+
+func TestTechniqueKeywords(t *testing.T) {
+	got := TechniqueKeywords("Oil on canvas, 101 x 201 cm, oil and gold")
+	want := []string{"oil", "canvas", "gold"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("TechniqueKeywords = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,197 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Kinds of the series grouping artworks.
+const (
+	SeriesCycle     = "cycle"
+	SeriesPolyptych = "polyptych"
+	SeriesPrints    = "print_series"
+	SeriesOther     = "other"
+)
+
+// SeriesKind is a kind of series with the label the pages show.
+type SeriesKind struct {
+	Value string
+	Label string
+}
+
+// SeriesKinds are the kinds a series can be.
+var SeriesKinds = []SeriesKind{
+	{SeriesCycle, "Cycle"},
+	{SeriesPolyptych, "Polyptych"},
+	{SeriesPrints, "Print series"},
+	{SeriesOther, "Other"},
+}
+
+// SeriesKindValues returns the values of the series kinds.
+func SeriesKindValues() []string {
+	values := make([]string, 0, len(SeriesKinds))
+	for _, k := range SeriesKinds {
+		values = append(values, k.Value)
+	}
+
+	return values
+}
+
+// SeriesKindLabel returns the label of the kind, empty for an unknown kind.
+func SeriesKindLabel(kind string) string {
+	for _, k := range SeriesKinds {
+		if k.Value == kind {
+			return k.Label
+		}
+	}
+
+	return ""
+}
+
+// seriesCollapseExpr keeps the artworks outside of a series and one part of every series among
+// the artworks matching the filter: the cover when it matches, otherwise the first matching part
+// in the order of the series. The series are collapsed after filtering, so a series whose cover
+// does not match is still shown by another part.
+func seriesCollapseExpr(app core.App, collection *core.Collection, f RecordFilter) (dbx.Expression, error) {
+	f.CollapseSeries = false
+
+	matches, err := FilterQuery(app, collection, f)
+	if err != nil {
+		return nil, err
+	}
+
+	// the matches are built on their own and number their id params from p0, like the
+	// outer query does, so their params are renamed
+	built := matches.Select("{{" + collection.Name + "}}.[[id]]").Build()
+	matchesSQL := built.SQL()
+	params := dbx.Params{}
+
+	for name, value := range built.Params() {
+		matchesSQL = strings.ReplaceAll(matchesSQL, "{:"+name+"}", "{:collapse_"+name+"}")
+		params["collapse_"+name] = value
+	}
+
+	// the parts rank by being the cover first, then in the order of the series
+	rank := func(table string) string {
+		return fmt.Sprintf("(%[1]s.[[id]] != [[s.cover]], %[1]s.[[series_position]], %[1]s.[[created]], %[1]s.[[id]])", table)
+	}
+
+	artworks := "{{" + collection.Name + "}}"
+
+	return dbx.NewExp(fmt.Sprintf(
+		"%[1]s.[[series]] = '' OR NOT EXISTS ("+
+			"SELECT 1 FROM %[1]s [[p]] JOIN {{%[2]s}} [[s]] ON [[s.id]] = [[p.series]] "+
+			"WHERE [[p.series]] = %[1]s.[[series]] AND [[p.id]] IN (%[3]s) AND %[4]s < %[5]s)",
+		artworks,
+		constants.CollectionSeries,
+		matchesSQL,
+		rank("[[p]]"),
+		rank(artworks),
+	), params), nil
+}
+
+// seriesPartsFilter matches the parts of a series shown on the pages, expecting the series id
+// as the series param.
+const seriesPartsFilter = "published = true && author:length > 0 && series = {:series}"
+
+type SeriesRepository struct {
+	app core.App
+}
+
+func NewSeriesRepository(app core.App) *SeriesRepository {
+	return &SeriesRepository{app: app}
+}
+
+// FindParts returns the published parts of the series in their order, the parts at the same
+// position in the order they were added.
+func (r *SeriesRepository) FindParts(seriesId string) ([]*core.Record, error) {
+	return r.app.FindRecordsByFilter(
+		constants.CollectionArtworks,
+		seriesPartsFilter,
+		"+series_position,+created,+id",
+		0,
+		0,
+		dbx.Params{"series": seriesId},
+	)
+}
+
+// CountParts returns the number of published parts of each of the series.
+func (r *SeriesRepository) CountParts(seriesIds []string) (map[string]int, error) {
+	counts := map[string]int{}
+	if len(seriesIds) == 0 {
+		return counts, nil
+	}
+
+	ids := make([]any, 0, len(seriesIds))
+	for _, id := range seriesIds {
+		ids = append(ids, id)
+	}
+
+	rows := []struct {
+		Series string `db:"series"`
+		Count  int    `db:"parts"`
+	}{}
+
+	err := r.app.DB().
+		Select("series", "COUNT(*) AS parts").
+		From(constants.CollectionArtworks).
+		Where(dbx.In("series", ids...)).
+		AndWhere(dbx.HashExp{"published": true}).
+		AndWhere(dbx.NewExp("json_array_length(author) > 0")).
+		GroupBy("series").
+		All(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.Series] = row.Count
+	}
+
+	return counts, nil
+}
+
+// RefreshCover keeps the cover of the series one of its published parts: a cover picked by
+// the editors is kept while it is one, otherwise the first part becomes the cover. A series
+// without published parts has no cover. Deleted series are ignored.
+func (r *SeriesRepository) RefreshCover(seriesId string) error {
+	series, err := r.app.FindRecordById(constants.CollectionSeries, seriesId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	parts, err := r.FindParts(seriesId)
+	if err != nil {
+		return err
+	}
+
+	current := series.GetString("cover")
+	cover := ""
+
+	for i, part := range parts {
+		if i == 0 || part.Id == current {
+			cover = part.Id
+		}
+
+		if part.Id == current {
+			break
+		}
+	}
+
+	if cover == current {
+		return nil
+	}
+
+	series.Set("cover", cover)
+
+	return r.app.Save(series)
+}
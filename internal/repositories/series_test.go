@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestSeriesRepositoryFindsThePartsInOrder(t *testing.T) {
+	app := newSeriesTestApp(t)
+	repo := NewSeriesRepository(app)
+
+	parts, err := repo.FindParts("series000000001")
+	if err != nil {
+		t.Fatalf("find parts: %v", err)
+	}
+
+	// the unpublished part and the part without an author are left out
+	if got, want := recordIds(parts), []string{"artwork00000002", "artwork00000001", "artwork00000003"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("parts = %v, want %v", got, want)
+	}
+
+	counts, err := repo.CountParts([]string{"series000000001", "series000000002"})
+	if err != nil {
+		t.Fatalf("count parts: %v", err)
+	}
+
+	if want := map[string]int{"series000000001": 3}; !reflect.DeepEqual(counts, want) {
+		t.Fatalf("counts = %v, want %v", counts, want)
+	}
+}
+
+func TestSeriesRepositoryRefreshesTheCover(t *testing.T) {
+	app := newSeriesTestApp(t)
+	repo := NewSeriesRepository(app)
+
+	for _, c := range []struct {
+		series string
+		cover  string
+		want   string
+	}{
+		// the first part becomes the cover
+		{"series000000001", "", "artwork00000002"},
+		// a cover picked among the published parts is kept
+		{"series000000001", "artwork00000003", "artwork00000003"},
+		// an unpublished cover is replaced
+		{"series000000001", "artwork00000004", "artwork00000002"},
+		// a series without published parts has no cover
+		{"series000000002", "artwork00000006", ""},
+	} {
+		series, err := app.FindRecordById("series", c.series)
+		if err != nil {
+			t.Fatalf("find series: %v", err)
+		}
+
+		series.Set("cover", c.cover)
+		if err := app.Save(series); err != nil {
+			t.Fatalf("save series: %v", err)
+		}
+
+		if err := repo.RefreshCover(c.series); err != nil {
+			t.Fatalf("refresh cover: %v", err)
+		}
+
+		series, err = app.FindRecordById("series", c.series)
+		if err != nil {
+			t.Fatalf("find series: %v", err)
+		}
+
+		if got := series.GetString("cover"); got != c.want {
+			t.Errorf("cover of %s picked as %q = %q, want %q", c.series, c.cover, got, c.want)
+		}
+	}
+
+	if err := repo.RefreshCover("series000000009"); err != nil {
+		t.Fatalf("refresh the cover of a deleted series: %v", err)
+	}
+}
+
+func TestSeriesCollapseKeepsTheFirstMatchingPart(t *testing.T) {
+	app := newSeriesTestApp(t)
+
+	if err := NewSeriesRepository(app).RefreshCover("series000000001"); err != nil {
+		t.Fatalf("refresh cover: %v", err)
+	}
+
+	collection, err := app.FindCollectionByNameOrId("artworks")
+	if err != nil {
+		t.Fatalf("find collection: %v", err)
+	}
+
+	for _, c := range []struct {
+		filter string
+		title  string
+		want   []string
+	}{
+		// the cover stands for the series when it matches
+		{"", "", []string{"artwork00000002", "artwork00000007"}},
+		// a part that is not the cover is kept when it is the only match
+		{" && title = {:title}", "Sermon to the Birds", []string{"artwork00000003"}},
+		// otherwise the first matching part in the order of the series is
+		{" && title ~ {:title}", "the", []string{"artwork00000001"}},
+	} {
+		records, err := findRecords(t, app, collection, RecordFilter{
+			Filter: "published = true && author:length > 0" + c.filter,
+			Params: dbx.Params{"title": c.title},
+			// the ids are bound both in the query and in the matches it collapses
+			Ids:            []string{"artwork00000001", "artwork00000002", "artwork00000003", "artwork00000007"},
+			CollapseSeries: true,
+		})
+		if err != nil {
+			t.Fatalf("find artworks: %v", err)
+		}
+
+		got := recordIds(records)
+		slices.Sort(got)
+
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("collapsed artworks matching %q = %v, want %v", c.title, got, c.want)
+		}
+	}
+}
+
+func newSeriesTestApp(t *testing.T) *tests.TestApp {
+	t.Helper()
+
+	app := newArtworksTestApp(t)
+
+	saveRecordValues(t, app, "artists", map[string]any{"id": "artist000000001", "name": "GIOTTO di Bondone"})
+
+	for _, values := range []map[string]any{
+		{"id": "series000000001", "title": "Legend of St Francis"},
+		{"id": "series000000002", "title": "Unpublished altarpiece"},
+	} {
+		saveRecordValues(t, app, "series", values)
+	}
+
+	for _, values := range []map[string]any{
+		{"id": "artwork00000001", "title": "Miracle of the Spring", "published": true, "author": []string{"artist000000001"}, "series": "series000000001", "series_position": 2},
+		{"id": "artwork00000002", "title": "Homage of a Simple Man", "published": true, "author": []string{"artist000000001"}, "series": "series000000001", "series_position": 1},
+		{"id": "artwork00000003", "title": "Sermon to the Birds", "published": true, "author": []string{"artist000000001"}, "series": "series000000001", "series_position": 3},
+		{"id": "artwork00000004", "title": "Unpublished scene", "author": []string{"artist000000001"}, "series": "series000000001", "series_position": 0},
+		{"id": "artwork00000005", "title": "Unattributed scene", "published": true, "series": "series000000001", "series_position": 0},
+		{"id": "artwork00000006", "title": "Unpublished panel", "author": []string{"artist000000001"}, "series": "series000000002"},
+		{"id": "artwork00000007", "title": "Ognissanti Madonna", "published": true, "author": []string{"artist000000001"}},
+	} {
+		saveRecordValues(t, app, "artworks", values)
+	}
+
+	return app
+}
@@ -0,0 +1,173 @@
+// Package dating reads and formats the structured dating of artworks: the earliest and
+// latest year they were made in, whether the dating is approximate, and how it is displayed.
+package dating
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Names of the dating fields of the artworks collection.
+const (
+	FieldEarliest = "date_earliest"
+	FieldLatest   = "date_latest"
+	FieldCirca    = "date_circa"
+	FieldDisplay  = "date_display"
+)
+
+// Dating is the date of an artwork as a range of years. Both ends are the same
+// for artworks dated to a single year.
+type Dating struct {
+	Earliest int
+	Latest   int
+	Circa    bool
+	Display  string
+}
+
+// IsZero checks if the dating is unknown.
+func (d Dating) IsZero() bool {
+	return d.Earliest == 0 && d.Latest == 0
+}
+
+// ISO8601 returns the dating as a year, or as an interval of years, in the form
+// schema.org expects for dateCreated.
+func (d Dating) ISO8601() string {
+	if d.IsZero() {
+		return ""
+	}
+
+	if d.Earliest == d.Latest {
+		return fmt.Sprintf("%04d", d.Earliest)
+	}
+
+	return fmt.Sprintf("%04d/%04d", d.Earliest, d.Latest)
+}
+
+// String returns the display string of the dating, or a display built from the years
+// when there is none.
+func (d Dating) String() string {
+	if d.Display != "" || d.IsZero() {
+		return d.Display
+	}
+
+	display := strconv.Itoa(d.Earliest)
+	if d.Latest != d.Earliest {
+		display += "–" + strconv.Itoa(d.Latest)
+	}
+
+	if d.Circa {
+		display = "c. " + display
+	}
+
+	return display
+}
+
+// FromRecord reads the dating of an artwork record.
+func FromRecord(r *core.Record) Dating {
+	return Dating{
+		Earliest: r.GetInt(FieldEarliest),
+		Latest:   r.GetInt(FieldLatest),
+		Circa:    r.GetBool(FieldCirca),
+		Display:  r.GetString(FieldDisplay),
+	}
+}
+
+const circaPrefix = `(?:(c\.|ca\.|circa|about|around)\s*)?`
+
+var (
+	centuryPattern = regexp.MustCompile(`(?i)` + circaPrefix + `(?:(early|mid|late|first half of the|second half of the)[\s-]+)?(\d{1,2})(?:st|nd|rd|th)[\s-]+century`)
+	rangePattern   = regexp.MustCompile(`(?i)` + circaPrefix + `\b(\d{4})\s*(?:-|–|—|to)\s*(\d{2,4})\b`)
+	decadePattern  = regexp.MustCompile(`(?i)` + circaPrefix + `\b(\d{3}0)s\b`)
+	yearPattern    = regexp.MustCompile(`(?i)` + circaPrefix + `\b(\d{4})\b`)
+
+	// dimensionAfter and dimensionBefore tell numbers of dimensions, like 1200 x 800 mm, from years.
+	dimensionAfter  = regexp.MustCompile(`^\s*(?:x|×|cm|mm)`)
+	dimensionBefore = regexp.MustCompile(`(?:x|×)\s*$`)
+)
+
+// Parse extracts the dating of an artwork from free text, like its comment.
+// It understands years (1485), ranges (1480-95, 1480–1500), decades (1480s) and centuries
+// (late 15th century), optionally introduced by c., ca., circa or about.
+// When the text has several dates, the first one wins. Parse reports false when the
+// text has no date it understands.
+func Parse(text string) (Dating, bool) {
+	text = strings.Join(strings.Fields(utils.StrippedHTML(text)), " ")
+
+	best := Dating{}
+	bestStart := -1
+
+	consider := func(d Dating, start int) {
+		if d.Earliest < 1000 || d.Latest > 2100 || d.Latest < d.Earliest {
+			return
+		}
+		if bestStart == -1 || start < bestStart {
+			best, bestStart = d, start
+		}
+	}
+
+	for _, m := range centuryPattern.FindAllStringSubmatchIndex(text, -1) {
+		century, _ := strconv.Atoi(text[m[6]:m[7]])
+		start := (century - 1) * 100
+		earliest, latest := start, start+99
+
+		if m[4] != -1 {
+			switch strings.ToLower(text[m[4]:m[5]]) {
+			case "early":
+				latest = start + 30
+			case "mid":
+				earliest, latest = start+35, start+65
+			case "late":
+				earliest = start + 70
+			case "first half of the":
+				latest = start + 49
+			case "second half of the":
+				earliest = start + 50
+			}
+		}
+
+		consider(Dating{Earliest: earliest, Latest: latest, Circa: m[2] != -1, Display: text[m[0]:m[1]]}, m[0])
+	}
+
+	for _, m := range rangePattern.FindAllStringSubmatchIndex(text, -1) {
+		if isDimension(text, m[4], m[7]) {
+			continue
+		}
+
+		first := text[m[4]:m[5]]
+		last := text[m[6]:m[7]]
+		// 1480-95 ends in 1495
+		last = first[:len(first)-len(last)] + last
+
+		earliest, _ := strconv.Atoi(first)
+		latest, _ := strconv.Atoi(last)
+
+		consider(Dating{Earliest: earliest, Latest: latest, Circa: m[2] != -1, Display: text[m[0]:m[1]]}, m[0])
+	}
+
+	for _, m := range decadePattern.FindAllStringSubmatchIndex(text, -1) {
+		decade, _ := strconv.Atoi(text[m[4]:m[5]])
+
+		consider(Dating{Earliest: decade, Latest: decade + 9, Circa: m[2] != -1, Display: text[m[0]:m[1]]}, m[0])
+	}
+
+	for _, m := range yearPattern.FindAllStringSubmatchIndex(text, -1) {
+		if isDimension(text, m[4], m[5]) {
+			continue
+		}
+
+		year, _ := strconv.Atoi(text[m[4]:m[5]])
+
+		consider(Dating{Earliest: year, Latest: year, Circa: m[2] != -1, Display: text[m[0]:m[1]]}, m[0])
+	}
+
+	return best, bestStart != -1
+}
+
+func isDimension(text string, start int, end int) bool {
+	return dimensionAfter.MatchString(text[end:]) || dimensionBefore.MatchString(text[:start])
+}
@@ -0,0 +1,46 @@
+package dating
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	cases := map[string]Dating{
+		"<p>1902 · Synthetic Gallery, Test City · 101 x 201 cm</p>": {Earliest: 1902, Latest: 1902, Display: "1902"},
+		"Tempera on panel, c. 1485":                                 {Earliest: 1485, Latest: 1485, Circa: true, Display: "c. 1485"},
+		"Painted 1480-95 for the Medici, restored in 1890":          {Earliest: 1480, Latest: 1495, Display: "1480-95"},
+		"ca. 1480 – 1500":                                           {Earliest: 1480, Latest: 1500, Circa: true, Display: "ca. 1480 – 1500"},
+		"Executed in the 1480s":                                     {Earliest: 1480, Latest: 1489, Display: "1480s"},
+		"Late 15th century, workshop":                               {Earliest: 1470, Latest: 1499, Display: "Late 15th century"},
+		"second half of the 16th century":                           {Earliest: 1550, Latest: 1599, Display: "second half of the 16th century"},
+		"Oil on canvas, 1200 x 1800 mm, signed 1632":                {Earliest: 1632, Latest: 1632, Display: "1632"},
+	}
+
+	for text, want := range cases {
+		got, ok := Parse(text)
+		if !ok || got != want {
+			t.Errorf("Parse(%q) = %+v, %v, want %+v", text, got, ok, want)
+		}
+	}
+
+	for _, text := range []string{"", "Oil on canvas, 101 x 201 cm", "Inventory 99"} {
+		if got, ok := Parse(text); ok {
+			t.Errorf("Parse(%q) = %+v, expected no dating", text, got)
+		}
+	}
+}
+
+func TestDatingFormats(t *testing.T) {
+	d := Dating{Earliest: 1480, Latest: 1500, Circa: true}
+
+	if got := d.ISO8601(); got != "1480/1500" {
+		t.Errorf("ISO8601() = %q", got)
+	}
+	if got := d.String(); got != "c. 1480–1500" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := (Dating{Earliest: 1485, Latest: 1485, Display: "1485?"}).String(); got != "1485?" {
+		t.Errorf("String() = %q, want the display string", got)
+	}
+	if got := (Dating{}).ISO8601(); got != "" {
+		t.Errorf("ISO8601() of an unknown dating = %q", got)
+	}
+}
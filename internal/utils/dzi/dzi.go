@@ -0,0 +1,124 @@
+// Package dzi builds Deep Zoom (DZI) tile pyramids for artwork images and stores
+// them next to the original through the PocketBase filesystem.
+package dzi
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"math"
+	"regexp"
+	"strconv"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	// TileSize is the edge of a tile without its overlap, so that tiles with
+	// overlap on both sides are 256 pixels wide.
+	TileSize = 254
+	Overlap  = 1
+	Format   = "jpg"
+
+	// DescriptorName is the name of the descriptor inside a pyramid directory.
+	// The tiles are under DescriptorName without extension, suffixed with "_files".
+	DescriptorName = "image.dzi"
+	TilesDirName   = "image_files"
+
+	tileQuality = 85
+)
+
+var tileNamePattern = regexp.MustCompile(`^(\d+)_(\d+)\.` + Format + `$`)
+
+// Levels returns the number of levels of the pyramid of an image: level 0 is a
+// single pixel and the last level is the image at full size.
+func Levels(width int, height int) int {
+	return int(math.Ceil(math.Log2(float64(max(width, height))))) + 1
+}
+
+// LevelSize returns the size of the image at a level of the pyramid.
+func LevelSize(width int, height int, level int) (int, int) {
+	scale := math.Pow(2, float64(Levels(width, height)-1-level))
+
+	return int(math.Ceil(float64(width) / scale)), int(math.Ceil(float64(height) / scale))
+}
+
+// Descriptor returns the XML descriptor of the pyramid of an image.
+func Descriptor(width int, height int) []byte {
+	return fmt.Appendf(nil,
+		`<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+			`<Image xmlns="http://schemas.microsoft.com/deepzoom/2008" TileSize="%d" Overlap="%d" Format="%s"><Size Width="%d" Height="%d"/></Image>`+"\n",
+		TileSize, Overlap, Format, width, height,
+	)
+}
+
+// TilePath returns the path of a tile inside a pyramid directory.
+func TilePath(level int, column int, row int) string {
+	return fmt.Sprintf("%s/%d/%d_%d.%s", TilesDirName, level, column, row, Format)
+}
+
+// ParseTileName parses a "{column}_{row}.jpg" tile name.
+func ParseTileName(name string) (int, int, bool) {
+	m := tileNamePattern.FindStringSubmatch(name)
+	if m == nil {
+		return 0, 0, false
+	}
+
+	column, errColumn := strconv.Atoi(m[1])
+	row, errRow := strconv.Atoi(m[2])
+
+	return column, row, errColumn == nil && errRow == nil
+}
+
+// Build cuts the image into its tile pyramid and passes the descriptor and every
+// tile to put, with its path inside the pyramid directory. Each level is scaled
+// down from the one above it, starting from the full size image.
+func Build(img image.Image, put func(path string, data []byte) error) error {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if width == 0 || height == 0 {
+		return fmt.Errorf("empty image")
+	}
+
+	level := imaging.Clone(img)
+
+	for l := Levels(width, height) - 1; l >= 0; l-- {
+		lw, lh := LevelSize(width, height, l)
+		if level.Bounds().Dx() != lw || level.Bounds().Dy() != lh {
+			level = imaging.Resize(level, lw, lh, imaging.Lanczos)
+		}
+
+		if err := putLevel(level, l, put); err != nil {
+			return err
+		}
+	}
+
+	return put(DescriptorName, Descriptor(width, height))
+}
+
+func putLevel(level image.Image, l int, put func(path string, data []byte) error) error {
+	lw, lh := level.Bounds().Dx(), level.Bounds().Dy()
+
+	var buff bytes.Buffer
+
+	for column := 0; column*TileSize < lw; column++ {
+		for row := 0; row*TileSize < lh; row++ {
+			bounds := image.Rect(
+				max(column*TileSize-Overlap, 0),
+				max(row*TileSize-Overlap, 0),
+				min((column+1)*TileSize+Overlap, lw),
+				min((row+1)*TileSize+Overlap, lh),
+			)
+
+			buff.Reset()
+			if err := imaging.Encode(&buff, imaging.Crop(level, bounds), imaging.JPEG, imaging.JPEGQuality(tileQuality)); err != nil {
+				return err
+			}
+
+			if err := put(TilePath(l, column, row), bytes.Clone(buff.Bytes())); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package dzi
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/blackfyre/wga/internal/testutils"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+func TestLevels(t *testing.T) {
+	cases := map[[2]int]int{
+		{1, 1}:       1,
+		{2, 1}:       2,
+		{254, 100}:   9,
+		{1000, 600}:  11,
+		{1024, 1024}: 11,
+		{1025, 10}:   12,
+	}
+
+	for size, want := range cases {
+		if got := Levels(size[0], size[1]); got != want {
+			t.Errorf("Levels(%d, %d) = %d, want %d", size[0], size[1], got, want)
+		}
+	}
+
+	if w, h := LevelSize(1000, 600, 10); w != 1000 || h != 600 {
+		t.Fatalf("full level = %dx%d", w, h)
+	}
+	if w, h := LevelSize(1000, 600, 9); w != 500 || h != 300 {
+		t.Fatalf("half level = %dx%d", w, h)
+	}
+	if w, h := LevelSize(1000, 600, 0); w != 1 || h != 1 {
+		t.Fatalf("first level = %dx%d", w, h)
+	}
+}
+
+func TestParseTileName(t *testing.T) {
+	if column, row, ok := ParseTileName("3_12.jpg"); !ok || column != 3 || row != 12 {
+		t.Fatalf("ParseTileName = %d, %d, %v", column, row, ok)
+	}
+
+	for _, name := range []string{"3_12.png", "3-12.jpg", "../1_1.jpg", "_1.jpg", "1_1.jpg.jpg"} {
+		if _, _, ok := ParseTileName(name); ok {
+			t.Errorf("expected %q to be rejected", name)
+		}
+	}
+}
+
+func TestBuildCutsOverlappingTiles(t *testing.T) {
+	tiles := map[string]image.Config{}
+
+	err := Build(image.NewNRGBA(image.Rect(0, 0, 600, 300)), func(path string, data []byte) error {
+		if path == DescriptorName {
+			if !strings.Contains(string(data), `<Size Width="600" Height="300"/>`) {
+				t.Errorf("unexpected descriptor %s", data)
+			}
+			return nil
+		}
+
+		config, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		tiles[path] = config
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	// 11 levels, the last two of which are wider than a tile
+	if len(tiles) != 9+2+6 {
+		t.Fatalf("got %d tiles", len(tiles))
+	}
+
+	cases := map[string][2]int{
+		TilePath(0, 0, 0):  {1, 1},
+		TilePath(10, 0, 0): {255, 255},
+		TilePath(10, 1, 0): {256, 255},
+		TilePath(10, 2, 1): {93, 47},
+		TilePath(9, 1, 0):  {47, 150},
+	}
+
+	for path, want := range cases {
+		config, ok := tiles[path]
+		if !ok {
+			t.Errorf("missing tile %s", path)
+			continue
+		}
+		if config.Width != want[0] || config.Height != want[1] {
+			t.Errorf("tile %s = %dx%d, want %dx%d", path, config.Width, config.Height, want[0], want[1])
+		}
+	}
+}
+
+func TestGenerateReplacesStalePyramids(t *testing.T) {
+	app := testutils.NewTestApp(t)
+
+	collection := core.NewBaseCollection("artworks")
+	collection.Fields.Add(
+		&core.FileField{Name: "image", MaxSelect: 1, MaxSize: 1 << 20},
+		&core.TextField{Name: TilesImageField},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatalf("save collection: %v", err)
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("image", testImageFile(t, "first.png"))
+	if err := app.Save(record); err != nil {
+		t.Fatalf("save record: %v", err)
+	}
+
+	saves := 0
+	app.OnRecordUpdate(collection.Name).BindFunc(func(e *core.RecordEvent) error {
+		saves++
+		return e.Next()
+	})
+
+	if err := Generate(app, record); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if saves != 0 {
+		t.Fatalf("expected the record to be marked without running the save hooks, ran them %d times", saves)
+	}
+
+	first, err := app.FindRecordById(collection.Id, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !UpToDate(first) {
+		t.Fatalf("expected the record to be up to date, tiles image is %q", first.GetString(TilesImageField))
+	}
+	firstDir := Dir(first)
+
+	first.Set("image", testImageFile(t, "second.png"))
+	if err := app.Save(first); err != nil {
+		t.Fatalf("save record: %v", err)
+	}
+	if UpToDate(first) {
+		t.Fatal("expected a new image to need a pyramid")
+	}
+
+	if err := Generate(app, first); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+
+	if ok, _ := fsys.Exists(Dir(first) + "/" + DescriptorName); !ok {
+		t.Fatal("expected the new pyramid to be stored")
+	}
+	if ok, _ := fsys.Exists(firstDir + "/" + DescriptorName); ok {
+		t.Fatal("expected the stale pyramid to be removed")
+	}
+}
+
+func testImageFile(t *testing.T, name string) *filesystem.File {
+	t.Helper()
+
+	var buff bytes.Buffer
+	if err := png.Encode(&buff, image.NewNRGBA(image.Rect(0, 0, 300, 200))); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := filesystem.NewFileFromBytes(buff.Bytes(), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
@@ -0,0 +1,111 @@
+package dzi
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/disintegration/imaging"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// TilesImageField holds the name of the image file the current pyramid of an
+// artwork was built from.
+const TilesImageField = "tiles_image"
+
+const dirPrefix = "dzi_"
+
+var ErrNoImage = errors.New("artwork has no image")
+
+// generateMu runs one build at a time: decoded scans are large, and concurrent
+// builds for the same record would remove each other's pyramids.
+var generateMu sync.Mutex
+
+// UpToDate reports whether the record has a pyramid for its current image.
+func UpToDate(record *core.Record) bool {
+	image := record.GetString("image")
+
+	return image != "" && record.GetString(TilesImageField) == image
+}
+
+// Dir returns the storage directory of the pyramid of the record's current image.
+// It sits next to the image, like the thumbs PocketBase generates.
+func Dir(record *core.Record) string {
+	return record.BaseFilesPath() + "/" + dirPrefix + record.GetString("image")
+}
+
+// Generate builds the pyramid of the record's image, removes the pyramids of
+// images the record no longer has, and marks the record as up to date. The
+// record is not marked when its image changed while the pyramid was built.
+// Only the tiles field is written, so the record save hooks do not run.
+func Generate(app core.App, record *core.Record) error {
+	image := record.GetString("image")
+	if image == "" {
+		return ErrNoImage
+	}
+
+	generateMu.Lock()
+	defer generateMu.Unlock()
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	reader, err := fsys.GetReader(record.BaseFilesPath() + "/" + image)
+	if err != nil {
+		return err
+	}
+
+	src, err := imaging.Decode(reader)
+	reader.Close()
+	if err != nil {
+		return err
+	}
+
+	dir := Dir(record)
+
+	err = Build(src, func(path string, data []byte) error {
+		return fsys.Upload(data, dir+"/"+path)
+	})
+	if err != nil {
+		return err
+	}
+
+	stale, err := fsys.List(record.BaseFilesPath() + "/" + dirPrefix)
+	if err != nil {
+		return err
+	}
+
+	removed := map[string]struct{}{}
+	for _, obj := range stale {
+		name, _, _ := strings.Cut(strings.TrimPrefix(obj.Key, record.BaseFilesPath()+"/"), "/")
+		staleDir := record.BaseFilesPath() + "/" + name
+
+		if _, ok := removed[staleDir]; ok || staleDir == dir {
+			continue
+		}
+		removed[staleDir] = struct{}{}
+
+		if errs := fsys.DeletePrefix(staleDir + "/"); len(errs) > 0 {
+			return errors.Join(errs...)
+		}
+	}
+
+	result, err := app.DB().Update(
+		record.Collection().Name,
+		dbx.Params{TilesImageField: image},
+		dbx.HashExp{"id": record.Id, "image": image},
+	).Execute()
+	if err != nil {
+		return err
+	}
+
+	if marked, err := result.RowsAffected(); err == nil && marked > 0 {
+		record.Set(TilesImageField, image)
+	}
+
+	return nil
+}
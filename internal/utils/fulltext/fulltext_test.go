@@ -0,0 +1,178 @@
+package fulltext
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/blackfyre/wga/internal/testutils"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestTokenizeFoldsDiacriticsAndPunctuation(t *testing.T) {
+	got := Tokenize(`Dürer's "Madonna", São-Paulo`)
+	want := []string{"durer", "s", "madonna", "sao", "paulo"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Tokenize() = %#v, want %#v", got, want)
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"madona", "madonna", 1},
+		{"botticeli", "botticelli", 1},
+		{"venus", "venus", 0},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, test := range tests {
+		if got := levenshtein(test.a, test.b); got != test.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestMatchRanksPrefixesDiacriticsAndTypos(t *testing.T) {
+	app := newIndexTestApp(t)
+
+	durer := saveRecord(t, app, "artists", map[string]any{"name": "Albrecht Dürer", "published": true})
+	botticelli := saveRecord(t, app, "artists", map[string]any{"name": "Sandro Botticelli", "bio": "Painter of the Madonna", "published": true})
+	saveRecord(t, app, "artists", map[string]any{"name": "Hidden Botticelli", "published": false})
+
+	venus := saveRecord(t, app, "artworks", map[string]any{"title": "The Birth of Venus", "author": []string{botticelli.Id}, "published": true})
+	madonna := saveRecord(t, app, "artworks", map[string]any{"title": "Madonna of the Magnificat", "author": []string{botticelli.Id}, "published": true})
+	saveRecord(t, app, "artworks", map[string]any{"title": "Study", "comment": "<p>A sketch after a <b>Madonna</b></p>", "author": []string{durer.Id}, "published": true})
+	saveRecord(t, app, "glossary", map[string]any{"expression": "tondo (It. round)", "definition": "A circular painting."})
+
+	if err := Rebuild(app); err != nil {
+		t.Fatalf("rebuild index: %v", err)
+	}
+
+	assertIds(t, app, KindArtist, "durer", []string{durer.Id})
+	assertIds(t, app, KindArtist, "botti", []string{botticelli.Id})
+	assertIds(t, app, KindArtwork, "venus botticelli", []string{venus.Id})
+
+	ids, err := MatchIds(app, KindArtwork, "madona", 10)
+	if err != nil {
+		t.Fatalf("match misspelled term: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != madonna.Id {
+		t.Fatalf("expected title match to rank first for a misspelled term, got %v", ids)
+	}
+
+	hits, err := Match(app, KindGlossary, "round", 10)
+	if err != nil {
+		t.Fatalf("match glossary: %v", err)
+	}
+	if len(hits) != 1 {
+		t.Fatalf("expected one glossary hit, got %d", len(hits))
+	}
+}
+
+func TestIndexRecordFollowsPublicationState(t *testing.T) {
+	app := newIndexTestApp(t)
+
+	if err := CreateIndex(app); err != nil {
+		t.Fatalf("create index: %v", err)
+	}
+
+	artist := saveRecord(t, app, "artists", map[string]any{"name": "Giotto", "published": true})
+	if err := IndexRecord(app, artist); err != nil {
+		t.Fatalf("index artist: %v", err)
+	}
+	assertIds(t, app, KindArtist, "giotto", []string{artist.Id})
+
+	artist.Set("published", false)
+	if err := app.Save(artist); err != nil {
+		t.Fatalf("unpublish artist: %v", err)
+	}
+	if err := IndexRecord(app, artist); err != nil {
+		t.Fatalf("reindex artist: %v", err)
+	}
+	assertIds(t, app, KindArtist, "giotto", []string{})
+}
+
+func assertIds(t *testing.T, app core.App, kind Kind, query string, want []string) {
+	t.Helper()
+
+	got, err := MatchIds(app, kind, query, 10)
+	if err != nil {
+		t.Fatalf("match %q: %v", query, err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("match %q = %v, want %v", query, got, want)
+	}
+}
+
+func newIndexTestApp(t *testing.T) *tests.TestApp {
+	t.Helper()
+
+	app := testutils.NewTestApp(t)
+
+	artists := core.NewBaseCollection("Artists")
+	artists.Id = "artists"
+	artists.Fields.Add(
+		&core.TextField{Name: "name"},
+		&core.EditorField{Name: "bio"},
+		&core.TextField{Name: "profession"},
+		&core.BoolField{Name: "published"},
+	)
+	if err := app.Save(artists); err != nil {
+		t.Fatalf("save artists collection: %v", err)
+	}
+
+	artists.Fields.Add(&core.RelationField{Name: "also_known_as", CollectionId: artists.Id, MaxSelect: 10})
+	if err := app.Save(artists); err != nil {
+		t.Fatalf("save artists aliases: %v", err)
+	}
+
+	artworks := core.NewBaseCollection("Artworks")
+	artworks.Id = "artworks"
+	artworks.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.RelationField{Name: "author", CollectionId: artists.Id, MaxSelect: 10},
+		&core.EditorField{Name: "comment"},
+		&core.TextField{Name: "technique"},
+		&core.BoolField{Name: "published"},
+	)
+	if err := app.Save(artworks); err != nil {
+		t.Fatalf("save artworks collection: %v", err)
+	}
+
+	glossary := core.NewBaseCollection("Glossary")
+	glossary.Id = "glossary"
+	glossary.Fields.Add(
+		&core.TextField{Name: "expression"},
+		&core.TextField{Name: "definition"},
+	)
+	if err := app.Save(glossary); err != nil {
+		t.Fatalf("save glossary collection: %v", err)
+	}
+
+	return app
+}
+
+func saveRecord(t *testing.T, app core.App, collection string, values map[string]any) *core.Record {
+	t.Helper()
+
+	c, err := app.FindCollectionByNameOrId(collection)
+	if err != nil {
+		t.Fatalf("find %s collection: %v", collection, err)
+	}
+
+	record := core.NewRecord(c)
+	for key, value := range values {
+		record.Set(key, value)
+	}
+
+	if err := app.Save(record); err != nil {
+		t.Fatalf("save %s record: %v", collection, err)
+	}
+
+	return record
+}
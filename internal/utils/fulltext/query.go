@@ -0,0 +1,195 @@
+package fulltext
+
+import (
+	"sort"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// maxAlternatives caps how many vocabulary terms a misspelled term expands to.
+const maxAlternatives = 5
+
+// Match runs a ranked full-text query against the index and returns at most
+// limit hits of the given kind, best match first.
+// Every term is prefix matched; when nothing matches, each term is expanded
+// to indexed terms within a small edit distance, so "madona" still finds "Madonna".
+func Match(app core.App, kind Kind, query string, limit int) ([]Hit, error) {
+	tokens := Tokenize(query)
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	terms := make([][]string, 0, len(tokens))
+	for _, token := range tokens {
+		terms = append(terms, []string{token})
+	}
+
+	hits, err := runMatch(app, kind, matchExpression(terms), limit)
+	if err != nil || len(hits) > 0 {
+		return hits, err
+	}
+
+	expanded, changed, err := expandTerms(app, tokens)
+	if err != nil || !changed {
+		return hits, err
+	}
+
+	return runMatch(app, kind, matchExpression(expanded), limit)
+}
+
+// MatchIds is a convenience wrapper around Match returning only the record ids.
+func MatchIds(app core.App, kind Kind, query string, limit int) ([]string, error) {
+	hits, err := Match(app, kind, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.RecordId)
+	}
+
+	return ids, nil
+}
+
+func runMatch(app core.App, kind Kind, expression string, limit int) ([]Hit, error) {
+	hits := []Hit{}
+
+	// bm25 weights follow the column order: kind, record_id, title, names, body.
+	err := app.DB().NewQuery(`
+		SELECT kind, record_id, bm25(` + TableName + `, 0.0, 0.0, 10.0, 5.0, 1.0) AS rank
+		FROM ` + TableName + `
+		WHERE ` + TableName + ` MATCH {:expression} AND kind = {:kind}
+		ORDER BY rank
+		LIMIT {:limit}
+	`).Bind(dbx.Params{
+		"expression": expression,
+		"kind":       string(kind),
+		"limit":      limit,
+	}).All(&hits)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return hits, nil
+}
+
+type vocabRow struct {
+	Term string `db:"term"`
+	Doc  int    `db:"doc"`
+}
+
+// expandTerms replaces every token with the indexed terms that are close to it.
+// It reports whether any token gained alternatives.
+func expandTerms(app core.App, tokens []string) ([][]string, bool, error) {
+	expanded := make([][]string, 0, len(tokens))
+	changed := false
+
+	for _, token := range tokens {
+		alternatives := []string{token}
+		maxDistance := allowedEdits(token)
+
+		if maxDistance == 0 {
+			expanded = append(expanded, alternatives)
+			continue
+		}
+
+		rows := []vocabRow{}
+		runes := []rune(token)
+
+		err := app.DB().NewQuery(`
+			SELECT term, doc FROM ` + vocabTableName + `
+			WHERE substr(term, 1, 1) = {:first}
+			AND length(term) BETWEEN {:min} AND {:max}
+		`).Bind(dbx.Params{
+			"first": string(runes[0]),
+			"min":   len(runes) - maxDistance,
+			"max":   len(runes) + maxDistance,
+		}).All(&rows)
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		type candidate struct {
+			term     string
+			distance int
+			docs     int
+		}
+
+		candidates := []candidate{}
+		for _, row := range rows {
+			if row.Term == token {
+				continue
+			}
+
+			if distance := levenshtein(token, row.Term); distance <= maxDistance {
+				candidates = append(candidates, candidate{term: row.Term, distance: distance, docs: row.Doc})
+			}
+		}
+
+		sort.Slice(candidates, func(i, j int) bool {
+			if candidates[i].distance != candidates[j].distance {
+				return candidates[i].distance < candidates[j].distance
+			}
+
+			return candidates[i].docs > candidates[j].docs
+		})
+
+		for index, c := range candidates {
+			if index == maxAlternatives {
+				break
+			}
+
+			alternatives = append(alternatives, c.term)
+			changed = true
+		}
+
+		expanded = append(expanded, alternatives)
+	}
+
+	return expanded, changed, nil
+}
+
+// allowedEdits scales the tolerated typo count with the term length;
+// short terms are too ambiguous to correct.
+func allowedEdits(term string) int {
+	switch n := len([]rune(term)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// levenshtein returns the edit distance between two strings, counted in runes.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
@@ -0,0 +1,34 @@
+package fulltext
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// OrderByIds sorts records into the order of the ranked id list.
+// Records whose id is not in the list are dropped.
+func OrderByIds(records []*core.Record, ids []string) []*core.Record {
+	byId := make(map[string]*core.Record, len(records))
+	for _, record := range records {
+		byId[record.Id] = record
+	}
+
+	ordered := make([]*core.Record, 0, len(records))
+	for _, id := range ids {
+		if record, ok := byId[id]; ok {
+			ordered = append(ordered, record)
+		}
+	}
+
+	return ordered
+}
+
+// Page returns the slice of records for the given offset and limit.
+func Page(records []*core.Record, offset int, limit int) []*core.Record {
+	if offset >= len(records) || offset < 0 {
+		return []*core.Record{}
+	}
+
+	end := min(offset+limit, len(records))
+
+	return records[offset:end]
+}
@@ -0,0 +1,392 @@
+package fulltext
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"golang.org/x/text/unicode/norm"
+)
+
+// TableName is the FTS5 virtual table holding the full-text search index.
+const TableName = "search_index"
+
+// vocabTableName exposes the index vocabulary, used for "did you mean" style
+// expansion of misspelled terms.
+const vocabTableName = "search_index_vocab"
+
+// Kind identifies the type of record stored in an index row.
+type Kind string
+
+const (
+	KindArtwork  Kind = "artwork"
+	KindArtist   Kind = "artist"
+	KindGlossary Kind = "glossary"
+)
+
+// Hit is a single ranked search result.
+type Hit struct {
+	Kind     Kind    `db:"kind"`
+	RecordId string  `db:"record_id"`
+	Rank     float64 `db:"rank"`
+}
+
+// document is the indexable representation of a record.
+// Columns are weighted title > names > body when ranking.
+type document struct {
+	Kind     Kind
+	RecordId string
+	Title    string
+	Names    string
+	Body     string
+}
+
+// CreateIndex creates the FTS5 table and its vocabulary companion.
+// The unicode61 tokenizer with remove_diacritics folds "Dürer" and "Durer" to the same token.
+func CreateIndex(app core.App) error {
+	_, err := app.DB().NewQuery(`
+		CREATE VIRTUAL TABLE IF NOT EXISTS ` + TableName + ` USING fts5(
+			kind UNINDEXED,
+			record_id UNINDEXED,
+			title,
+			names,
+			body,
+			tokenize = 'unicode61 remove_diacritics 2'
+		)
+	`).Execute()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = app.DB().NewQuery(`CREATE VIRTUAL TABLE IF NOT EXISTS ` + vocabTableName + ` USING fts5vocab(` + TableName + `, 'row')`).Execute()
+
+	return err
+}
+
+// DropIndex removes the FTS5 table and its vocabulary companion.
+func DropIndex(app core.App) error {
+	if _, err := app.DB().NewQuery("DROP TABLE IF EXISTS " + vocabTableName).Execute(); err != nil {
+		return err
+	}
+
+	_, err := app.DB().NewQuery("DROP TABLE IF EXISTS " + TableName).Execute()
+
+	return err
+}
+
+// IndexAvailable reports whether the search index table exists.
+// Record hooks fire during the seed migrations, before the index is created.
+func IndexAvailable(app core.App) bool {
+	return app.HasTable(TableName)
+}
+
+// Rebuild drops every row from the index and re-indexes all published
+// artworks, artists and glossary entries.
+func Rebuild(app core.App) error {
+	if err := CreateIndex(app); err != nil {
+		return err
+	}
+
+	return app.RunInTransaction(func(txApp core.App) error {
+		if _, err := txApp.DB().NewQuery("DELETE FROM " + TableName).Execute(); err != nil {
+			return err
+		}
+
+		artists, err := txApp.FindRecordsByFilter(constants.CollectionArtists, "published = true", "", 0, 0)
+		if err != nil {
+			return err
+		}
+
+		artistNames := make(map[string]string, len(artists))
+		for _, artist := range artists {
+			artistNames[artist.Id] = artist.GetString("name")
+		}
+
+		otherNames, err := artistOtherNames(txApp, nil)
+		if err != nil {
+			return err
+		}
+
+		for _, artist := range artists {
+			if err := insertDocument(txApp, artistDocument(artist, artistNames, otherNames[artist.Id])); err != nil {
+				return err
+			}
+		}
+
+		artworks, err := txApp.FindRecordsByFilter(constants.CollectionArtworks, "published = true", "", 0, 0)
+		if err != nil {
+			return err
+		}
+
+		for _, artwork := range artworks {
+			if err := insertDocument(txApp, artworkDocument(artwork, artistNames)); err != nil {
+				return err
+			}
+		}
+
+		entries, err := txApp.FindRecordsByFilter(constants.CollectionGlossary, "", "", 0, 0)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if err := insertDocument(txApp, glossaryDocument(entry)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// IndexRecord (re)indexes a single artwork, artist or glossary record.
+// Unpublished records are removed from the index.
+func IndexRecord(app core.App, record *core.Record) error {
+	kind, ok := KindForCollection(record.Collection())
+	if !ok {
+		return nil
+	}
+
+	if err := RemoveRecord(app, kind, record.Id); err != nil {
+		return err
+	}
+
+	switch kind {
+	case KindArtwork:
+		if !record.GetBool("published") {
+			return nil
+		}
+
+		names, err := artistNamesByID(app, record.GetStringSlice("author"))
+		if err != nil {
+			return err
+		}
+
+		return insertDocument(app, artworkDocument(record, names))
+	case KindArtist:
+		if record.GetBool("published") {
+			names, err := artistNamesByID(app, record.GetStringSlice("also_known_as"))
+			if err != nil {
+				return err
+			}
+
+			otherNames, err := artistOtherNames(app, []string{record.Id})
+			if err != nil {
+				return err
+			}
+
+			if err := insertDocument(app, artistDocument(record, names, otherNames[record.Id])); err != nil {
+				return err
+			}
+		}
+
+		// Artwork rows carry their authors' names, so keep them in step.
+		works, err := app.FindRecordsByFilter(constants.CollectionArtworks, "author ?~ {:authorId}", "", 0, 0, dbx.Params{
+			"authorId": record.Id,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, work := range works {
+			if err := IndexRecord(app, work); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case KindGlossary:
+		return insertDocument(app, glossaryDocument(record))
+	}
+
+	return nil
+}
+
+// RemoveRecord deletes the index row of the given record.
+func RemoveRecord(app core.App, kind Kind, recordId string) error {
+	_, err := app.DB().NewQuery("DELETE FROM " + TableName + " WHERE kind = {:kind} AND record_id = {:id}").Bind(dbx.Params{
+		"kind": string(kind),
+		"id":   recordId,
+	}).Execute()
+
+	return err
+}
+
+// KindForCollection maps a collection to the index kind its records are stored under.
+func KindForCollection(collection *core.Collection) (Kind, bool) {
+	if collection == nil {
+		return "", false
+	}
+
+	switch strings.ToLower(collection.Name) {
+	case constants.CollectionArtworks:
+		return KindArtwork, true
+	case constants.CollectionArtists:
+		return KindArtist, true
+	case strings.ToLower(constants.CollectionGlossary):
+		return KindGlossary, true
+	}
+
+	return "", false
+}
+
+func insertDocument(app core.App, d document) error {
+	_, err := app.DB().NewQuery(
+		"INSERT INTO " + TableName + " (kind, record_id, title, names, body) VALUES ({:kind}, {:id}, {:title}, {:names}, {:body})",
+	).Bind(dbx.Params{
+		"kind":  string(d.Kind),
+		"id":    d.RecordId,
+		"title": d.Title,
+		"names": d.Names,
+		"body":  d.Body,
+	}).Execute()
+
+	return err
+}
+
+func artworkDocument(r *core.Record, artistNames map[string]string) document {
+	names := make([]string, 0, len(r.GetStringSlice("author")))
+	for _, id := range r.GetStringSlice("author") {
+		if name := artistNames[id]; name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return document{
+		Kind:     KindArtwork,
+		RecordId: r.Id,
+		Title:    r.GetString("title"),
+		Names:    strings.Join(names, " "),
+		Body:     utils.StrippedHTML(r.GetString("comment")) + " " + r.GetString("technique"),
+	}
+}
+
+func artistDocument(r *core.Record, artistNames map[string]string, otherNames []string) document {
+	names := make([]string, 0, len(r.GetStringSlice("also_known_as"))+len(otherNames))
+	for _, id := range r.GetStringSlice("also_known_as") {
+		if name := artistNames[id]; name != "" {
+			names = append(names, name)
+		}
+	}
+	names = append(names, otherNames...)
+
+	return document{
+		Kind:     KindArtist,
+		RecordId: r.Id,
+		Title:    r.GetString("name"),
+		Names:    strings.Join(names, " "),
+		Body:     utils.StrippedHTML(r.GetString("bio")) + " " + r.GetString("profession"),
+	}
+}
+
+func glossaryDocument(r *core.Record) document {
+	return document{
+		Kind:     KindGlossary,
+		RecordId: r.Id,
+		Title:    r.GetString("expression"),
+		Body:     utils.StrippedHTML(r.GetString("definition")),
+	}
+}
+
+func artistNamesByID(app core.App, ids []string) (map[string]string, error) {
+	names := make(map[string]string, len(ids))
+	if len(ids) == 0 {
+		return names, nil
+	}
+
+	records, err := app.FindRecordsByIds(constants.CollectionArtists, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		names[record.Id] = record.GetString("name")
+	}
+
+	return names, nil
+}
+
+// artistOtherNames returns the aliases, variants and sort keys of the artists by artist id,
+// or of every artist when ids is nil. Apps without other names return none.
+func artistOtherNames(app core.App, ids []string) (map[string][]string, error) {
+	names := map[string][]string{}
+	if !app.HasTable(constants.CollectionArtistNames) || (ids != nil && len(ids) == 0) {
+		return names, nil
+	}
+
+	query := app.DB().Select("artist", "name").From(constants.CollectionArtistNames).OrderBy("artist", "name")
+	if ids != nil {
+		values := make([]any, 0, len(ids))
+		for _, id := range ids {
+			values = append(values, id)
+		}
+		query.Where(dbx.In("artist", values...))
+	}
+
+	rows := []struct {
+		Artist string `db:"artist"`
+		Name   string `db:"name"`
+	}{}
+	if err := query.All(&rows); err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		names[row.Artist] = append(names[row.Artist], row.Name)
+	}
+
+	return names, nil
+}
+
+// Tokenize splits a free-text query into folded, lower-case terms.
+// Punctuation acts as a separator, so the result is always safe to
+// quote inside an FTS5 MATCH expression.
+func Tokenize(query string) []string {
+	folded := Fold(query)
+
+	return strings.FieldsFunc(folded, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Fold lower-cases the input and strips combining diacritical marks,
+// mirroring what the index tokenizer does to stored text.
+func Fold(s string) string {
+	var b strings.Builder
+
+	for _, r := range norm.NFD.String(s) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
+
+// matchExpression builds an FTS5 query in which every term must match,
+// with prefix matching on each term. Alternatives for a term are OR-ed.
+func matchExpression(terms [][]string) string {
+	groups := make([]string, 0, len(terms))
+
+	for _, alternatives := range terms {
+		quoted := make([]string, 0, len(alternatives))
+		for _, term := range alternatives {
+			quoted = append(quoted, fmt.Sprintf(`"%s"*`, term))
+		}
+
+		if len(quoted) == 1 {
+			groups = append(groups, quoted[0])
+			continue
+		}
+
+		groups = append(groups, "("+strings.Join(quoted, " OR ")+")")
+	}
+
+	return strings.Join(groups, " AND ")
+}
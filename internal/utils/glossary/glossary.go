@@ -34,6 +34,8 @@ const (
 
 // GlossaryEntry represents a single glossary term with its matchable form and definition.
 type GlossaryEntry struct {
+	Id         string // record id
+	Url        string // glossary page of the term
 	Expression string // full expression from DB
 	MatchTerm  string // cleaned term used for matching
 	Definition string // HTML definition
@@ -70,6 +72,8 @@ func GetGlossaryEntries(app *pocketbase.PocketBase) ([]GlossaryEntry, error) {
 			continue
 		}
 		entries = append(entries, GlossaryEntry{
+			Id:         r.Id,
+			Url:        EntryUrl(r.Id, matchTerm),
 			Expression: expr,
 			MatchTerm:  matchTerm,
 			Definition: sanitizer.Sanitize(r.GetString("definition")),
@@ -213,7 +217,7 @@ func annotateTextNode(textNode *html.Node, entries []GlossaryEntry, matched map[
 		}
 		span.AppendChild(&html.Node{Type: html.TextNode, Data: text[matchStart:termEnd]})
 		parent.InsertBefore(span, textNode)
-		parent.InsertBefore(glossaryDefinitionTemplate(matchEntry.Definition), textNode)
+		parent.InsertBefore(glossaryDefinitionTemplate(matchEntry.Definition, matchEntry.Url), textNode)
 
 		matched[strings.ToLower(matchEntry.MatchTerm)] = true
 		cursor = termEnd
@@ -228,7 +232,7 @@ func annotateTextNode(textNode *html.Node, entries []GlossaryEntry, matched map[
 	parent.RemoveChild(textNode)
 }
 
-func glossaryDefinitionTemplate(definition string) *html.Node {
+func glossaryDefinitionTemplate(definition string, url string) *html.Node {
 	// Keep the sanitized markup in the server-rendered DOM so client code never
 	// needs to parse a definition string.
 	template := &html.Node{
@@ -252,6 +256,23 @@ func glossaryDefinitionTemplate(definition string) *html.Node {
 		template.AppendChild(definitionNode)
 	}
 
+	if url != "" {
+		link := &html.Node{
+			Type:     html.ElementNode,
+			DataAtom: atom.A,
+			Data:     "a",
+			Attr: []html.Attribute{
+				{Key: "href", Val: url},
+				{Key: "class", Val: "glossary-more"},
+			},
+		}
+		link.AppendChild(&html.Node{Type: html.TextNode, Data: "Read more in the glossary"})
+
+		paragraph := &html.Node{Type: html.ElementNode, DataAtom: atom.P, Data: "p"}
+		paragraph.AppendChild(link)
+		template.AppendChild(paragraph)
+	}
+
 	return template
 }
 
@@ -226,3 +226,39 @@ func TestIndexWholeWord(t *testing.T) {
 		})
 	}
 }
+
+func TestMentionsTerm(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		term string
+		want bool
+	}{
+		{"whole word", "<p>Painted in fresco on the wall.</p>", "fresco", true},
+		{"part of a longer word", "<p>The frescoes were restored.</p>", "fresco", false},
+		{"across elements", "<p>aerial</p><p>perspective</p>", "aerial perspective", true},
+		{"adjacent elements keep words apart", "<b>al</b><i>tar</i>", "altar", false},
+		{"case insensitive", "<p>A TONDO by Botticelli.</p>", "tondo", true},
+		{"empty text", "", "tondo", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MentionsTerm(tt.html, tt.term); got != tt.want {
+				t.Errorf("MentionsTerm(%q, %q) = %v, want %v", tt.html, tt.term, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnotateHTML_LinksToGlossaryPage(t *testing.T) {
+	entries := []GlossaryEntry{
+		{MatchTerm: "fresco", Definition: "Wall painting on wet plaster", Url: EntryUrl("abc123", "fresco")},
+	}
+
+	got := AnnotateHTML("<p>The fresco was beautiful.</p>", entries)
+
+	if !strings.Contains(got, `<a href="/glossary/fresco-abc123" class="glossary-more">`) {
+		t.Errorf("expected the definition to link to the glossary page, got %s", got)
+	}
+}
@@ -0,0 +1,115 @@
+package glossary
+
+import (
+	"strings"
+
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"golang.org/x/net/html"
+)
+
+// Mentions holds the ids of the published artworks and artists whose text
+// mentions a glossary term.
+type Mentions struct {
+	ArtworkIds []string
+	ArtistIds  []string
+}
+
+// EntryUrl returns the URL of the glossary page of a term.
+func EntryUrl(id string, matchTerm string) string {
+	return "/glossary/" + utils.Slugify(matchTerm) + "-" + id
+}
+
+// MentionsTerm reports whether the text content of an HTML fragment contains
+// the term as a whole word, using the same matching rules as AnnotateHTML.
+func MentionsTerm(htmlStr string, term string) bool {
+	if htmlStr == "" || term == "" {
+		return false
+	}
+
+	idx, _ := indexWholeWordBounds(textContent(htmlStr), term)
+	return idx >= 0
+}
+
+// FindMentions returns the published artworks and artists that mention the term
+// in their comment or bio. Candidates are narrowed down with a LIKE query first,
+// so the whole-word check only runs on texts that contain the term at all.
+// The results are cached for as long as the glossary entries.
+func FindMentions(app core.App, term string, limit int) (Mentions, error) {
+	key := "glossary:mentions:" + strings.ToLower(term)
+
+	return utils.GetOrLoadCachedValue(app, key, glossaryTTL, func() (Mentions, error) {
+		artworkIds, err := findMentioningIds(app, constants.CollectionArtworks, "comment", term, limit)
+		if err != nil {
+			return Mentions{}, err
+		}
+
+		artistIds, err := findMentioningIds(app, constants.CollectionArtists, "bio", term, limit)
+		if err != nil {
+			return Mentions{}, err
+		}
+
+		return Mentions{ArtworkIds: artworkIds, ArtistIds: artistIds}, nil
+	})
+}
+
+func findMentioningIds(app core.App, collection string, field string, term string, limit int) ([]string, error) {
+	rows := []struct {
+		Id   string `db:"id"`
+		Text string `db:"text"`
+	}{}
+
+	err := app.DB().
+		Select("id", field+" AS text").
+		From(collection).
+		Where(dbx.NewExp("published IS true")).
+		AndWhere(dbx.Like(field, term)).
+		OrderBy("id").
+		All(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	for _, row := range rows {
+		if !MentionsTerm(row.Text, term) {
+			continue
+		}
+
+		ids = append(ids, row.Id)
+		if limit > 0 && len(ids) >= limit {
+			break
+		}
+	}
+
+	return ids, nil
+}
+
+// textContent returns the text of an HTML fragment. Text nodes are separated
+// by spaces so words in adjacent elements do not run together.
+func textContent(htmlStr string) string {
+	nodes, err := html.ParseFragment(strings.NewReader(htmlStr), nil)
+	if err != nil {
+		return htmlStr
+	}
+
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			b.WriteString(" ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+
+	for _, n := range nodes {
+		walk(n)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package glossary
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/blackfyre/wga/internal/testutils"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func TestFindMentionsUsesWholeWordsOfPublishedRecords(t *testing.T) {
+	app := testutils.NewTestApp(t)
+
+	artists := core.NewBaseCollection("Artists")
+	artists.Id = "artists"
+	artists.Fields.Add(
+		&core.TextField{Name: "name"},
+		&core.EditorField{Name: "bio"},
+		&core.BoolField{Name: "published"},
+	)
+	if err := app.Save(artists); err != nil {
+		t.Fatalf("save artists collection: %v", err)
+	}
+
+	artworks := core.NewBaseCollection("Artworks")
+	artworks.Id = "artworks"
+	artworks.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.EditorField{Name: "comment"},
+		&core.BoolField{Name: "published"},
+	)
+	if err := app.Save(artworks); err != nil {
+		t.Fatalf("save artworks collection: %v", err)
+	}
+
+	records := []struct {
+		collection *core.Collection
+		id         string
+		field      string
+		text       string
+		published  bool
+	}{
+		{artworks, "artwork00000001", "comment", "<p>A <b>tondo</b> of the Madonna.</p>", true},
+		{artworks, "artwork00000002", "comment", "<p>Several tondos survive.</p>", true},
+		{artworks, "artwork00000003", "comment", "<p>An unpublished tondo.</p>", false},
+		{artists, "artist000000001", "bio", "<p>He painted a Tondo for the Doni family.</p>", true},
+	}
+
+	for _, r := range records {
+		record := core.NewRecord(r.collection)
+		record.Set("id", r.id)
+		record.Set(r.field, r.text)
+		record.Set("published", r.published)
+		if err := app.Save(record); err != nil {
+			t.Fatalf("save record %s: %v", r.id, err)
+		}
+	}
+
+	mentions, err := FindMentions(app, "tondo", 10)
+	if err != nil {
+		t.Fatalf("find mentions: %v", err)
+	}
+
+	want := Mentions{
+		ArtworkIds: []string{"artwork00000001"},
+		ArtistIds:  []string{"artist000000001"},
+	}
+	if !reflect.DeepEqual(mentions, want) {
+		t.Fatalf("FindMentions() = %+v, want %+v", mentions, want)
+	}
+}
@@ -0,0 +1,332 @@
+// Package iiif implements the parts of the IIIF Image API 3.0 and Presentation API 3.0
+// the gallery serves: parsing and applying image requests, and the JSON documents
+// describing images, artworks and artists.
+package iiif
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	// MaxWidth and MaxHeight bound the size of a served image.
+	MaxWidth  = 4096
+	MaxHeight = 4096
+
+	ImageContext        = "http://iiif.io/api/image/3/context.json"
+	PresentationContext = "http://iiif.io/api/presentation/3/context.json"
+)
+
+var (
+	// ErrInvalidRequest is returned for image requests that are malformed or
+	// ask for an image outside the source or the size limits.
+	ErrInvalidRequest = errors.New("invalid IIIF image request")
+	// ErrUnsupported is returned for valid requests using features that are not implemented.
+	ErrUnsupported = errors.New("unsupported IIIF image request")
+)
+
+// ImageRequest is a parsed IIIF image request, resolved against the size of the source image.
+type ImageRequest struct {
+	Region   image.Rectangle
+	Width    int
+	Height   int
+	Rotation int
+	Mirror   bool
+	Quality  string
+	Format   string
+}
+
+// ParseImageRequest parses the region, size, rotation and quality.format path
+// segments of an image request for a source image of the given size.
+func ParseImageRequest(region string, size string, rotation string, qualityFormat string, sourceWidth int, sourceHeight int) (ImageRequest, error) {
+	req := ImageRequest{}
+
+	var err error
+	if req.Region, err = parseRegion(region, sourceWidth, sourceHeight); err != nil {
+		return req, err
+	}
+
+	if req.Width, req.Height, err = parseSize(size, req.Region.Dx(), req.Region.Dy()); err != nil {
+		return req, err
+	}
+
+	if req.Rotation, req.Mirror, err = parseRotation(rotation); err != nil {
+		return req, err
+	}
+
+	quality, format, ok := strings.Cut(qualityFormat, ".")
+	if !ok {
+		return req, fmt.Errorf("%w: missing format", ErrInvalidRequest)
+	}
+
+	switch quality {
+	case "default", "color", "gray", "bitonal":
+		req.Quality = quality
+	default:
+		return req, fmt.Errorf("%w: quality %q", ErrInvalidRequest, quality)
+	}
+
+	switch format {
+	case "jpg", "png":
+		req.Format = format
+	case "tif", "gif", "pdf", "jp2", "webp":
+		return req, fmt.Errorf("%w: format %q", ErrUnsupported, format)
+	default:
+		return req, fmt.Errorf("%w: format %q", ErrInvalidRequest, format)
+	}
+
+	return req, nil
+}
+
+// ContentType returns the media type of the requested format.
+func (r ImageRequest) ContentType() string {
+	if r.Format == "png" {
+		return "image/png"
+	}
+
+	return "image/jpeg"
+}
+
+// Apply crops, scales, mirrors, rotates and recolours the source image, in the
+// order the Image API defines.
+func (r ImageRequest) Apply(src image.Image) image.Image {
+	var img image.Image = imaging.Crop(src, r.Region.Add(src.Bounds().Min))
+
+	if img.Bounds().Dx() != r.Width || img.Bounds().Dy() != r.Height {
+		img = imaging.Resize(img, r.Width, r.Height, imaging.Lanczos)
+	}
+
+	if r.Mirror {
+		img = imaging.FlipH(img)
+	}
+
+	// imaging rotates counter-clockwise, IIIF rotation is clockwise
+	switch r.Rotation {
+	case 90:
+		img = imaging.Rotate270(img)
+	case 180:
+		img = imaging.Rotate180(img)
+	case 270:
+		img = imaging.Rotate90(img)
+	}
+
+	switch r.Quality {
+	case "gray":
+		img = imaging.Grayscale(img)
+	case "bitonal":
+		img = bitonal(img)
+	}
+
+	return img
+}
+
+// Encode writes the image in the requested format.
+func (r ImageRequest) Encode(w io.Writer, img image.Image) error {
+	if r.Format == "png" {
+		return imaging.Encode(w, img, imaging.PNG)
+	}
+
+	return imaging.Encode(w, img, imaging.JPEG, imaging.JPEGQuality(85))
+}
+
+func parseRegion(region string, width int, height int) (image.Rectangle, error) {
+	full := image.Rect(0, 0, width, height)
+
+	switch {
+	case region == "full":
+		return full, nil
+	case region == "square":
+		side := min(width, height)
+		x := (width - side) / 2
+		y := (height - side) / 2
+		return image.Rect(x, y, x+side, y+side), nil
+	}
+
+	raw, percent := strings.CutPrefix(region, "pct:")
+	values, err := parseNumbers(raw, 4, percent)
+	if err != nil {
+		return image.Rectangle{}, fmt.Errorf("%w: region %q", ErrInvalidRequest, region)
+	}
+
+	if percent {
+		values[0] = values[0] * float64(width) / 100
+		values[1] = values[1] * float64(height) / 100
+		values[2] = values[2] * float64(width) / 100
+		values[3] = values[3] * float64(height) / 100
+	}
+
+	x, y := int(math.Round(values[0])), int(math.Round(values[1]))
+	rect := image.Rect(x, y, x+int(math.Round(values[2])), y+int(math.Round(values[3]))).Intersect(full)
+
+	if values[2] <= 0 || values[3] <= 0 || rect.Empty() {
+		return image.Rectangle{}, fmt.Errorf("%w: region %q is outside the image", ErrInvalidRequest, region)
+	}
+
+	return rect, nil
+}
+
+// parseSize resolves a size against the size of the region. Without the ^ prefix
+// the image is never scaled up, and the result is bounded by MaxWidth and MaxHeight.
+func parseSize(size string, regionWidth int, regionHeight int) (int, int, error) {
+	raw, upscale := strings.CutPrefix(size, "^")
+	aspect := float64(regionWidth) / float64(regionHeight)
+
+	var width, height int
+
+	switch {
+	case raw == "max":
+		width, height = regionWidth, regionHeight
+		if upscale || width > MaxWidth || height > MaxHeight {
+			width, height = fit(regionWidth, regionHeight, MaxWidth, MaxHeight, upscale)
+		}
+		return width, height, nil
+	case strings.HasPrefix(raw, "pct:"):
+		values, err := parseNumbers(strings.TrimPrefix(raw, "pct:"), 1, true)
+		if err != nil || values[0] <= 0 {
+			return 0, 0, fmt.Errorf("%w: size %q", ErrInvalidRequest, size)
+		}
+		width = int(math.Round(float64(regionWidth) * values[0] / 100))
+		height = int(math.Round(float64(regionHeight) * values[0] / 100))
+	case strings.HasPrefix(raw, "!"):
+		w, h, err := parseDimensions(strings.TrimPrefix(raw, "!"))
+		if err != nil || w == 0 || h == 0 {
+			return 0, 0, fmt.Errorf("%w: size %q", ErrInvalidRequest, size)
+		}
+		width, height = fit(regionWidth, regionHeight, w, h, upscale)
+	default:
+		w, h, err := parseDimensions(raw)
+		if err != nil || (w == 0 && h == 0) {
+			return 0, 0, fmt.Errorf("%w: size %q", ErrInvalidRequest, size)
+		}
+		width, height = w, h
+		if width == 0 {
+			width = int(math.Round(float64(height) * aspect))
+		}
+		if height == 0 {
+			height = int(math.Round(float64(width) / aspect))
+		}
+	}
+
+	width, height = max(width, 1), max(height, 1)
+
+	if !upscale && (width > regionWidth || height > regionHeight) {
+		return 0, 0, fmt.Errorf("%w: size %q scales the region up", ErrInvalidRequest, size)
+	}
+
+	if width > MaxWidth || height > MaxHeight {
+		return 0, 0, fmt.Errorf("%w: size %q is larger than %dx%d", ErrInvalidRequest, size, MaxWidth, MaxHeight)
+	}
+
+	return width, height, nil
+}
+
+// fit returns the largest size with the aspect ratio of the region that fits in the bounds.
+func fit(regionWidth int, regionHeight int, boundWidth int, boundHeight int, upscale bool) (int, int) {
+	scale := math.Min(float64(boundWidth)/float64(regionWidth), float64(boundHeight)/float64(regionHeight))
+	if !upscale {
+		scale = math.Min(scale, 1)
+	}
+
+	return max(int(float64(regionWidth)*scale), 1), max(int(float64(regionHeight)*scale), 1)
+}
+
+func parseRotation(rotation string) (int, bool, error) {
+	raw, mirror := strings.CutPrefix(rotation, "!")
+
+	degrees, err := strconv.ParseFloat(raw, 64)
+	if err != nil || degrees < 0 || degrees > 360 {
+		return 0, false, fmt.Errorf("%w: rotation %q", ErrInvalidRequest, rotation)
+	}
+
+	switch degrees {
+	case 0, 360:
+		return 0, mirror, nil
+	case 90, 180, 270:
+		return int(degrees), mirror, nil
+	}
+
+	return 0, false, fmt.Errorf("%w: rotation %q is not a multiple of 90", ErrUnsupported, rotation)
+}
+
+// parseDimensions parses "w,h", "w," and ",h", returning 0 for a missing dimension.
+func parseDimensions(raw string) (int, int, error) {
+	ws, hs, ok := strings.Cut(raw, ",")
+	if !ok {
+		return 0, 0, ErrInvalidRequest
+	}
+
+	parse := func(s string) (int, error) {
+		if s == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return 0, ErrInvalidRequest
+		}
+		return n, nil
+	}
+
+	w, err := parse(ws)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	h, err := parse(hs)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return w, h, nil
+}
+
+// parseNumbers parses a comma separated list of count non-negative numbers.
+// Fractions are only accepted for percentages.
+func parseNumbers(raw string, count int, fractions bool) ([]float64, error) {
+	parts := strings.Split(raw, ",")
+	if len(parts) != count {
+		return nil, ErrInvalidRequest
+	}
+
+	values := make([]float64, count)
+	for i, part := range parts {
+		var err error
+		if fractions {
+			values[i], err = strconv.ParseFloat(part, 64)
+		} else {
+			var n int
+			n, err = strconv.Atoi(part)
+			values[i] = float64(n)
+		}
+
+		if err != nil || values[i] < 0 || math.IsInf(values[i], 0) || math.IsNaN(values[i]) {
+			return nil, ErrInvalidRequest
+		}
+	}
+
+	return values, nil
+}
+
+// bitonal converts the image to black and white pixels only.
+func bitonal(img image.Image) image.Image {
+	gray := imaging.Grayscale(img)
+	bounds := gray.Bounds()
+	out := image.NewGray(bounds)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if gray.NRGBAAt(x, y).R >= 128 {
+				out.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,127 @@
+package iiif
+
+import (
+	"errors"
+	"image"
+	"testing"
+)
+
+func TestParseImageRequestRegions(t *testing.T) {
+	cases := map[string]image.Rectangle{
+		"full":            image.Rect(0, 0, 400, 200),
+		"square":          image.Rect(100, 0, 300, 200),
+		"10,20,100,50":    image.Rect(10, 20, 110, 70),
+		"350,150,100,100": image.Rect(350, 150, 400, 200),
+		"pct:50,50,50,50": image.Rect(200, 100, 400, 200),
+	}
+
+	for region, want := range cases {
+		req, err := ParseImageRequest(region, "max", "0", "default.jpg", 400, 200)
+		if err != nil {
+			t.Errorf("region %q: %v", region, err)
+			continue
+		}
+		if req.Region != want {
+			t.Errorf("region %q = %v, want %v", region, req.Region, want)
+		}
+	}
+
+	for _, region := range []string{"400,0,10,10", "0,0,0,10", "a,b,c,d", "1,2,3", "pct:-1,0,10,10"} {
+		if _, err := ParseImageRequest(region, "max", "0", "default.jpg", 400, 200); !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("region %q: expected an invalid request, got %v", region, err)
+		}
+	}
+}
+
+func TestParseImageRequestSizes(t *testing.T) {
+	cases := map[string][2]int{
+		"max":        {400, 200},
+		"200,":       {200, 100},
+		",50":        {100, 50},
+		"pct:25":     {100, 50},
+		"!100,100":   {100, 50},
+		"300,100":    {300, 100},
+		"^800,":      {800, 400},
+		"^max":       {4096, 2048},
+		"^!1000,100": {200, 100},
+	}
+
+	for size, want := range cases {
+		req, err := ParseImageRequest("full", size, "0", "default.jpg", 400, 200)
+		if err != nil {
+			t.Errorf("size %q: %v", size, err)
+			continue
+		}
+		if req.Width != want[0] || req.Height != want[1] {
+			t.Errorf("size %q = %dx%d, want %dx%d", size, req.Width, req.Height, want[0], want[1])
+		}
+	}
+
+	for _, size := range []string{"800,", "pct:150", "^5000,", "0,0", ",", "big"} {
+		if _, err := ParseImageRequest("full", size, "0", "default.jpg", 400, 200); !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("size %q: expected an invalid request, got %v", size, err)
+		}
+	}
+}
+
+func TestParseImageRequestRotationQualityAndFormat(t *testing.T) {
+	req, err := ParseImageRequest("full", "max", "!90", "gray.png", 400, 200)
+	if err != nil {
+		t.Fatalf("ParseImageRequest: %v", err)
+	}
+	if req.Rotation != 90 || !req.Mirror || req.Quality != "gray" || req.ContentType() != "image/png" {
+		t.Fatalf("unexpected request %+v", req)
+	}
+
+	unsupported := [][2]string{{"45", "default.jpg"}, {"0", "default.webp"}}
+	for _, c := range unsupported {
+		if _, err := ParseImageRequest("full", "max", c[0], c[1], 400, 200); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("%s %s: expected an unsupported request, got %v", c[0], c[1], err)
+		}
+	}
+
+	invalid := [][2]string{{"-90", "default.jpg"}, {"0", "sepia.jpg"}, {"0", "default"}, {"0", "default.bmp"}}
+	for _, c := range invalid {
+		if _, err := ParseImageRequest("full", "max", c[0], c[1], 400, 200); !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("%s %s: expected an invalid request, got %v", c[0], c[1], err)
+		}
+	}
+}
+
+func TestApplyCropsScalesAndRotates(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 400, 200))
+
+	req, err := ParseImageRequest("0,0,200,200", "100,", "90", "bitonal.jpg", 400, 200)
+	if err != nil {
+		t.Fatalf("ParseImageRequest: %v", err)
+	}
+
+	out := req.Apply(src)
+	if out.Bounds().Dx() != 100 || out.Bounds().Dy() != 100 {
+		t.Fatalf("output size = %v, want 100x100", out.Bounds())
+	}
+
+	req, err = ParseImageRequest("full", "200,", "270", "default.jpg", 400, 200)
+	if err != nil {
+		t.Fatalf("ParseImageRequest: %v", err)
+	}
+
+	out = req.Apply(src)
+	if out.Bounds().Dx() != 100 || out.Bounds().Dy() != 200 {
+		t.Fatalf("rotated output size = %v, want 100x200", out.Bounds())
+	}
+}
+
+func TestNewImageInfoSizes(t *testing.T) {
+	info := NewImageInfo("https://example.org/iiif/image/1", 1000, 600)
+
+	want := []Size{{Width: 250, Height: 150}, {Width: 500, Height: 300}, {Width: 1000, Height: 600}}
+	if len(info.Sizes) != len(want) {
+		t.Fatalf("sizes = %v, want %v", info.Sizes, want)
+	}
+	for i := range want {
+		if info.Sizes[i] != want[i] {
+			t.Fatalf("sizes = %v, want %v", info.Sizes, want)
+		}
+	}
+}
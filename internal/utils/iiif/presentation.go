@@ -0,0 +1,162 @@
+package iiif
+
+// LanguageMap is a IIIF language map. The gallery's texts are all English.
+type LanguageMap map[string][]string
+
+// English returns a language map holding an English value.
+func English(value string) LanguageMap {
+	return LanguageMap{"en": {value}}
+}
+
+type Size struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// ImageInfo is the info.json document of an image service.
+type ImageInfo struct {
+	Context        string   `json:"@context"`
+	Id             string   `json:"id"`
+	Type           string   `json:"type"`
+	Protocol       string   `json:"protocol"`
+	Profile        string   `json:"profile"`
+	Width          int      `json:"width"`
+	Height         int      `json:"height"`
+	MaxWidth       int      `json:"maxWidth"`
+	MaxHeight      int      `json:"maxHeight"`
+	Sizes          []Size   `json:"sizes,omitempty"`
+	ExtraQualities []string `json:"extraQualities"`
+	ExtraFeatures  []string `json:"extraFeatures"`
+}
+
+// NewImageInfo describes the image service at serviceId for an image of the given size.
+// The preferred sizes halve the image until it is smaller than a thumbnail.
+func NewImageInfo(serviceId string, width int, height int) ImageInfo {
+	info := ImageInfo{
+		Context:        ImageContext,
+		Id:             serviceId,
+		Type:           "ImageService3",
+		Protocol:       "http://iiif.io/api/image",
+		Profile:        "level2",
+		Width:          width,
+		Height:         height,
+		MaxWidth:       MaxWidth,
+		MaxHeight:      MaxHeight,
+		ExtraQualities: []string{"gray", "bitonal"},
+		ExtraFeatures:  []string{"mirroring", "sizeUpscaling"},
+	}
+
+	for w, h := width, height; w >= 150 && h >= 150; w, h = w/2, h/2 {
+		if w <= MaxWidth && h <= MaxHeight {
+			info.Sizes = append([]Size{{Width: w, Height: h}}, info.Sizes...)
+		}
+	}
+
+	return info
+}
+
+type Service struct {
+	Id      string `json:"id"`
+	Type    string `json:"type"`
+	Profile string `json:"profile"`
+}
+
+type Resource struct {
+	Id      string      `json:"id"`
+	Type    string      `json:"type"`
+	Label   LanguageMap `json:"label,omitempty"`
+	Format  string      `json:"format,omitempty"`
+	Width   int         `json:"width,omitempty"`
+	Height  int         `json:"height,omitempty"`
+	Service []Service   `json:"service,omitempty"`
+}
+
+type Annotation struct {
+	Id         string   `json:"id"`
+	Type       string   `json:"type"`
+	Motivation string   `json:"motivation"`
+	Target     string   `json:"target"`
+	Body       Resource `json:"body"`
+}
+
+type AnnotationPage struct {
+	Id    string       `json:"id"`
+	Type  string       `json:"type"`
+	Items []Annotation `json:"items"`
+}
+
+type Canvas struct {
+	Id     string           `json:"id"`
+	Type   string           `json:"type"`
+	Label  LanguageMap      `json:"label,omitempty"`
+	Width  int              `json:"width"`
+	Height int              `json:"height"`
+	Items  []AnnotationPage `json:"items"`
+}
+
+type MetadataEntry struct {
+	Label LanguageMap `json:"label"`
+	Value LanguageMap `json:"value"`
+}
+
+type Manifest struct {
+	Context           string          `json:"@context"`
+	Id                string          `json:"id"`
+	Type              string          `json:"type"`
+	Label             LanguageMap     `json:"label"`
+	Summary           LanguageMap     `json:"summary,omitempty"`
+	Metadata          []MetadataEntry `json:"metadata,omitempty"`
+	RequiredStatement *MetadataEntry  `json:"requiredStatement,omitempty"`
+	Homepage          []Resource      `json:"homepage,omitempty"`
+	Thumbnail         []Resource      `json:"thumbnail,omitempty"`
+	Items             []Canvas        `json:"items"`
+}
+
+// ManifestRef is a manifest listed in a collection.
+type ManifestRef struct {
+	Id        string      `json:"id"`
+	Type      string      `json:"type"`
+	Label     LanguageMap `json:"label"`
+	Thumbnail []Resource  `json:"thumbnail,omitempty"`
+}
+
+type Collection struct {
+	Context  string        `json:"@context"`
+	Id       string        `json:"id"`
+	Type     string        `json:"type"`
+	Label    LanguageMap   `json:"label"`
+	Homepage []Resource    `json:"homepage,omitempty"`
+	Items    []ManifestRef `json:"items"`
+}
+
+// ImageCanvas returns a canvas painted with the full image of an image service.
+func ImageCanvas(canvasId string, serviceId string, width int, height int) Canvas {
+	return Canvas{
+		Id:     canvasId,
+		Type:   "Canvas",
+		Width:  width,
+		Height: height,
+		Items: []AnnotationPage{{
+			Id:   canvasId + "/page",
+			Type: "AnnotationPage",
+			Items: []Annotation{{
+				Id:         canvasId + "/page/image",
+				Type:       "Annotation",
+				Motivation: "painting",
+				Target:     canvasId,
+				Body: Resource{
+					Id:     serviceId + "/full/max/0/default.jpg",
+					Type:   "Image",
+					Format: "image/jpeg",
+					Width:  width,
+					Height: height,
+					Service: []Service{{
+						Id:      serviceId,
+						Type:    "ImageService3",
+						Profile: "level2",
+					}},
+				},
+			}},
+		}},
+	}
+}
@@ -0,0 +1,188 @@
+package imagehash
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/logging"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// DefaultDistance is the Hamming distance within which images are reported as
+// near-duplicates, unless configured otherwise.
+const DefaultDistance = 8
+
+// hashBatchSize is how many artworks are loaded at a time while hashing.
+const hashBatchSize = 200
+
+// HashMissing computes the hashes of the artwork images without up to date hashes,
+// or of every artwork image when all is set. Images that fail to hash are logged and skipped.
+func HashMissing(app core.App, all bool) (int, error) {
+	filter := "image != '' && image_hash_source != image && id > {:after}"
+	if all {
+		filter = "image != '' && id > {:after}"
+	}
+
+	hashed := 0
+	after := ""
+
+	for {
+		records, err := app.FindRecordsByFilter(constants.CollectionArtworks, filter, "id", hashBatchSize, 0, dbx.Params{"after": after})
+		if err != nil {
+			return hashed, err
+		}
+
+		for _, record := range records {
+			if err := Generate(app, record); err != nil {
+				app.Logger().Warn("Image hashing failed",
+					"event", "artwork.image_hash.build",
+					"record_id", record.Id,
+					"outcome", "failed",
+					"error_type", logging.ErrorType(err),
+					"error", logging.Redact(err),
+				)
+				continue
+			}
+			hashed++
+		}
+
+		if len(records) < hashBatchSize {
+			return hashed, nil
+		}
+
+		after = records[len(records)-1].Id
+	}
+}
+
+// Cluster is a group of artworks whose images are near-duplicates. Every artwork is
+// within the distance of at least one other artwork of the cluster; the distances
+// of the matches are to the first artwork.
+type Cluster []Match
+
+// Clusters groups the entries whose hashes are within distance of each other.
+// Entries without near-duplicates are left out. The largest clusters come first.
+func Clusters(entries []Entry, distance int) []Cluster {
+	parents := make([]int, len(entries))
+	for i := range parents {
+		parents[i] = i
+	}
+
+	var root func(i int) int
+	root = func(i int) int {
+		if parents[i] != i {
+			parents[i] = root(parents[i])
+		}
+		return parents[i]
+	}
+
+	for i := range entries {
+		for j := i + 1; j < len(entries); j++ {
+			if Distance(entries[i].Hash, entries[j].Hash) <= distance {
+				parents[root(j)] = root(i)
+			}
+		}
+	}
+
+	groups := map[int][]int{}
+	for i := range entries {
+		groups[root(i)] = append(groups[root(i)], i)
+	}
+
+	clusters := []Cluster{}
+	for _, members := range groups {
+		if len(members) < 2 {
+			continue
+		}
+
+		first := entries[members[0]]
+		cluster := make(Cluster, 0, len(members))
+		for _, i := range members {
+			cluster = append(cluster, Match{Entry: entries[i], Distance: Distance(first.Hash, entries[i].Hash)})
+		}
+		clusters = append(clusters, cluster)
+	}
+
+	slices.SortFunc(clusters, func(a, b Cluster) int {
+		return cmp.Or(len(b)-len(a), cmp.Compare(a[0].Id, b[0].Id))
+	})
+
+	return clusters
+}
+
+// Report formats, see WriteReport.
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// ValidateFormat checks that a report format is one of the Format constants, so that a
+// command can reject it before hashing.
+func ValidateFormat(format string) error {
+	if format != FormatJSON && format != FormatCSV {
+		return fmt.Errorf("unknown report format %q, expected %s or %s", format, FormatJSON, FormatCSV)
+	}
+
+	return nil
+}
+
+type reportArtwork struct {
+	Id       string `json:"id"`
+	Title    string `json:"title"`
+	Image    string `json:"image"`
+	Hash     string `json:"hash"`
+	Distance int    `json:"distance"`
+}
+
+type report struct {
+	Hash     Kind              `json:"hash"`
+	Distance int               `json:"distance"`
+	Clusters [][]reportArtwork `json:"clusters"`
+}
+
+// WriteReport writes the clusters found with a hash and distance as JSON or CSV.
+// The CSV has a row per artwork, numbering the clusters from 1.
+func WriteReport(w io.Writer, format string, kind Kind, distance int, clusters []Cluster) error {
+	switch format {
+	case FormatJSON:
+		r := report{Hash: kind, Distance: distance, Clusters: make([][]reportArtwork, 0, len(clusters))}
+		for _, cluster := range clusters {
+			artworks := make([]reportArtwork, 0, len(cluster))
+			for _, m := range cluster {
+				artworks = append(artworks, reportArtwork{Id: m.Id, Title: m.Title, Image: m.Image, Hash: m.Hash.String(), Distance: m.Distance})
+			}
+			r.Clusters = append(r.Clusters, artworks)
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(r)
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"cluster", "id", "title", "image", string(kind), "distance"}); err != nil {
+			return err
+		}
+
+		for index, cluster := range clusters {
+			for _, m := range cluster {
+				err := cw.Write([]string{strconv.Itoa(index + 1), m.Id, m.Title, m.Image, m.Hash.String(), strconv.Itoa(m.Distance)})
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		cw.Flush()
+
+		return cw.Error()
+	default:
+		return ValidateFormat(format)
+	}
+}
@@ -0,0 +1,201 @@
+// Package imagehash computes perceptual hashes of artwork images and finds the
+// artworks whose images are near-duplicates of each other.
+package imagehash
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"math/bits"
+	"slices"
+	"strconv"
+
+	"github.com/disintegration/imaging"
+)
+
+// Kind is one of the perceptual hashes of an image.
+type Kind string
+
+const (
+	// AHash compares every pixel of an 8x8 thumbnail to the mean.
+	AHash Kind = "ahash"
+	// DHash compares every pixel of a 9x8 thumbnail to its right neighbour.
+	DHash Kind = "dhash"
+	// PHash compares the low frequencies of the DCT of a 32x32 thumbnail to their median.
+	// It is the most robust to rescaling, recompression and small colour changes.
+	PHash Kind = "phash"
+)
+
+// Kinds are the hashes computed for every image.
+var Kinds = []Kind{AHash, DHash, PHash}
+
+// ParseKind parses a hash kind, as named by the Kind constants.
+func ParseKind(value string) (Kind, error) {
+	kind := Kind(value)
+	if !slices.Contains(Kinds, kind) {
+		return "", fmt.Errorf("unknown hash %q, expected one of %v", value, Kinds)
+	}
+
+	return kind, nil
+}
+
+// Hash is a 64 bit perceptual hash.
+type Hash uint64
+
+// String returns the hash as 16 hex digits, the way it is stored on the records.
+func (h Hash) String() string {
+	return fmt.Sprintf("%016x", uint64(h))
+}
+
+// ParseHash parses a hash formatted by Hash.String.
+func ParseHash(value string) (Hash, error) {
+	h, err := strconv.ParseUint(value, 16, 64)
+	if err != nil || len(value) != 16 {
+		return 0, fmt.Errorf("invalid hash %q", value)
+	}
+
+	return Hash(h), nil
+}
+
+// Distance returns the Hamming distance between two hashes, the number of bits they differ in.
+// Images of the same painting are usually within a distance of 10.
+func Distance(a Hash, b Hash) int {
+	return bits.OnesCount64(uint64(a ^ b))
+}
+
+// Hashes are the perceptual hashes of an image.
+type Hashes struct {
+	A Hash
+	D Hash
+	P Hash
+}
+
+// Get returns the hash of a kind.
+func (h Hashes) Get(kind Kind) Hash {
+	switch kind {
+	case AHash:
+		return h.A
+	case DHash:
+		return h.D
+	default:
+		return h.P
+	}
+}
+
+// Compute returns the perceptual hashes of an image.
+func Compute(img image.Image) Hashes {
+	gray := imaging.Grayscale(img)
+
+	return Hashes{
+		A: averageHash(gray),
+		D: differenceHash(gray),
+		P: perceptionHash(gray),
+	}
+}
+
+// luminance returns the pixels of the image scaled down to width x height, row by row.
+func luminance(gray image.Image, width int, height int) []float64 {
+	small := imaging.Resize(gray, width, height, imaging.Lanczos)
+
+	pixels := make([]float64, 0, width*height)
+	for y := range height {
+		for x := range width {
+			// the image is grayscale, so any channel will do
+			pixels = append(pixels, float64(small.Pix[y*small.Stride+x*4]))
+		}
+	}
+
+	return pixels
+}
+
+func averageHash(gray image.Image) Hash {
+	pixels := luminance(gray, 8, 8)
+
+	mean := 0.0
+	for _, p := range pixels {
+		mean += p
+	}
+	mean /= float64(len(pixels))
+
+	var h Hash
+	for i, p := range pixels {
+		if p > mean {
+			h |= 1 << i
+		}
+	}
+
+	return h
+}
+
+func differenceHash(gray image.Image) Hash {
+	pixels := luminance(gray, 9, 8)
+
+	var h Hash
+	for y := range 8 {
+		for x := range 8 {
+			if pixels[y*9+x] < pixels[y*9+x+1] {
+				h |= 1 << (y*8 + x)
+			}
+		}
+	}
+
+	return h
+}
+
+func perceptionHash(gray image.Image) Hash {
+	const size = 32
+
+	pixels := luminance(gray, size, size)
+
+	// the 2D DCT is computed as the DCT of the rows, then of the columns
+	rows := make([]float64, size*size)
+	for y := range size {
+		copy(rows[y*size:], dct(pixels[y*size:(y+1)*size]))
+	}
+
+	coefficients := make([]float64, size*size)
+	column := make([]float64, size)
+	for x := range size {
+		for y := range size {
+			column[y] = rows[y*size+x]
+		}
+		for y, c := range dct(column) {
+			coefficients[y*size+x] = c
+		}
+	}
+
+	// the lowest 8x8 frequencies, the first of which is the mean brightness
+	low := make([]float64, 0, 64)
+	for y := range 8 {
+		low = append(low, coefficients[y*size:y*size+8]...)
+	}
+
+	sorted := slices.Clone(low[1:])
+	slices.Sort(sorted)
+	median := (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+
+	var h Hash
+	for i, c := range low {
+		if c > median {
+			h |= 1 << i
+		}
+	}
+
+	return h
+}
+
+// dct returns the (unscaled) type II discrete cosine transform of the values.
+func dct(values []float64) []float64 {
+	n := len(values)
+	out := make([]float64, n)
+
+	for k := range n {
+		sum := 0.0
+		for i, v := range values {
+			sum += v * math.Cos(math.Pi/float64(n)*(float64(i)+0.5)*float64(k))
+		}
+		out[k] = sum
+	}
+
+	return out
+}
@@ -0,0 +1,220 @@
+package imagehash
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/blackfyre/wga/internal/testutils"
+	"github.com/disintegration/imaging"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+func TestHashRoundTrip(t *testing.T) {
+	h := Hash(0x00ff00ff12345678)
+
+	got, err := ParseHash(h.String())
+	if err != nil || got != h {
+		t.Fatalf("ParseHash(%q) = %v, %v", h.String(), got, err)
+	}
+
+	for _, value := range []string{"", "xyz", "ff", "00ff00ff123456789"} {
+		if _, err := ParseHash(value); err == nil {
+			t.Errorf("expected %q to be rejected", value)
+		}
+	}
+
+	if d := Distance(0b1011, 0b0110); d != 3 {
+		t.Fatalf("Distance = %d, want 3", d)
+	}
+}
+
+func TestComputeMatchesCopiesOfTheSameImage(t *testing.T) {
+	original := Compute(testPattern(400, 300, false))
+
+	// a smaller copy, recompressed as JPEG
+	var buff bytes.Buffer
+	if err := jpeg.Encode(&buff, imaging.Resize(testPattern(400, 300, false), 200, 150, imaging.Box), &jpeg.Options{Quality: 60}); err != nil {
+		t.Fatal(err)
+	}
+	copied, err := Decode(&buff)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	different := Compute(testPattern(400, 300, true))
+
+	for _, kind := range Kinds {
+		if d := Distance(original.Get(kind), copied.Get(kind)); d > DefaultDistance {
+			t.Errorf("%s of the copy is %d bits away", kind, d)
+		}
+		if d := Distance(original.Get(kind), different.Get(kind)); d <= DefaultDistance {
+			t.Errorf("%s of a different image is only %d bits away", kind, d)
+		}
+	}
+}
+
+func TestClusters(t *testing.T) {
+	entries := []Entry{
+		{Id: "a", Hash: 0b0000},
+		{Id: "b", Hash: 0b0001},
+		// only within distance of b, still in the cluster of a and b
+		{Id: "c", Hash: 0b0011},
+		{Id: "d", Hash: 0xff00},
+		{Id: "e", Hash: 0xff01},
+		{Id: "f", Hash: 0xf0f0},
+	}
+
+	clusters := Clusters(entries, 1)
+	if len(clusters) != 2 {
+		t.Fatalf("got %d clusters", len(clusters))
+	}
+
+	got := [][]string{}
+	for _, cluster := range clusters {
+		ids := []string{}
+		for _, m := range cluster {
+			ids = append(ids, m.Id)
+		}
+		got = append(got, ids)
+	}
+
+	if strings.Join(got[0], ",") != "a,b,c" || strings.Join(got[1], ",") != "d,e" {
+		t.Fatalf("clusters = %v", got)
+	}
+
+	if clusters[0][2].Distance != 2 {
+		t.Fatalf("distance of c to a = %d, want 2", clusters[0][2].Distance)
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	clusters := []Cluster{{
+		{Entry: Entry{Id: "a", Title: "Venus, again", Image: "a.jpg", Hash: 1}},
+		{Entry: Entry{Id: "b", Title: "Venus", Image: "b.jpg", Hash: 3}, Distance: 1},
+	}}
+
+	var buff bytes.Buffer
+	if err := WriteReport(&buff, FormatCSV, PHash, 4, clusters); err != nil {
+		t.Fatalf("WriteReport: %v", err)
+	}
+
+	want := "cluster,id,title,image,phash,distance\n" +
+		"1,a,\"Venus, again\",a.jpg,0000000000000001,0\n" +
+		"1,b,Venus,b.jpg,0000000000000003,1\n"
+	if buff.String() != want {
+		t.Fatalf("CSV report:\n%s\nwant:\n%s", buff.String(), want)
+	}
+
+	buff.Reset()
+	if err := WriteReport(&buff, FormatJSON, PHash, 4, clusters); err != nil {
+		t.Fatalf("WriteReport: %v", err)
+	}
+	if !strings.Contains(buff.String(), `"id": "b"`) || !strings.Contains(buff.String(), `"distance": 4`) {
+		t.Fatalf("unexpected JSON report %s", buff.String())
+	}
+
+	if err := WriteReport(&buff, "xml", PHash, 4, clusters); err == nil {
+		t.Fatal("expected an unknown format to be rejected")
+	}
+	if err := ValidateFormat("xml"); err == nil {
+		t.Fatal("expected an unknown format to be invalid")
+	}
+	if err := ValidateFormat(FormatCSV); err != nil {
+		t.Fatalf("ValidateFormat: %v", err)
+	}
+}
+
+func TestGenerateAndFindNear(t *testing.T) {
+	app := testutils.NewTestApp(t)
+
+	collection := core.NewBaseCollection("artworks")
+	collection.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.FileField{Name: "image", MaxSelect: 1, MaxSize: 1 << 20},
+	)
+	for _, name := range []string{AHashField, DHashField, PHashField, SourceField} {
+		collection.Fields.Add(&core.TextField{Name: name})
+	}
+	if err := app.Save(collection); err != nil {
+		t.Fatalf("save collection: %v", err)
+	}
+
+	records := []*core.Record{}
+	for _, inverted := range []bool{false, false, true} {
+		record := core.NewRecord(collection)
+		record.Set("image", testImageFile(t, testPattern(300, 200, inverted)))
+		if err := app.Save(record); err != nil {
+			t.Fatalf("save record: %v", err)
+		}
+		records = append(records, record)
+	}
+
+	hashed, err := HashMissing(app, false)
+	if err != nil || hashed != 3 {
+		t.Fatalf("HashMissing = %d, %v", hashed, err)
+	}
+
+	if hashed, _ := HashMissing(app, false); hashed != 0 {
+		t.Fatalf("expected up to date hashes to be skipped, %d hashed", hashed)
+	}
+
+	first, err := app.FindRecordById(collection.Id, records[0].Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !UpToDate(first) {
+		t.Fatal("expected the hashes to be up to date")
+	}
+
+	h, _ := ParseHash(first.GetString(PHashField))
+
+	matches, err := FindNear(app, PHash, h, DefaultDistance, first.Id)
+	if err != nil {
+		t.Fatalf("FindNear: %v", err)
+	}
+	if len(matches) != 1 || matches[0].Id != records[1].Id || matches[0].Distance != 0 {
+		t.Fatalf("matches = %+v", matches)
+	}
+}
+
+// testPattern draws overlapping soft waves, a little like a photograph, or their negative.
+func testPattern(width int, height int, inverted bool) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	for y := range height {
+		for x := range width {
+			fx, fy := float64(x)/float64(width), float64(y)/float64(height)
+			v := 0.5 + 0.2*math.Sin(7*fx+2*fy) + 0.15*math.Cos(3*fx*fy*9+1) + 0.1*math.Sin(11*fy-5*fx)
+			if inverted {
+				v = 1 - v
+			}
+			c := uint8(max(0, min(255, v*255)))
+			img.Set(x, y, color.NRGBA{R: c, G: c / 2, B: 255 - c, A: 255})
+		}
+	}
+
+	return img
+}
+
+func testImageFile(t *testing.T, img image.Image) *filesystem.File {
+	t.Helper()
+
+	var buff bytes.Buffer
+	if err := png.Encode(&buff, img); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := filesystem.NewFileFromBytes(buff.Bytes(), "scan.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
@@ -0,0 +1,167 @@
+package imagehash
+
+import (
+	"errors"
+	"io"
+	"slices"
+
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/disintegration/imaging"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Fields of the artworks holding the hashes of their image. SourceField holds the
+// name of the image file the hashes were computed from.
+const (
+	AHashField  = "image_ahash"
+	DHashField  = "image_dhash"
+	PHashField  = "image_phash"
+	SourceField = "image_hash_source"
+)
+
+// DuplicatesField is the field of the artworks listing the artworks whose image was a
+// near-duplicate of theirs when it was uploaded, the closest first.
+const DuplicatesField = "image_duplicates"
+
+// MaxRecordedDuplicates bounds how many near-duplicates are recorded on an artwork.
+const MaxRecordedDuplicates = 10
+
+var ErrNoImage = errors.New("artwork has no image")
+
+// Field returns the field holding the hash of a kind.
+func Field(kind Kind) string {
+	switch kind {
+	case AHash:
+		return AHashField
+	case DHash:
+		return DHashField
+	default:
+		return PHashField
+	}
+}
+
+// UpToDate reports whether the record has the hashes of its current image.
+func UpToDate(record *core.Record) bool {
+	image := record.GetString("image")
+
+	return image != "" && record.GetString(SourceField) == image
+}
+
+// Set stores the hashes of the image file on the record, without saving it. The image is
+// passed by name, as an upload has no name in the image field until the record is saved.
+func Set(record *core.Record, image string, hashes Hashes) {
+	record.Set(AHashField, hashes.A.String())
+	record.Set(DHashField, hashes.D.String())
+	record.Set(PHashField, hashes.P.String())
+	record.Set(SourceField, image)
+}
+
+// Decode decodes an image and returns its hashes.
+func Decode(reader io.Reader) (Hashes, error) {
+	img, err := imaging.Decode(reader)
+	if err != nil {
+		return Hashes{}, err
+	}
+
+	return Compute(img), nil
+}
+
+// Generate computes the hashes of the record's image, read through the PocketBase
+// filesystem, and saves them on the record.
+func Generate(app core.App, record *core.Record) error {
+	image := record.GetString("image")
+	if image == "" {
+		return ErrNoImage
+	}
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	reader, err := fsys.GetReader(record.BaseFilesPath() + "/" + image)
+	if err != nil {
+		return err
+	}
+
+	hashes, err := Decode(reader)
+	reader.Close()
+	if err != nil {
+		return err
+	}
+
+	Set(record, image, hashes)
+
+	return app.SaveNoValidate(record)
+}
+
+// Entry is a hashed artwork image.
+type Entry struct {
+	Id    string
+	Title string
+	Image string
+	Hash  Hash
+}
+
+// Entries returns the artworks with a hash of the kind, in id order.
+// Stale hashes of replaced images are left out.
+func Entries(app core.App, kind Kind) ([]Entry, error) {
+	rows := []struct {
+		Id    string `db:"id"`
+		Title string `db:"title"`
+		Image string `db:"image"`
+		Hash  string `db:"hash"`
+	}{}
+
+	err := app.DB().
+		Select("id", "title", "image", Field(kind)+" AS hash").
+		From(constants.CollectionArtworks).
+		Where(dbx.NewExp("[[image]] != '' AND [[" + SourceField + "]] = [[image]]")).
+		OrderBy("id").
+		All(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(rows))
+	for _, row := range rows {
+		h, err := ParseHash(row.Hash)
+		if err != nil {
+			continue
+		}
+
+		entries = append(entries, Entry{Id: row.Id, Title: row.Title, Image: row.Image, Hash: h})
+	}
+
+	return entries, nil
+}
+
+// Match is an artwork whose image is within some distance of another image.
+type Match struct {
+	Entry
+	Distance int
+}
+
+// FindNear returns the artworks other than excludeId whose hash of the kind is within
+// distance of the hash, the closest first.
+func FindNear(app core.App, kind Kind, hash Hash, distance int, excludeId string) ([]Match, error) {
+	entries, err := Entries(app, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []Match{}
+	for _, entry := range entries {
+		if d := Distance(entry.Hash, hash); d <= distance && entry.Id != excludeId {
+			matches = append(matches, Match{Entry: entry, Distance: d})
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b Match) int {
+		return a.Distance - b.Distance
+	})
+
+	return matches, nil
+}
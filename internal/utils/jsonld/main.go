@@ -4,14 +4,16 @@ import (
 	"fmt"
 
 	"github.com/blackfyre/wga/internal/utils"
+	"github.com/blackfyre/wga/internal/utils/dating"
 	"github.com/pocketbase/pocketbase/core"
 )
 
 // ArtistJsonLd generates a JSON-LD representation of an artist.
 // It takes an instance of wgaModels.Artist and an echo.Context as input.
 // It returns a Person struct representing the artist in JSON-LD format.
-func ArtistJsonLd(r *core.Record) Person {
-	return newPerson(Person{
+// Related artists are added to the property they name, unknown properties are skipped.
+func ArtistJsonLd(r *core.Record, related ...Related) Person {
+	p := newPerson(Person{
 		Name:      r.GetString("name"),
 		Url:       utils.AssetUrl("/artists/" + r.GetString("slug") + "-" + r.GetString("id")),
 		BirthDate: fmt.Sprint(r.GetString("year_of_birth")),
@@ -27,6 +29,25 @@ func ArtistJsonLd(r *core.Record) Person {
 		}),
 		Description: utils.StrippedHTML(r.GetString("bio")),
 	})
+
+	for _, rel := range related {
+		ref := PersonRef{Type: "Person", Name: rel.Name, Url: rel.Url}
+
+		switch rel.Property {
+		case "knows":
+			p.Knows = append(p.Knows, ref)
+		case "colleague":
+			p.Colleague = append(p.Colleague, ref)
+		case "parent":
+			p.Parent = append(p.Parent, ref)
+		case "children":
+			p.Children = append(p.Children, ref)
+		case "knowsAbout":
+			p.KnowsAbout = append(p.KnowsAbout, ref)
+		}
+	}
+
+	return p
 }
 
 // generateVisualArtworkJsonLdContent generates a map containing JSON-LD content for a visual artwork record.
@@ -44,17 +65,46 @@ func GenerateVisualArtworkJsonLdContent(r *core.Record) map[string]any {
 	return d
 }
 
+// ArtworkJsonLd generates a JSON-LD representation of an artwork by the artist.
+// The location holding the artwork is read from the expanded location of the record. It is where
+// the artwork was created only for the artworks still in situ, like a fresco or an altarpiece in
+// its church, so it is left out for the others. The place the artwork shows is its depicted place.
 func ArtworkJsonLd(artWork *core.Record, artist *core.Record) VisualArtwork {
-	return VisualArtwork{
+	a := VisualArtwork{
 		Name:        artWork.GetString("name"),
 		Description: utils.StrippedHTML(artWork.GetString("comment")),
 		Artform:     artWork.GetString("technique"),
 		Url:         utils.AssetUrl("/artworks/" + artWork.GetString("slug") + "-" + artWork.GetString("id")),
 		Artist:      ArtistJsonLd(artist),
 		ArtMedium:   artWork.GetString("medium"),
+		DateCreated: dating.FromRecord(artWork).ISO8601(),
 		Image: ImageObject{
 			Image: utils.AssetUrl("/images/" + artWork.GetString("image")),
 		},
 	}
 
+	if location := artWork.ExpandedOne("location"); location != nil && artWork.GetBool("in_situ") {
+		place := LocationPlace(location)
+		a.LocationCreated = &place
+	}
+
+	if depicted := artWork.GetString("depicted_place"); depicted != "" {
+		place := newPlace(Place{Name: depicted})
+		a.ContentLocation = &place
+	}
+
+	return a
+}
+
+// LocationPlace generates a JSON-LD representation of a location holding artworks.
+func LocationPlace(location *core.Record) Place {
+	return newPlace(Place{
+		Name: location.GetString("name"),
+		Url:  utils.AssetUrl("/locations/" + location.GetString("slug")),
+		Address: &PostalAddress{
+			Type:            "PostalAddress",
+			AddressLocality: location.GetString("city"),
+			AddressCountry:  location.GetString("country"),
+		},
+	})
 }
@@ -0,0 +1,52 @@
+package jsonld
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func TestArtworkJsonLdPlaces(t *testing.T) {
+	locations := core.NewBaseCollection("Locations")
+	locations.Fields.Add(
+		&core.TextField{Name: "name"},
+		&core.TextField{Name: "slug"},
+		&core.TextField{Name: "city"},
+		&core.TextField{Name: "country"},
+	)
+
+	artworks := core.NewBaseCollection("Artworks")
+	artworks.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.BoolField{Name: "in_situ"},
+		&core.TextField{Name: "depicted_place"},
+	)
+
+	artists := core.NewBaseCollection("Artists")
+	artists.Fields.Add(&core.TextField{Name: "name"})
+
+	location := core.NewRecord(locations)
+	location.Load(map[string]any{"name": "Cappella Sistina", "slug": "cappella-sistina-vatican", "city": "Vatican", "country": "Vatican City"})
+
+	artwork := core.NewRecord(artworks)
+	artwork.Set("depicted_place", "Garden of Eden")
+	artwork.SetExpand(map[string]any{"location": location})
+
+	artist := core.NewRecord(artists)
+
+	// a location holding the artwork is not where it was made
+	a := ArtworkJsonLd(artwork, artist)
+	if a.LocationCreated != nil {
+		t.Fatalf("expected no creation place for an artwork not in situ, got %+v", a.LocationCreated)
+	}
+	if a.ContentLocation == nil || a.ContentLocation.Name != "Garden of Eden" {
+		t.Fatalf("content location = %+v", a.ContentLocation)
+	}
+
+	artwork.Set("in_situ", true)
+
+	a = ArtworkJsonLd(artwork, artist)
+	if a.LocationCreated == nil || a.LocationCreated.Name != "Cappella Sistina" || a.LocationCreated.Address.AddressLocality != "Vatican" {
+		t.Fatalf("creation place = %+v", a.LocationCreated)
+	}
+}
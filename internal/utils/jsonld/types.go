@@ -2,23 +2,52 @@ package jsonld
 
 // Person represents a person entity in JSON-LD format.
 type Person struct {
-	Context       string     `json:"@context,omitempty"`
-	Type          string     `json:"@type,omitempty"`
-	Name          string     `json:"name,omitempty"`
-	Url           string     `json:"url,omitempty"`
-	BirthDate     string     `json:"birthDate,omitempty"`
-	DeathDate     string     `json:"deathDate,omitempty"`
-	PlaceOfBirth  Place      `json:"birthPlace,omitempty"`
-	PlaceOfDeath  Place      `json:"deathPlace,omitempty"`
-	Description   string     `json:"description,omitempty"`
-	HasOccupation Occupation `json:"hasOccupation,omitempty"`
+	Context       string      `json:"@context,omitempty"`
+	Type          string      `json:"@type,omitempty"`
+	Name          string      `json:"name,omitempty"`
+	Url           string      `json:"url,omitempty"`
+	BirthDate     string      `json:"birthDate,omitempty"`
+	DeathDate     string      `json:"deathDate,omitempty"`
+	PlaceOfBirth  Place       `json:"birthPlace,omitempty"`
+	PlaceOfDeath  Place       `json:"deathPlace,omitempty"`
+	Description   string      `json:"description,omitempty"`
+	HasOccupation Occupation  `json:"hasOccupation,omitempty"`
+	Knows         []PersonRef `json:"knows,omitempty"`
+	Colleague     []PersonRef `json:"colleague,omitempty"`
+	Parent        []PersonRef `json:"parent,omitempty"`
+	Children      []PersonRef `json:"children,omitempty"`
+	KnowsAbout    []PersonRef `json:"knowsAbout,omitempty"`
+}
+
+// PersonRef refers to another person from a Person, by name and url.
+type PersonRef struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+	Url  string `json:"url,omitempty"`
+}
+
+// Related is an artist related to the artist of a Person, through one of the Person properties
+// referring to other persons, like "knows" or "children".
+type Related struct {
+	Property string
+	Name     string
+	Url      string
 }
 
 // Place represents a place entity in JSON-LD format.
 type Place struct {
-	Context string `json:"@context,omitempty"`
-	Type    string `json:"@type,omitempty"`
-	Name    string `json:"name,omitempty"`
+	Context string         `json:"@context,omitempty"`
+	Type    string         `json:"@type,omitempty"`
+	Name    string         `json:"name,omitempty"`
+	Url     string         `json:"url,omitempty"`
+	Address *PostalAddress `json:"address,omitempty"`
+}
+
+// PostalAddress represents the address of a place in JSON-LD format.
+type PostalAddress struct {
+	Type            string `json:"@type"`
+	AddressLocality string `json:"addressLocality,omitempty"`
+	AddressCountry  string `json:"addressCountry,omitempty"`
 }
 
 // Occupation represents an occupation entity in JSON-LD format.
@@ -38,7 +67,11 @@ type VisualArtwork struct {
 	Artform     string      `json:"artform,omitempty"`
 	Artist      Person      `json:"artist,omitempty"`
 	ArtMedium   string      `json:"artMedium,omitempty"`
+	DateCreated string      `json:"dateCreated,omitempty"`
 	Image       ImageObject `json:"image,omitempty"`
+	// LocationCreated is where the artwork was made, ContentLocation the place it shows.
+	LocationCreated *Place `json:"locationCreated,omitempty"`
+	ContentLocation *Place `json:"contentLocation,omitempty"`
 }
 
 // ImageObject represents an image object entity in JSON-LD format.
@@ -19,6 +19,10 @@ type Pagination struct {
 	htmxTarget  string
 	htmxBaseUrl string
 
+	// cursors of the last and first record of the current page, see SetCursors
+	nextCursor     string
+	previousCursor string
+
 	// render parts
 	firstPart  []string
 	middlePart []string
@@ -56,6 +60,14 @@ func (p *Pagination) TotalPages() int {
 	return p.totalPage
 }
 
+// SetCursors makes the next and previous buttons continue from the last and first record
+// of the current page, passed as the after and before query parameters, so the next page
+// is found by keyset instead of counting records from the start. Empty cursors are not used.
+func (p *Pagination) SetCursors(next string, previous string) {
+	p.nextCursor = next
+	p.previousCursor = previous
+}
+
 // HasPages returns true if there are more than one pages in the pagination.
 func (p *Pagination) HasPages() bool {
 	return p.TotalPages() > 1
@@ -157,22 +169,37 @@ func (p *Pagination) getUrlRange(start, end int) []string {
 // Otherwise, it constructs the URL by appending the page number and any query parameters from the base URL and htmx base URL.
 // The constructed URL is then wrapped in the available page wrapper along with the given text.
 func (p *Pagination) getUrl(page int, text string) string {
+	return p.getCursorUrl(page, text, "", "")
+}
+
+// getCursorUrl returns the URL for a specific page like getUrl, with the cursor set as
+// the cursorParam query parameter when both are given.
+func (p *Pagination) getCursorUrl(page int, text string, cursorParam string, cursor string) string {
 	strPage := strconv.Itoa(page)
 	if p.currentPage == page {
 		return p.GetActivePageWrapper(strPage)
 	} else {
+		setParams := func(params url.Values) {
+			delete(params, "page")
+			delete(params, "after")
+			delete(params, "before")
+			params.Set("page", strPage)
+
+			if cursorParam != "" && cursor != "" {
+				params.Set(cursorParam, cursor)
+			}
+		}
+
 		baseUrl, _ := url.Parse(p.baseUrl)
 		params := baseUrl.Query()
-		delete(params, "page")
-		params.Set("page", strPage)
+		setParams(params)
 
 		href := baseUrl.Path + "?" + params.Encode()
 
 		htmxBase, _ := url.Parse(p.htmxBaseUrl)
 
 		htmxParams := htmxBase.Query()
-		delete(htmxParams, "page")
-		htmxParams.Set("page", strPage)
+		setParams(htmxParams)
 
 		htmxUrl := htmxBase.Path + "?" + htmxParams.Encode()
 
@@ -222,7 +249,7 @@ func (p *Pagination) GetPreviousButton(text string) string {
 		return p.GetDisabledPageWrapper(text)
 	}
 
-	return p.getUrl(p.currentPage-1, text)
+	return p.getCursorUrl(p.currentPage-1, text, "before", p.previousCursor)
 }
 
 // GetNextButton returns the HTML code for the next button in the pagination.
@@ -232,7 +259,7 @@ func (p *Pagination) GetNextButton(text string) string {
 	if p.currentPage == p.TotalPages() {
 		return p.GetDisabledPageWrapper(text)
 	}
-	return p.getUrl(p.currentPage+1, text)
+	return p.getCursorUrl(p.currentPage+1, text, "after", p.nextCursor)
 }
 
 // Render generates the HTML for the pagination component and returns it as a template.HTML value.
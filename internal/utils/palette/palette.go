@@ -0,0 +1,272 @@
+// Package palette extracts the dominant colours of artwork images and compares
+// colours the way people see them, in the CIELAB colour space.
+package palette
+
+import (
+	"cmp"
+	"fmt"
+	"image"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/disintegration/imaging"
+)
+
+// Size is the number of colours of a palette.
+const Size = 5
+
+const (
+	// sampleSize bounds the edge of the thumbnail the colours are sampled from.
+	sampleSize = 64
+	// maxIterations bounds the k-means refinement, which usually settles well before.
+	maxIterations = 20
+)
+
+// Color is an sRGB colour.
+type Color struct {
+	R, G, B uint8
+}
+
+// Hex returns the colour as #rrggbb.
+func (c Color) Hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// NamedColors are the colour names ParseColor understands, with the colour they stand for.
+var NamedColors = map[string]string{
+	"red":    "#b22222",
+	"orange": "#e07b39",
+	"yellow": "#e8c547",
+	"gold":   "#c9a23f",
+	"green":  "#3f7f3f",
+	"blue":   "#2b4f9e",
+	"purple": "#6b3f8f",
+	"pink":   "#e8a0b4",
+	"brown":  "#7b5534",
+	"black":  "#151515",
+	"gray":   "#808080",
+	"grey":   "#808080",
+	"white":  "#f2f0ea",
+}
+
+// ParseColor parses a colour given as #rrggbb, rrggbb, #rgb or one of the NamedColors.
+func ParseColor(value string) (Color, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if named, ok := NamedColors[value]; ok {
+		value = named
+	}
+
+	hex := strings.TrimPrefix(value, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 6 {
+		return Color{}, fmt.Errorf("invalid colour %q", value)
+	}
+
+	return Color{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb)}, nil
+}
+
+// Lab is a colour in the CIELAB colour space, under the D65 illuminant.
+type Lab struct {
+	L, A, B float64
+}
+
+// Distance returns the CIE76 colour difference (ΔE) of two colours. A difference
+// of about 2 is barely noticeable, colours more than 30 apart look clearly different.
+func Distance(x Lab, y Lab) float64 {
+	return math.Sqrt((x.L-y.L)*(x.L-y.L) + (x.A-y.A)*(x.A-y.A) + (x.B-y.B)*(x.B-y.B))
+}
+
+// D65 reference white
+const (
+	whiteX = 0.95047
+	whiteY = 1.0
+	whiteZ = 1.08883
+)
+
+// Lab converts the colour to CIELAB.
+func (c Color) Lab() Lab {
+	r, g, b := linear(c.R), linear(c.G), linear(c.B)
+
+	x := labF((0.4124564*r + 0.3575761*g + 0.1804375*b) / whiteX)
+	y := labF((0.2126729*r + 0.7151522*g + 0.0721750*b) / whiteY)
+	z := labF((0.0193339*r + 0.1191920*g + 0.9503041*b) / whiteZ)
+
+	return Lab{L: 116*y - 16, A: 500 * (x - y), B: 200 * (y - z)}
+}
+
+// Color converts the colour back to sRGB, clamping colours outside of its gamut.
+func (l Lab) Color() Color {
+	y := (l.L + 16) / 116
+	x := y + l.A/500
+	z := y - l.B/200
+
+	x, y, z = labFInverse(x)*whiteX, labFInverse(y)*whiteY, labFInverse(z)*whiteZ
+
+	return Color{
+		R: gamma(3.2404542*x - 1.5371385*y - 0.4985314*z),
+		G: gamma(-0.9692660*x + 1.8760108*y + 0.0415560*z),
+		B: gamma(0.0556434*x - 0.2040259*y + 1.0572252*z),
+	}
+}
+
+func linear(v uint8) float64 {
+	c := float64(v) / 255
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+func gamma(c float64) uint8 {
+	if c <= 0.0031308 {
+		c *= 12.92
+	} else {
+		c = 1.055*math.Pow(c, 1/2.4) - 0.055
+	}
+
+	return uint8(math.Round(max(0, min(1, c)) * 255))
+}
+
+func labF(t float64) float64 {
+	if t > 216.0/24389 {
+		return math.Cbrt(t)
+	}
+
+	return (24389.0/27*t + 16) / 116
+}
+
+func labFInverse(t float64) float64 {
+	if t*t*t > 216.0/24389 {
+		return t * t * t
+	}
+
+	return (116*t - 16) / (24389.0 / 27)
+}
+
+// Swatch is a colour of a palette, with the share of the image it covers.
+type Swatch struct {
+	Color  string  `json:"color"`
+	Weight float64 `json:"weight"`
+}
+
+// Extract returns the palette of an image: up to Size colours found by k-means
+// clustering of its pixels in CIELAB, the most dominant first. Transparent pixels
+// are ignored, and the clustering is seeded so the same image always gives the same palette.
+func Extract(img image.Image) []Swatch {
+	sample := imaging.Fit(img, sampleSize, sampleSize, imaging.Box)
+
+	pixels := make([]Lab, 0, sample.Bounds().Dx()*sample.Bounds().Dy())
+	for i := 0; i < len(sample.Pix); i += 4 {
+		if sample.Pix[i+3] < 128 {
+			continue
+		}
+		pixels = append(pixels, Color{R: sample.Pix[i], G: sample.Pix[i+1], B: sample.Pix[i+2]}.Lab())
+	}
+
+	if len(pixels) == 0 {
+		return []Swatch{}
+	}
+
+	centroids, counts := kMeans(pixels, min(Size, len(pixels)))
+
+	swatches := make([]Swatch, 0, len(centroids))
+	for i, centroid := range centroids {
+		if counts[i] == 0 {
+			continue
+		}
+
+		swatches = append(swatches, Swatch{
+			Color:  centroid.Color().Hex(),
+			Weight: math.Round(float64(counts[i])/float64(len(pixels))*1000) / 1000,
+		})
+	}
+
+	slices.SortStableFunc(swatches, func(a, b Swatch) int {
+		return cmp.Compare(b.Weight, a.Weight)
+	})
+
+	return swatches
+}
+
+// kMeans clusters the pixels into k colours, seeded with k-means++, and returns
+// the colours with the number of pixels of each.
+func kMeans(pixels []Lab, k int) ([]Lab, []int) {
+	random := rand.New(rand.NewPCG(uint64(len(pixels)), uint64(k)))
+
+	centroids := []Lab{pixels[random.IntN(len(pixels))]}
+	nearest := make([]float64, len(pixels))
+	for len(centroids) < k {
+		total := 0.0
+		for i, p := range pixels {
+			d := Distance(p, centroids[len(centroids)-1])
+			if len(centroids) == 1 || d*d < nearest[i] {
+				nearest[i] = d * d
+			}
+			total += nearest[i]
+		}
+
+		if total == 0 {
+			// fewer distinct colours than k
+			break
+		}
+
+		target := random.Float64() * total
+		for i := range pixels {
+			target -= nearest[i]
+			if target <= 0 {
+				centroids = append(centroids, pixels[i])
+				break
+			}
+		}
+	}
+
+	assignments := make([]int, len(pixels))
+	counts := make([]int, len(centroids))
+
+	for range maxIterations {
+		changed := false
+		for i, p := range pixels {
+			closest := 0
+			for j := range centroids {
+				if Distance(p, centroids[j]) < Distance(p, centroids[closest]) {
+					closest = j
+				}
+			}
+			if closest != assignments[i] {
+				changed = true
+				assignments[i] = closest
+			}
+		}
+
+		sums := make([]Lab, len(centroids))
+		clear(counts)
+		for i, p := range pixels {
+			j := assignments[i]
+			sums[j].L += p.L
+			sums[j].A += p.A
+			sums[j].B += p.B
+			counts[j]++
+		}
+
+		for j := range centroids {
+			if counts[j] > 0 {
+				n := float64(counts[j])
+				centroids[j] = Lab{L: sums[j].L / n, A: sums[j].A / n, B: sums[j].B / n}
+			}
+		}
+
+		if !changed {
+			break
+		}
+	}
+
+	return centroids, counts
+}
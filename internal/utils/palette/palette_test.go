@@ -0,0 +1,174 @@
+package palette
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/blackfyre/wga/internal/testutils"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+func TestParseColor(t *testing.T) {
+	cases := map[string]Color{
+		"#2b4f9e": {0x2b, 0x4f, 0x9e},
+		"2B4F9E":  {0x2b, 0x4f, 0x9e},
+		"#fa0":    {0xff, 0xaa, 0x00},
+		" Blue ":  {0x2b, 0x4f, 0x9e},
+	}
+
+	for value, want := range cases {
+		if got, err := ParseColor(value); err != nil || got != want {
+			t.Errorf("ParseColor(%q) = %v, %v, want %v", value, got, err, want)
+		}
+	}
+
+	for _, value := range []string{"", "#12345", "#gggggg", "ultramarine", "#1234567"} {
+		if _, err := ParseColor(value); err == nil {
+			t.Errorf("expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestLab(t *testing.T) {
+	white := Color{255, 255, 255}.Lab()
+	if math.Abs(white.L-100) > 0.01 || math.Abs(white.A) > 0.01 || math.Abs(white.B) > 0.01 {
+		t.Fatalf("white = %+v", white)
+	}
+
+	if d := Distance(white, Color{0, 0, 0}.Lab()); math.Abs(d-100) > 0.01 {
+		t.Fatalf("white to black = %f, want 100", d)
+	}
+
+	for _, c := range []Color{{0, 0, 0}, {0x2b, 0x4f, 0x9e}, {255, 0, 0}, {12, 200, 34}} {
+		if got := c.Lab().Color(); got != c {
+			t.Errorf("%v round trips to %v", c, got)
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	blue, red := Color{0x2b, 0x4f, 0x9e}, Color{0xb2, 0x22, 0x22}
+	swatches := Extract(testImage(200, 100, blue, red))
+
+	if len(swatches) == 0 || len(swatches) > Size {
+		t.Fatalf("got %d swatches", len(swatches))
+	}
+
+	if swatches[0].Color != blue.Hex() || math.Abs(swatches[0].Weight-0.75) > 0.02 {
+		t.Fatalf("dominant swatch = %+v, want %s covering 75%%", swatches[0], blue.Hex())
+	}
+
+	if !slices.ContainsFunc(swatches, func(s Swatch) bool { return s.Color == red.Hex() }) {
+		t.Fatalf("expected %s in %+v", red.Hex(), swatches)
+	}
+
+	if !slices.Equal(swatches, Extract(testImage(200, 100, blue, red))) {
+		t.Fatal("expected the same image to always give the same palette")
+	}
+
+	if got := Coverage(swatches, Color{0x30, 0x50, 0xa0}); got < 0.6 || got > 0.75 {
+		t.Fatalf("coverage of a close blue = %f", got)
+	}
+	if got := Coverage(swatches, Color{0x3f, 0x7f, 0x3f}); got != 0 {
+		t.Fatalf("coverage of green = %f, want 0", got)
+	}
+}
+
+func TestMatchIds(t *testing.T) {
+	app := testutils.NewTestApp(t)
+
+	collection := core.NewBaseCollection("artworks")
+	collection.Fields.Add(
+		&core.FileField{Name: "image", MaxSelect: 1, MaxSize: 1 << 20},
+		&core.JSONField{Name: Field},
+		&core.TextField{Name: SourceField},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatalf("save collection: %v", err)
+	}
+
+	blue, red, white := Color{0x2b, 0x4f, 0x9e}, Color{0xb2, 0x22, 0x22}, Color{0xf2, 0xf0, 0xea}
+
+	ids := []string{}
+	for _, colors := range [][2]Color{{blue, red}, {red, blue}, {white, red}} {
+		record := core.NewRecord(collection)
+		record.Set("image", testImageFile(t, testImage(120, 60, colors[0], colors[1])))
+		if err := app.Save(record); err != nil {
+			t.Fatalf("save record: %v", err)
+		}
+		ids = append(ids, record.Id)
+	}
+
+	extracted, err := ExtractMissing(app, false)
+	if err != nil || extracted != 3 {
+		t.Fatalf("ExtractMissing = %d, %v", extracted, err)
+	}
+
+	record, err := app.FindRecordById(collection.Id, ids[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !UpToDate(record) || len(FromRecord(record)) == 0 {
+		t.Fatalf("expected a stored palette, got %v", record.Get(Field))
+	}
+
+	matches, err := MatchIds(app, blue, 10)
+	if err != nil {
+		t.Fatalf("MatchIds: %v", err)
+	}
+
+	// the mostly blue artwork first, the one without blue left out
+	if !slices.Equal(matches, ids[:2]) {
+		t.Fatalf("matches = %v, want %v", matches, ids[:2])
+	}
+
+	// the palettes are cached, so a palette cleared since is still matched
+	if _, err := app.DB().Update(collection.Name, dbx.Params{Field: "[]"}, dbx.HashExp{"id": ids[0]}).Execute(); err != nil {
+		t.Fatalf("clear palette: %v", err)
+	}
+
+	matches, err = MatchIds(app, blue, 10)
+	if err != nil || !slices.Equal(matches, ids[:2]) {
+		t.Fatalf("cached matches = %v, %v, want %v", matches, err, ids[:2])
+	}
+}
+
+// testImage paints three quarters of an image in one colour and the rest in another.
+func testImage(width int, height int, main Color, rest Color) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	for y := range height {
+		for x := range width {
+			c := main
+			if x >= width*3/4 {
+				c = rest
+			}
+			img.Set(x, y, color.NRGBA{R: c.R, G: c.G, B: c.B, A: 255})
+		}
+	}
+
+	return img
+}
+
+func testImageFile(t *testing.T, img image.Image) *filesystem.File {
+	t.Helper()
+
+	var buff bytes.Buffer
+	if err := png.Encode(&buff, img); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := filesystem.NewFileFromBytes(buff.Bytes(), "scan.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
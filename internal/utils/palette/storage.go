@@ -0,0 +1,224 @@
+package palette
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/logging"
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/disintegration/imaging"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Fields of the artworks holding the palette of their image. SourceField holds the
+// name of the image file the palette was extracted from.
+const (
+	Field       = "palette"
+	SourceField = "palette_image"
+)
+
+// MatchDistance is the colour difference under which a colour of a palette counts
+// as the searched colour. The closer the colour, the more it counts.
+const MatchDistance = 30
+
+// minMatchCoverage is the share of an image the searched colour has to cover for it to match.
+const minMatchCoverage = 0.05
+
+// extractBatchSize is how many artworks are loaded at a time while extracting palettes.
+const extractBatchSize = 200
+
+// palettesTTL is how long the palettes searched by colour are cached.
+const palettesTTL = 10 * time.Minute
+
+const palettesCacheKey = "palette:artworks"
+
+var ErrNoImage = errors.New("artwork has no image")
+
+// UpToDate reports whether the record has the palette of its current image.
+func UpToDate(record *core.Record) bool {
+	image := record.GetString("image")
+
+	return image != "" && record.GetString(SourceField) == image
+}
+
+// FromRecord returns the palette stored on the record, empty when it has none.
+func FromRecord(record *core.Record) []Swatch {
+	swatches := []Swatch{}
+	if err := record.UnmarshalJSONField(Field, &swatches); err != nil {
+		return []Swatch{}
+	}
+
+	return swatches
+}
+
+// Set stores the palette of the image file on the record, without saving it. The image is
+// passed by name, as an upload has no name in the image field until the record is saved.
+func Set(record *core.Record, image string, swatches []Swatch) {
+	record.Set(Field, swatches)
+	record.Set(SourceField, image)
+}
+
+// Generate extracts the palette of the record's image, read through the PocketBase
+// filesystem, and saves it on the record.
+func Generate(app core.App, record *core.Record) error {
+	image := record.GetString("image")
+	if image == "" {
+		return ErrNoImage
+	}
+
+	fsys, err := app.NewFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fsys.Close()
+
+	reader, err := fsys.GetReader(record.BaseFilesPath() + "/" + image)
+	if err != nil {
+		return err
+	}
+
+	img, err := imaging.Decode(reader)
+	reader.Close()
+	if err != nil {
+		return err
+	}
+
+	Set(record, image, Extract(img))
+
+	return app.SaveNoValidate(record)
+}
+
+// ExtractMissing extracts the palettes of the artwork images without an up to date palette,
+// or of every artwork image when all is set. Images that fail are logged and skipped.
+func ExtractMissing(app core.App, all bool) (int, error) {
+	filter := "image != '' && palette_image != image && id > {:after}"
+	if all {
+		filter = "image != '' && id > {:after}"
+	}
+
+	extracted := 0
+	after := ""
+
+	for {
+		records, err := app.FindRecordsByFilter(constants.CollectionArtworks, filter, "id", extractBatchSize, 0, dbx.Params{"after": after})
+		if err != nil {
+			return extracted, err
+		}
+
+		for _, record := range records {
+			if err := Generate(app, record); err != nil {
+				app.Logger().Warn("Palette extraction failed",
+					"event", "artwork.palette.build",
+					"record_id", record.Id,
+					"outcome", "failed",
+					"error_type", logging.ErrorType(err),
+					"error", logging.Redact(err),
+				)
+				continue
+			}
+			extracted++
+		}
+
+		if len(records) < extractBatchSize {
+			return extracted, nil
+		}
+
+		after = records[len(records)-1].Id
+	}
+}
+
+// Coverage returns the share of an image the colour covers according to its palette.
+// Each colour of the palette within MatchDistance of the colour adds its weight,
+// scaled down the further it is.
+func Coverage(swatches []Swatch, color Color) float64 {
+	target := color.Lab()
+	coverage := 0.0
+
+	for _, s := range swatches {
+		c, err := ParseColor(s.Color)
+		if err != nil {
+			continue
+		}
+
+		if d := Distance(c.Lab(), target); d < MatchDistance {
+			coverage += s.Weight * (1 - d/MatchDistance)
+		}
+	}
+
+	return coverage
+}
+
+// artworkPalette is the up to date palette of an artwork image.
+type artworkPalette struct {
+	Id       string
+	Swatches []Swatch
+}
+
+// artworkPalettes returns the up to date palettes of the artwork images. They are
+// cached, so colour searches do not load and decode every palette each time.
+func artworkPalettes(app core.App) ([]artworkPalette, error) {
+	return utils.GetOrLoadCachedValue(app, palettesCacheKey, palettesTTL, func() ([]artworkPalette, error) {
+		rows := []struct {
+			Id      string `db:"id"`
+			Palette string `db:"palette"`
+		}{}
+
+		err := app.DB().
+			Select("id", Field).
+			From(constants.CollectionArtworks).
+			Where(dbx.NewExp("[[image]] != '' AND [[" + SourceField + "]] = [[image]]")).
+			All(&rows)
+		if err != nil {
+			return nil, err
+		}
+
+		palettes := make([]artworkPalette, 0, len(rows))
+		for _, row := range rows {
+			swatches := []Swatch{}
+			if err := json.Unmarshal([]byte(row.Palette), &swatches); err != nil {
+				continue
+			}
+
+			palettes = append(palettes, artworkPalette{Id: row.Id, Swatches: swatches})
+		}
+
+		return palettes, nil
+	})
+}
+
+// MatchIds returns the ids of up to limit artworks whose image shows the colour,
+// the ones it covers the most first. New palettes are matched once the cache expires.
+func MatchIds(app core.App, color Color, limit int) ([]string, error) {
+	palettes, err := artworkPalettes(app)
+	if err != nil {
+		return nil, err
+	}
+
+	type match struct {
+		id       string
+		coverage float64
+	}
+
+	matches := []match{}
+	for _, p := range palettes {
+		if coverage := Coverage(p.Swatches, color); coverage >= minMatchCoverage {
+			matches = append(matches, match{p.Id, coverage})
+		}
+	}
+
+	slices.SortFunc(matches, func(a, b match) int {
+		return cmp.Or(cmp.Compare(b.coverage, a.coverage), cmp.Compare(a.id, b.id))
+	})
+
+	ids := make([]string, 0, min(limit, len(matches)))
+	for _, m := range matches[:min(limit, len(matches))] {
+		ids = append(ids, m.id)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,104 @@
+package postcard
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// OpenedAtField is the JSON field of the postcards collection holding the time
+// each recipient first opened the pickup page, keyed by the recipient token.
+const OpenedAtField = "opened_at"
+
+// RecipientToken returns the token identifying a recipient of a postcard on the
+// pickup page. It is derived from the postcard id and the address, so the
+// address itself never appears in the pickup link.
+func RecipientToken(postcardId string, address string) string {
+	sum := sha256.Sum256([]byte(postcardId + "\n" + strings.ToLower(strings.TrimSpace(address))))
+
+	return hex.EncodeToString(sum[:8])
+}
+
+// PickUpPath returns the relative pickup link of a postcard for a recipient.
+// Without an address the link opens the postcard without being counted as a pickup.
+func PickUpPath(postcardId string, address string) string {
+	params := url.Values{"p": {postcardId}}
+	if address != "" {
+		params.Set("r", RecipientToken(postcardId, address))
+	}
+
+	return "/postcard?" + params.Encode()
+}
+
+// Opens returns the first pickup time of every recipient who opened the postcard,
+// keyed by the recipient token.
+func Opens(r *core.Record) map[string]time.Time {
+	raw := map[string]string{}
+	opens := map[string]time.Time{}
+
+	if err := r.UnmarshalJSONField(OpenedAtField, &raw); err != nil {
+		return opens
+	}
+
+	for token, value := range raw {
+		openedAt, err := types.ParseDateTime(value)
+		if err != nil || openedAt.IsZero() {
+			continue
+		}
+		opens[token] = openedAt.Time()
+	}
+
+	return opens
+}
+
+// OpenedAt returns when the recipient first opened the postcard and whether they did at all.
+func OpenedAt(r *core.Record, address string) (time.Time, bool) {
+	openedAt, ok := Opens(r)[RecipientToken(r.Id, address)]
+
+	return openedAt, ok
+}
+
+// MarkOpened records the first pickup of the postcard by the recipient the token
+// belongs to and moves the postcard to the received state. It reports whether
+// anything was recorded: unknown tokens and repeated pickups are ignored.
+func MarkOpened(app core.App, r *core.Record, token string) (bool, error) {
+	if token == "" || !hasRecipientToken(r, token) {
+		return false, nil
+	}
+
+	raw := map[string]string{}
+	if err := r.UnmarshalJSONField(OpenedAtField, &raw); err != nil {
+		raw = map[string]string{}
+	}
+
+	if _, ok := raw[token]; ok {
+		return false, nil
+	}
+
+	raw[token] = types.NowDateTime().String()
+	r.Set(OpenedAtField, raw)
+	if r.GetString("status") == "sent" {
+		r.Set("status", "received")
+	}
+
+	if err := app.Save(r); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func hasRecipientToken(r *core.Record, token string) bool {
+	for _, address := range strings.Split(r.GetString("recipients"), ",") {
+		if strings.TrimSpace(address) != "" && RecipientToken(r.Id, address) == token {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,80 @@
+package postcard
+
+import (
+	"testing"
+
+	"github.com/blackfyre/wga/internal/testutils"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func TestRecipientTokenIgnoresCaseAndSpacing(t *testing.T) {
+	if RecipientToken("postcard", "Someone@Example.test ") != RecipientToken("postcard", "someone@example.test") {
+		t.Fatal("expected the token to ignore case and surrounding spaces")
+	}
+	if RecipientToken("postcard", "someone@example.test") == RecipientToken("other", "someone@example.test") {
+		t.Fatal("expected the token to depend on the postcard")
+	}
+}
+
+func TestPickUpPath(t *testing.T) {
+	if got := PickUpPath("abc", ""); got != "/postcard?p=abc" {
+		t.Fatalf("PickUpPath without recipient = %q", got)
+	}
+
+	want := "/postcard?p=abc&r=" + RecipientToken("abc", "someone@example.test")
+	if got := PickUpPath("abc", "someone@example.test"); got != want {
+		t.Fatalf("PickUpPath = %q, want %q", got, want)
+	}
+}
+
+func TestMarkOpenedRecordsFirstPickupOnly(t *testing.T) {
+	app := testutils.NewTestApp(t)
+	collection := core.NewBaseCollection("postcards")
+	collection.Fields.Add(
+		&core.TextField{Name: "status"},
+		&core.TextField{Name: "recipients"},
+		&core.JSONField{Name: OpenedAtField},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatalf("create postcards collection: %v", err)
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("status", "sent")
+	record.Set("recipients", "first@example.test,second@example.test")
+	if err := app.Save(record); err != nil {
+		t.Fatalf("create postcard: %v", err)
+	}
+
+	if marked, err := MarkOpened(app, record, "unknown"); err != nil || marked {
+		t.Fatalf("MarkOpened with unknown token = %v, %v", marked, err)
+	}
+
+	token := RecipientToken(record.Id, "second@example.test")
+	if marked, err := MarkOpened(app, record, token); err != nil || !marked {
+		t.Fatalf("MarkOpened = %v, %v, want first pickup recorded", marked, err)
+	}
+
+	stored, err := app.FindRecordById(collection.Id, record.Id)
+	if err != nil {
+		t.Fatalf("reload postcard: %v", err)
+	}
+	if got := stored.GetString("status"); got != "received" {
+		t.Fatalf("status = %q, want %q", got, "received")
+	}
+
+	first, ok := OpenedAt(stored, "second@example.test")
+	if !ok {
+		t.Fatal("expected the pickup to be recorded")
+	}
+	if _, ok := OpenedAt(stored, "first@example.test"); ok {
+		t.Fatal("expected no pickup for the other recipient")
+	}
+
+	if marked, err := MarkOpened(app, stored, token); err != nil || marked {
+		t.Fatalf("repeated MarkOpened = %v, %v, want nothing recorded", marked, err)
+	}
+	if again, _ := OpenedAt(stored, "second@example.test"); !again.Equal(first) {
+		t.Fatalf("first pickup time changed from %v to %v", first, again)
+	}
+}
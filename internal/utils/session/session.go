@@ -0,0 +1,81 @@
+// Package session keeps visitors signed in to their account between page loads,
+// by storing their PocketBase auth token in a cookie.
+package session
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/utils"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const CookieName = "wga_auth"
+
+// LoadAuth sets the authenticated user of the request from the session cookie,
+// unless the request is already authenticated. Invalid or expired tokens are ignored.
+// The PocketBase API keeps authenticating with its Authorization header only.
+func LoadAuth(e *core.RequestEvent) error {
+	if e.Auth != nil || strings.HasPrefix(e.Request.URL.Path, "/api/") {
+		return e.Next()
+	}
+
+	cookie, err := e.Request.Cookie(CookieName)
+	if err != nil || cookie.Value == "" {
+		return e.Next()
+	}
+
+	record, err := e.App.FindAuthRecordByToken(cookie.Value, core.TokenTypeAuth)
+	if err == nil && record.Collection().Name == constants.CollectionUsers {
+		e.Auth = record
+	}
+
+	return e.Next()
+}
+
+// User returns the signed in user of the request, or nil.
+func User(e *core.RequestEvent) *core.Record {
+	if e.Auth == nil || e.Auth.Collection().Name != constants.CollectionUsers {
+		return nil
+	}
+
+	return e.Auth
+}
+
+// SignIn starts a session for the user.
+func SignIn(e *core.RequestEvent, user *core.Record) error {
+	token, err := user.NewAuthToken()
+	if err != nil {
+		return err
+	}
+
+	e.SetCookie(&http.Cookie{
+		Name:     CookieName,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(user.Collection().AuthToken.DurationTime().Seconds()),
+		HttpOnly: true,
+		Secure:   secure(),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return nil
+}
+
+// SignOut ends the session of the request.
+func SignOut(e *core.RequestEvent) {
+	e.SetCookie(&http.Cookie{
+		Name:     CookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   secure(),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func secure() bool {
+	return strings.HasPrefix(utils.AssetUrl("/"), "https://")
+}
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/blackfyre/wga/internal/config"
+	"github.com/blackfyre/wga/internal/constants"
+	"github.com/blackfyre/wga/internal/repositories"
 	"github.com/blackfyre/wga/internal/utils/url"
 
 	"github.com/pocketbase/pocketbase"
@@ -106,6 +108,13 @@ func generateArtworksMap(app *pocketbase.PocketBase, index *smg.SitemapIndex) {
 		log.Fatal("Unable to Save Sitemap:", err)
 	}
 
+	images, err := repositories.NewArtworksRepository(app).FindAllImages()
+
+	if err != nil {
+		// the artworks are listed with their main images only
+		app.Logger().Error("Error fetching artwork images for sitemap", "error", err)
+	}
+
 	for _, m := range records {
 
 		if errs := app.ExpandRecord(m, []string{"author"}, nil); len(errs) > 0 {
@@ -114,7 +123,15 @@ func generateArtworksMap(app *pocketbase.PocketBase, index *smg.SitemapIndex) {
 			continue // we're skipping failed items
 		}
 
-		author := m.ExpandedOne("author")
+		// the canonical url of an artwork is under its first published author
+		var author *core.Record
+
+		for _, a := range m.ExpandedAll("author") {
+			if a.GetBool("published") {
+				author = a
+				break
+			}
+		}
 
 		if author == nil {
 			//every item in the db should have an author
@@ -136,6 +153,7 @@ func generateArtworksMap(app *pocketbase.PocketBase, index *smg.SitemapIndex) {
 			LastMod:    &lastMod,
 			ChangeFreq: smg.Monthly,
 			Priority:   0.8,
+			Images:     artworkSitemapImages(m, images[m.Id]),
 		})
 
 		if err != nil {
@@ -144,3 +162,22 @@ func generateArtworksMap(app *pocketbase.PocketBase, index *smg.SitemapIndex) {
 		}
 	}
 }
+
+// artworkSitemapImages returns the main image of the artwork followed by its other images.
+func artworkSitemapImages(artwork *core.Record, images []*core.Record) []*smg.SitemapImage {
+	sitemapImages := []*smg.SitemapImage{}
+
+	if name := artwork.GetString("image"); name != "" {
+		sitemapImages = append(sitemapImages, &smg.SitemapImage{
+			ImageLoc: url.GenerateFileUrl(constants.CollectionArtworks, artwork.Id, name, ""),
+		})
+	}
+
+	for _, image := range images {
+		sitemapImages = append(sitemapImages, &smg.SitemapImage{
+			ImageLoc: url.GenerateFileUrl(constants.CollectionArtworkImages, image.Id, image.GetString("image"), ""),
+		})
+	}
+
+	return sitemapImages
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/blackfyre/wga/internal/utils"
+	"github.com/blackfyre/wga/internal/utils/dzi"
 	"github.com/labstack/echo/v5"
 	"github.com/pocketbase/pocketbase/core"
 )
@@ -43,6 +44,42 @@ func GenerateThumbUrl(collection string, collectionId string, fileName string, t
 	return url
 }
 
+// GenerateTilesUrl returns the url of the deep zoom descriptor of an artwork.
+// The viewer resolves the tile urls from it.
+func GenerateTilesUrl(artworkId string) string {
+	return fmt.Sprintf("/artworks/%s/tiles/%s", artworkId, dzi.DescriptorName)
+}
+
+// GenerateRelatedArtworksUrl returns the url of the "more like this" fragment of an artwork.
+func GenerateRelatedArtworksUrl(artworkId string) string {
+	return fmt.Sprintf("/artworks/%s/related", artworkId)
+}
+
+// GenerateArtistNetworkDataUrl returns the url of the JSON graph of the relationships around an artist.
+func GenerateArtistNetworkDataUrl(artistId string, depth int) string {
+	return fmt.Sprintf("/api/v1/artists/%s/network?depth=%d", artistId, depth)
+}
+
+// GenerateColorSearchUrl returns the url of the artwork search for a colour.
+func GenerateColorSearchUrl(color string) string {
+	return "/artworks?" + url.Values{"color": {color}}.Encode()
+}
+
+// GenerateLocationUrl returns the url of the page of a location holding artworks.
+func GenerateLocationUrl(slug string) string {
+	return "/locations/" + slug
+}
+
+// GenerateCitySearchUrl returns the url of the artwork search for the artworks held in a city.
+func GenerateCitySearchUrl(city string) string {
+	return "/artworks?" + url.Values{"city": {city}}.Encode()
+}
+
+// GenerateSeriesUrl returns the url of the page of a series of artworks.
+func GenerateSeriesUrl(title string, seriesId string) string {
+	return fmt.Sprintf("/series/%v-%v", utils.Slugify(title), seriesId)
+}
+
 type ArtworkUrlDTO struct {
 	ArtistName   string
 	ArtistId     string
@@ -381,7 +381,62 @@ const maybeInitStatisticsCharts = async () => {
 	}
 };
 
-const dualLookupFailureContent =
+let networkModule: typeof import("./network") | null = null;
+
+const maybeInitArtistNetwork = async () => {
+	if (!document.querySelector("[data-artist-network]")) {
+		return;
+	}
+
+	try {
+		networkModule ??= await import("./network");
+		await networkModule.initArtistNetwork();
+	} catch (error) {
+		logger.error("Failed to initialise the artist network", error);
+	}
+};
+
+// Deep zoom viewers are created when their panel is first opened, so the
+// viewer library is only loaded on artwork pages that have a tile pyramid.
+const initDeepZoomViewers = () => {
+	const elements = document.querySelectorAll<HTMLElement>(
+		"[data-deep-zoom]:not([data-deep-zoom-ready])",
+	);
+
+	for (const element of elements) {
+		element.dataset.deepZoomReady = "true";
+		const panel = element.closest("details");
+
+		const open = async () => {
+			try {
+				const { default: OpenSeadragon } = await import("openseadragon");
+				OpenSeadragon({
+					element,
+					tileSources: element.dataset.deepZoom,
+					showNavigationControl: false,
+					showNavigator: true,
+				});
+			} catch (error) {
+				logger.error("Failed to initialise deep zoom viewer", error);
+			}
+		};
+
+		if (!panel) {
+			void open();
+			continue;
+		}
+
+		panel.addEventListener(
+			"toggle",
+			() => {
+				if (panel.open) void open();
+			},
+			{ once: true },
+		);
+	}
+};
+
+
 	'<p class="p-4 text-sm text-base-content/70">Unable to load lookup results.</p>';
 
 const requestDualLookupResults = async (
@@ -535,7 +590,9 @@ const wgaInternal: wgaInternals = {
 				wgaInternal.func.cloner();
 				wgaInternal.func.dualLookupModal();
 				wgaInternal.func.glossary();
+				initDeepZoomViewers();
 				void maybeInitStatisticsCharts();
+				void maybeInitArtistNetwork();
 			});
 			document.body.addEventListener("htmx:beforeSwap", () => {
 				glossaryClosePopup();
@@ -681,6 +738,9 @@ const wgaInternal: wgaInternals = {
 				for (const element of elements) {
 					const e = element as HTMLElement;
 					new Viewer(e, {
+						// galleries show thumbnails and open the full image
+						url: (image: HTMLImageElement) =>
+							image.dataset.original ?? image.src,
 						toolbar: {
 							zoomIn: 1,
 							zoomOut: 1,
@@ -781,7 +841,9 @@ const wgaInternal: wgaInternals = {
 			wgaInternal.setup.htmx();
 			wgaInternal.setup.elements();
 			wgaInternal.func.glossary();
+			initDeepZoomViewers();
 			void maybeInitStatisticsCharts();
+			void maybeInitArtistNetwork();
 
 			// Run all event listeners
 			logger.debug("Running event listeners");
@@ -0,0 +1,143 @@
+import logger from "./logger";
+
+interface NetworkNode {
+	id: string;
+	name: string;
+	url: string;
+	depth: number;
+}
+
+interface NetworkEdge {
+	id: string;
+	source: string;
+	target: string;
+	type: string;
+	label: string;
+}
+
+interface Network {
+	nodes: NetworkNode[];
+	edges: NetworkEdge[];
+	truncated: boolean;
+}
+
+const svgNs = "http://www.w3.org/2000/svg";
+const size = 640;
+const ringGap = 140;
+
+const svgElement = <K extends keyof SVGElementTagNameMap>(
+	name: K,
+	attributes: Record<string, string | number>,
+): SVGElementTagNameMap[K] => {
+	const el = document.createElementNS(svgNs, name);
+	for (const [key, value] of Object.entries(attributes)) {
+		el.setAttribute(key, String(value));
+	}
+	return el;
+};
+
+// Places the centre in the middle and every further depth on its own ring.
+const layout = (nodes: NetworkNode[]) => {
+	const positions = new Map<string, { x: number; y: number }>();
+	const rings = new Map<number, NetworkNode[]>();
+
+	for (const node of nodes) {
+		rings.set(node.depth, [...(rings.get(node.depth) ?? []), node]);
+	}
+
+	for (const [depth, ring] of rings) {
+		ring.forEach((node, i) => {
+			const angle = (2 * Math.PI * i) / ring.length - Math.PI / 2;
+			const radius = depth * ringGap;
+			positions.set(node.id, {
+				x: size / 2 + radius * Math.cos(angle),
+				y: size / 2 + radius * Math.sin(angle),
+			});
+		});
+	}
+
+	return positions;
+};
+
+const drawNetwork = (container: HTMLElement, network: Network) => {
+	const positions = layout(network.nodes);
+	const svg = svgElement("svg", {
+		viewBox: `0 0 ${size} ${size}`,
+		role: "img",
+		"aria-label": container.dataset.artistNetworkLabel ?? "Artist network",
+		class: "w-full max-w-3xl mx-auto",
+	});
+
+	for (const edge of network.edges) {
+		const from = positions.get(edge.source);
+		const to = positions.get(edge.target);
+		if (!from || !to) continue;
+
+		const line = svgElement("line", {
+			x1: from.x,
+			y1: from.y,
+			x2: to.x,
+			y2: to.y,
+			class: "stroke-base-content/30",
+			"stroke-width": 1.5,
+		});
+		const title = svgElement("title", {});
+		title.textContent = edge.label;
+		line.appendChild(title);
+		svg.appendChild(line);
+	}
+
+	for (const node of network.nodes) {
+		const position = positions.get(node.id);
+		if (!position) continue;
+
+		const link = svgElement("a", { href: node.url });
+		link.appendChild(
+			svgElement("circle", {
+				cx: position.x,
+				cy: position.y,
+				r: node.depth === 0 ? 10 : 6,
+				class: node.depth === 0 ? "fill-primary" : "fill-secondary",
+			}),
+		);
+
+		const label = svgElement("text", {
+			x: position.x,
+			y: position.y + 20,
+			"text-anchor": "middle",
+			class: "fill-base-content text-xs",
+		});
+		label.textContent = node.name;
+		link.appendChild(label);
+		svg.appendChild(link);
+	}
+
+	container.replaceChildren(svg);
+};
+
+export const initArtistNetwork = async () => {
+	const container = document.querySelector<HTMLElement>(
+		"[data-artist-network]",
+	);
+	if (!container || container.dataset.artistNetworkDrawn === "true") {
+		return;
+	}
+
+	const url = container.dataset.artistNetwork;
+	if (!url) return;
+
+	try {
+		const response = await fetch(url, {
+			headers: { Accept: "application/json" },
+		});
+		if (!response.ok) {
+			throw new Error(`Unexpected status ${response.status}`);
+		}
+
+		drawNetwork(container, (await response.json()) as Network);
+		container.dataset.artistNetworkDrawn = "true";
+	} catch (error) {
+		// the list of relationships below the graph stays usable
+		logger.error("Failed to load the artist network", error);
+	}
+};
@@ -2,3 +2,5 @@ declare module "*.svg" {
 	const content: string;
 	export default content;
 }
+
+declare module "openseadragon";